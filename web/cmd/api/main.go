@@ -37,12 +37,16 @@ func main() {
 	r := router.New(application)
 
 	srv := server.New(cfg, r)
+	srv.OnShutdown(application.Services.LiveSessions.Shutdown)
 
 	log.Printf("Listening on %s", listenURL(cfg.HTTPAddr))
 
 	if err := srv.Start(ctx); err != nil {
 		log.Fatalf("server: %v", err)
 	}
+
+	// Let chord request emails already in flight finish before the pool closes.
+	application.Services.ChordRequests.Wait()
 }
 
 func listenURL(addr string) string {
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/lyricapp/lyric/web/internal/config"
+	accountsvc "github.com/lyricapp/lyric/web/internal/services/account"
+	"github.com/lyricapp/lyric/web/internal/storage/postgres"
+	accountrepo "github.com/lyricapp/lyric/web/internal/storage/postgres/account"
+)
+
+// The purge command permanently removes accounts that were deleted and not
+// restored within the grace period. Run it on a schedule, e.g. daily from cron.
+func main() {
+	log.SetFlags(log.LstdFlags | log.Lmicroseconds)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+	defer cancel()
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("config: %v", err)
+	}
+
+	pool, err := postgres.Connect(ctx, cfg.Database)
+	if err != nil {
+		log.Fatalf("database: %v", err)
+	}
+	defer pool.Close()
+
+	purged, err := accountsvc.NewService(accountrepo.NewRepository(pool)).Purge(ctx)
+	if err != nil {
+		log.Fatalf("purge: %v", err)
+	}
+	log.Printf("purge: removed %d accounts", purged)
+}
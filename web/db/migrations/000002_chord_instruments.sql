@@ -0,0 +1,15 @@
+--bun:split
+
+alter table chord_positions
+    add column if not exists instrument varchar(20) not null default 'guitar'
+        check (instrument in ('guitar', 'ukulele', 'mandolin', 'bass', 'piano'));
+
+--bun:split
+
+alter table chord_positions
+    add column if not exists keys jsonb;
+
+--bun:split
+
+create index if not exists chord_positions_chord_id_instrument_idx
+    on chord_positions (chord_id, instrument);
@@ -0,0 +1,10 @@
+--bun:split
+
+alter table chords
+    add column if not exists canonical_name varchar(100);
+
+--bun:split
+
+create unique index if not exists chords_canonical_name_idx
+    on chords (canonical_name)
+    where canonical_name is not null;
@@ -0,0 +1,39 @@
+--bun:split
+
+create table if not exists chord_requests (
+    id serial primary key,
+    name varchar(100) not null,
+    status varchar(20) not null check (status in ('open', 'fulfilled')) default 'open',
+    chord_id int,
+    fulfilled_at timestamp,
+    created_at timestamp not null default now(),
+    updated_at timestamp not null default now(),
+    foreign key (chord_id) references chords(id) on delete set null
+);
+
+--bun:split
+
+create unique index if not exists chord_requests_open_name_idx
+    on chord_requests (name)
+    where status = 'open';
+
+--bun:split
+
+create trigger update_chord_requests_updated_at
+before update on chord_requests
+for each row
+execute procedure update_updated_at_column();
+
+--bun:split
+
+create table if not exists chord_request_votes (
+    chord_request_id int not null,
+    user_id int not null,
+    song_id int,
+    notified_at timestamp,
+    created_at timestamp not null default now(),
+    primary key (chord_request_id, user_id),
+    foreign key (chord_request_id) references chord_requests(id) on delete cascade,
+    foreign key (user_id) references users(id) on delete cascade,
+    foreign key (song_id) references songs(id) on delete set null
+);
@@ -0,0 +1,5 @@
+--bun:split
+
+alter table users
+    add column if not exists plan varchar(20) not null default 'free'
+        check (plan in ('free', 'premium'));
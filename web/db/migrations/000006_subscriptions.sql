@@ -0,0 +1,28 @@
+--bun:split
+
+create table if not exists subscriptions (
+    id serial primary key,
+    user_id int not null,
+    provider varchar(20) not null check (provider in ('app_store', 'google_play', 'stub')),
+    product_id varchar(100) not null,
+    kind varchar(20) not null check (kind in ('subscription', 'unlock')),
+    transaction_id varchar(255) not null,
+    starts_at timestamp not null,
+    expires_at timestamp not null,
+    created_at timestamp not null default now(),
+    updated_at timestamp not null default now(),
+    unique (provider, transaction_id),
+    foreign key (user_id) references users(id) on delete cascade
+);
+
+--bun:split
+
+create index if not exists subscriptions_user_id_expires_at_idx
+    on subscriptions (user_id, expires_at);
+
+--bun:split
+
+create trigger update_subscriptions_updated_at
+before update on subscriptions
+for each row
+execute procedure update_updated_at_column();
@@ -0,0 +1,21 @@
+--bun:split
+
+alter table playlist_song
+    add column if not exists position int not null default 0;
+
+--bun:split
+
+update playlist_song ps
+set position = ordered.position
+from (
+    select playlist_id, song_id,
+        row_number() over (partition by playlist_id order by created_at, song_id) as position
+    from playlist_song
+) ordered
+where ordered.playlist_id = ps.playlist_id
+  and ordered.song_id = ps.song_id;
+
+--bun:split
+
+create index if not exists playlist_song_playlist_id_position_idx
+    on playlist_song (playlist_id, position);
@@ -0,0 +1,10 @@
+--bun:split
+
+alter table playlist_song
+    add column if not exists transpose smallint not null default 0
+        check (transpose between -11 and 11),
+    add column if not exists capo smallint not null default 0
+        check (capo between 0 and 12),
+    add column if not exists display_mode varchar(20)
+        check (display_mode in ('overlay', 'inline', 'lyric')),
+    add column if not exists note varchar(280);
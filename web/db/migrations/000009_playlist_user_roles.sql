@@ -0,0 +1,18 @@
+--bun:split
+
+delete from playlist_user pu
+using playlist_user dup
+where pu.playlist_id = dup.playlist_id
+  and pu.user_id = dup.user_id
+  and pu.ctid > dup.ctid;
+
+--bun:split
+
+alter table playlist_user
+    add column if not exists role varchar(20) not null default 'viewer'
+        check (role in ('viewer', 'editor'));
+
+--bun:split
+
+create unique index if not exists playlist_user_playlist_id_user_id_idx
+    on playlist_user (playlist_id, user_id);
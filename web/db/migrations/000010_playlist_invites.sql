@@ -0,0 +1,29 @@
+--bun:split
+
+create table if not exists playlist_invites (
+    id serial primary key,
+    playlist_id int not null,
+    created_by int not null,
+    token_hash varchar(64) not null unique,
+    role varchar(20) not null default 'viewer' check (role in ('viewer', 'editor')),
+    max_uses int check (max_uses > 0),
+    uses int not null default 0,
+    expires_at timestamp not null,
+    revoked_at timestamp,
+    created_at timestamp not null default now(),
+    updated_at timestamp not null default now(),
+    foreign key (playlist_id) references playlists(id) on delete cascade,
+    foreign key (created_by) references users(id) on delete cascade
+);
+
+--bun:split
+
+create index if not exists playlist_invites_playlist_id_idx
+    on playlist_invites (playlist_id);
+
+--bun:split
+
+create trigger update_playlist_invites_updated_at
+before update on playlist_invites
+for each row
+execute procedure update_updated_at_column();
@@ -0,0 +1,10 @@
+--bun:split
+alter table songs
+    add column if not exists bpm smallint
+        check (bpm between 20 and 300),
+    add column if not exists time_signature varchar(5)
+        check (time_signature ~ '^[0-9]{1,2}/(1|2|4|8|16|32)$'),
+    add column if not exists duration_seconds integer
+        check (duration_seconds between 1 and 3600),
+    add column if not exists capo smallint
+        check (capo between 0 and 12);
@@ -0,0 +1,163 @@
+--bun:split
+
+create table if not exists sync_tombstones (
+    id bigserial primary key,
+    entity varchar(20) not null,
+    entity_id int not null,
+    user_id int,
+    deleted_at timestamp not null default now()
+);
+
+--bun:split
+
+create index if not exists sync_tombstones_deleted_at_idx
+    on sync_tombstones (deleted_at, id);
+
+--bun:split
+
+create or replace function record_sync_tombstone()
+returns trigger as $$
+begin
+    insert into sync_tombstones (entity, entity_id) values (tg_argv[0], old.id);
+    return old;
+end;
+$$ language 'plpgsql';
+
+--bun:split
+
+create trigger songs_sync_tombstone
+after delete on songs
+for each row
+execute procedure record_sync_tombstone('songs');
+
+--bun:split
+
+create trigger artists_sync_tombstone
+after delete on artists
+for each row
+execute procedure record_sync_tombstone('artists');
+
+--bun:split
+
+create trigger albums_sync_tombstone
+after delete on albums
+for each row
+execute procedure record_sync_tombstone('albums');
+
+--bun:split
+
+create trigger writers_sync_tombstone
+after delete on writers
+for each row
+execute procedure record_sync_tombstone('writers');
+
+--bun:split
+
+create trigger languages_sync_tombstone
+after delete on languages
+for each row
+execute procedure record_sync_tombstone('languages');
+
+--bun:split
+
+create trigger levels_sync_tombstone
+after delete on levels
+for each row
+execute procedure record_sync_tombstone('levels');
+
+--bun:split
+
+-- Playlists are private, so their tombstones name the user who lost them: the
+-- owner when the playlist is deleted, a collaborator when they leave or are removed.
+create or replace function record_playlist_owner_tombstone()
+returns trigger as $$
+begin
+    insert into sync_tombstones (entity, entity_id, user_id) values ('playlists', old.id, old.user_id);
+    return old;
+end;
+$$ language 'plpgsql';
+
+--bun:split
+
+create trigger playlists_sync_tombstone
+after delete on playlists
+for each row
+execute procedure record_playlist_owner_tombstone();
+
+--bun:split
+
+create or replace function record_playlist_member_tombstone()
+returns trigger as $$
+begin
+    insert into sync_tombstones (entity, entity_id, user_id) values ('playlists', old.playlist_id, old.user_id);
+    return old;
+end;
+$$ language 'plpgsql';
+
+--bun:split
+
+create trigger playlist_user_sync_tombstone
+after delete on playlist_user
+for each row
+execute procedure record_playlist_member_tombstone();
+
+--bun:split
+
+-- Songs, members and arrangements are synced as part of their playlist, so any
+-- change to them marks the playlist as updated.
+create or replace function touch_playlist()
+returns trigger as $$
+begin
+    update playlists set updated_at = now()
+    where id = case when tg_op = 'DELETE' then old.playlist_id else new.playlist_id end;
+    return null;
+end;
+$$ language 'plpgsql';
+
+--bun:split
+
+create trigger playlist_song_touch_playlist
+after insert or update or delete on playlist_song
+for each row
+execute procedure touch_playlist();
+
+--bun:split
+
+create trigger playlist_user_touch_playlist
+after insert or update or delete on playlist_user
+for each row
+execute procedure touch_playlist();
+
+--bun:split
+
+-- Songs carry their artist, writer and album ids, so relinking them marks the
+-- song as updated.
+create or replace function touch_song()
+returns trigger as $$
+begin
+    update songs set updated_at = now()
+    where id = case when tg_op = 'DELETE' then old.song_id else new.song_id end;
+    return null;
+end;
+$$ language 'plpgsql';
+
+--bun:split
+
+create trigger artist_song_touch_song
+after insert or update or delete on artist_song
+for each row
+execute procedure touch_song();
+
+--bun:split
+
+create trigger song_writer_touch_song
+after insert or update or delete on song_writer
+for each row
+execute procedure touch_song();
+
+--bun:split
+
+create trigger album_song_touch_song
+after insert or update or delete on album_song
+for each row
+execute procedure touch_song();
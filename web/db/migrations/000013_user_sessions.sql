@@ -0,0 +1,33 @@
+--bun:split
+
+create table if not exists user_sessions (
+    id bigserial primary key,
+    user_id int not null,
+    device_name varchar(100),
+    user_agent varchar(255),
+    refresh_token_hash varchar(64) not null unique,
+    previous_token_hash varchar(64),
+    last_used_at timestamp not null default now(),
+    expires_at timestamp not null,
+    revoked_at timestamp,
+    created_at timestamp not null default now(),
+    updated_at timestamp not null default now(),
+    foreign key (user_id) references users(id) on delete cascade
+);
+
+--bun:split
+
+create index if not exists user_sessions_user_id_idx
+    on user_sessions (user_id);
+
+--bun:split
+
+create index if not exists user_sessions_previous_token_hash_idx
+    on user_sessions (previous_token_hash);
+
+--bun:split
+
+create trigger update_user_sessions_updated_at
+before update on user_sessions
+for each row
+execute procedure update_updated_at_column();
@@ -0,0 +1,26 @@
+--bun:split
+
+create table if not exists user_identities (
+    id serial primary key,
+    user_id int not null,
+    provider varchar(20) not null,
+    subject varchar(255) not null,
+    email varchar(255),
+    last_login_at timestamp not null default now(),
+    created_at timestamp not null default now(),
+    updated_at timestamp not null default now(),
+    unique (provider, subject),
+    foreign key (user_id) references users(id) on delete cascade
+);
+
+--bun:split
+
+create index if not exists user_identities_user_id_idx
+    on user_identities (user_id);
+
+--bun:split
+
+create trigger update_user_identities_updated_at
+before update on user_identities
+for each row
+execute procedure update_updated_at_column();
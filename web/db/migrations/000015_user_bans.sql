@@ -0,0 +1,7 @@
+--bun:split
+
+alter table users
+    add column if not exists ban_reason varchar(500),
+    add column if not exists banned_at timestamp,
+    add column if not exists banned_by int references users(id) on delete set null;
+
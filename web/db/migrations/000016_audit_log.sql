@@ -0,0 +1,40 @@
+--bun:split
+
+create table if not exists audit_log (
+    id bigserial primary key,
+    actor_id int,
+    actor_email varchar(100),
+    action varchar(50) not null,
+    entity_type varchar(50) not null,
+    entity_id varchar(50) not null,
+    before jsonb,
+    after jsonb,
+    request_id varchar(100),
+    created_at timestamp not null default now()
+);
+
+--bun:split
+
+create index if not exists audit_log_entity_idx
+    on audit_log (entity_type, entity_id);
+
+--bun:split
+
+create index if not exists audit_log_actor_id_idx
+    on audit_log (actor_id);
+
+--bun:split
+
+create or replace function prevent_audit_log_changes()
+returns trigger as $$
+begin
+    raise exception 'audit_log is append-only';
+end;
+$$ language 'plpgsql';
+
+--bun:split
+
+create trigger audit_log_append_only
+before update or delete on audit_log
+for each row
+execute procedure prevent_audit_log_changes();
@@ -0,0 +1,13 @@
+--bun:split
+
+alter table users
+    add column if not exists purge_after timestamp;
+
+--bun:split
+
+create index if not exists users_purge_after_idx on users (purge_after) where purge_after is not null;
+
+--bun:split
+
+-- Accounts deleted before the grace period existed are purged on the next run.
+update users set purge_after = now() where status = 'deleted' and purge_after is null;
@@ -0,0 +1,21 @@
+--bun:split
+
+create table if not exists user_preferences (
+    user_id int primary key,
+    theme varchar(10) not null default 'system' check (theme in ('system', 'light', 'dark')),
+    language varchar(2) not null default 'en' check (language in ('en', 'mm')),
+    font_size smallint not null default 14 check (font_size between 12 and 28),
+    display_mode varchar(10) not null default 'overlay' check (display_mode in ('overlay', 'inline', 'lyric')),
+    instrument varchar(20) not null default 'guitar' check (instrument in ('guitar', 'ukulele', 'mandolin', 'bass', 'piano')),
+    scroll_speed smallint not null default 30 check (scroll_speed between 1 and 100),
+    created_at timestamp not null default now(),
+    updated_at timestamp not null default now(),
+    foreign key (user_id) references users(id) on delete cascade
+);
+
+--bun:split
+
+create trigger update_user_preferences_updated_at
+before update on user_preferences
+for each row
+execute procedure update_updated_at_column();
@@ -0,0 +1,39 @@
+--bun:split
+
+create table if not exists favorite_songs (
+    user_id int not null,
+    song_id int not null,
+    created_at timestamp not null default now(),
+    primary key (user_id, song_id),
+    foreign key (user_id) references users(id) on delete cascade,
+    foreign key (song_id) references songs(id) on delete cascade
+);
+
+--bun:split
+
+create table if not exists favorite_albums (
+    user_id int not null,
+    album_id int not null,
+    created_at timestamp not null default now(),
+    primary key (user_id, album_id),
+    foreign key (user_id) references users(id) on delete cascade,
+    foreign key (album_id) references albums(id) on delete cascade
+);
+
+--bun:split
+
+create table if not exists favorite_artists (
+    user_id int not null,
+    artist_id int not null,
+    created_at timestamp not null default now(),
+    primary key (user_id, artist_id),
+    foreign key (user_id) references users(id) on delete cascade,
+    foreign key (artist_id) references artists(id) on delete cascade
+);
+
+--bun:split
+
+-- Recently viewed songs are read from the user's own plays.
+create index if not exists plays_user_id_created_at_idx
+    on plays (user_id, created_at desc)
+    where user_id is not null;
@@ -0,0 +1,93 @@
+--bun:split
+
+-- Each user keeps one difficulty vote per song: their most recent one.
+delete from level_song ls
+using level_song newer
+where ls.song_id = newer.song_id
+  and ls.user_id = newer.user_id
+  and (ls.updated_at, ls.id) < (newer.updated_at, newer.id);
+
+--bun:split
+
+create unique index if not exists level_song_song_id_user_id_idx
+    on level_song (song_id, user_id);
+
+--bun:split
+
+alter table songs
+    add column if not exists consensus_level_id int references levels(id) on delete set null,
+    add column if not exists level_votes int not null default 0;
+
+--bun:split
+
+create index if not exists songs_consensus_level_id_idx
+    on songs (consensus_level_id)
+    where consensus_level_id is not null;
+
+--bun:split
+
+-- The consensus is the weighted mode of the votes: editors and admins count
+-- twice, ties go to the level voted for most recently. Songs with fewer than
+-- three voters have no consensus. Keep in step with songs.MinLevelVotes.
+create or replace function refresh_level_consensus(target int)
+returns void as $$
+declare
+    voters int;
+    winner int;
+begin
+    select count(*) into voters from level_song where song_id = target;
+
+    select ls.level_id into winner
+    from level_song ls
+    join users u on u.id = ls.user_id
+    where ls.song_id = target
+    group by ls.level_id
+    order by sum(case when u.role in ('admin', 'editor') then 2 else 1 end) desc,
+             max(ls.updated_at) desc,
+             ls.level_id
+    limit 1;
+
+    if voters < 3 then
+        winner := null;
+    end if;
+
+    update songs
+    set consensus_level_id = winner,
+        level_votes = voters
+    where id = target
+      and (consensus_level_id is distinct from winner or level_votes <> voters);
+end;
+$$ language 'plpgsql';
+
+--bun:split
+
+create or replace function level_song_refresh_consensus()
+returns trigger as $$
+begin
+    if tg_op in ('UPDATE', 'DELETE') then
+        perform refresh_level_consensus(old.song_id);
+    end if;
+    if tg_op = 'INSERT' or (tg_op = 'UPDATE' and new.song_id <> old.song_id) then
+        perform refresh_level_consensus(new.song_id);
+    end if;
+    return null;
+end;
+$$ language 'plpgsql';
+
+--bun:split
+
+create trigger level_song_consensus
+after insert or update or delete on level_song
+for each row
+execute procedure level_song_refresh_consensus();
+
+--bun:split
+
+select refresh_level_consensus(id) from songs where exists (select 1 from level_song ls where ls.song_id = songs.id);
+
+--bun:split
+
+-- Trending charts filter their songs by the author's level or the consensus.
+alter table trending_songs
+    add column if not exists level_source varchar(20) not null default 'author'
+        check (level_source in ('author', 'consensus'));
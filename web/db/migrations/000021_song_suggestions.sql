@@ -0,0 +1,38 @@
+--bun:split
+
+create table if not exists song_suggestions (
+    id serial primary key,
+    song_id int not null,
+    user_id int not null,
+    -- The song's lyric when the suggestion was made, to tell when it went stale.
+    base_lyric text,
+    lyric text not null,
+    comment varchar(1000) not null,
+    status varchar(20) not null check (status in ('pending', 'accepted', 'rejected')) default 'pending',
+    reviewed_by int,
+    review_note varchar(1000),
+    reviewed_at timestamp,
+    notified_at timestamp,
+    created_at timestamp not null default now(),
+    updated_at timestamp not null default now(),
+    foreign key (song_id) references songs(id) on delete cascade,
+    foreign key (user_id) references users(id) on delete cascade,
+    foreign key (reviewed_by) references users(id) on delete set null
+);
+
+--bun:split
+
+create index if not exists song_suggestions_song_id_status_idx
+    on song_suggestions (song_id, status, created_at desc);
+
+--bun:split
+
+create index if not exists song_suggestions_user_id_idx
+    on song_suggestions (user_id);
+
+--bun:split
+
+create trigger update_song_suggestions_updated_at
+before update on song_suggestions
+for each row
+execute procedure update_updated_at_column();
@@ -0,0 +1,6 @@
+--bun:split
+
+-- Legacy chords are given canonical names by the migrate command's Go step
+-- for this file, which runs first in the same transaction.
+alter table chords
+    alter column canonical_name set not null;
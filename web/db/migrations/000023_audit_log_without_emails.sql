@@ -0,0 +1,48 @@
+--bun:split
+
+-- Audit entries name their actor by id only, so a purged account leaves no
+-- email behind. Existing entries lose the copied email and the emails held in
+-- their snapshots; this is the one change the append-only trigger lets through.
+create or replace function audit_strip_emails(value jsonb)
+returns jsonb as $$
+begin
+    case jsonb_typeof(value)
+    when 'object' then
+        return (
+            select coalesce(jsonb_object_agg(e.key, audit_strip_emails(e.value)), '{}'::jsonb)
+            from jsonb_each(value) e
+            where e.key <> 'email'
+        );
+    when 'array' then
+        return (
+            select coalesce(jsonb_agg(audit_strip_emails(a.value) order by a.position), '[]'::jsonb)
+            from jsonb_array_elements(value) with ordinality a(value, position)
+        );
+    else
+        return value;
+    end case;
+end;
+$$ language 'plpgsql' immutable;
+
+--bun:split
+
+alter table audit_log disable trigger audit_log_append_only;
+
+--bun:split
+
+update audit_log
+set before = audit_strip_emails(before),
+    after = audit_strip_emails(after)
+where before is not null or after is not null;
+
+--bun:split
+
+alter table audit_log enable trigger audit_log_append_only;
+
+--bun:split
+
+alter table audit_log drop column if exists actor_email;
+
+--bun:split
+
+drop function audit_strip_emails(jsonb);
@@ -0,0 +1,21 @@
+--bun:split
+
+-- Votes are weighted by the voter's current role, so a role change refreshes
+-- the consensus of every song the user voted on.
+create or replace function users_refresh_level_consensus()
+returns trigger as $$
+begin
+    perform refresh_level_consensus(ls.song_id)
+    from level_song ls
+    where ls.user_id = new.id;
+    return null;
+end;
+$$ language 'plpgsql';
+
+--bun:split
+
+create trigger users_level_consensus
+after update of role on users
+for each row
+when (old.role is distinct from new.role)
+execute procedure users_refresh_level_consensus();
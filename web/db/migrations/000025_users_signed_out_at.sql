@@ -0,0 +1,7 @@
+--bun:split
+
+-- When an admin last signed the user out of every device. Credentials that
+-- carry no device session, admin cookies and access tokens from before
+-- sessions, are checked against it.
+alter table users
+    add column if not exists signed_out_at timestamp;
@@ -61,11 +61,11 @@ values
     ('Midweek Focus', (select id from levels where name = 'medium'), 'Moderate arrangements teams'),
     ('Advanced Picks', (select id from levels where name = 'hard'), 'Challenging selections by players');
 
-insert into chords (name)
+insert into chords (name, canonical_name)
 values
-    ('C'),
-    ('G'),
-    ('D');
+    ('C', 'C'),
+    ('G', 'G'),
+    ('D', 'D');
 
 insert into chord_positions (chord_id, base_fret, frets, fingers)
 values
@@ -1,39 +1,68 @@
 package app
 
 import (
+	"context"
+	"log"
+	"os"
 	"strings"
+	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	adminsession "github.com/lyricapp/lyric/web/internal/auth/admin"
 	"github.com/lyricapp/lyric/web/internal/config"
+	accountsvc "github.com/lyricapp/lyric/web/internal/services/account"
 	adminauthsvc "github.com/lyricapp/lyric/web/internal/services/adminauth"
 	albumsvc "github.com/lyricapp/lyric/web/internal/services/albums"
 	artistsvc "github.com/lyricapp/lyric/web/internal/services/artists"
+	auditsvc "github.com/lyricapp/lyric/web/internal/services/audit"
+	chordrequestsvc "github.com/lyricapp/lyric/web/internal/services/chordrequests"
 	chordsvc "github.com/lyricapp/lyric/web/internal/services/chords"
+	deltasyncsvc "github.com/lyricapp/lyric/web/internal/services/deltasync"
 	feedbacksvc "github.com/lyricapp/lyric/web/internal/services/feedback"
 	healthsvc "github.com/lyricapp/lyric/web/internal/services/health"
 	languagesvc "github.com/lyricapp/lyric/web/internal/services/languages"
 	levelsvc "github.com/lyricapp/lyric/web/internal/services/levels"
+	librarysvc "github.com/lyricapp/lyric/web/internal/services/library"
+	livesessionsvc "github.com/lyricapp/lyric/web/internal/services/livesessions"
 	loginsvc "github.com/lyricapp/lyric/web/internal/services/login"
+	permissionsvc "github.com/lyricapp/lyric/web/internal/services/permissions"
+	plansvc "github.com/lyricapp/lyric/web/internal/services/plans"
+	playlistinvitesvc "github.com/lyricapp/lyric/web/internal/services/playlistinvites"
 	playlistsvc "github.com/lyricapp/lyric/web/internal/services/playlists"
+	preferencesvc "github.com/lyricapp/lyric/web/internal/services/preferences"
 	releaseyearsvc "github.com/lyricapp/lyric/web/internal/services/releaseyear"
 	songsvc "github.com/lyricapp/lyric/web/internal/services/songs"
+	subscriptionsvc "github.com/lyricapp/lyric/web/internal/services/subscriptions"
+	suggestionsvc "github.com/lyricapp/lyric/web/internal/services/suggestions"
 	trendingsvc "github.com/lyricapp/lyric/web/internal/services/trending"
 	usersvc "github.com/lyricapp/lyric/web/internal/services/users"
 	writersvc "github.com/lyricapp/lyric/web/internal/services/writers"
+	"github.com/lyricapp/lyric/web/internal/storage"
+	accountrepo "github.com/lyricapp/lyric/web/internal/storage/postgres/account"
 	adminrepo "github.com/lyricapp/lyric/web/internal/storage/postgres/admin"
 	albumrepo "github.com/lyricapp/lyric/web/internal/storage/postgres/albums"
 	artistrepo "github.com/lyricapp/lyric/web/internal/storage/postgres/artists"
+	auditrepo "github.com/lyricapp/lyric/web/internal/storage/postgres/audit"
+	chordrequestrepo "github.com/lyricapp/lyric/web/internal/storage/postgres/chordrequests"
 	chordrepo "github.com/lyricapp/lyric/web/internal/storage/postgres/chords"
+	deltasyncrepo "github.com/lyricapp/lyric/web/internal/storage/postgres/deltasync"
 	feedbackrepo "github.com/lyricapp/lyric/web/internal/storage/postgres/feedback"
 	healthrepo "github.com/lyricapp/lyric/web/internal/storage/postgres/health"
 	languagerepo "github.com/lyricapp/lyric/web/internal/storage/postgres/languages"
 	levelrepo "github.com/lyricapp/lyric/web/internal/storage/postgres/levels"
+	libraryrepo "github.com/lyricapp/lyric/web/internal/storage/postgres/library"
+	livesessionrepo "github.com/lyricapp/lyric/web/internal/storage/postgres/livesessions"
 	loginrepo "github.com/lyricapp/lyric/web/internal/storage/postgres/login"
+	permissionrepo "github.com/lyricapp/lyric/web/internal/storage/postgres/permissions"
+	planrepo "github.com/lyricapp/lyric/web/internal/storage/postgres/plans"
+	playlistinviterepo "github.com/lyricapp/lyric/web/internal/storage/postgres/playlistinvites"
 	playlistrepo "github.com/lyricapp/lyric/web/internal/storage/postgres/playlists"
+	preferencerepo "github.com/lyricapp/lyric/web/internal/storage/postgres/preferences"
 	releaseyearrepo "github.com/lyricapp/lyric/web/internal/storage/postgres/releaseyear"
 	songrepo "github.com/lyricapp/lyric/web/internal/storage/postgres/songs"
+	subscriptionrepo "github.com/lyricapp/lyric/web/internal/storage/postgres/subscriptions"
+	suggestionrepo "github.com/lyricapp/lyric/web/internal/storage/postgres/suggestions"
 	trendingrepo "github.com/lyricapp/lyric/web/internal/storage/postgres/trending"
 	usersrepo "github.com/lyricapp/lyric/web/internal/storage/postgres/users"
 	writerrepo "github.com/lyricapp/lyric/web/internal/storage/postgres/writers"
@@ -49,33 +78,49 @@ type Application struct {
 
 // Services aggregates domain services for easier handler composition.
 type Services struct {
-	Health      healthsvc.Service
-	Songs       songsvc.Service
-	Albums      albumsvc.Service
-	Artists     artistsvc.Service
-	Writers     writersvc.Service
-	ReleaseYear releaseyearsvc.Service
-	Playlists   playlistsvc.Service
-	Trendings   trendingsvc.Service
-	Chords      chordsvc.Service
-	Feedback    feedbacksvc.Service
-	AdminAuth   adminauthsvc.Service
-	Levels      levelsvc.Service
-	Languages   languagesvc.Service
-	Login       loginsvc.Service
-	Users       usersvc.Service
+	Health        healthsvc.Service
+	Songs         songsvc.Service
+	Albums        albumsvc.Service
+	Artists       artistsvc.Service
+	Writers       writersvc.Service
+	ReleaseYear   releaseyearsvc.Service
+	Playlists     playlistsvc.Service
+	Invites       playlistinvitesvc.Service
+	LiveSessions  livesessionsvc.Service
+	Sync          deltasyncsvc.Service
+	Plans         plansvc.Service
+	Subscriptions subscriptionsvc.Service
+	Trendings     trendingsvc.Service
+	Chords        chordsvc.Service
+	ChordRequests chordrequestsvc.Service
+	Feedback      feedbacksvc.Service
+	AdminAuth     adminauthsvc.Service
+	Levels        levelsvc.Service
+	Languages     languagesvc.Service
+	Login         loginsvc.Service
+	Users         usersvc.Service
+	Permissions   permissionsvc.Service
+	Audit         auditsvc.Service
+	Account       accountsvc.Service
+	Preferences   preferencesvc.Service
+	Library       librarysvc.Service
+	Suggestions   suggestionsvc.Service
 }
 
+// syncSettle keeps delta sync passes behind write transactions still in flight.
+const syncSettle = 10 * time.Second
+
 // New constructs a new Application instance with default implementations.
 func New(cfg config.Config, db *pgxpool.Pool) *Application {
-	songRepository := songrepo.NewRepository(db)
 	albumRepository := albumrepo.NewRepository(db)
 	artistRepository := artistrepo.NewRepository(db)
 	writerRepository := writerrepo.NewRepository(db)
 	releaseYearRepository := releaseyearrepo.NewRepository(db)
 	playlistRepository := playlistrepo.NewRepository(db)
+	playlistInviteRepository := playlistinviterepo.NewRepository(db)
 	trendingRepository := trendingrepo.NewRepository(db)
 	chordRepository := chordrepo.NewRepository(db)
+	chordRequestRepository := chordrequestrepo.NewRepository(db)
 	feedbackRepository := feedbackrepo.NewRepository(db)
 	healthRepository := healthrepo.NewRepository(db)
 	adminRepository := adminrepo.NewRepository(db)
@@ -83,6 +128,9 @@ func New(cfg config.Config, db *pgxpool.Pool) *Application {
 	languageRepository := languagerepo.NewRepository(db)
 	loginRepository := loginrepo.NewRepository(db)
 	userRepository := usersrepo.NewRepository(db)
+	planRepository := planrepo.NewRepository(db)
+	subscriptionRepository := subscriptionrepo.NewRepository(db)
+	syncRepository := deltasyncrepo.NewRepository(db)
 
 	adminSessions := adminsession.NewManager(
 		cfg.Admin.SessionCookie,
@@ -91,50 +139,154 @@ func New(cfg config.Config, db *pgxpool.Pool) *Application {
 		cfg.Admin.SessionSecure,
 	)
 
-	var loginMailer loginsvc.Mailer
+	var (
+		loginMailer          loginsvc.Mailer
+		chordRequestNotifier chordrequestsvc.Notifier
+		suggestionNotifier   suggestionsvc.Notifier
+	)
 	if strings.EqualFold(cfg.Api.AppEnv, "production") && cfg.Auth.SMTP.Host != "" && cfg.Auth.SMTP.From != "" {
-		loginMailer = loginsvc.NewSMTPMailer(loginsvc.SMTPSettings{
+		smtpSettings := loginsvc.SMTPSettings{
 			Host:     cfg.Auth.SMTP.Host,
 			Port:     cfg.Auth.SMTP.Port,
 			Username: cfg.Auth.SMTP.Username,
 			Password: cfg.Auth.SMTP.Password,
 			From:     cfg.Auth.SMTP.From,
-		})
+		}
+		loginMailer = loginsvc.NewSMTPMailer(smtpSettings)
+		chordRequestNotifier = chordrequestsvc.NewSMTPNotifier(smtpSettings)
+		suggestionNotifier = suggestionsvc.NewSMTPNotifier(smtpSettings)
 	} else {
 		loginMailer = loginsvc.NewConsoleMailer(cfg.Auth.SMTP.From)
+		chordRequestNotifier = chordrequestsvc.NewConsoleNotifier(cfg.Auth.SMTP.From)
+		suggestionNotifier = suggestionsvc.NewConsoleNotifier(cfg.Auth.SMTP.From)
+	}
+
+	// Accepted suggestions build the songs service on their transaction, so
+	// wiring added here applies to them too.
+	newSongService := func(db storage.Querier) songsvc.Service {
+		return songsvc.NewService(songrepo.NewRepository(db))
+	}
+	songService := newSongService(db)
+	suggestionTx := func(ctx context.Context, fn func(suggestionsvc.Repository, suggestionsvc.Songs) error) error {
+		return storage.InTx(ctx, db, func(tx storage.Querier) error {
+			return fn(suggestionrepo.NewRepository(tx), newSongService(tx))
+		})
 	}
+	chordRequestService := chordrequestsvc.NewService(chordRequestRepository, chordRequestNotifier)
+	planService := plansvc.NewService(planRepository, plansvc.Config{
+		Free:    plansvc.Limits{Playlists: cfg.Plans.FreePlaylists, Shares: cfg.Plans.FreeShares},
+		Premium: plansvc.Limits{Playlists: cfg.Plans.PremiumPlaylists, Shares: cfg.Plans.PremiumShares},
+	})
+	subscriptionService := subscriptionsvc.NewService(subscriptionRepository, planService, purchaseProviders(cfg)...)
+	inviteService := playlistinvitesvc.NewService(playlistInviteRepository, planService, playlistinvitesvc.Config{
+		BaseURL: cfg.Api.FrontendUrl,
+	})
 
 	loginService := loginsvc.NewService(
 		loginRepository,
 		loginMailer,
 		loginsvc.Config{
-			CodeLength:  cfg.Auth.OTPLength,
-			TTL:         cfg.Auth.OTPTTL,
-			TokenSecret: cfg.Auth.TokenSecret,
-			TokenTTL:    cfg.Auth.TokenTTL,
+			CodeLength:    cfg.Auth.OTPLength,
+			TTL:           cfg.Auth.OTPTTL,
+			TokenSecret:   cfg.Auth.TokenSecret,
+			TokenTTL:      cfg.Auth.TokenTTL,
+			RefreshTTL:    cfg.Auth.RefreshTTL,
+			DeletionGrace: cfg.Auth.DeletionGrace,
 		},
+		identityProviders(cfg)...,
 	)
 
 	return &Application{
 		Config: cfg,
 		DB:     db,
 		Services: Services{
-			Health:      healthsvc.NewService(healthRepository),
-			Songs:       songsvc.NewService(songRepository),
-			Albums:      albumsvc.NewService(albumRepository),
-			Artists:     artistsvc.NewService(artistRepository),
-			Writers:     writersvc.NewService(writerRepository),
-			ReleaseYear: releaseyearsvc.NewService(releaseYearRepository),
-			Playlists:   playlistsvc.NewService(playlistRepository),
-			Trendings:   trendingsvc.NewService(trendingRepository),
-			Chords:      chordsvc.NewService(chordRepository),
-			Feedback:    feedbacksvc.NewService(feedbackRepository),
-			AdminAuth:    adminauthsvc.NewService(adminRepository),
-			Levels:      levelsvc.NewService(levelRepository),
-			Languages:   languagesvc.NewService(languageRepository),
-			Login:       loginService,
-			Users:       usersvc.NewService(userRepository),
+			Health:        healthsvc.NewService(healthRepository),
+			Songs:         songService,
+			Albums:        albumsvc.NewService(albumRepository),
+			Artists:       artistsvc.NewService(artistRepository),
+			Writers:       writersvc.NewService(writerRepository),
+			ReleaseYear:   releaseyearsvc.NewService(releaseYearRepository),
+			Playlists:     playlistsvc.NewService(playlistRepository, planService),
+			Invites:       inviteService,
+			LiveSessions:  livesessionsvc.NewService(livesessionrepo.NewRepository(db), livesessionsvc.NewLocalFanOut()),
+			Sync:          deltasyncsvc.NewService(syncRepository, deltasyncsvc.Config{Settle: syncSettle}),
+			Plans:         planService,
+			Subscriptions: subscriptionService,
+			Trendings:     trendingsvc.NewService(trendingRepository),
+			Chords:        chordsvc.NewService(chordRepository, chordRequestService),
+			ChordRequests: chordRequestService,
+			Feedback:      feedbacksvc.NewService(feedbackRepository),
+			AdminAuth:     adminauthsvc.NewService(adminRepository),
+			Levels:        levelsvc.NewService(levelRepository),
+			Languages:     languagesvc.NewService(languageRepository),
+			Login:         loginService,
+			Users:         usersvc.NewService(userRepository),
+			Permissions:   permissionsvc.NewService(permissionrepo.NewRepository(db)),
+			Audit:         auditsvc.NewService(auditrepo.NewRepository(db)),
+			Account:       accountsvc.NewService(accountrepo.NewRepository(db)),
+			Preferences:   preferencesvc.NewService(preferencerepo.NewRepository(db)),
+			Library:       librarysvc.NewService(libraryrepo.NewRepository(db)),
+			Suggestions:   suggestionsvc.NewService(suggestionrepo.NewRepository(db), songService, suggestionNotifier, suggestionTx),
 		},
 		AdminSessions: adminSessions,
 	}
 }
+
+// identityProviders returns the OpenID Connect providers users may sign in with,
+// those with client ids configured.
+func identityProviders(cfg config.Config) []loginsvc.IdentityProvider {
+	var providers []loginsvc.IdentityProvider
+	add := func(name string, oidc config.OIDCConfig, issuers []string, jwksURL string) {
+		if len(oidc.ClientIDs) == 0 {
+			return
+		}
+		if oidc.Issuer != "" {
+			issuers, jwksURL = []string{oidc.Issuer}, ""
+		}
+		if oidc.JWKSURL != "" {
+			jwksURL = oidc.JWKSURL
+		}
+		providers = append(providers, loginsvc.NewOIDCProvider(loginsvc.OIDCSettings{
+			Name:      name,
+			Issuers:   issuers,
+			JWKSURL:   jwksURL,
+			ClientIDs: oidc.ClientIDs,
+		}))
+	}
+	add(loginsvc.ProviderGoogle, cfg.Auth.Google, loginsvc.GoogleIssuers, loginsvc.GoogleJWKSURL)
+	add(loginsvc.ProviderApple, cfg.Auth.Apple, loginsvc.AppleIssuers, loginsvc.AppleJWKSURL)
+	return providers
+}
+
+// purchaseProviders returns the store verifiers enabled by configuration. The stub
+// provider grants premium for any "stub:" receipt, so it is only turned on by an
+// explicit WEB_IAP_STUB_ENABLED and never inferred from APP_ENV.
+func purchaseProviders(cfg config.Config) []subscriptionsvc.Provider {
+	var providers []subscriptionsvc.Provider
+	if cfg.Purchases.StubEnabled {
+		log.Printf("WARNING: stub purchase provider enabled; any %q receipt grants premium", subscriptionsvc.StubReceiptPrefix)
+		providers = append(providers, subscriptionsvc.NewStubProvider())
+	}
+	if cfg.Purchases.AppStoreSharedSecret != "" {
+		providers = append(providers, subscriptionsvc.NewAppStoreProvider(subscriptionsvc.AppStoreSettings{
+			SharedSecret: cfg.Purchases.AppStoreSharedSecret,
+		}))
+	}
+	if cfg.Purchases.GooglePlayPackageName != "" && cfg.Purchases.GooglePlayCredentialsFile != "" {
+		credentials, err := os.ReadFile(cfg.Purchases.GooglePlayCredentialsFile)
+		if err != nil {
+			log.Printf("google play purchases disabled: %v", err)
+			return providers
+		}
+		provider, err := subscriptionsvc.NewGooglePlayProvider(subscriptionsvc.GooglePlaySettings{
+			PackageName:     cfg.Purchases.GooglePlayPackageName,
+			CredentialsJSON: credentials,
+		})
+		if err != nil {
+			log.Printf("google play purchases disabled: %v", err)
+			return providers
+		}
+		providers = append(providers, provider)
+	}
+	return providers
+}
@@ -54,3 +54,10 @@ func Forbidden(message string) *AppError {
 func Internal(message string, err error) *AppError {
     return New(http.StatusInternalServerError, message, err)
 }
+func UpgradeRequired(message string, details map[string]string) *AppError {
+    return &AppError{
+        Status:  http.StatusPaymentRequired,
+        Message: message,
+        Details: details,
+    }
+}
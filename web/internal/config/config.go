@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	_ "github.com/joho/godotenv"
@@ -24,7 +25,11 @@ const (
 	defaultAuthOTPTTL         = 5 * time.Minute
 	defaultSMTPPort           = 587
 	defaultAuthTokenSecret    = "change-me"
-	defaultAuthTokenTTL       = 30 * 24 * time.Hour
+	defaultAuthTokenTTL       = 15 * time.Minute
+	defaultAuthRefreshTTL     = 60 * 24 * time.Hour
+	defaultAuthDeletionGrace  = 30 * 24 * time.Hour
+	defaultFreePlaylistLimit  = 3
+	defaultFreeShareLimit     = 3
 )
 
 // Config collects runtime configuration for the web service.
@@ -35,6 +40,8 @@ type Config struct {
 	Admin           AdminConfig
 	Api             ApiConfig
 	Auth            AuthConfig
+	Plans           PlansConfig
+	Purchases       PurchasesConfig
 }
 
 // DatabaseConfig holds PostgreSQL connection settings.
@@ -59,12 +66,45 @@ type ApiConfig struct {
 }
 
 // AuthConfig contains settings for login OTP generation and delivery.
+// TokenTTL is the access token lifetime; RefreshTTL is how long a device stays
+// signed in without refreshing. DeletionGrace is how long a deleted account
+// waits before it is purged.
 type AuthConfig struct {
-	OTPLength   int
-	OTPTTL      time.Duration
-	TokenSecret string
-	TokenTTL    time.Duration
-	SMTP        SMTPConfig
+	OTPLength     int
+	OTPTTL        time.Duration
+	TokenSecret   string
+	TokenTTL      time.Duration
+	RefreshTTL    time.Duration
+	DeletionGrace time.Duration
+	SMTP          SMTPConfig
+	Google        OIDCConfig
+	Apple         OIDCConfig
+}
+
+// OIDCConfig enables signing in with an OpenID Connect provider once ClientIDs
+// are set. Issuer and JWKSURL override the provider's own, e.g. to point at a
+// local issuer; the keys are discovered from the issuer when JWKSURL is unset.
+type OIDCConfig struct {
+	ClientIDs []string
+	Issuer    string
+	JWKSURL   string
+}
+
+// PlansConfig caps feature usage per plan. Zero means unlimited.
+type PlansConfig struct {
+	FreePlaylists    int
+	FreeShares       int
+	PremiumPlaylists int
+	PremiumShares    int
+}
+
+// PurchasesConfig holds store credentials used to verify in-app purchases.
+// A store is enabled only when its credentials are set.
+type PurchasesConfig struct {
+	AppStoreSharedSecret      string
+	GooglePlayPackageName     string
+	GooglePlayCredentialsFile string
+	StubEnabled               bool
 }
 
 // SMTPConfig encapsulates email transport configuration.
@@ -96,14 +136,20 @@ func Load() (Config, error) {
 			FrontendUrl: defaultFrontendUrl,
 		},
 		Auth: AuthConfig{
-			OTPLength:   defaultAuthOTPLength,
-			OTPTTL:      defaultAuthOTPTTL,
-			TokenSecret: defaultAuthTokenSecret,
-			TokenTTL:    defaultAuthTokenTTL,
+			OTPLength:     defaultAuthOTPLength,
+			OTPTTL:        defaultAuthOTPTTL,
+			TokenSecret:   defaultAuthTokenSecret,
+			TokenTTL:      defaultAuthTokenTTL,
+			RefreshTTL:    defaultAuthRefreshTTL,
+			DeletionGrace: defaultAuthDeletionGrace,
 			SMTP: SMTPConfig{
 				Port: defaultSMTPPort,
 			},
 		},
+		Plans: PlansConfig{
+			FreePlaylists: defaultFreePlaylistLimit,
+			FreeShares:    defaultFreeShareLimit,
+		},
 	}
 
 	if v, ok := os.LookupEnv("WEB_HTTP_ADDR"); ok && v != "" {
@@ -240,6 +286,84 @@ func Load() (Config, error) {
 		cfg.Auth.TokenTTL = d
 	}
 
+	if v, ok := os.LookupEnv("WEB_AUTH_REFRESH_TTL"); ok && v != "" {
+		d, err := parseDuration(v)
+		if err != nil {
+			return Config{}, fmt.Errorf("parse WEB_AUTH_REFRESH_TTL: %w", err)
+		}
+		cfg.Auth.RefreshTTL = d
+	}
+
+	if v, ok := os.LookupEnv("WEB_AUTH_DELETION_GRACE"); ok && v != "" {
+		d, err := parseDuration(v)
+		if err != nil {
+			return Config{}, fmt.Errorf("parse WEB_AUTH_DELETION_GRACE: %w", err)
+		}
+		cfg.Auth.DeletionGrace = d
+	}
+
+	providers := []struct {
+		prefix string
+		target *OIDCConfig
+	}{
+		{"WEB_OIDC_GOOGLE", &cfg.Auth.Google},
+		{"WEB_OIDC_APPLE", &cfg.Auth.Apple},
+	}
+	for _, provider := range providers {
+		if v, ok := os.LookupEnv(provider.prefix + "_CLIENT_IDS"); ok && v != "" {
+			for _, id := range strings.Split(v, ",") {
+				if id = strings.TrimSpace(id); id != "" {
+					provider.target.ClientIDs = append(provider.target.ClientIDs, id)
+				}
+			}
+		}
+		if v, ok := os.LookupEnv(provider.prefix + "_ISSUER"); ok && v != "" {
+			provider.target.Issuer = v
+		}
+		if v, ok := os.LookupEnv(provider.prefix + "_JWKS_URL"); ok && v != "" {
+			provider.target.JWKSURL = v
+		}
+	}
+
+	if v, ok := os.LookupEnv("WEB_IAP_APP_STORE_SHARED_SECRET"); ok {
+		cfg.Purchases.AppStoreSharedSecret = v
+	}
+
+	if v, ok := os.LookupEnv("WEB_IAP_GOOGLE_PLAY_PACKAGE"); ok {
+		cfg.Purchases.GooglePlayPackageName = v
+	}
+
+	if v, ok := os.LookupEnv("WEB_IAP_GOOGLE_PLAY_CREDENTIALS_FILE"); ok {
+		cfg.Purchases.GooglePlayCredentialsFile = v
+	}
+
+	if v, ok := os.LookupEnv("WEB_IAP_STUB_ENABLED"); ok && v != "" {
+		enabled, err := strconv.ParseBool(v)
+		if err != nil {
+			return Config{}, fmt.Errorf("parse WEB_IAP_STUB_ENABLED: %w", err)
+		}
+		cfg.Purchases.StubEnabled = enabled
+	}
+
+	limits := []struct {
+		key    string
+		target *int
+	}{
+		{"WEB_PLAN_FREE_PLAYLISTS", &cfg.Plans.FreePlaylists},
+		{"WEB_PLAN_FREE_SHARES", &cfg.Plans.FreeShares},
+		{"WEB_PLAN_PREMIUM_PLAYLISTS", &cfg.Plans.PremiumPlaylists},
+		{"WEB_PLAN_PREMIUM_SHARES", &cfg.Plans.PremiumShares},
+	}
+	for _, limit := range limits {
+		if v, ok := os.LookupEnv(limit.key); ok && v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 0 {
+				return Config{}, fmt.Errorf("parse %s: must be a non-negative integer", limit.key)
+			}
+			*limit.target = n
+		}
+	}
+
 	return cfg, nil
 }
 
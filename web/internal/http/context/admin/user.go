@@ -1,6 +1,10 @@
 package admin
 
-import "context"
+import (
+	"context"
+
+	"github.com/lyricapp/lyric/web/internal/services/permissions"
+)
 
 type userKey struct{}
 
@@ -11,6 +15,16 @@ type User struct {
 	Role     string
 }
 
+// Principal returns the user for permission checks.
+func (u User) Principal() permissions.Principal {
+	return permissions.Principal{UserID: u.ID, Role: permissions.Role(u.Role)}
+}
+
+// Can reports whether the user's role grants the capability.
+func (u User) Can(capability permissions.Capability) bool {
+	return u.Principal().Can(capability)
+}
+
 // WithUser stores the admin user on the provided context.
 func WithUser(ctx context.Context, user User) context.Context {
 	return context.WithValue(ctx, userKey{}, user)
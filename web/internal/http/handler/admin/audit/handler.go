@@ -0,0 +1,76 @@
+package audit
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/a-h/templ"
+
+	"github.com/lyricapp/lyric/web/internal/apperror"
+	adminctx "github.com/lyricapp/lyric/web/internal/http/context/admin"
+	auditsvc "github.com/lyricapp/lyric/web/internal/services/audit"
+	"github.com/lyricapp/lyric/web/internal/web/components"
+)
+
+const perPage = 50
+
+// Handler serves the audit log.
+type Handler struct {
+	audit auditsvc.Service
+}
+
+// New constructs an audit log admin handler.
+func New(audit auditsvc.Service) *Handler {
+	return &Handler{audit: audit}
+}
+
+// Index renders audit entries, newest first, filtered by actor, action and entity.
+func (h *Handler) Index(w http.ResponseWriter, r *http.Request) {
+	user, ok := adminctx.FromContext(r.Context())
+	if !ok {
+		http.Redirect(w, r, "/admin/login", http.StatusFound)
+		return
+	}
+
+	query := r.URL.Query()
+	page, _ := strconv.Atoi(query.Get("page"))
+	params := auditsvc.ListParams{
+		Actor:      query.Get("actor"),
+		Action:     query.Get("action"),
+		EntityType: query.Get("entity_type"),
+		EntityID:   query.Get("entity_id"),
+		Page:       page,
+		PerPage:    perPage,
+	}
+
+	props := components.AdminAuditListProps{
+		Actor:       params.Actor,
+		Action:      params.Action,
+		EntityType:  params.EntityType,
+		EntityID:    params.EntityID,
+		Page:        1,
+		CurrentUser: user.Username,
+	}
+
+	result, err := h.audit.List(r.Context(), params)
+	if err != nil {
+		var appErr *apperror.AppError
+		if !errors.As(err, &appErr) || appErr.Status == http.StatusInternalServerError {
+			http.Error(w, "failed to load audit log", http.StatusInternalServerError)
+			return
+		}
+		for _, message := range appErr.Details {
+			props.Errors = append(props.Errors, message)
+		}
+		sort.Strings(props.Errors)
+	} else {
+		props.Entries = result.Data
+		props.Total = result.Total
+		props.Page = result.Page
+		props.HasNext = result.Page*result.PerPage < result.Total
+	}
+
+	templ.Handler(components.AdminAuditListPage(props)).ServeHTTP(w, r)
+}
@@ -0,0 +1,180 @@
+package chordrequests
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/a-h/templ"
+
+	"github.com/lyricapp/lyric/web/internal/apperror"
+	adminctx "github.com/lyricapp/lyric/web/internal/http/context/admin"
+	"github.com/lyricapp/lyric/web/internal/http/handler"
+	auditsvc "github.com/lyricapp/lyric/web/internal/services/audit"
+	chordrequestsvc "github.com/lyricapp/lyric/web/internal/services/chordrequests"
+	chordsvc "github.com/lyricapp/lyric/web/internal/services/chords"
+	"github.com/lyricapp/lyric/web/internal/web/components"
+)
+
+const perPage = 50
+
+// Handler serves the chord request queue and adds chords to the library.
+type Handler struct {
+	requests chordrequestsvc.Service
+	chords   chordsvc.Service
+	audit    auditsvc.Service
+}
+
+// New constructs a chord request admin handler. Chords added to the library are
+// recorded in the audit log.
+func New(requests chordrequestsvc.Service, chords chordsvc.Service, audit auditsvc.Service) *Handler {
+	return &Handler{requests: requests, chords: chords, audit: audit}
+}
+
+// Index renders open chord requests, most requested first.
+func (h *Handler) Index(w http.ResponseWriter, r *http.Request) {
+	user, ok := adminctx.FromContext(r.Context())
+	if !ok {
+		http.Redirect(w, r, "/admin/login", http.StatusFound)
+		return
+	}
+
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	h.render(w, r, user.Username, page, r.URL.Query().Get("added"), nil)
+}
+
+// AddChord stores a chord in the library; the chord service closes any matching request.
+// Leaving frets blank stores the generated guitar shapes.
+func (h *Handler) AddChord(w http.ResponseWriter, r *http.Request) {
+	user, ok := adminctx.FromContext(r.Context())
+	if !ok {
+		http.Redirect(w, r, "/admin/login", http.StatusFound)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "invalid form submission", http.StatusBadRequest)
+		return
+	}
+
+	params := chordsvc.CreateParams{
+		Name:       r.PostFormValue("name"),
+		Instrument: chordsvc.InstrumentGuitar,
+	}
+
+	if frets := strings.TrimSpace(r.PostFormValue("frets")); frets != "" {
+		position, err := parsePosition(r.PostFormValue("base_fret"), frets, r.PostFormValue("fingers"))
+		if err != nil {
+			h.render(w, r, user.Username, 1, "", []string{err.Error()})
+			return
+		}
+		params.Positions = []chordsvc.Position{position}
+	}
+
+	chord, err := h.chords.Create(r.Context(), params)
+	if err != nil {
+		var appErr *apperror.AppError
+		if !errors.As(err, &appErr) || appErr.Status == http.StatusInternalServerError {
+			http.Error(w, "failed to add chord", http.StatusInternalServerError)
+			return
+		}
+		messages := []string{appErr.Message}
+		if len(appErr.Details) > 0 {
+			messages = messages[:0]
+			for _, message := range appErr.Details {
+				messages = append(messages, message)
+			}
+			sort.Strings(messages)
+		}
+		h.render(w, r, user.Username, 1, "", messages)
+		return
+	}
+	handler.Audit(r, h.audit, auditsvc.RecordParams{
+		ActorID:    user.ID,
+		Action:     auditsvc.ActionChordCreate,
+		EntityType: auditsvc.EntityChord,
+		EntityID:   chord.ID,
+		After:      chord,
+	})
+
+	http.Redirect(w, r, "/admin/chord-requests?added="+url.QueryEscape(chord.CanonicalName), http.StatusSeeOther)
+}
+
+func (h *Handler) render(w http.ResponseWriter, r *http.Request, currentUser string, page int, added string, errs []string) {
+	queue, err := h.requests.Queue(r.Context(), chordrequestsvc.ListParams{Page: page, PerPage: perPage})
+	if err != nil {
+		http.Error(w, "failed to load chord requests", http.StatusInternalServerError)
+		return
+	}
+
+	props := components.AdminChordRequestListProps{
+		Requests:    queue.Data,
+		Total:       queue.Total,
+		Page:        queue.Page,
+		HasNext:     queue.Page*queue.PerPage < queue.Total,
+		Added:       added,
+		Errors:      errs,
+		CurrentUser: currentUser,
+	}
+
+	templ.Handler(components.AdminChordRequestListPage(props)).ServeHTTP(w, r)
+}
+
+// parsePosition reads a single guitar shape. Frets and fingers are written either
+// compactly ("x32010") or separated by spaces or commas ("x 3 2 0 1 0", "-1,10,12").
+func parsePosition(rawBaseFret, rawFrets, rawFingers string) (chordsvc.Position, error) {
+	position := chordsvc.Position{BaseFret: 1}
+
+	if value := strings.TrimSpace(rawBaseFret); value != "" {
+		baseFret, err := strconv.Atoi(value)
+		if err != nil {
+			return chordsvc.Position{}, fmt.Errorf("base fret must be a number")
+		}
+		position.BaseFret = baseFret
+	}
+
+	for _, token := range splitShape(rawFrets) {
+		if isMuted(token) {
+			position.Frets = append(position.Frets, -1)
+			continue
+		}
+		fret, err := strconv.Atoi(token)
+		if err != nil {
+			return chordsvc.Position{}, fmt.Errorf("frets must be numbers or x")
+		}
+		position.Frets = append(position.Frets, fret)
+	}
+
+	for _, token := range splitShape(rawFingers) {
+		if isMuted(token) || token == "0" {
+			position.Fingers = append(position.Fingers, nil)
+			continue
+		}
+		finger, err := strconv.Atoi(token)
+		if err != nil {
+			return chordsvc.Position{}, fmt.Errorf("fingers must be numbers or x")
+		}
+		position.Fingers = append(position.Fingers, &finger)
+	}
+
+	return position, nil
+}
+
+func splitShape(raw string) []string {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil
+	}
+	if strings.ContainsAny(raw, " ,") {
+		return strings.FieldsFunc(raw, func(r rune) bool { return r == ' ' || r == ',' })
+	}
+	return strings.Split(raw, "")
+}
+
+func isMuted(token string) bool {
+	return strings.EqualFold(token, "x") || token == "-" || token == "-1"
+}
@@ -8,6 +8,7 @@ import (
 	adminsession "github.com/lyricapp/lyric/web/internal/auth/admin"
 	adminctx "github.com/lyricapp/lyric/web/internal/http/context/admin"
 	loginsvc "github.com/lyricapp/lyric/web/internal/services/login"
+	"github.com/lyricapp/lyric/web/internal/services/permissions"
 	"github.com/lyricapp/lyric/web/internal/web/components"
 )
 
@@ -81,7 +82,7 @@ func (h *Handler) Verify(w http.ResponseWriter, r *http.Request) {
 	code := strings.TrimSpace(r.FormValue("code"))
 	redirectTarget := safeRedirect(strings.TrimSpace(r.FormValue("redirect")))
 
-	result, err := h.login.VerifyCode(r.Context(), code)
+	user, err := h.login.ConsumeCode(r.Context(), code)
 	if err != nil {
 		props := components.AdminVerifyProps{
 			Email:    email,
@@ -92,7 +93,7 @@ func (h *Handler) Verify(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if result.User.Role != "admin" && result.User.Role != "editor" {
+	if !permissions.Role(user.Role).Can(permissions.AccessAdmin) {
 		props := components.AdminLoginProps{
 			Email:    email,
 			Error:    "You don't have permission to access this page",
@@ -102,7 +103,7 @@ func (h *Handler) Verify(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	claims := adminsession.Claims{ID: result.User.ID, Username: result.User.Email, Role: result.User.Role}
+	claims := adminsession.Claims{ID: user.ID, Username: user.Email, Role: user.Role}
 	if err := h.sessions.Issue(w, claims); err != nil {
 		http.Error(w, "unable to establish session", http.StatusInternalServerError)
 		return
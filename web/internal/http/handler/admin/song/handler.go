@@ -6,6 +6,7 @@ import (
 	"log"
 	"net/http"
 	"net/url"
+	"regexp"
 	"strconv"
 	"strings"
 
@@ -14,10 +15,13 @@ import (
 
 	"github.com/lyricapp/lyric/web/internal/apperror"
 	adminctx "github.com/lyricapp/lyric/web/internal/http/context/admin"
+	"github.com/lyricapp/lyric/web/internal/http/handler"
 	albumsvc "github.com/lyricapp/lyric/web/internal/services/albums"
 	artistsvc "github.com/lyricapp/lyric/web/internal/services/artists"
+	auditsvc "github.com/lyricapp/lyric/web/internal/services/audit"
 	languagesvc "github.com/lyricapp/lyric/web/internal/services/languages"
 	levelsvc "github.com/lyricapp/lyric/web/internal/services/levels"
+	"github.com/lyricapp/lyric/web/internal/services/permissions"
 	songsvc "github.com/lyricapp/lyric/web/internal/services/songs"
 	writersvc "github.com/lyricapp/lyric/web/internal/services/writers"
 	"github.com/lyricapp/lyric/web/internal/web/components"
@@ -31,11 +35,13 @@ type Handler struct {
 	writers   writersvc.Service
 	levels    levelsvc.Service
 	languages languagesvc.Service
+	audit     auditsvc.Service
 }
 
-// New constructs a song admin handler with the required dependencies.
-func New(songs songsvc.Service, albums albumsvc.Service, artists artistsvc.Service, writers writersvc.Service, levels levelsvc.Service, languages languagesvc.Service) *Handler {
-	return &Handler{songs: songs, albums: albums, artists: artists, writers: writers, levels: levels, languages: languages}
+// New constructs a song admin handler with the required dependencies. Every
+// change is recorded in the audit log.
+func New(songs songsvc.Service, albums albumsvc.Service, artists artistsvc.Service, writers writersvc.Service, levels levelsvc.Service, languages languagesvc.Service, audit auditsvc.Service) *Handler {
+	return &Handler{songs: songs, albums: albums, artists: artists, writers: writers, levels: levels, languages: languages, audit: audit}
 }
 
 // Index renders the admin song list with optional search.
@@ -77,12 +83,12 @@ func (h *Handler) Index(w http.ResponseWriter, r *http.Request) {
 	}
 
 	props := components.AdminSongListProps{
-		SearchTerm:      searchTerm,
-		Total:           list.Total,
-		Songs:           items,
-		CurrentUser:     user.Username,
-		CurrentUserRole: user.Role,
-		ResultsLabel:    resultsLabel,
+		SearchTerm:   searchTerm,
+		Total:        list.Total,
+		Songs:        items,
+		CurrentUser:  user.Username,
+		CanDelete:    user.Can(permissions.DeleteAnySong),
+		ResultsLabel: resultsLabel,
 	}
 
 	templ.Handler(components.AdminSongListPage(props)).ServeHTTP(w, r)
@@ -195,11 +201,19 @@ func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
 	if payload.ReleaseYear != nil {
 		params.ReleaseYear = payload.ReleaseYear
 	}
+	params.BPM = payload.BPM
+	if payload.Values.TimeSignature != "" {
+		signature := payload.Values.TimeSignature
+		params.TimeSignature = &signature
+	}
+	params.DurationSeconds = payload.Duration
+	params.Capo = payload.Capo
 
 	createdBy := user.ID
 	params.CreatedBy = &createdBy
 
-	if _, err := h.songs.Create(r.Context(), params); err != nil {
+	songID, err := h.songs.Create(r.Context(), params)
+	if err != nil {
 		payload.Errors = append(payload.Errors, "Failed to save the song. Please try again.")
 		props := components.AdminSongCreateProps{
 			Values:      payload.Values,
@@ -215,6 +229,7 @@ func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
 		templ.Handler(components.AdminSongCreatePage(props)).ServeHTTP(w, r)
 		return
 	}
+	h.record(r, user, auditsvc.ActionSongCreate, songID, nil)
 
 	http.Redirect(w, r, "/admin/songs/create?created=1", http.StatusFound)
 }
@@ -333,6 +348,8 @@ func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
 			WriterIDs: payload.WriterIDs,
 			AlbumIDs:  payload.AlbumIDs,
 		},
+		UserID:  user.ID,
+		OwnerID: user.Principal().OwnerScope(permissions.EditAnySong),
 	}
 
 	if payload.LevelID != nil {
@@ -352,11 +369,25 @@ func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
 	if payload.ReleaseYear != nil {
 		params.ReleaseYear = payload.ReleaseYear
 	}
+	params.BPM = payload.BPM
+	if payload.Values.TimeSignature != "" {
+		signature := payload.Values.TimeSignature
+		params.TimeSignature = &signature
+	}
+	params.DurationSeconds = payload.Duration
+	params.Capo = payload.Capo
+
+	before, err := h.songs.Get(r.Context(), songID)
+	if err != nil {
+		http.Error(w, "failed to load song", http.StatusInternalServerError)
+		return
+	}
 
 	if err := h.songs.Update(r.Context(), songID, params); err != nil {
 		http.Error(w, "failed to update song", http.StatusInternalServerError)
 		return
 	}
+	h.record(r, user, auditsvc.ActionSongUpdate, songID, &before)
 
 	http.Redirect(w, r, fmt.Sprintf("/admin/songs/%d/edit?updated=1", songID), http.StatusFound)
 }
@@ -369,11 +400,6 @@ func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if user.Role != "admin" {
-		http.Error(w, "forbidden", http.StatusForbidden)
-		return
-	}
-
 	rawID := strings.TrimSpace(chi.URLParam(r, "id"))
 	songID, err := strconv.Atoi(rawID)
 	if err != nil || songID <= 0 {
@@ -381,21 +407,55 @@ func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	searchTerm := strings.TrimSpace(r.FormValue("q"))
+	redirectURL := "/admin/songs"
+	if searchTerm := strings.TrimSpace(r.FormValue("q")); searchTerm != "" {
+		redirectURL = "/admin/songs?q=" + url.QueryEscape(searchTerm)
+	}
 
-	if err := h.songs.Delete(r.Context(), songID, songsvc.DeleteParams{}); err != nil && !errors.Is(err, apperror.NotFound("song not found")) {
-		http.Error(w, "failed to delete song", http.StatusInternalServerError)
+	// Deletion is permanent, so the song is kept in the audit log.
+	before, err := h.songs.Get(r.Context(), songID)
+	if err != nil {
+		var appErr *apperror.AppError
+		if errors.As(err, &appErr) && appErr.Status == http.StatusNotFound {
+			http.Redirect(w, r, redirectURL, http.StatusFound)
+			return
+		}
+		http.Error(w, "failed to load song", http.StatusInternalServerError)
 		return
 	}
 
-	redirectURL := "/admin/songs"
-	if searchTerm != "" {
-		redirectURL = "/admin/songs?q=" + url.QueryEscape(searchTerm)
+	if err := h.songs.Delete(r.Context(), songID, songsvc.DeleteParams{UserID: user.Principal().OwnerScope(permissions.DeleteAnySong)}); err != nil {
+		http.Error(w, "failed to delete song", http.StatusInternalServerError)
+		return
 	}
+	handler.Audit(r, h.audit, auditsvc.RecordParams{
+		ActorID:    user.ID,
+		Action:     auditsvc.ActionSongDelete,
+		EntityType: auditsvc.EntitySong,
+		EntityID:   songID,
+		Before:     before,
+	})
 
 	http.Redirect(w, r, redirectURL, http.StatusFound)
 }
 
+// record audits a song change, snapshotting the song as it is now.
+func (h *Handler) record(r *http.Request, user adminctx.User, action string, songID int, before *songsvc.Song) {
+	params := auditsvc.RecordParams{
+		ActorID:    user.ID,
+		Action:     action,
+		EntityType: auditsvc.EntitySong,
+		EntityID:   songID,
+	}
+	if before != nil {
+		params.Before = before
+	}
+	if after, err := h.songs.Get(r.Context(), songID); err == nil {
+		params.After = after
+	}
+	handler.Audit(r, h.audit, params)
+}
+
 type songFormPayload struct {
 	Values      components.AdminSongFormValues
 	FieldErrors map[string]string
@@ -406,6 +466,9 @@ type songFormPayload struct {
 	AlbumIDs    []int
 	ArtistIDs   []int
 	WriterIDs   []int
+	BPM         *int
+	Duration    *int
+	Capo        *int
 }
 
 func parseSongForm(r *http.Request) (songFormPayload, error) {
@@ -432,6 +495,10 @@ func parseSongForm(r *http.Request) (songFormPayload, error) {
 	payload.Values.ArtistIDs = r.Form["artist_ids"]
 	payload.Values.WriterIDs = r.Form["writer_ids"]
 	payload.Values.Lyric = r.FormValue("lyric")
+	payload.Values.BPM = strings.TrimSpace(r.FormValue("bpm"))
+	payload.Values.TimeSignature = strings.TrimSpace(r.FormValue("time_signature"))
+	payload.Values.Duration = strings.TrimSpace(r.FormValue("duration"))
+	payload.Values.Capo = strings.TrimSpace(r.FormValue("capo"))
 
 	if payload.Values.Title == "" {
 		payload.FieldErrors["title"] = "Title is required."
@@ -464,6 +531,25 @@ func parseSongForm(r *http.Request) (songFormPayload, error) {
 		payload.ReleaseYear = releaseYear
 	}
 
+	if bpm, err := parseOptionalInt(payload.Values.BPM); err != nil || (bpm != nil && (*bpm < songsvc.MinBPM || *bpm > songsvc.MaxBPM)) {
+		payload.FieldErrors["bpm"] = fmt.Sprintf("BPM must be between %d and %d.", songsvc.MinBPM, songsvc.MaxBPM)
+	} else {
+		payload.BPM = bpm
+	}
+	if signature := payload.Values.TimeSignature; signature != "" && !timeSignaturePattern.MatchString(signature) {
+		payload.FieldErrors["time_signature"] = "Time signature must look like 4/4, 3/4 or 6/8."
+	}
+	if duration, err := parseDuration(payload.Values.Duration); err != nil {
+		payload.FieldErrors["duration"] = "Duration must be minutes and seconds, e.g. 3:45."
+	} else {
+		payload.Duration = duration
+	}
+	if capo, err := parseOptionalCapo(payload.Values.Capo); err != nil {
+		payload.FieldErrors["capo"] = fmt.Sprintf("Capo must be between 0 and %d.", songsvc.MaxCapo)
+	} else {
+		payload.Capo = capo
+	}
+
 	if albumIDs, err := parseIDList(payload.Values.AlbumIDs); err != nil {
 		payload.FieldErrors["album_ids"] = "Album must be a valid number."
 	} else {
@@ -626,6 +712,18 @@ func buildValuesFromSong(song songsvc.Song) components.AdminSongFormValues {
 	if song.Lyric != nil {
 		values.Lyric = *song.Lyric
 	}
+	if song.BPM != nil {
+		values.BPM = strconv.Itoa(*song.BPM)
+	}
+	if song.TimeSignature != nil {
+		values.TimeSignature = *song.TimeSignature
+	}
+	if song.DurationSeconds != nil {
+		values.Duration = formatDuration(*song.DurationSeconds)
+	}
+	if song.Capo != nil {
+		values.Capo = strconv.Itoa(*song.Capo)
+	}
 
 	return values
 }
@@ -652,6 +750,56 @@ func parseOptionalInt(raw string) (*int, error) {
 	return &value, nil
 }
 
+var timeSignaturePattern = regexp.MustCompile(`^[0-9]{1,2}/(1|2|4|8|16|32)$`)
+
+// parseDuration accepts "m:ss" or plain seconds.
+func parseDuration(raw string) (*int, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, nil
+	}
+	seconds := 0
+	if minutes, rest, found := strings.Cut(raw, ":"); found {
+		m, err := strconv.Atoi(minutes)
+		if err != nil || m < 0 {
+			return nil, errors.New("invalid minutes")
+		}
+		secs, err := strconv.Atoi(rest)
+		if err != nil || secs < 0 || secs >= 60 || len(rest) != 2 {
+			return nil, errors.New("invalid seconds")
+		}
+		seconds = m*60 + secs
+	} else {
+		value, err := strconv.Atoi(raw)
+		if err != nil {
+			return nil, err
+		}
+		seconds = value
+	}
+	if seconds <= 0 || seconds > songsvc.MaxDurationSeconds {
+		return nil, errors.New("duration out of range")
+	}
+	return &seconds, nil
+}
+
+func formatDuration(seconds int) string {
+	return fmt.Sprintf("%d:%02d", seconds/60, seconds%60)
+}
+
+func parseOptionalCapo(raw string) (*int, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	value, err := strconv.Atoi(raw)
+	if err != nil {
+		return nil, err
+	}
+	if value < 0 || value > songsvc.MaxCapo {
+		return nil, errors.New("capo out of range")
+	}
+	return &value, nil
+}
+
 func parseIDList(values []string) ([]int, error) {
 	if len(values) == 0 {
 		return []int{}, nil
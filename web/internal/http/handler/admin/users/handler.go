@@ -1,25 +1,36 @@
 package users
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
+	"sort"
+	"strconv"
 
 	"github.com/a-h/templ"
+	"github.com/go-chi/chi/v5"
+	"github.com/lyricapp/lyric/web/internal/apperror"
 	adminctx "github.com/lyricapp/lyric/web/internal/http/context/admin"
+	"github.com/lyricapp/lyric/web/internal/http/handler"
+	auditsvc "github.com/lyricapp/lyric/web/internal/services/audit"
 	usersvc "github.com/lyricapp/lyric/web/internal/services/users"
 	"github.com/lyricapp/lyric/web/internal/web/components"
 )
 
-// Handler serves the admin user list.
+const perPage = 25
+
+// Handler serves the admin user pages.
 type Handler struct {
 	users usersvc.Service
+	audit auditsvc.Service
 }
 
-// New constructs a user admin handler.
-func New(users usersvc.Service) *Handler {
-	return &Handler{users: users}
+// New constructs a user admin handler. Every change is recorded in the audit log.
+func New(users usersvc.Service, audit auditsvc.Service) *Handler {
+	return &Handler{users: users, audit: audit}
 }
 
-// Index renders the admin user list.
+// Index renders the admin user list, filtered by email, role and status.
 func (h *Handler) Index(w http.ResponseWriter, r *http.Request) {
 	user, ok := adminctx.FromContext(r.Context())
 	if !ok {
@@ -27,16 +38,187 @@ func (h *Handler) Index(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	users, err := h.users.List(r.Context())
-	if err != nil {
-		http.Error(w, "failed to load users", http.StatusInternalServerError)
-		return
+	query := r.URL.Query()
+	page, _ := strconv.Atoi(query.Get("page"))
+	params := usersvc.ListParams{
+		Query:   query.Get("q"),
+		Role:    query.Get("role"),
+		Status:  query.Get("status"),
+		Page:    page,
+		PerPage: perPage,
 	}
 
 	props := components.AdminUserListProps{
-		Users:       users,
+		Query:       params.Query,
+		Role:        params.Role,
+		Status:      params.Status,
+		Page:        1,
 		CurrentUser: user.Username,
 	}
 
+	result, err := h.users.List(r.Context(), params)
+	if err != nil {
+		messages, ok := errorMessages(err)
+		if !ok {
+			http.Error(w, "failed to load users", http.StatusInternalServerError)
+			return
+		}
+		props.Errors = messages
+	} else {
+		props.Users = result.Data
+		props.Total = result.Total
+		props.Page = result.Page
+		props.HasNext = result.Page*result.PerPage < result.Total
+	}
+
 	templ.Handler(components.AdminUserListPage(props)).ServeHTTP(w, r)
 }
+
+// Show renders a user with their songs, playlists, feedback and sign ins.
+func (h *Handler) Show(w http.ResponseWriter, r *http.Request) {
+	user, ok := adminctx.FromContext(r.Context())
+	if !ok {
+		http.Redirect(w, r, "/admin/login", http.StatusFound)
+		return
+	}
+
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	h.render(w, r, user, id, r.URL.Query().Get("notice"), nil)
+}
+
+// ChangeRole sets the user's role.
+func (h *Handler) ChangeRole(w http.ResponseWriter, r *http.Request) {
+	h.act(w, r, auditsvc.ActionUserRole, "role", func(user adminctx.User, id int) (any, error) {
+		return h.users.ChangeRole(r.Context(), usersvc.RoleParams{
+			ActorID: user.ID,
+			UserID:  id,
+			Role:    r.PostFormValue("role"),
+		})
+	})
+}
+
+// Ban bans the user with the submitted reason and signs them out everywhere.
+func (h *Handler) Ban(w http.ResponseWriter, r *http.Request) {
+	h.act(w, r, auditsvc.ActionUserBan, "banned", func(user adminctx.User, id int) (any, error) {
+		return h.users.Ban(r.Context(), usersvc.BanParams{
+			ActorID: user.ID,
+			UserID:  id,
+			Reason:  r.PostFormValue("reason"),
+		})
+	})
+}
+
+// Unban lifts the user's ban.
+func (h *Handler) Unban(w http.ResponseWriter, r *http.Request) {
+	h.act(w, r, auditsvc.ActionUserUnban, "unbanned", func(user adminctx.User, id int) (any, error) {
+		return h.users.Unban(r.Context(), user.ID, id)
+	})
+}
+
+// Logout signs the user out of every device.
+func (h *Handler) Logout(w http.ResponseWriter, r *http.Request) {
+	h.act(w, r, auditsvc.ActionUserLogout, "logged-out", func(user adminctx.User, id int) (any, error) {
+		revoked, err := h.users.ForceLogout(r.Context(), id)
+		return map[string]int{"sessions_revoked": revoked}, err
+	})
+}
+
+// act runs a form action against the user in the URL and audits it, then
+// redirects back to their page with notice, or re-renders it with the
+// validation errors. action returns the snapshot recorded after the change.
+func (h *Handler) act(w http.ResponseWriter, r *http.Request, auditAction, notice string, action func(user adminctx.User, id int) (any, error)) {
+	user, ok := adminctx.FromContext(r.Context())
+	if !ok {
+		http.Redirect(w, r, "/admin/login", http.StatusFound)
+		return
+	}
+
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "invalid form submission", http.StatusBadRequest)
+		return
+	}
+
+	before, err := h.users.Get(r.Context(), id)
+	if err != nil {
+		var appErr *apperror.AppError
+		if errors.As(err, &appErr) && appErr.Status == http.StatusNotFound {
+			http.NotFound(w, r)
+			return
+		}
+		http.Error(w, "failed to load user", http.StatusInternalServerError)
+		return
+	}
+
+	after, err := action(user, id)
+	if err != nil {
+		messages, ok := errorMessages(err)
+		if !ok {
+			http.Error(w, "failed to update user", http.StatusInternalServerError)
+			return
+		}
+		h.render(w, r, user, id, "", messages)
+		return
+	}
+	handler.Audit(r, h.audit, auditsvc.RecordParams{
+		ActorID:    user.ID,
+		Action:     auditAction,
+		EntityType: auditsvc.EntityUser,
+		EntityID:   id,
+		Before:     before,
+		After:      after,
+	})
+
+	http.Redirect(w, r, fmt.Sprintf("/admin/users/%d?notice=%s", id, notice), http.StatusSeeOther)
+}
+
+func (h *Handler) render(w http.ResponseWriter, r *http.Request, user adminctx.User, id int, notice string, errs []string) {
+	detail, err := h.users.Detail(r.Context(), id)
+	if err != nil {
+		var appErr *apperror.AppError
+		if errors.As(err, &appErr) && appErr.Status == http.StatusNotFound {
+			http.NotFound(w, r)
+			return
+		}
+		http.Error(w, "failed to load user", http.StatusInternalServerError)
+		return
+	}
+
+	props := components.AdminUserDetailProps{
+		User:        detail,
+		Self:        detail.ID == user.ID,
+		Notice:      notice,
+		Errors:      errs,
+		CurrentUser: user.Username,
+	}
+
+	templ.Handler(components.AdminUserDetailPage(props)).ServeHTTP(w, r)
+}
+
+// errorMessages flattens a client error into messages for the page, reporting
+// false for errors the user cannot fix.
+func errorMessages(err error) ([]string, bool) {
+	var appErr *apperror.AppError
+	if !errors.As(err, &appErr) || appErr.Status == http.StatusInternalServerError {
+		return nil, false
+	}
+	if len(appErr.Details) == 0 {
+		return []string{appErr.Message}, true
+	}
+	messages := make([]string, 0, len(appErr.Details))
+	for _, message := range appErr.Details {
+		messages = append(messages, message)
+	}
+	sort.Strings(messages)
+	return messages, true
+}
@@ -0,0 +1,63 @@
+package account
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/lyricapp/lyric/web/internal/apperror"
+	"github.com/lyricapp/lyric/web/internal/http/handler"
+	"github.com/lyricapp/lyric/web/internal/http/handler/api/util"
+	accountsvc "github.com/lyricapp/lyric/web/internal/services/account"
+)
+
+// Handler serves account data exports.
+type Handler struct {
+	svc accountsvc.Service
+}
+
+// New constructs an account handler.
+func New(svc accountsvc.Service) Handler {
+	return Handler{svc: svc}
+}
+
+// Export downloads everything stored about the current user, as JSON or, with
+// ?format=zip, a ZIP archive with one JSON file per section.
+func (h Handler) Export(w http.ResponseWriter, r *http.Request) {
+	userID, err := util.CurrentUserID(r)
+	if err != nil {
+		handler.Error(w, err)
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format != "" && format != "json" && format != "zip" {
+		handler.Error(w, apperror.Validation("msg", map[string]string{"format": "format must be json or zip"}))
+		return
+	}
+
+	export, err := h.svc.Export(r.Context(), userID)
+	if err != nil {
+		handler.Error(w, err)
+		return
+	}
+
+	name := fmt.Sprintf("lyric-export-%d-%s", userID, export.ExportedAt.Format("20060102"))
+	if format != "zip" {
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name+".json"))
+		handler.Success(w, http.StatusOK, export)
+		return
+	}
+
+	var archive bytes.Buffer
+	if err := export.WriteZip(&archive); err != nil {
+		handler.Error(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name+".zip"))
+	w.Header().Set("Content-Length", strconv.Itoa(archive.Len()))
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(archive.Bytes())
+}
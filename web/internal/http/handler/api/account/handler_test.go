@@ -0,0 +1,111 @@
+package account_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/lyricapp/lyric/web/internal/http/handler"
+	"github.com/lyricapp/lyric/web/internal/http/handler/api/account"
+	accountsvc "github.com/lyricapp/lyric/web/internal/services/account"
+	"github.com/lyricapp/lyric/web/internal/storage"
+	accountrepo "github.com/lyricapp/lyric/web/internal/storage/postgres/account"
+	"github.com/lyricapp/lyric/web/internal/testutil"
+)
+
+func getHandler(conn storage.Querier) account.Handler {
+	return account.New(accountsvc.NewService(accountrepo.NewRepository(conn)))
+}
+
+func TestHandler_Export(t *testing.T) {
+	conn := testutil.SetupDB(t)
+	defer conn.Close()
+
+	ctx := context.Background()
+	tx, _ := conn.Begin(ctx)
+	defer tx.Rollback(ctx)
+
+	var userID, playlistID int
+	if err := tx.QueryRow(ctx, "insert into users (email, role) values ('export@test.com', 'musician') returning id").Scan(&userID); err != nil {
+		t.Fatalf("failed to seed user: %v", err)
+	}
+	if err := tx.QueryRow(ctx, "insert into playlists (name, user_id) values ('setlist', $1) returning id", userID).Scan(&playlistID); err != nil {
+		t.Fatalf("failed to seed playlist: %v", err)
+	}
+	if _, err := tx.Exec(ctx, "insert into feedbacks (user_id, message) values ($1, 'hello')", userID); err != nil {
+		t.Fatalf("failed to seed feedback: %v", err)
+	}
+
+	h := getHandler(tx)
+	r, accessToken := testutil.AuthToken(t, userID)
+	r.Get("/api/me/export", h.Export)
+
+	get := func(url string) *httptest.ResponseRecorder {
+		req, err := http.NewRequest("GET", url, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", accessToken))
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("json", func(t *testing.T) {
+		rr := get("/api/me/export")
+		if rr.Code != http.StatusOK {
+			t.Fatalf("unexpected status code: got %d want %d: %s", rr.Code, http.StatusOK, rr.Body.String())
+		}
+
+		var res handler.ResponseMessage[accountsvc.Export]
+		if err := json.NewDecoder(rr.Body).Decode(&res); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if res.Data.Profile.Email != "export@test.com" {
+			t.Errorf("unexpected profile: %+v", res.Data.Profile)
+		}
+		if len(res.Data.Playlists) != 1 || res.Data.Playlists[0].ID != playlistID {
+			t.Errorf("unexpected playlists: %+v", res.Data.Playlists)
+		}
+		if len(res.Data.Feedback) != 1 || res.Data.Feedback[0].Message != "hello" {
+			t.Errorf("unexpected feedback: %+v", res.Data.Feedback)
+		}
+	})
+
+	t.Run("zip", func(t *testing.T) {
+		rr := get("/api/me/export?format=zip")
+		if rr.Code != http.StatusOK {
+			t.Fatalf("unexpected status code: got %d want %d: %s", rr.Code, http.StatusOK, rr.Body.String())
+		}
+		if ct := rr.Header().Get("Content-Type"); ct != "application/zip" {
+			t.Fatalf("unexpected content type %q", ct)
+		}
+
+		body := rr.Body.Bytes()
+		archive, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+		if err != nil {
+			t.Fatalf("failed to open archive: %v", err)
+		}
+		files := map[string]bool{}
+		for _, file := range archive.File {
+			files[file.Name] = true
+		}
+		for _, name := range []string{"profile.json", "playlists.json", "feedback.json", "songs.json"} {
+			if !files[name] {
+				t.Errorf("expected %s in the archive, got %v", name, files)
+			}
+		}
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		rr := get("/api/me/export?format=csv")
+		if rr.Code != http.StatusUnprocessableEntity {
+			t.Fatalf("unexpected status code: got %d want %d", rr.Code, http.StatusUnprocessableEntity)
+		}
+	})
+}
@@ -42,6 +42,16 @@ func (h Handler) List(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Playlist albums follow the playlist, which is left to the ETag.
+	if params.PlaylistID == nil {
+		modified, err := h.svc.LastModified(r.Context())
+		if err != nil {
+			handler.Error(w, err)
+			return
+		}
+		handler.LastModified(w, modified)
+	}
+
 	result, err := h.svc.List(r.Context(), params)
 	if err != nil {
 		handler.Error(w, err)
@@ -0,0 +1,52 @@
+package chordrequests
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/lyricapp/lyric/web/internal/apperror"
+	"github.com/lyricapp/lyric/web/internal/http/handler"
+	"github.com/lyricapp/lyric/web/internal/http/handler/api/util"
+	chordrequestsvc "github.com/lyricapp/lyric/web/internal/services/chordrequests"
+)
+
+// Handler accepts chord requests from the app.
+type Handler struct {
+	svc chordrequestsvc.Service
+}
+
+// New constructs a chord request handler.
+func New(svc chordrequestsvc.Service) Handler {
+	return Handler{svc: svc}
+}
+
+// Create records the current user's request for a chord.
+func (h Handler) Create(w http.ResponseWriter, r *http.Request) {
+	userID, authErr := util.CurrentUserID(r)
+	if authErr != nil {
+		handler.Error(w, authErr)
+		return
+	}
+
+	var payload struct {
+		Name   string `json:"name"`
+		SongID *int   `json:"song_id"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		handler.Error(w, apperror.BadRequest("invalid JSON payload"))
+		return
+	}
+
+	request, err := h.svc.Create(r.Context(), chordrequestsvc.CreateParams{
+		UserID: userID,
+		Name:   payload.Name,
+		SongID: payload.SongID,
+	})
+	if err != nil {
+		handler.Error(w, err)
+		return
+	}
+
+	handler.Success(w, http.StatusCreated, request)
+}
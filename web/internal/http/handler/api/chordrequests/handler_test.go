@@ -0,0 +1,168 @@
+package chordrequests_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/lyricapp/lyric/web/internal/http/handler"
+	"github.com/lyricapp/lyric/web/internal/http/handler/api/chordrequests"
+	chordrequestsvc "github.com/lyricapp/lyric/web/internal/services/chordrequests"
+	chordsvc "github.com/lyricapp/lyric/web/internal/services/chords"
+	"github.com/lyricapp/lyric/web/internal/storage"
+	chordrequestrepo "github.com/lyricapp/lyric/web/internal/storage/postgres/chordrequests"
+	chordrepo "github.com/lyricapp/lyric/web/internal/storage/postgres/chords"
+	"github.com/lyricapp/lyric/web/internal/testutil"
+)
+
+type recordingNotifier struct {
+	sent map[string]string
+}
+
+func (n *recordingNotifier) ChordAvailable(_ context.Context, email, chordName string) error {
+	n.sent[email] = chordName
+	return nil
+}
+
+func getService(db storage.Querier) (chordrequestsvc.Service, *recordingNotifier) {
+	notifier := &recordingNotifier{sent: map[string]string{}}
+	return chordrequestsvc.NewService(chordrequestrepo.NewRepository(db), notifier), notifier
+}
+
+func postRequest(t *testing.T, h chordrequests.Handler, userID int, body map[string]any) *httptest.ResponseRecorder {
+	t.Helper()
+	r, accessToken := testutil.AuthToken(t, userID)
+	r.Post("/api/chord-requests", h.Create)
+
+	requestBody, _ := json.Marshal(body)
+	req, err := http.NewRequest("POST", "/api/chord-requests", bytes.NewBuffer(requestBody))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", accessToken))
+
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+	return rr
+}
+
+func TestHandler_Create_DeduplicatesByCanonicalName(t *testing.T) {
+	conn := testutil.SetupDB(t)
+	defer conn.Close()
+
+	ctx := context.Background()
+	tx, _ := conn.Begin(ctx)
+	defer tx.Rollback(ctx)
+
+	// given
+	var firstUser, secondUser, langID, songID int
+	if err := tx.QueryRow(ctx, "insert into users (email, role) values ('first@user.com', 'musician') returning id").Scan(&firstUser); err != nil {
+		t.Fatalf("failed to insert users: %v", err)
+	}
+	if err := tx.QueryRow(ctx, "insert into users (email, role) values ('second@user.com', 'musician') returning id").Scan(&secondUser); err != nil {
+		t.Fatalf("failed to insert users: %v", err)
+	}
+	if err := tx.QueryRow(ctx, "insert into languages (name) values ('english') returning id").Scan(&langID); err != nil {
+		t.Fatalf("failed to insert languages: %v", err)
+	}
+	if err := tx.QueryRow(ctx, "insert into songs (title, language_id) values ('Song One', $1) returning id", langID).Scan(&songID); err != nil {
+		t.Fatalf("failed to insert songs: %v", err)
+	}
+	svc, _ := getService(tx)
+	h := chordrequests.New(svc)
+
+	// when
+	postRequest(t, h, firstUser, map[string]any{"name": "A#m7b5", "song_id": songID})
+	postRequest(t, h, firstUser, map[string]any{"name": "Bbm7b5"})
+	rr := postRequest(t, h, secondUser, map[string]any{"name": "B♭ø"})
+
+	// then
+	if status := rr.Code; status != http.StatusCreated {
+		t.Fatalf("handler returned wrong status code: got %v want %v", status, http.StatusCreated)
+	}
+	var response handler.ResponseMessage[chordrequestsvc.Request]
+	if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if response.Data.Name != "Bbm7b5" {
+		t.Errorf("expected canonical name Bbm7b5, got %q", response.Data.Name)
+	}
+	if response.Data.Votes != 2 {
+		t.Errorf("expected 2 votes, got %d", response.Data.Votes)
+	}
+	if len(response.Data.Songs) != 1 || response.Data.Songs[0].ID != songID {
+		t.Errorf("expected song %d to be referenced, got %+v", songID, response.Data.Songs)
+	}
+
+	var count int
+	tx.QueryRow(ctx, "select count(*) from chord_requests where name = 'Bbm7b5'").Scan(&count)
+	if count != 1 {
+		t.Errorf("expected a single request row, got %d", count)
+	}
+}
+
+func TestHandler_Create_InvalidName(t *testing.T) {
+	conn := testutil.SetupDB(t)
+	defer conn.Close()
+
+	ctx := context.Background()
+	tx, _ := conn.Begin(ctx)
+	defer tx.Rollback(ctx)
+
+	var userID int
+	if err := tx.QueryRow(ctx, "insert into users (email, role) values ('test@user.com', 'musician') returning id").Scan(&userID); err != nil {
+		t.Fatalf("failed to insert users: %v", err)
+	}
+	svc, _ := getService(tx)
+	h := chordrequests.New(svc)
+
+	rr := postRequest(t, h, userID, map[string]any{"name": "not a chord"})
+
+	if status := rr.Code; status != http.StatusUnprocessableEntity {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusUnprocessableEntity)
+	}
+}
+
+func TestChordAdded_ClosesRequestAndNotifies(t *testing.T) {
+	conn := testutil.SetupDB(t)
+	defer conn.Close()
+
+	ctx := context.Background()
+	tx, _ := conn.Begin(ctx)
+	defer tx.Rollback(ctx)
+
+	// given
+	var userID int
+	if err := tx.QueryRow(ctx, "insert into users (email, role) values ('waiting@user.com', 'musician') returning id").Scan(&userID); err != nil {
+		t.Fatalf("failed to insert users: %v", err)
+	}
+	requests, notifier := getService(tx)
+	if _, err := requests.Create(ctx, chordrequestsvc.CreateParams{UserID: userID, Name: "C#m9"}); err != nil {
+		t.Fatalf("failed to create request: %v", err)
+	}
+	chords := chordsvc.NewService(chordrepo.NewRepository(tx), requests)
+
+	// when
+	chord, err := chords.Create(ctx, chordsvc.CreateParams{Name: "Dbm9"})
+	requests.Wait()
+
+	// then
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if chord.CanonicalName != "C#m9" {
+		t.Errorf("expected canonical name C#m9, got %q", chord.CanonicalName)
+	}
+	var status string
+	tx.QueryRow(ctx, "select status from chord_requests where name = 'C#m9'").Scan(&status)
+	if status != chordrequestsvc.StatusFulfilled {
+		t.Errorf("expected request to be fulfilled, got %q", status)
+	}
+	if notifier.sent["waiting@user.com"] != "C#m9" {
+		t.Errorf("expected requester to be notified, got %+v", notifier.sent)
+	}
+}
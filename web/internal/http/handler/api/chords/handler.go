@@ -2,33 +2,46 @@ package chords
 
 import (
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 
 	"github.com/go-chi/chi/v5"
 
 	"github.com/lyricapp/lyric/web/internal/apperror"
 	"github.com/lyricapp/lyric/web/internal/http/handler"
+	"github.com/lyricapp/lyric/web/internal/http/handler/api/util"
 	chordsvc "github.com/lyricapp/lyric/web/internal/services/chords"
+	songsvc "github.com/lyricapp/lyric/web/internal/services/songs"
 )
 
 // Handler serves chord lookups.
 type Handler struct {
-	svc chordsvc.Service
+	svc   chordsvc.Service
+	songs songsvc.Service
 }
 
 // New constructs a chord handler instance.
-func New(svc chordsvc.Service) Handler {
-	return Handler{svc: svc}
+func New(svc chordsvc.Service, songs songsvc.Service) Handler {
+	return Handler{svc: svc, songs: songs}
 }
 
-// Show responds with a chord definition.
+// Show responds with a chord definition for the requested instrument.
 func (h Handler) Show(w http.ResponseWriter, r *http.Request) {
-	name := chi.URLParam(r, "name")
-	if name == "" {
+	// Slash chords arrive as "Gsus2%2FB" since chi routes on the escaped path.
+	name, err := url.PathUnescape(chi.URLParam(r, "name"))
+	if err != nil || strings.TrimSpace(name) == "" {
 		handler.Error(w, apperror.BadRequest("chord name is required"))
 		return
 	}
 
-	chord, err := h.svc.Find(r.Context(), name)
+	instrument, err := chordsvc.ParseInstrument(r.URL.Query().Get("instrument"))
+	if err != nil {
+		handler.Error(w, err)
+		return
+	}
+
+	chord, err := h.svc.Find(r.Context(), name, instrument)
 	if err != nil {
 		handler.Error(w, err)
 		return
@@ -36,3 +49,51 @@ func (h Handler) Show(w http.ResponseWriter, r *http.Request) {
 
 	handler.Success(w, http.StatusOK, chord)
 }
+
+// Song responds with diagrams for every chord used in a song's lyric.
+func (h Handler) Song(w http.ResponseWriter, r *http.Request) {
+	rawID := strings.TrimSpace(chi.URLParam(r, "id"))
+	songID, err := strconv.Atoi(rawID)
+	if err != nil || songID <= 0 {
+		handler.Error(w, apperror.BadRequest("Invalid song id"))
+		return
+	}
+
+	instrument, err := chordsvc.ParseInstrument(r.URL.Query().Get("instrument"))
+	if err != nil {
+		handler.Error(w, err)
+		return
+	}
+
+	// Within a playlist the diagrams follow its arrangement of the song, which
+	// only the playlist's members may see.
+	var song songsvc.Song
+	if rawPlaylistID := strings.TrimSpace(r.URL.Query().Get("playlist_id")); rawPlaylistID != "" {
+		playlistID, convErr := strconv.Atoi(rawPlaylistID)
+		if convErr != nil || playlistID <= 0 {
+			handler.Error(w, apperror.Validation("msg", map[string]string{"playlist_id": "playlist_id must be a positive integer"}))
+			return
+		}
+		userID, _ := util.CurrentUserID(r)
+		song, err = h.songs.GetInPlaylist(r.Context(), songID, playlistID, userID)
+	} else {
+		song, err = h.songs.Get(r.Context(), songID)
+	}
+	if err != nil {
+		handler.Error(w, err)
+		return
+	}
+
+	lyric := ""
+	if song.Lyric != nil {
+		lyric = *song.Lyric
+	}
+
+	chords, err := h.svc.FindAll(r.Context(), lyric, instrument)
+	if err != nil {
+		handler.Error(w, err)
+		return
+	}
+
+	handler.Success(w, http.StatusOK, chords)
+}
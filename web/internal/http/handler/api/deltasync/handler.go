@@ -0,0 +1,50 @@
+package deltasync
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/lyricapp/lyric/web/internal/apperror"
+	"github.com/lyricapp/lyric/web/internal/http/handler"
+	"github.com/lyricapp/lyric/web/internal/http/handler/api/util"
+	deltasyncsvc "github.com/lyricapp/lyric/web/internal/services/deltasync"
+)
+
+// Handler serves offline delta sync pages.
+type Handler struct {
+	svc deltasyncsvc.Service
+}
+
+// New constructs a delta sync handler.
+func New(svc deltasyncsvc.Service) Handler {
+	return Handler{svc: svc}
+}
+
+// Changes returns the catalogue and playlist changes after ?since=, a page of at
+// most ?limit= records at a time.
+func (h Handler) Changes(w http.ResponseWriter, r *http.Request) {
+	userID, authErr := util.CurrentUserID(r)
+	if authErr != nil {
+		handler.Error(w, authErr)
+		return
+	}
+
+	query := r.URL.Query()
+	validationErrors := map[string]string{}
+	limit := 0
+	if value := util.ParseOptionalPositiveInt(query.Get("limit"), "limit", validationErrors); value != nil {
+		limit = *value
+	}
+	if len(validationErrors) > 0 {
+		handler.Error(w, apperror.Validation("failed validation", validationErrors))
+		return
+	}
+
+	delta, err := h.svc.Changes(r.Context(), userID, strings.TrimSpace(query.Get("since")), limit)
+	if err != nil {
+		handler.Error(w, err)
+		return
+	}
+
+	handler.Success(w, http.StatusOK, delta)
+}
@@ -0,0 +1,166 @@
+package deltasync_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/lyricapp/lyric/web/internal/http/handler/api/deltasync"
+	deltasyncsvc "github.com/lyricapp/lyric/web/internal/services/deltasync"
+	deltasyncrepo "github.com/lyricapp/lyric/web/internal/storage/postgres/deltasync"
+	"github.com/lyricapp/lyric/web/internal/testutil"
+)
+
+func TestHandler_Changes(t *testing.T) {
+	conn := testutil.SetupDB(t)
+	defer conn.Close()
+
+	ctx := context.Background()
+	tx, _ := conn.Begin(ctx)
+	defer tx.Rollback(ctx)
+
+	// given
+	var ownerID, memberID, languageID, songID, playlistID int
+	for email, id := range map[string]*int{
+		"owner-sync@user.com":  &ownerID,
+		"member-sync@user.com": &memberID,
+	} {
+		if err := tx.QueryRow(ctx, "insert into users (email, role) values ($1, 'musician') returning id", email).Scan(id); err != nil {
+			t.Fatalf("failed to insert user: %v", err)
+		}
+	}
+	// now() is fixed inside the test transaction, so the seed rows are dated
+	// back and the first pass lags behind them to leave room for later changes.
+	if err := tx.QueryRow(ctx, "insert into languages (name, updated_at) values ('sync', now() - interval '1 hour') returning id").Scan(&languageID); err != nil {
+		t.Fatalf("failed to insert language: %v", err)
+	}
+	if err := tx.QueryRow(ctx, "insert into songs (title, language_id, bpm, updated_at) values ('offline', $1, 90, now() - interval '1 hour') returning id", languageID).Scan(&songID); err != nil {
+		t.Fatalf("failed to insert song: %v", err)
+	}
+
+	repo := deltasyncrepo.NewRepository(tx)
+	lagged := deltasync.New(deltasyncsvc.NewService(repo, deltasyncsvc.Config{Settle: 30 * time.Minute}))
+	current := deltasync.New(deltasyncsvc.NewService(repo, deltasyncsvc.Config{}))
+	fetch := func(h deltasync.Handler, userID int, since string, limit int) (int, deltasyncsvc.Delta) {
+		r, accessToken := testutil.AuthToken(t, userID)
+		r.Get("/api/sync", h.Changes)
+		query := url.Values{"since": {since}}
+		if limit > 0 {
+			query.Set("limit", fmt.Sprint(limit))
+		}
+		req, err := http.NewRequest("GET", "/api/sync?"+query.Encode(), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", accessToken))
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+
+		var body struct {
+			Data deltasyncsvc.Delta `json:"data"`
+		}
+		if rr.Code == http.StatusOK {
+			if err := json.Unmarshal(rr.Body.Bytes(), &body); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+		}
+		return rr.Code, body.Data
+	}
+
+	// when: a full download in pages of one
+	var songs []deltasyncsvc.Song
+	var languages []deltasyncsvc.Named
+	cursor := ""
+	for page := 0; ; page++ {
+		if page > 10000 {
+			t.Fatal("full download did not finish")
+		}
+		status, delta := fetch(lagged, ownerID, cursor, 1)
+		if status != http.StatusOK {
+			t.Fatalf("full download: got %d want %d", status, http.StatusOK)
+		}
+		songs = append(songs, delta.Changes.Songs...)
+		languages = append(languages, delta.Changes.Languages...)
+		cursor = delta.Cursor
+		if !delta.HasMore {
+			break
+		}
+	}
+
+	// then
+	if !containsSong(songs, songID) || !containsNamed(languages, languageID) {
+		t.Fatalf("full download missed the seeded song or language")
+	}
+
+	// given: changes after the full download
+	if err := tx.QueryRow(ctx, "insert into playlists (name, user_id) values ('setlist', $1) returning id", ownerID).Scan(&playlistID); err != nil {
+		t.Fatalf("failed to insert playlist: %v", err)
+	}
+	if _, err := tx.Exec(ctx, "insert into playlist_user (playlist_id, user_id) values ($1, $2)", playlistID, memberID); err != nil {
+		t.Fatalf("failed to share playlist: %v", err)
+	}
+	if _, err := tx.Exec(ctx, "delete from playlist_user where playlist_id = $1 and user_id = $2", playlistID, memberID); err != nil {
+		t.Fatalf("failed to unshare playlist: %v", err)
+	}
+	if _, err := tx.Exec(ctx, "delete from songs where id = $1", songID); err != nil {
+		t.Fatalf("failed to delete song: %v", err)
+	}
+
+	// when
+	status, delta := fetch(current, ownerID, cursor, 0)
+
+	// then
+	if status != http.StatusOK || delta.HasMore {
+		t.Fatalf("incremental sync: got %d, has_more %v", status, delta.HasMore)
+	}
+	if containsSong(delta.Changes.Songs, songID) || !containsID(delta.Deleted[deltasyncsvc.EntitySongs], songID) {
+		t.Fatalf("expected song %d to be deleted, got %+v", songID, delta.Deleted)
+	}
+	if len(delta.Changes.Playlists) != 1 || delta.Changes.Playlists[0].ID != playlistID || delta.Changes.Playlists[0].Role != "owner" {
+		t.Fatalf("expected the new playlist, got %+v", delta.Changes.Playlists)
+	}
+	if containsID(delta.Deleted[deltasyncsvc.EntityPlaylists], playlistID) {
+		t.Fatalf("owner should not see the member's playlist removal")
+	}
+
+	status, delta = fetch(current, memberID, cursor, 0)
+	if status != http.StatusOK || !containsID(delta.Deleted[deltasyncsvc.EntityPlaylists], playlistID) {
+		t.Fatalf("expected the removed member to get a playlist tombstone, got %d %+v", status, delta.Deleted)
+	}
+
+	if status, _ := fetch(current, ownerID, "not-a-cursor", 0); status != http.StatusUnprocessableEntity {
+		t.Fatalf("invalid cursor: got %d want %d", status, http.StatusUnprocessableEntity)
+	}
+}
+
+func containsSong(songs []deltasyncsvc.Song, id int) bool {
+	for _, song := range songs {
+		if song.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+func containsNamed(items []deltasyncsvc.Named, id int) bool {
+	for _, item := range items {
+		if item.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+func containsID(ids []int, id int) bool {
+	for _, candidate := range ids {
+		if candidate == id {
+			return true
+		}
+	}
+	return false
+}
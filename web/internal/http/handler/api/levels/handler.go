@@ -19,10 +19,16 @@ func New(svc levelsvc.Service) Handler {
 
 // List responds with all available levels.
 func (h Handler) List(w http.ResponseWriter, r *http.Request) {
+	modified, err := h.svc.LastModified(r.Context())
+	if err != nil {
+		handler.Error(w, err)
+		return
+	}
 	levels, err := h.svc.List(r.Context())
 	if err != nil {
 		handler.Error(w, err)
 		return
 	}
+	handler.LastModified(w, modified)
 	handler.Success(w, http.StatusOK, levels)
 }
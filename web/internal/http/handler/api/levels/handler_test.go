@@ -59,3 +59,45 @@ func TestHandler_List(t *testing.T) {
 		t.Fatalf("data mot match")
 	}
 }
+
+func TestHandler_List_LastModified(t *testing.T) {
+	conn := testutil.SetupDB(t)
+	defer conn.Close()
+
+	ctx := context.Background()
+	tx, _ := conn.Begin(ctx)
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, "insert into levels (name, updated_at) values ('Easy', '2026-10-01 12:30:15')"); err != nil {
+		t.Fatalf("failed to seed levels table: %v", err)
+	}
+	h := handler.Conditional(handler.CachePolicy{CacheControl: "public, max-age=60"})(http.HandlerFunc(getHandler(tx).List))
+	get := func(since string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/api/levels", nil)
+		if since != "" {
+			req.Header.Set("If-Modified-Since", since)
+		}
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+		return rr
+	}
+
+	// when
+	first := get("")
+	unchanged := get(first.Header().Get("Last-Modified"))
+	if _, err := tx.Exec(ctx, "insert into levels (name) values ('Hard')"); err != nil {
+		t.Fatalf("failed to add a level: %v", err)
+	}
+	changed := get(first.Header().Get("Last-Modified"))
+
+	// then
+	if got := first.Header().Get("Last-Modified"); got != "Thu, 01 Oct 2026 12:30:15 GMT" {
+		t.Errorf("unexpected last-modified %q", got)
+	}
+	if unchanged.Code != http.StatusNotModified {
+		t.Errorf("unchanged: got %d want %d", unchanged.Code, http.StatusNotModified)
+	}
+	if changed.Code != http.StatusOK {
+		t.Errorf("changed: got %d want %d", changed.Code, http.StatusOK)
+	}
+}
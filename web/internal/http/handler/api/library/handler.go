@@ -0,0 +1,119 @@
+package library
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/lyricapp/lyric/web/internal/apperror"
+	"github.com/lyricapp/lyric/web/internal/http/handler"
+	"github.com/lyricapp/lyric/web/internal/http/handler/api/util"
+	librarysvc "github.com/lyricapp/lyric/web/internal/services/library"
+)
+
+// Handler serves the current user's favourites and recently viewed songs.
+type Handler struct {
+	svc librarysvc.Service
+}
+
+// New constructs a library handler.
+func New(svc librarysvc.Service) Handler {
+	return Handler{svc: svc}
+}
+
+// Favorites lists the current user's favourite songs, albums and artists.
+func (h Handler) Favorites(w http.ResponseWriter, r *http.Request) {
+	userID, err := util.CurrentUserID(r)
+	if err != nil {
+		handler.Error(w, err)
+		return
+	}
+
+	favorites, err := h.svc.Favorites(r.Context(), userID)
+	if err != nil {
+		handler.Error(w, err)
+		return
+	}
+	handler.Success(w, http.StatusOK, favorites)
+}
+
+// AddFavorite favourites the song, album or artist named in the URL.
+func (h Handler) AddFavorite(w http.ResponseWriter, r *http.Request) {
+	userID, kind, id, err := favoriteTarget(r)
+	if err != nil {
+		handler.Error(w, err)
+		return
+	}
+
+	if err := h.svc.AddFavorite(r.Context(), userID, kind, id); err != nil {
+		handler.Error(w, err)
+		return
+	}
+	handler.Success(w, http.StatusOK, map[string]string{
+		"message": "Added to favourites",
+	})
+}
+
+// RemoveFavorite removes the song, album or artist named in the URL from the
+// current user's favourites.
+func (h Handler) RemoveFavorite(w http.ResponseWriter, r *http.Request) {
+	userID, kind, id, err := favoriteTarget(r)
+	if err != nil {
+		handler.Error(w, err)
+		return
+	}
+
+	if err := h.svc.RemoveFavorite(r.Context(), userID, kind, id); err != nil {
+		handler.Error(w, err)
+		return
+	}
+	handler.Success(w, http.StatusOK, map[string]string{
+		"message": "Removed from favourites",
+	})
+}
+
+// Recent lists the songs the current user opened most recently.
+func (h Handler) Recent(w http.ResponseWriter, r *http.Request) {
+	userID, err := util.CurrentUserID(r)
+	if err != nil {
+		handler.Error(w, err)
+		return
+	}
+
+	validationErrors := map[string]string{}
+	limit := util.ParseOptionalPositiveInt(r.URL.Query().Get("limit"), "limit", validationErrors)
+	if len(validationErrors) > 0 {
+		handler.Error(w, apperror.Validation("failed validation", validationErrors))
+		return
+	}
+	if limit == nil {
+		limit = new(int)
+	}
+
+	songs, err := h.svc.Recent(r.Context(), userID, *limit)
+	if err != nil {
+		handler.Error(w, err)
+		return
+	}
+	handler.Success(w, http.StatusOK, songs)
+}
+
+// favoriteTarget reads the current user and the {kind}/{id} favourite from the
+// request.
+func favoriteTarget(r *http.Request) (int, librarysvc.Kind, int, error) {
+	userID, err := util.CurrentUserID(r)
+	if err != nil {
+		return 0, "", 0, err
+	}
+	kind, err := librarysvc.ParseKind(chi.URLParam(r, "kind"))
+	if err != nil {
+		return 0, "", 0, err
+	}
+	id, err := strconv.Atoi(strings.TrimSpace(chi.URLParam(r, "id")))
+	if err != nil || id <= 0 {
+		return 0, "", 0, apperror.BadRequest("Invalid id")
+	}
+	return userID, kind, id, nil
+}
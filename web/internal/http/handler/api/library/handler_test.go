@@ -0,0 +1,221 @@
+package library_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/lyricapp/lyric/web/internal/http/handler"
+	"github.com/lyricapp/lyric/web/internal/http/handler/api/library"
+	"github.com/lyricapp/lyric/web/internal/http/handler/api/songs"
+	auditsvc "github.com/lyricapp/lyric/web/internal/services/audit"
+	librarysvc "github.com/lyricapp/lyric/web/internal/services/library"
+	songsvc "github.com/lyricapp/lyric/web/internal/services/songs"
+	"github.com/lyricapp/lyric/web/internal/storage"
+	auditrepo "github.com/lyricapp/lyric/web/internal/storage/postgres/audit"
+	libraryrepo "github.com/lyricapp/lyric/web/internal/storage/postgres/library"
+	songrepo "github.com/lyricapp/lyric/web/internal/storage/postgres/songs"
+	"github.com/lyricapp/lyric/web/internal/testutil"
+)
+
+func getHandler(conn storage.Querier) library.Handler {
+	return library.New(librarysvc.NewService(libraryrepo.NewRepository(conn)))
+}
+
+func getSongsHandler(conn storage.Querier) songs.Handler {
+	return songs.New(songsvc.NewService(songrepo.NewRepository(conn)), auditsvc.NewService(auditrepo.NewRepository(conn)))
+}
+
+// seed adds a user and two songs, the first by a named artist.
+func seed(t *testing.T, tx storage.Querier) (userID, songID, otherSongID, artistID int) {
+	t.Helper()
+	ctx := context.Background()
+	var langID int
+	if err := tx.QueryRow(ctx, "insert into users (email, role) values ('library@test.com', 'musician') returning id").Scan(&userID); err != nil {
+		t.Fatalf("failed to seed user: %v", err)
+	}
+	if err := tx.QueryRow(ctx, "insert into languages (name) values ('english') returning id").Scan(&langID); err != nil {
+		t.Fatalf("failed to seed language: %v", err)
+	}
+	if err := tx.QueryRow(ctx, "insert into songs (title, language_id, key) values ('first', $1, 'G') returning id", langID).Scan(&songID); err != nil {
+		t.Fatalf("failed to seed song: %v", err)
+	}
+	if err := tx.QueryRow(ctx, "insert into songs (title, language_id) values ('second', $1) returning id", langID).Scan(&otherSongID); err != nil {
+		t.Fatalf("failed to seed song: %v", err)
+	}
+	if err := tx.QueryRow(ctx, "insert into artists (name) values ('band') returning id").Scan(&artistID); err != nil {
+		t.Fatalf("failed to seed artist: %v", err)
+	}
+	if _, err := tx.Exec(ctx, "insert into artist_song (artist_id, song_id) values ($1, $2)", artistID, songID); err != nil {
+		t.Fatalf("failed to seed artist song: %v", err)
+	}
+	return userID, songID, otherSongID, artistID
+}
+
+func TestHandler_Favorites(t *testing.T) {
+	conn := testutil.SetupDB(t)
+	defer conn.Close()
+
+	ctx := context.Background()
+	tx, _ := conn.Begin(ctx)
+	defer tx.Rollback(ctx)
+
+	userID, songID, otherSongID, artistID := seed(t, tx)
+
+	h := getHandler(tx)
+	r, accessToken := testutil.AuthToken(t, userID)
+	r.Get("/api/me/favorites", h.Favorites)
+	r.Put("/api/me/favorites/{kind}/{id}", h.AddFavorite)
+	r.Delete("/api/me/favorites/{kind}/{id}", h.RemoveFavorite)
+	r.Get("/api/songs", getSongsHandler(tx).List)
+
+	send := func(method, url string) *httptest.ResponseRecorder {
+		req, err := http.NewRequest(method, url, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", accessToken))
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		return rr
+	}
+	favorites := func() librarysvc.Favorites {
+		rr := send("GET", "/api/me/favorites")
+		if rr.Code != http.StatusOK {
+			t.Fatalf("unexpected status code: got %d want %d", rr.Code, http.StatusOK)
+		}
+		var res handler.ResponseMessage[librarysvc.Favorites]
+		if err := json.NewDecoder(rr.Body).Decode(&res); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		return res.Data
+	}
+
+	t.Run("add", func(t *testing.T) {
+		for _, url := range []string{
+			fmt.Sprintf("/api/me/favorites/songs/%d", songID),
+			fmt.Sprintf("/api/me/favorites/songs/%d", songID),
+			fmt.Sprintf("/api/me/favorites/artists/%d", artistID),
+		} {
+			if rr := send("PUT", url); rr.Code != http.StatusOK {
+				t.Fatalf("PUT %s: got %d want %d: %s", url, rr.Code, http.StatusOK, rr.Body.String())
+			}
+		}
+
+		got := favorites()
+		if len(got.Songs) != 1 || got.Songs[0].ID != songID || len(got.Songs[0].Artists) != 1 || got.Songs[0].Artists[0] != "band" {
+			t.Errorf("unexpected favourite songs: %+v", got.Songs)
+		}
+		if len(got.Artists) != 1 || got.Artists[0].ID != artistID {
+			t.Errorf("unexpected favourite artists: %+v", got.Artists)
+		}
+		if len(got.Albums) != 0 {
+			t.Errorf("expected no favourite albums, got %+v", got.Albums)
+		}
+	})
+
+	t.Run("songs filter", func(t *testing.T) {
+		rr := send("GET", "/api/songs?favorite=1")
+		if rr.Code != http.StatusOK {
+			t.Fatalf("unexpected status code: got %d want %d", rr.Code, http.StatusOK)
+		}
+		var res handler.PageResponse[songsvc.Song]
+		if err := json.NewDecoder(rr.Body).Decode(&res); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if res.Total != 1 || len(res.Data) != 1 || res.Data[0].ID != songID || !res.Data[0].IsFavorite {
+			t.Errorf("expected only the favourite song, got %+v", res.Data)
+		}
+	})
+
+	t.Run("missing entries", func(t *testing.T) {
+		if rr := send("PUT", fmt.Sprintf("/api/me/favorites/albums/%d", otherSongID+1000)); rr.Code != http.StatusNotFound {
+			t.Errorf("unknown album: got %d want %d", rr.Code, http.StatusNotFound)
+		}
+		if rr := send("PUT", fmt.Sprintf("/api/me/favorites/playlists/%d", songID)); rr.Code != http.StatusNotFound {
+			t.Errorf("unknown kind: got %d want %d", rr.Code, http.StatusNotFound)
+		}
+	})
+
+	t.Run("remove", func(t *testing.T) {
+		if rr := send("DELETE", fmt.Sprintf("/api/me/favorites/songs/%d", songID)); rr.Code != http.StatusOK {
+			t.Fatalf("unexpected status code: got %d want %d", rr.Code, http.StatusOK)
+		}
+		if got := favorites(); len(got.Songs) != 0 || len(got.Artists) != 1 {
+			t.Errorf("expected only the song to be removed, got %+v", got)
+		}
+	})
+}
+
+func TestHandler_Recent(t *testing.T) {
+	conn := testutil.SetupDB(t)
+	defer conn.Close()
+
+	ctx := context.Background()
+	tx, _ := conn.Begin(ctx)
+	defer tx.Rollback(ctx)
+
+	userID, songID, otherSongID, _ := seed(t, tx)
+
+	h := getHandler(tx)
+	r, accessToken := testutil.AuthToken(t, userID)
+	r.Get("/api/me/recent", h.Recent)
+	r.Post("/api/songs/{id}/plays", getSongsHandler(tx).Play)
+
+	send := func(method, url string) *httptest.ResponseRecorder {
+		req, err := http.NewRequest(method, url, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", accessToken))
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		return rr
+	}
+
+	play := func(id int) {
+		t.Helper()
+		if rr := send("POST", fmt.Sprintf("/api/songs/%d/plays", id)); rr.Code != http.StatusOK {
+			t.Fatalf("play song %d: got %d want %d: %s", id, rr.Code, http.StatusOK, rr.Body.String())
+		}
+	}
+
+	// given
+	play(songID)
+	play(otherSongID)
+	// The inserts share a transaction timestamp; order the plays explicitly.
+	if _, err := tx.Exec(ctx, "update plays set created_at = now() - interval '2 hours' where user_id = $1 and song_id = $2", userID, songID); err != nil {
+		t.Fatalf("failed to age play: %v", err)
+	}
+	if _, err := tx.Exec(ctx, "update plays set created_at = now() - interval '1 hour' where user_id = $1 and song_id = $2", userID, otherSongID); err != nil {
+		t.Fatalf("failed to age play: %v", err)
+	}
+	// Playing again counts once within the window.
+	play(songID)
+	play(songID)
+
+	// when
+	rr := send("GET", "/api/me/recent")
+
+	// then
+	if rr.Code != http.StatusOK {
+		t.Fatalf("unexpected status code: got %d want %d", rr.Code, http.StatusOK)
+	}
+	var res handler.ResponseMessage[[]librarysvc.RecentSong]
+	if err := json.NewDecoder(rr.Body).Decode(&res); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(res.Data) != 2 {
+		t.Fatalf("expected each song once, got %+v", res.Data)
+	}
+	if res.Data[0].ID != songID || res.Data[0].Views != 2 || res.Data[1].ID != otherSongID {
+		t.Errorf("unexpected recent songs: %+v", res.Data)
+	}
+
+	if rr := send("GET", "/api/me/recent?limit=0"); rr.Code != http.StatusUnprocessableEntity {
+		t.Errorf("invalid limit: got %d want %d", rr.Code, http.StatusUnprocessableEntity)
+	}
+}
@@ -0,0 +1,197 @@
+package livesessions
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/lyricapp/lyric/web/internal/apperror"
+	"github.com/lyricapp/lyric/web/internal/http/handler"
+	"github.com/lyricapp/lyric/web/internal/http/handler/api/util"
+	livesessionsvc "github.com/lyricapp/lyric/web/internal/services/livesessions"
+)
+
+// heartbeatInterval keeps idle event streams open through proxies.
+const heartbeatInterval = 25 * time.Second
+
+// Handler serves live band sessions.
+type Handler struct {
+	svc livesessionsvc.Service
+}
+
+// New constructs a live session handler.
+func New(svc livesessionsvc.Service) Handler {
+	return Handler{svc: svc}
+}
+
+func request(w http.ResponseWriter, r *http.Request) (playlistID, userID int, ok bool) {
+	userID, authErr := util.CurrentUserID(r)
+	if authErr != nil {
+		handler.Error(w, authErr)
+		return 0, 0, false
+	}
+	playlistID, err := strconv.Atoi(strings.TrimSpace(chi.URLParam(r, "id")))
+	if err != nil || playlistID <= 0 {
+		handler.Error(w, apperror.Validation("msg", map[string]string{"id": "id must be a positive integer"}))
+		return 0, 0, false
+	}
+	return playlistID, userID, true
+}
+
+// Open starts a live session with the caller as leader.
+func (h Handler) Open(w http.ResponseWriter, r *http.Request) {
+	playlistID, userID, ok := request(w, r)
+	if !ok {
+		return
+	}
+
+	session, err := h.svc.Open(r.Context(), playlistID, userID)
+	if err != nil {
+		handler.Error(w, err)
+		return
+	}
+
+	handler.Success(w, http.StatusCreated, session)
+}
+
+// Show returns the running session and its current state.
+func (h Handler) Show(w http.ResponseWriter, r *http.Request) {
+	playlistID, userID, ok := request(w, r)
+	if !ok {
+		return
+	}
+
+	session, err := h.svc.Get(r.Context(), playlistID, userID)
+	if err != nil {
+		handler.Error(w, err)
+		return
+	}
+
+	handler.Success(w, http.StatusOK, session)
+}
+
+// Update broadcasts the leader's song, section, transpose and scroll position.
+func (h Handler) Update(w http.ResponseWriter, r *http.Request) {
+	playlistID, userID, ok := request(w, r)
+	if !ok {
+		return
+	}
+
+	var payload struct {
+		SongID    *int     `json:"song_id"`
+		Section   *string  `json:"section"`
+		Transpose *int     `json:"transpose"`
+		Scroll    *float64 `json:"scroll"`
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&payload); err != nil {
+		handler.Error(w, apperror.BadRequest("invalid JSON payload"))
+		return
+	}
+
+	session, err := h.svc.Update(r.Context(), playlistID, userID, livesessionsvc.UpdateParams{
+		SongID:    payload.SongID,
+		Section:   payload.Section,
+		Transpose: payload.Transpose,
+		Scroll:    payload.Scroll,
+	})
+	if err != nil {
+		handler.Error(w, err)
+		return
+	}
+
+	handler.Success(w, http.StatusOK, session)
+}
+
+// End stops the live session for everyone.
+func (h Handler) End(w http.ResponseWriter, r *http.Request) {
+	playlistID, userID, ok := request(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.svc.End(r.Context(), playlistID, userID); err != nil {
+		handler.Error(w, err)
+		return
+	}
+
+	handler.Success(w, http.StatusOK, map[string]string{
+		"message": "Session ended successfully",
+	})
+}
+
+// Events streams the session as Server-Sent Events. The first event is the
+// current state, so late joiners catch up immediately. The stream ends with an
+// "ended" event, or without one when the server shuts down and clients should
+// reconnect.
+func (h Handler) Events(w http.ResponseWriter, r *http.Request) {
+	playlistID, userID, ok := request(w, r)
+	if !ok {
+		return
+	}
+
+	session, events, release, err := h.svc.Join(r.Context(), playlistID, userID)
+	if err != nil {
+		handler.Error(w, err)
+		return
+	}
+	defer release()
+
+	controller := http.NewResponseController(w)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	if err := writeEvent(w, controller, livesessionsvc.Event{Type: livesessionsvc.EventState, Session: session}); err != nil {
+		return
+	}
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return
+			}
+			if err := controller.Flush(); err != nil {
+				return
+			}
+		case event, open := <-events:
+			if !open {
+				return
+			}
+			if err := writeEvent(w, controller, event); err != nil {
+				return
+			}
+			if event.Type == livesessionsvc.EventEnded {
+				return
+			}
+		}
+	}
+}
+
+func writeEvent(w http.ResponseWriter, controller *http.ResponseController, event livesessionsvc.Event) error {
+	data, err := json.Marshal(event.Session)
+	if err != nil {
+		log.Printf("ERROR: could not marshal session event: %v", err)
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.Session.State.Version, event.Type, data); err != nil {
+		return err
+	}
+	return controller.Flush()
+}
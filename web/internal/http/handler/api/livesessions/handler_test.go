@@ -0,0 +1,220 @@
+package livesessions_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/lyricapp/lyric/web/internal/http/handler/api/livesessions"
+	livesessionsvc "github.com/lyricapp/lyric/web/internal/services/livesessions"
+	livesessionrepo "github.com/lyricapp/lyric/web/internal/storage/postgres/livesessions"
+	"github.com/lyricapp/lyric/web/internal/testutil"
+)
+
+type sseEvent struct {
+	name    string
+	session livesessionsvc.Session
+}
+
+func readEvent(t *testing.T, reader *bufio.Reader) sseEvent {
+	t.Helper()
+	var event sseEvent
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("failed to read event stream: %v", err)
+		}
+		line = strings.TrimRight(line, "\n")
+		switch {
+		case line == "":
+			if event.name != "" {
+				return event
+			}
+		case strings.HasPrefix(line, "event: "):
+			event.name = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &event.session); err != nil {
+				t.Fatalf("failed to decode event data: %v", err)
+			}
+		}
+	}
+}
+
+func TestHandler_LiveSession(t *testing.T) {
+	conn := testutil.SetupDB(t)
+	defer conn.Close()
+
+	ctx := context.Background()
+	tx, _ := conn.Begin(ctx)
+	defer tx.Rollback(ctx)
+
+	// given
+	var leaderID, memberID, strangerID, languageID, songID, playlistID int
+	for email, id := range map[string]*int{
+		"leader-live@user.com":   &leaderID,
+		"member-live@user.com":   &memberID,
+		"stranger-live@user.com": &strangerID,
+	} {
+		if err := tx.QueryRow(ctx, "insert into users (email, role) values ($1, 'musician') returning id", email).Scan(id); err != nil {
+			t.Fatalf("failed to insert user: %v", err)
+		}
+	}
+	if err := tx.QueryRow(ctx, "insert into languages (name) values ('live') returning id").Scan(&languageID); err != nil {
+		t.Fatalf("failed to insert language: %v", err)
+	}
+	if err := tx.QueryRow(ctx, "insert into songs (title, language_id) values ('opener', $1) returning id", languageID).Scan(&songID); err != nil {
+		t.Fatalf("failed to insert song: %v", err)
+	}
+	if err := tx.QueryRow(ctx, "insert into playlists (name, user_id) values ('gig', $1) returning id", leaderID).Scan(&playlistID); err != nil {
+		t.Fatalf("failed to insert playlist: %v", err)
+	}
+	if _, err := tx.Exec(ctx, "insert into playlist_song (playlist_id, song_id, position) values ($1, $2, 1)", playlistID, songID); err != nil {
+		t.Fatalf("failed to add song: %v", err)
+	}
+	if _, err := tx.Exec(ctx, "insert into playlist_user (playlist_id, user_id) values ($1, $2)", playlistID, memberID); err != nil {
+		t.Fatalf("failed to share playlist: %v", err)
+	}
+
+	svc := livesessionsvc.NewService(livesessionrepo.NewRepository(tx), livesessionsvc.NewLocalFanOut())
+	defer svc.Shutdown()
+	h := livesessions.New(svc)
+	path := fmt.Sprintf("/api/playlists/%d/session", playlistID)
+	send := func(userID int, method string, fn http.HandlerFunc, body any) int {
+		r, accessToken := testutil.AuthToken(t, userID)
+		r.Method(method, "/api/playlists/{id}/session", fn)
+		payload, _ := json.Marshal(body)
+		req, err := http.NewRequest(method, path, bytes.NewBuffer(payload))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", accessToken))
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		return rr.Code
+	}
+
+	// when / then
+	if status := send(memberID, "POST", h.Open, nil); status != http.StatusForbidden {
+		t.Fatalf("viewer opening session: got %d want %d", status, http.StatusForbidden)
+	}
+	if status := send(leaderID, "POST", h.Open, nil); status != http.StatusCreated {
+		t.Fatalf("opening session: got %d want %d", status, http.StatusCreated)
+	}
+	if status := send(leaderID, "PUT", h.Update, map[string]any{"song_id": songID, "section": "Verse 1", "transpose": 2, "scroll": 0.25}); status != http.StatusOK {
+		t.Fatalf("updating state: got %d want %d", status, http.StatusOK)
+	}
+	if status := send(memberID, "PUT", h.Update, map[string]any{"scroll": 0.5}); status != http.StatusForbidden {
+		t.Fatalf("member updating state: got %d want %d", status, http.StatusForbidden)
+	}
+	if status := send(leaderID, "PUT", h.Update, map[string]any{"scroll": 2}); status != http.StatusUnprocessableEntity {
+		t.Fatalf("invalid scroll: got %d want %d", status, http.StatusUnprocessableEntity)
+	}
+	if status := send(strangerID, "GET", h.Show, nil); status != http.StatusNotFound {
+		t.Fatalf("stranger reading session: got %d want %d", status, http.StatusNotFound)
+	}
+
+	// a late joiner receives the current state, then live updates
+	r, accessToken := testutil.AuthToken(t, memberID)
+	r.Get("/api/playlists/{id}/session/events", h.Events)
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	req, err := http.NewRequest("GET", server.URL+path+"/events", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", accessToken))
+	res, err := server.Client().Do(req)
+	if err != nil {
+		t.Fatalf("failed to open event stream: %v", err)
+	}
+	defer res.Body.Close()
+	if ct := res.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("unexpected content type %q", ct)
+	}
+	stream := bufio.NewReader(res.Body)
+
+	first := readEvent(t, stream)
+	if first.name != "state" || first.session.State.Section != "Verse 1" || first.session.State.Transpose != 2 {
+		t.Fatalf("unexpected first event: %+v", first)
+	}
+
+	if status := send(leaderID, "PUT", h.Update, map[string]any{"section": "Chorus"}); status != http.StatusOK {
+		t.Fatalf("updating state: got %d want %d", status, http.StatusOK)
+	}
+	next := readEvent(t, stream)
+	if next.name != "state" || next.session.State.Section != "Chorus" || next.session.State.Version <= first.session.State.Version {
+		t.Fatalf("unexpected update event: %+v", next)
+	}
+
+	if status := send(leaderID, "DELETE", h.End, nil); status != http.StatusOK {
+		t.Fatalf("ending session: got %d want %d", status, http.StatusOK)
+	}
+	if ended := readEvent(t, stream); ended.name != "ended" {
+		t.Fatalf("expected ended event, got %+v", ended)
+	}
+	if status := send(memberID, "GET", h.Show, nil); status != http.StatusNotFound {
+		t.Fatalf("reading ended session: got %d want %d", status, http.StatusNotFound)
+	}
+}
+
+func TestHandler_LiveSession_DemotedLeader(t *testing.T) {
+	conn := testutil.SetupDB(t)
+	defer conn.Close()
+
+	ctx := context.Background()
+	tx, _ := conn.Begin(ctx)
+	defer tx.Rollback(ctx)
+
+	// given
+	var ownerID, editorID, playlistID int
+	if err := tx.QueryRow(ctx, "insert into users (email, role) values ('owner-demote@user.com', 'musician') returning id").Scan(&ownerID); err != nil {
+		t.Fatalf("failed to insert user: %v", err)
+	}
+	if err := tx.QueryRow(ctx, "insert into users (email, role) values ('editor-demote@user.com', 'musician') returning id").Scan(&editorID); err != nil {
+		t.Fatalf("failed to insert user: %v", err)
+	}
+	if err := tx.QueryRow(ctx, "insert into playlists (name, user_id) values ('gig', $1) returning id", ownerID).Scan(&playlistID); err != nil {
+		t.Fatalf("failed to insert playlist: %v", err)
+	}
+	if _, err := tx.Exec(ctx, "insert into playlist_user (playlist_id, user_id, role) values ($1, $2, 'editor')", playlistID, editorID); err != nil {
+		t.Fatalf("failed to share playlist: %v", err)
+	}
+
+	svc := livesessionsvc.NewService(livesessionrepo.NewRepository(tx), livesessionsvc.NewLocalFanOut())
+	defer svc.Shutdown()
+	h := livesessions.New(svc)
+	path := fmt.Sprintf("/api/playlists/%d/session", playlistID)
+	send := func(method string, fn http.HandlerFunc, body any) int {
+		r, accessToken := testutil.AuthToken(t, editorID)
+		r.Method(method, "/api/playlists/{id}/session", fn)
+		payload, _ := json.Marshal(body)
+		req, err := http.NewRequest(method, path, bytes.NewBuffer(payload))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", accessToken))
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		return rr.Code
+	}
+	if status := send("POST", h.Open, nil); status != http.StatusCreated {
+		t.Fatalf("opening session: got %d want %d", status, http.StatusCreated)
+	}
+
+	// when
+	if _, err := tx.Exec(ctx, "update playlist_user set role = 'viewer' where playlist_id = $1 and user_id = $2", playlistID, editorID); err != nil {
+		t.Fatalf("failed to demote editor: %v", err)
+	}
+
+	// then
+	if status := send("PUT", h.Update, map[string]any{"section": "Chorus"}); status != http.StatusForbidden {
+		t.Fatalf("demoted leader updating state: got %d want %d", status, http.StatusForbidden)
+	}
+}
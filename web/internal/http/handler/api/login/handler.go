@@ -3,21 +3,28 @@ package login
 import (
 	"encoding/json"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
 
 	"github.com/lyricapp/lyric/web/internal/apperror"
 	"github.com/lyricapp/lyric/web/internal/http/handler"
 	"github.com/lyricapp/lyric/web/internal/http/handler/api/util"
 	loginsvc "github.com/lyricapp/lyric/web/internal/services/login"
+	plansvc "github.com/lyricapp/lyric/web/internal/services/plans"
 )
 
 // Handler manages login OTP requests.
 type Handler struct {
-	svc loginsvc.Service
+	svc   loginsvc.Service
+	plans plansvc.Service
 }
 
 // New creates a login handler instance.
-func New(svc loginsvc.Service) Handler {
-	return Handler{svc: svc}
+func New(svc loginsvc.Service, plans plansvc.Service) Handler {
+	return Handler{svc: svc, plans: plans}
 }
 
 // Request accepts a username/email and issues an OTP code.
@@ -38,10 +45,11 @@ func (h Handler) Request(w http.ResponseWriter, r *http.Request) {
 	handler.Success(w, http.StatusOK, map[string]string{"message": "Success"})
 }
 
-// Verify handles OTP code verification and token issuance.
+// Verify handles OTP code verification and signs the device in.
 func (h Handler) Verify(w http.ResponseWriter, r *http.Request) {
 	var payload struct {
-		Code any `json:"code"`
+		Code       any    `json:"code"`
+		DeviceName string `json:"device_name"`
 	}
 
 	decoder := json.NewDecoder(r.Body)
@@ -51,12 +59,124 @@ func (h Handler) Verify(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	result, svcErr := h.svc.VerifyCode(r.Context(), payload.Code)
+	result, svcErr := h.svc.VerifyCode(r.Context(), payload.Code, loginsvc.Device{
+		Name:      payload.DeviceName,
+		UserAgent: r.UserAgent(),
+	})
 	if svcErr != nil {
 		handler.Error(w, svcErr)
 		return
 	}
-	handler.Success(w, http.StatusOK, map[string]string{"access_token": result.Token})
+	handler.Success(w, http.StatusOK, tokenResponse(result))
+}
+
+// SignIn exchanges an ID token from a configured OpenID Connect provider, such as
+// Sign in with Google or Apple, for the same tokens as Verify.
+func (h Handler) SignIn(w http.ResponseWriter, r *http.Request) {
+	var payload struct {
+		Provider   string `json:"provider"`
+		IDToken    string `json:"id_token"`
+		Nonce      string `json:"nonce"`
+		DeviceName string `json:"device_name"`
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&payload); err != nil {
+		handler.Error(w, apperror.BadRequest("invalid json payload"))
+		return
+	}
+
+	result, err := h.svc.SignInWithIDToken(r.Context(), payload.Provider, payload.IDToken, payload.Nonce, loginsvc.Device{
+		Name:      payload.DeviceName,
+		UserAgent: r.UserAgent(),
+	})
+	if err != nil {
+		handler.Error(w, err)
+		return
+	}
+	handler.Success(w, http.StatusOK, tokenResponse(result))
+}
+
+// Refresh trades a refresh token for a new access and refresh token.
+func (h Handler) Refresh(w http.ResponseWriter, r *http.Request) {
+	var payload struct {
+		RefreshToken string `json:"refresh_token"`
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&payload); err != nil {
+		handler.Error(w, apperror.BadRequest("invalid json payload"))
+		return
+	}
+
+	result, err := h.svc.Refresh(r.Context(), payload.RefreshToken)
+	if err != nil {
+		handler.Error(w, err)
+		return
+	}
+	handler.Success(w, http.StatusOK, tokenResponse(result))
+}
+
+func tokenResponse(result loginsvc.VerifyResult) map[string]string {
+	return map[string]string{
+		"access_token":  result.Token,
+		"token_type":    "Bearer",
+		"expires_at":    result.ExpiresAt.UTC().Format(time.RFC3339),
+		"refresh_token": result.RefreshToken,
+	}
+}
+
+// Sessions lists the devices signed in to the account.
+func (h Handler) Sessions(w http.ResponseWriter, r *http.Request) {
+	userID, err := util.CurrentUserID(r)
+	if err != nil {
+		handler.Error(w, err)
+		return
+	}
+
+	sessions, err := h.svc.Sessions(r.Context(), userID, util.CurrentSessionID(r))
+	if err != nil {
+		handler.Error(w, err)
+		return
+	}
+	handler.Success(w, http.StatusOK, sessions)
+}
+
+// RevokeSession signs one device out.
+func (h Handler) RevokeSession(w http.ResponseWriter, r *http.Request) {
+	userID, err := util.CurrentUserID(r)
+	if err != nil {
+		handler.Error(w, err)
+		return
+	}
+	sessionID, err := strconv.ParseInt(strings.TrimSpace(chi.URLParam(r, "id")), 10, 64)
+	if err != nil || sessionID <= 0 {
+		handler.Error(w, apperror.Validation("msg", map[string]string{"id": "id must be a positive integer"}))
+		return
+	}
+
+	if err := h.svc.RevokeSession(r.Context(), userID, sessionID); err != nil {
+		handler.Error(w, err)
+		return
+	}
+	handler.Success(w, http.StatusOK, map[string]string{"message": "Session signed out"})
+}
+
+// RevokeOtherSessions signs out every device except the one making the request.
+func (h Handler) RevokeOtherSessions(w http.ResponseWriter, r *http.Request) {
+	userID, err := util.CurrentUserID(r)
+	if err != nil {
+		handler.Error(w, err)
+		return
+	}
+
+	if err := h.svc.RevokeOtherSessions(r.Context(), userID, util.CurrentSessionID(r)); err != nil {
+		handler.Error(w, err)
+		return
+	}
+	handler.Success(w, http.StatusOK, map[string]string{"message": "Other sessions signed out"})
 }
 
 // Me returns the authenticated user's profile.
@@ -71,14 +191,21 @@ func (h Handler) Me(w http.ResponseWriter, r *http.Request) {
 		handler.Error(w, err)
 		return
 	}
+	plan, err := h.plans.Plan(r.Context(), user.ID)
+	if err != nil {
+		handler.Error(w, err)
+		return
+	}
 	handler.Success(w, http.StatusOK, map[string]any{
 		"id":       user.ID,
 		"username": user.Email,
 		"role":     user.Role,
+		"plan":     plan,
 	})
 }
 
-// Delete marks the authenticated user's account as deleted.
+// Delete marks the authenticated user's account as deleted. Signing in again
+// before purge_at restores it.
 func (h Handler) Delete(w http.ResponseWriter, r *http.Request) {
 	userID, err := util.CurrentUserID(r)
 	if err != nil {
@@ -86,12 +213,14 @@ func (h Handler) Delete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.svc.DeleteAccount(r.Context(), userID); err != nil {
+	purgeAt, err := h.svc.DeleteAccount(r.Context(), userID)
+	if err != nil {
 		handler.Error(w, err)
 		return
 	}
 
 	handler.Success(w, http.StatusOK, map[string]string{
-		"message": "Account deleted successfully",
+		"message":  "Account deleted successfully",
+		"purge_at": purgeAt.UTC().Format(time.RFC3339),
 	})
 }
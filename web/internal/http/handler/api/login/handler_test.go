@@ -8,36 +8,50 @@ import (
 	"log"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"testing"
 	"time"
 
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/jwtauth/v5"
+
 	"github.com/lyricapp/lyric/web/internal/config"
 	"github.com/lyricapp/lyric/web/internal/http/handler"
 	"github.com/lyricapp/lyric/web/internal/http/handler/api/login"
+	authmw "github.com/lyricapp/lyric/web/internal/http/middleware/auth"
 	loginsvc "github.com/lyricapp/lyric/web/internal/services/login"
+	plansvc "github.com/lyricapp/lyric/web/internal/services/plans"
 	"github.com/lyricapp/lyric/web/internal/storage"
 	loginrepo "github.com/lyricapp/lyric/web/internal/storage/postgres/login"
+	planrepo "github.com/lyricapp/lyric/web/internal/storage/postgres/plans"
 	"github.com/lyricapp/lyric/web/internal/testutil"
 )
 
-func getHandler(conn storage.Querier) login.Handler {
+func getService(conn storage.Querier, providers ...loginsvc.IdentityProvider) loginsvc.Service {
 	repo := loginrepo.NewRepository(conn)
 	cfg, err := config.Load()
 	if err != nil {
 		panic(err)
 	}
 	loginMailer := loginsvc.NewConsoleMailer(cfg.Auth.SMTP.From)
-	svc := loginsvc.NewService(
+	return loginsvc.NewService(
 		repo,
 		loginMailer,
 		loginsvc.Config{
-			CodeLength:  cfg.Auth.OTPLength,
-			TTL:         cfg.Auth.OTPTTL,
-			TokenSecret: cfg.Auth.TokenSecret,
-			TokenTTL:    cfg.Auth.TokenTTL,
+			CodeLength:    cfg.Auth.OTPLength,
+			TTL:           cfg.Auth.OTPTTL,
+			TokenSecret:   cfg.Auth.TokenSecret,
+			TokenTTL:      cfg.Auth.TokenTTL,
+			RefreshTTL:    cfg.Auth.RefreshTTL,
+			DeletionGrace: cfg.Auth.DeletionGrace,
 		},
+		providers...,
 	)
-	handler := login.New(svc)
+}
+
+func getHandler(conn storage.Querier) login.Handler {
+	plans := plansvc.NewService(planrepo.NewRepository(conn), plansvc.Config{})
+	handler := login.New(getService(conn), plans)
 	return handler
 }
 
@@ -558,13 +572,77 @@ func TestHandler_Delete_Success(t *testing.T) {
 		t.Fatalf("unexpected response message: %s", response.Data["message"])
 	}
 
+	purgeAt, err := time.Parse(time.RFC3339, response.Data["purge_at"])
+	if err != nil {
+		t.Fatalf("unexpected purge_at %q: %v", response.Data["purge_at"], err)
+	}
+	if !purgeAt.After(time.Now()) {
+		t.Fatalf("expected purge_at in the future, got %s", purgeAt)
+	}
+
 	var status string
-	if err := tx.QueryRow(ctx, "select status from users where id = $1", userID).Scan(&status); err != nil {
+	var purgeAfter *time.Time
+	if err := tx.QueryRow(ctx, "select status, purge_after from users where id = $1", userID).Scan(&status, &purgeAfter); err != nil {
 		t.Fatalf("failed to fetch user: %v", err)
 	}
 	if status != "deleted" {
 		t.Fatalf("expected user status to be deleted, got %s", status)
 	}
+	if purgeAfter == nil {
+		t.Fatal("expected purge_after to be set")
+	}
+}
+
+func TestHandler_Verify_RestoresDeletedUser(t *testing.T) {
+	conn := testutil.SetupDB(t)
+	defer conn.Close()
+
+	ctx := context.Background()
+	tx, _ := conn.Begin(ctx)
+	defer tx.Rollback(ctx)
+
+	// given
+	var pendingID, purgedID int
+	if err := tx.QueryRow(ctx, "insert into users (email, role, status, purge_after) values ('pending@test.com', 'musician', 'deleted', now() + interval '1 day') returning id").Scan(&pendingID); err != nil {
+		t.Fatalf("failed to seed user: %v", err)
+	}
+	if err := tx.QueryRow(ctx, "insert into users (email, role, status, purge_after) values ('due@test.com', 'musician', 'deleted', now() - interval '1 day') returning id").Scan(&purgedID); err != nil {
+		t.Fatalf("failed to seed user: %v", err)
+	}
+	expiresAt := time.Now().Add(5 * time.Minute)
+	if _, err := tx.Exec(ctx, "insert into user_login_codes (user_id, code, expires_at) values ($1, '123456', $3), ($2, '654321', $3)", pendingID, purgedID, expiresAt); err != nil {
+		t.Fatalf("failed to seed codes: %v", err)
+	}
+	h := getHandler(tx)
+
+	verify := func(code string) int {
+		body, _ := json.Marshal(map[string]string{"code": code})
+		req, _ := http.NewRequest("POST", "/api/code", bytes.NewBuffer(body))
+		rr := httptest.NewRecorder()
+		h.Verify(rr, req)
+		return rr.Code
+	}
+
+	// when
+	pendingStatus := verify("123456")
+	purgedStatus := verify("654321")
+
+	// then
+	if pendingStatus != http.StatusOK {
+		t.Fatalf("expected signing in during the grace period to succeed, got %d", pendingStatus)
+	}
+	if purgedStatus != http.StatusForbidden {
+		t.Fatalf("expected signing in after the grace period to be forbidden, got %d", purgedStatus)
+	}
+
+	var status string
+	var purgeAfter *time.Time
+	if err := tx.QueryRow(ctx, "select status, purge_after from users where id = $1", pendingID).Scan(&status, &purgeAfter); err != nil {
+		t.Fatalf("failed to fetch user: %v", err)
+	}
+	if status != "active" || purgeAfter != nil {
+		t.Fatalf("expected the account to be restored, got status %s purge_after %v", status, purgeAfter)
+	}
 }
 
 func TestHandler_Delete_Unauthorized(t *testing.T) {
@@ -596,3 +674,241 @@ func TestHandler_Delete_Unauthorized(t *testing.T) {
 		t.Fatalf("unexpected status code: got %d want %d", status, http.StatusUnauthorized)
 	}
 }
+
+func TestHandler_RefreshAndSessions(t *testing.T) {
+	conn := testutil.SetupDB(t)
+	defer conn.Close()
+
+	ctx := context.Background()
+	tx, _ := conn.Begin(ctx)
+	defer tx.Rollback(ctx)
+
+	// given
+	var userID int
+	if err := tx.QueryRow(ctx, "insert into users (email, role, status) values ('sessions@mail.com', 'musician', 'active') returning id").Scan(&userID); err != nil {
+		t.Fatalf("failed to insert user: %v", err)
+	}
+
+	svc := getService(tx)
+	h := getHandler(tx)
+	r := chi.NewRouter()
+	r.Use(jwtauth.Verifier(svc.TokenAuth()))
+	r.Post("/api/code", h.Verify)
+	r.Post("/api/token/refresh", h.Refresh)
+	r.Group(func(protected chi.Router) {
+		protected.Use(authmw.Authenticator(svc.TokenAuth(), svc))
+		protected.Get("/api/me/sessions", h.Sessions)
+		protected.Delete("/api/me/sessions", h.RevokeOtherSessions)
+		protected.Delete("/api/me/sessions/{id}", h.RevokeSession)
+	})
+	send := func(method, path, accessToken string, body any) (int, json.RawMessage) {
+		payload, _ := json.Marshal(body)
+		req, err := http.NewRequest(method, path, bytes.NewBuffer(payload))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if accessToken != "" {
+			req.Header.Set("Authorization", "Bearer "+accessToken)
+		}
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		var res struct {
+			Data json.RawMessage `json:"data"`
+		}
+		_ = json.Unmarshal(rr.Body.Bytes(), &res)
+		return rr.Code, res.Data
+	}
+	signIn := func(code, device string) map[string]string {
+		if _, err := tx.Exec(ctx, "delete from user_login_codes where user_id = $1", userID); err != nil {
+			t.Fatalf("failed to clear codes: %v", err)
+		}
+		if _, err := tx.Exec(ctx, "insert into user_login_codes (user_id, code, expires_at) values ($1, $2, $3)", userID, code, time.Now().Add(5*time.Minute)); err != nil {
+			t.Fatalf("failed to insert code: %v", err)
+		}
+		status, data := send("POST", "/api/code", "", map[string]string{"code": code, "device_name": device})
+		if status != http.StatusOK {
+			t.Fatalf("sign in: got %d want %d", status, http.StatusOK)
+		}
+		var tokens map[string]string
+		if err := json.Unmarshal(data, &tokens); err != nil {
+			t.Fatalf("failed to decode tokens: %v", err)
+		}
+		if tokens["access_token"] == "" || tokens["refresh_token"] == "" {
+			t.Fatalf("missing tokens: %v", tokens)
+		}
+		return tokens
+	}
+
+	phone := signIn("111111", "Phone")
+	tablet := signIn("222222", "Tablet")
+
+	// when: the phone refreshes
+	status, data := send("POST", "/api/token/refresh", "", map[string]string{"refresh_token": phone["refresh_token"]})
+	if status != http.StatusOK {
+		t.Fatalf("refresh: got %d want %d", status, http.StatusOK)
+	}
+	var refreshed map[string]string
+	_ = json.Unmarshal(data, &refreshed)
+	if refreshed["refresh_token"] == "" || refreshed["refresh_token"] == phone["refresh_token"] {
+		t.Fatalf("refresh token was not rotated")
+	}
+
+	// then: both devices are listed, the caller's flagged as current
+	status, data = send("GET", "/api/me/sessions", refreshed["access_token"], nil)
+	var sessions []loginsvc.Session
+	_ = json.Unmarshal(data, &sessions)
+	if status != http.StatusOK || len(sessions) != 2 {
+		t.Fatalf("listing sessions: got %d with %d sessions", status, len(sessions))
+	}
+	var tabletID int64
+	for _, session := range sessions {
+		if session.DeviceName != nil && *session.DeviceName == "Tablet" {
+			tabletID = session.ID
+			if session.Current {
+				t.Fatalf("tablet flagged as current")
+			}
+		}
+	}
+
+	// when: the tablet is signed out from the phone
+	if status, _ := send("DELETE", fmt.Sprintf("/api/me/sessions/%d", tabletID), refreshed["access_token"], nil); status != http.StatusOK {
+		t.Fatalf("revoking tablet: got %d want %d", status, http.StatusOK)
+	}
+
+	// then: its access and refresh tokens stop working
+	if status, _ := send("GET", "/api/me/sessions", tablet["access_token"], nil); status != http.StatusUnauthorized {
+		t.Fatalf("revoked access token: got %d want %d", status, http.StatusUnauthorized)
+	}
+	if status, _ := send("POST", "/api/token/refresh", "", map[string]string{"refresh_token": tablet["refresh_token"]}); status != http.StatusUnauthorized {
+		t.Fatalf("revoked refresh token: got %d want %d", status, http.StatusUnauthorized)
+	}
+
+	// when: the phone's used refresh token is replayed
+	if status, _ := send("POST", "/api/token/refresh", "", map[string]string{"refresh_token": phone["refresh_token"]}); status != http.StatusUnauthorized {
+		t.Fatalf("replayed refresh token: got %d want %d", status, http.StatusUnauthorized)
+	}
+
+	// then: the phone's session is revoked too
+	if status, _ := send("GET", "/api/me/sessions", refreshed["access_token"], nil); status != http.StatusUnauthorized {
+		t.Fatalf("session after replay: got %d want %d", status, http.StatusUnauthorized)
+	}
+}
+
+func TestHandler_SignIn(t *testing.T) {
+	conn := testutil.SetupDB(t)
+	defer conn.Close()
+
+	ctx := context.Background()
+	tx, _ := conn.Begin(ctx)
+	defer tx.Rollback(ctx)
+
+	// given
+	var existingID int
+	if err := tx.QueryRow(ctx, "insert into users (email, role, status) values ('otp@mail.com', 'musician', 'active') returning id").Scan(&existingID); err != nil {
+		t.Fatalf("failed to insert user: %v", err)
+	}
+	issuer := testutil.NewOIDCIssuer(t)
+	provider := loginsvc.NewOIDCProvider(loginsvc.OIDCSettings{
+		Name:      loginsvc.ProviderGoogle,
+		Issuers:   []string{issuer.URL},
+		ClientIDs: []string{"lyric-android"},
+	})
+	plans := plansvc.NewService(planrepo.NewRepository(tx), plansvc.Config{})
+	h := login.New(getService(tx, provider), plans)
+
+	signIn := func(payload map[string]string) (int, map[string]string) {
+		body, _ := json.Marshal(payload)
+		req, _ := http.NewRequest("POST", "/api/login/oidc", bytes.NewBuffer(body))
+		rr := httptest.NewRecorder()
+		h.SignIn(rr, req)
+		var res handler.ResponseMessage[map[string]string]
+		_ = json.Unmarshal(rr.Body.Bytes(), &res)
+		return rr.Code, res.Data
+	}
+	userOf := func(tokens map[string]string) int {
+		token, err := getService(tx).TokenAuth().Decode(tokens["access_token"])
+		if err != nil {
+			t.Fatalf("failed to decode access token: %v", err)
+		}
+		id, _ := strconv.Atoi(token.Subject())
+		return id
+	}
+
+	testCases := []struct {
+		name           string
+		payload        map[string]string
+		expectedStatus int
+		expectedUser   int
+	}{
+		{
+			name: "links the account with the same verified email",
+			payload: map[string]string{"provider": "google", "device_name": "Pixel", "id_token": issuer.IDToken(t, "lyric-android", "g-1", map[string]any{
+				"email": "OTP@mail.com", "email_verified": true,
+			})},
+			expectedStatus: http.StatusOK,
+			expectedUser:   existingID,
+		},
+		{
+			name: "finds the linked account after the email changes",
+			payload: map[string]string{"provider": "google", "id_token": issuer.IDToken(t, "lyric-android", "g-1", map[string]any{
+				"email": "renamed@mail.com", "email_verified": true,
+			})},
+			expectedStatus: http.StatusOK,
+			expectedUser:   existingID,
+		},
+		{
+			name: "rejects an unverified email",
+			payload: map[string]string{"provider": "google", "id_token": issuer.IDToken(t, "lyric-android", "g-2", map[string]any{
+				"email": "otp@mail.com", "email_verified": false,
+			})},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "rejects a token for another app",
+			payload:        map[string]string{"provider": "google", "id_token": issuer.IDToken(t, "other-app", "g-3", nil)},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "rejects a provider that is not configured",
+			payload:        map[string]string{"provider": "apple", "id_token": issuer.IDToken(t, "lyric-android", "g-1", nil)},
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// when
+			status, tokens := signIn(tc.payload)
+
+			// then
+			if status != tc.expectedStatus {
+				t.Fatalf("handler returned wrong status code: got %v want %v", status, tc.expectedStatus)
+			}
+			if tc.expectedUser != 0 {
+				if tokens["refresh_token"] == "" {
+					t.Fatalf("missing refresh token: %v", tokens)
+				}
+				if got := userOf(tokens); got != tc.expectedUser {
+					t.Errorf("signed in as user %d, want %d", got, tc.expectedUser)
+				}
+			}
+		})
+	}
+
+	// when: a new verified email signs in
+	status, tokens := signIn(map[string]string{"provider": "google", "id_token": issuer.IDToken(t, "lyric-android", "g-4", map[string]any{
+		"email": "new@mail.com", "email_verified": true,
+	})})
+
+	// then: a user is created and linked
+	if status != http.StatusOK {
+		t.Fatalf("new user sign in: got %d want %d", status, http.StatusOK)
+	}
+	var linked int
+	if err := tx.QueryRow(ctx, "select i.user_id from user_identities i join users u on u.id = i.user_id where i.provider = 'google' and i.subject = 'g-4' and u.email = 'new@mail.com'").Scan(&linked); err != nil {
+		t.Fatalf("identity not linked: %v", err)
+	}
+	if linked != userOf(tokens) {
+		t.Errorf("signed in as user %d, identity linked to %d", userOf(tokens), linked)
+	}
+}
@@ -0,0 +1,142 @@
+package playlistinvites
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/lyricapp/lyric/web/internal/apperror"
+	"github.com/lyricapp/lyric/web/internal/http/handler"
+	"github.com/lyricapp/lyric/web/internal/http/handler/api/util"
+	playlistinvitesvc "github.com/lyricapp/lyric/web/internal/services/playlistinvites"
+)
+
+// Handler manages playlist invite links.
+type Handler struct {
+	svc playlistinvitesvc.Service
+}
+
+// New constructs an invite link handler.
+func New(svc playlistinvitesvc.Service) Handler {
+	return Handler{svc: svc}
+}
+
+func playlistID(r *http.Request) (int, error) {
+	id, err := strconv.Atoi(strings.TrimSpace(chi.URLParam(r, "id")))
+	if err != nil || id <= 0 {
+		return 0, apperror.Validation("msg", map[string]string{"id": "id must be a positive integer"})
+	}
+	return id, nil
+}
+
+// Create issues an invite link for the playlist.
+func (h Handler) Create(w http.ResponseWriter, r *http.Request) {
+	userID, authErr := util.CurrentUserID(r)
+	if authErr != nil {
+		handler.Error(w, authErr)
+		return
+	}
+	id, err := playlistID(r)
+	if err != nil {
+		handler.Error(w, err)
+		return
+	}
+
+	var payload struct {
+		Role           string `json:"role"`
+		MaxUses        *int   `json:"max_uses"`
+		ExpiresInHours int    `json:"expires_in_hours"`
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&payload); err != nil {
+		handler.Error(w, apperror.BadRequest("invalid JSON payload"))
+		return
+	}
+
+	invite, err := h.svc.Create(r.Context(), playlistinvitesvc.CreateParams{
+		PlaylistID: id,
+		OwnerID:    userID,
+		Role:       payload.Role,
+		MaxUses:    payload.MaxUses,
+		ExpiresIn:  time.Duration(payload.ExpiresInHours) * time.Hour,
+	})
+	if err != nil {
+		handler.Error(w, err)
+		return
+	}
+
+	handler.Success(w, http.StatusCreated, invite)
+}
+
+// List returns the playlist's outstanding invite links.
+func (h Handler) List(w http.ResponseWriter, r *http.Request) {
+	userID, authErr := util.CurrentUserID(r)
+	if authErr != nil {
+		handler.Error(w, authErr)
+		return
+	}
+	id, err := playlistID(r)
+	if err != nil {
+		handler.Error(w, err)
+		return
+	}
+
+	invites, err := h.svc.List(r.Context(), id, userID)
+	if err != nil {
+		handler.Error(w, err)
+		return
+	}
+
+	handler.Success(w, http.StatusOK, invites)
+}
+
+// Revoke stops an invite link from being accepted.
+func (h Handler) Revoke(w http.ResponseWriter, r *http.Request) {
+	userID, authErr := util.CurrentUserID(r)
+	if authErr != nil {
+		handler.Error(w, authErr)
+		return
+	}
+	id, err := playlistID(r)
+	if err != nil {
+		handler.Error(w, err)
+		return
+	}
+	inviteID, err := strconv.Atoi(strings.TrimSpace(chi.URLParam(r, "invite_id")))
+	if err != nil || inviteID <= 0 {
+		handler.Error(w, apperror.Validation("msg", map[string]string{"invite_id": "invite_id must be a positive integer"}))
+		return
+	}
+
+	if err := h.svc.Revoke(r.Context(), id, inviteID, userID); err != nil {
+		handler.Error(w, err)
+		return
+	}
+
+	handler.Success(w, http.StatusOK, map[string]string{
+		"message": "Invite revoked successfully",
+	})
+}
+
+// Accept joins the authenticated user to the invite's playlist.
+func (h Handler) Accept(w http.ResponseWriter, r *http.Request) {
+	userID, authErr := util.CurrentUserID(r)
+	if authErr != nil {
+		handler.Error(w, authErr)
+		return
+	}
+
+	membership, err := h.svc.Accept(r.Context(), chi.URLParam(r, "token"), userID)
+	if err != nil {
+		handler.Error(w, err)
+		return
+	}
+
+	handler.Success(w, http.StatusOK, membership)
+}
@@ -0,0 +1,223 @@
+package playlistinvites_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/lyricapp/lyric/web/internal/http/handler/api/playlistinvites"
+	plansvc "github.com/lyricapp/lyric/web/internal/services/plans"
+	playlistinvitesvc "github.com/lyricapp/lyric/web/internal/services/playlistinvites"
+	"github.com/lyricapp/lyric/web/internal/storage"
+	planrepo "github.com/lyricapp/lyric/web/internal/storage/postgres/plans"
+	playlistinviterepo "github.com/lyricapp/lyric/web/internal/storage/postgres/playlistinvites"
+	"github.com/lyricapp/lyric/web/internal/testutil"
+)
+
+func getHandler(storage storage.Querier) playlistinvites.Handler {
+	plans := plansvc.NewService(planrepo.NewRepository(storage), plansvc.Config{
+		Free: plansvc.Limits{Playlists: 3, Shares: 3},
+	})
+	svc := playlistinvitesvc.NewService(playlistinviterepo.NewRepository(storage), plans, playlistinvitesvc.Config{
+		BaseURL: "https://lyric.test",
+	})
+	return playlistinvites.New(svc)
+}
+
+func TestHandler_InviteLifecycle(t *testing.T) {
+	conn := testutil.SetupDB(t)
+	defer conn.Close()
+
+	ctx := context.Background()
+	tx, _ := conn.Begin(ctx)
+	defer tx.Rollback(ctx)
+
+	// given
+	var ownerID, firstID, secondID, playlistID int
+	for email, id := range map[string]*int{
+		"owner-invite@user.com":  &ownerID,
+		"first-invite@user.com":  &firstID,
+		"second-invite@user.com": &secondID,
+	} {
+		if err := tx.QueryRow(ctx, "insert into users (email, role) values ($1, 'musician') returning id", email).Scan(id); err != nil {
+			t.Fatalf("failed to insert user: %v", err)
+		}
+	}
+	if err := tx.QueryRow(ctx, "insert into playlists (name, user_id) values ('invites', $1) returning id", ownerID).Scan(&playlistID); err != nil {
+		t.Fatalf("failed to insert playlist: %v", err)
+	}
+
+	h := getHandler(tx)
+	send := func(userID int, method, path, pattern string, fn http.HandlerFunc, body any) *httptest.ResponseRecorder {
+		r, accessToken := testutil.AuthToken(t, userID)
+		r.Method(method, pattern, fn)
+		var payload []byte
+		if body != nil {
+			payload, _ = json.Marshal(body)
+		}
+		req, err := http.NewRequest(method, path, bytes.NewBuffer(payload))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", accessToken))
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		return rr
+	}
+	invitesPath := fmt.Sprintf("/api/playlists/%d/invites", playlistID)
+	accept := func(userID int, token string) int {
+		return send(userID, "POST", "/api/invites/"+token+"/accept", "/api/invites/{token}/accept", h.Accept, nil).Code
+	}
+
+	// when
+	if rr := send(firstID, "POST", invitesPath, "/api/playlists/{id}/invites", h.Create, map[string]any{"role": "editor"}); rr.Code != http.StatusNotFound {
+		t.Fatalf("non-owner creating invite: got %d want %d", rr.Code, http.StatusNotFound)
+	}
+	rr := send(ownerID, "POST", invitesPath, "/api/playlists/{id}/invites", h.Create,
+		map[string]any{"role": "editor", "max_uses": 1, "expires_in_hours": 24})
+
+	// then
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("creating invite: got %d want %d (%s)", rr.Code, http.StatusCreated, rr.Body.String())
+	}
+	var created struct {
+		Data playlistinvitesvc.Invite `json:"data"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&created); err != nil {
+		t.Fatalf("failed to decode invite: %v", err)
+	}
+	invite := created.Data
+	if invite.Token == "" || invite.URL != "https://lyric.test/invites/"+invite.Token {
+		t.Fatalf("unexpected invite link: %+v", invite)
+	}
+	var stored string
+	if err := tx.QueryRow(ctx, "select token_hash from playlist_invites where id = $1", invite.ID).Scan(&stored); err != nil {
+		t.Fatalf("failed to load invite: %v", err)
+	}
+	if stored == invite.Token {
+		t.Fatal("invite token must not be stored in plain text")
+	}
+
+	if status := accept(firstID, "not-a-token"); status != http.StatusNotFound {
+		t.Errorf("unknown token: got %d want %d", status, http.StatusNotFound)
+	}
+	if status := accept(firstID, invite.Token); status != http.StatusOK {
+		t.Fatalf("accepting invite: got %d want %d", status, http.StatusOK)
+	}
+	var role string
+	if err := tx.QueryRow(ctx, "select role from playlist_user where playlist_id = $1 and user_id = $2", playlistID, firstID).Scan(&role); err != nil {
+		t.Fatalf("failed to load membership: %v", err)
+	}
+	if role != "editor" {
+		t.Errorf("expected editor role, got %q", role)
+	}
+	if status := accept(firstID, invite.Token); status != http.StatusOK {
+		t.Errorf("accepting again as a member: got %d want %d", status, http.StatusOK)
+	}
+	if status := accept(secondID, invite.Token); status != http.StatusGone {
+		t.Errorf("accepting a used up invite: got %d want %d", status, http.StatusGone)
+	}
+
+	// revoked and expired links are refused
+	rr = send(ownerID, "POST", invitesPath, "/api/playlists/{id}/invites", h.Create, map[string]any{})
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("creating second invite: got %d want %d", rr.Code, http.StatusCreated)
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&created); err != nil {
+		t.Fatalf("failed to decode invite: %v", err)
+	}
+	revoked := created.Data
+	if rr := send(ownerID, "GET", invitesPath, "/api/playlists/{id}/invites", h.List, nil); rr.Code != http.StatusOK {
+		t.Fatalf("listing invites: got %d want %d", rr.Code, http.StatusOK)
+	} else {
+		var listed struct {
+			Data []playlistinvitesvc.Invite `json:"data"`
+		}
+		if err := json.NewDecoder(rr.Body).Decode(&listed); err != nil {
+			t.Fatalf("failed to decode invites: %v", err)
+		}
+		if len(listed.Data) != 1 || listed.Data[0].ID != revoked.ID || listed.Data[0].Token != "" {
+			t.Errorf("expected only the unused invite without its token, got %+v", listed.Data)
+		}
+	}
+	revokePath := fmt.Sprintf("%s/%d", invitesPath, revoked.ID)
+	if rr := send(ownerID, "DELETE", revokePath, "/api/playlists/{id}/invites/{invite_id}", h.Revoke, nil); rr.Code != http.StatusOK {
+		t.Fatalf("revoking invite: got %d want %d", rr.Code, http.StatusOK)
+	}
+	if status := accept(secondID, revoked.Token); status != http.StatusGone {
+		t.Errorf("accepting a revoked invite: got %d want %d", status, http.StatusGone)
+	}
+
+	if _, err := tx.Exec(ctx, "update playlist_invites set revoked_at = null, expires_at = now() - interval '1 minute' where id = $1", revoked.ID); err != nil {
+		t.Fatalf("failed to expire invite: %v", err)
+	}
+	if status := accept(secondID, revoked.Token); status != http.StatusGone {
+		t.Errorf("accepting an expired invite: got %d want %d", status, http.StatusGone)
+	}
+}
+
+func TestHandler_Create_Validation(t *testing.T) {
+	conn := testutil.SetupDB(t)
+	defer conn.Close()
+
+	ctx := context.Background()
+	tx, _ := conn.Begin(ctx)
+	defer tx.Rollback(ctx)
+
+	var ownerID, playlistID int
+	if err := tx.QueryRow(ctx, "insert into users (email, role) values ('owner-validate@user.com', 'musician') returning id").Scan(&ownerID); err != nil {
+		t.Fatalf("failed to insert user: %v", err)
+	}
+	if err := tx.QueryRow(ctx, "insert into playlists (name, user_id) values ('invites', $1) returning id", ownerID).Scan(&playlistID); err != nil {
+		t.Fatalf("failed to insert playlist: %v", err)
+	}
+	h := getHandler(tx)
+
+	tests := []struct {
+		name string
+		body map[string]any
+		key  string
+	}{
+		{name: "owner role", body: map[string]any{"role": "owner"}, key: "role"},
+		{name: "zero uses", body: map[string]any{"max_uses": 0}, key: "max_uses"},
+		{name: "too many uses", body: map[string]any{"max_uses": playlistinvitesvc.MaxUsesCap + 1}, key: "max_uses"},
+		{name: "expires too late", body: map[string]any{"expires_in_hours": 24 * 31}, key: "expires_in_hours"},
+		{name: "negative expiry", body: map[string]any{"expires_in_hours": -1}, key: "expires_in_hours"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// given
+			r, accessToken := testutil.AuthToken(t, ownerID)
+			r.Post("/api/playlists/{id}/invites", h.Create)
+			payload, _ := json.Marshal(tt.body)
+			req, err := http.NewRequest("POST", fmt.Sprintf("/api/playlists/%d/invites", playlistID), bytes.NewBuffer(payload))
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", accessToken))
+
+			// when
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, req)
+
+			// then
+			if rr.Code != http.StatusUnprocessableEntity {
+				t.Fatalf("got %d want %d", rr.Code, http.StatusUnprocessableEntity)
+			}
+			var res struct {
+				Errors map[string]string `json:"errors"`
+			}
+			if err := json.NewDecoder(rr.Body).Decode(&res); err != nil {
+				t.Fatalf("failed to decode errors: %v", err)
+			}
+			if _, ok := res.Errors[tt.key]; !ok {
+				t.Errorf("expected %s error, got %v", tt.key, res.Errors)
+			}
+		})
+	}
+}
@@ -299,3 +299,130 @@ func (h Handler) UpdateSongs(w http.ResponseWriter, r *http.Request) {
 		"message": "Playlist songs updated successfully",
 	})
 }
+
+// Reorder applies a full or partial song ordering to the playlist.
+func (h Handler) Reorder(w http.ResponseWriter, r *http.Request) {
+	userID, authErr := util.CurrentUserID(r)
+	if authErr != nil {
+		handler.Error(w, authErr)
+		return
+	}
+
+	rawID := strings.TrimSpace(chi.URLParam(r, "id"))
+	playlistID, err := strconv.Atoi(rawID)
+	if err != nil || playlistID <= 0 {
+		handler.Error(w, apperror.Validation("msg", map[string]string{"id": "id must be a positive integer"}))
+		return
+	}
+
+	var payload struct {
+		SongIDs []int `json:"song_ids"`
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&payload); err != nil {
+		handler.Error(w, apperror.BadRequest("invalid JSON payload"))
+		return
+	}
+
+	if err := h.svc.Reorder(r.Context(), userID, playlistID, payload.SongIDs); err != nil {
+		handler.Error(w, err)
+		return
+	}
+
+	handler.Success(w, http.StatusOK, map[string]string{
+		"message": "Playlist order updated successfully",
+	})
+}
+
+// UpdateArrangement sets the transpose, capo, display mode and note for a playlist song.
+func (h Handler) UpdateArrangement(w http.ResponseWriter, r *http.Request) {
+	userID, authErr := util.CurrentUserID(r)
+	if authErr != nil {
+		handler.Error(w, authErr)
+		return
+	}
+
+	playlistID, err := strconv.Atoi(strings.TrimSpace(chi.URLParam(r, "id")))
+	if err != nil || playlistID <= 0 {
+		handler.Error(w, apperror.Validation("msg", map[string]string{"id": "id must be a positive integer"}))
+		return
+	}
+	songID, err := strconv.Atoi(strings.TrimSpace(chi.URLParam(r, "song_id")))
+	if err != nil || songID <= 0 {
+		handler.Error(w, apperror.Validation("msg", map[string]string{"song_id": "song_id must be a positive integer"}))
+		return
+	}
+
+	var payload struct {
+		Transpose   int     `json:"transpose"`
+		Capo        int     `json:"capo"`
+		DisplayMode *string `json:"display_mode"`
+		Note        *string `json:"note"`
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&payload); err != nil {
+		handler.Error(w, apperror.BadRequest("invalid JSON payload"))
+		return
+	}
+
+	if err := h.svc.UpdateArrangement(r.Context(), playlistsvc.ArrangementParams{
+		UserID:      userID,
+		PlaylistID:  playlistID,
+		SongID:      songID,
+		Transpose:   payload.Transpose,
+		Capo:        payload.Capo,
+		DisplayMode: payload.DisplayMode,
+		Note:        payload.Note,
+	}); err != nil {
+		handler.Error(w, err)
+		return
+	}
+
+	handler.Success(w, http.StatusOK, map[string]string{
+		"message": "Playlist arrangement updated successfully",
+	})
+}
+
+// UpdateRole changes a collaborator's role on the playlist.
+func (h Handler) UpdateRole(w http.ResponseWriter, r *http.Request) {
+	ownerID, authErr := util.CurrentUserID(r)
+	if authErr != nil {
+		handler.Error(w, authErr)
+		return
+	}
+
+	playlistID, err := strconv.Atoi(strings.TrimSpace(chi.URLParam(r, "id")))
+	if err != nil || playlistID <= 0 {
+		handler.Error(w, apperror.Validation("msg", map[string]string{"id": "id must be a positive integer"}))
+		return
+	}
+	userID, err := strconv.Atoi(strings.TrimSpace(chi.URLParam(r, "user_id")))
+	if err != nil || userID <= 0 {
+		handler.Error(w, apperror.Validation("msg", map[string]string{"user_id": "user_id must be a positive integer"}))
+		return
+	}
+
+	var payload struct {
+		Role string `json:"role"`
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&payload); err != nil {
+		handler.Error(w, apperror.BadRequest("invalid JSON payload"))
+		return
+	}
+
+	if err := h.svc.UpdateRole(r.Context(), playlistID, ownerID, userID, payload.Role); err != nil {
+		handler.Error(w, err)
+		return
+	}
+
+	handler.Success(w, http.StatusOK, map[string]string{
+		"message": "Playlist role updated successfully",
+	})
+}
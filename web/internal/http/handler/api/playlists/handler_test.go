@@ -11,15 +11,20 @@ import (
 
 	"github.com/lyricapp/lyric/web/internal/http/handler"
 	"github.com/lyricapp/lyric/web/internal/http/handler/api/playlists"
+	plansvc "github.com/lyricapp/lyric/web/internal/services/plans"
 	playlistsvc "github.com/lyricapp/lyric/web/internal/services/playlists"
 	"github.com/lyricapp/lyric/web/internal/storage"
+	planrepo "github.com/lyricapp/lyric/web/internal/storage/postgres/plans"
 	playlistrepo "github.com/lyricapp/lyric/web/internal/storage/postgres/playlists"
 	"github.com/lyricapp/lyric/web/internal/testutil"
 )
 
 func getHandler(storage storage.Querier) playlists.Handler {
 	repo := playlistrepo.NewRepository(storage)
-	svc := playlistsvc.NewService(repo)
+	plans := plansvc.NewService(planrepo.NewRepository(storage), plansvc.Config{
+		Free: plansvc.Limits{Playlists: 3, Shares: 3},
+	})
+	svc := playlistsvc.NewService(repo, plans)
 	return playlists.New(svc)
 }
 
@@ -196,6 +201,121 @@ func TestHandler_Create_Fail(t *testing.T) {
 	}
 }
 
+func TestHandler_Create_PlanLimit(t *testing.T) {
+	conn := testutil.SetupDB(t)
+	defer conn.Close()
+
+	ctx := context.Background()
+	tx, _ := conn.Begin(ctx)
+	defer tx.Rollback(ctx)
+
+	// given
+	var freeUserID, premiumUserID int
+	if err := tx.QueryRow(ctx, "insert into users (email, role) values ('free@test.com', 'musician') returning id").Scan(&freeUserID); err != nil {
+		t.Fatalf("failed to seed user: %v", err)
+	}
+	if err := tx.QueryRow(ctx, "insert into users (email, role, plan) values ('premium@test.com', 'musician', 'premium') returning id").Scan(&premiumUserID); err != nil {
+		t.Fatalf("failed to seed user: %v", err)
+	}
+	for _, userID := range []int{freeUserID, premiumUserID} {
+		if _, err := tx.Exec(ctx, "insert into playlists (name, user_id) values ('one', $1), ('two', $1), ('three', $1)", userID); err != nil {
+			t.Fatalf("failed to seed playlists: %v", err)
+		}
+	}
+	h := getHandler(tx)
+
+	testCases := []struct {
+		name               string
+		userID             int
+		expectedStatusCode int
+	}{
+		{name: "free plan at limit", userID: freeUserID, expectedStatusCode: http.StatusPaymentRequired},
+		{name: "premium plan is unlimited", userID: premiumUserID, expectedStatusCode: http.StatusCreated},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r, accessToken := testutil.AuthToken(t, tc.userID)
+			r.Post("/api/playlists", h.Create)
+
+			// when
+			requestBody, _ := json.Marshal(map[string]string{"name": "four"})
+			req, err := http.NewRequest("POST", "/api/playlists", bytes.NewBuffer(requestBody))
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", accessToken))
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, req)
+
+			// then
+			if status := rr.Code; status != tc.expectedStatusCode {
+				t.Fatalf("handler returned wrong status code: got %v want %v", status, tc.expectedStatusCode)
+			}
+			if tc.expectedStatusCode != http.StatusPaymentRequired {
+				return
+			}
+			var res handler.ErrorResponse[map[string]string]
+			if err := json.NewDecoder(rr.Body).Decode(&res); err != nil {
+				t.Fatalf("Failed to decode or response format is wrong: %v", err)
+			}
+			if res.Errors["code"] != plansvc.ErrorCode || res.Errors["feature"] != "playlists" || res.Errors["limit"] != "3" {
+				t.Errorf("handler returned unexpected upgrade details: %v", res.Errors)
+			}
+		})
+	}
+}
+
+func TestHandler_Share_PlanLimit(t *testing.T) {
+	conn := testutil.SetupDB(t)
+	defer conn.Close()
+
+	ctx := context.Background()
+	tx, _ := conn.Begin(ctx)
+	defer tx.Rollback(ctx)
+
+	// given
+	var ownerID, playlistID int
+	if err := tx.QueryRow(ctx, "insert into users (email, role) values ('owner@test.com', 'musician') returning id").Scan(&ownerID); err != nil {
+		t.Fatalf("failed to seed user: %v", err)
+	}
+	if err := tx.QueryRow(ctx, "insert into playlists (name, user_id) values ('shared', $1) returning id", ownerID).Scan(&playlistID); err != nil {
+		t.Fatalf("failed to seed playlist: %v", err)
+	}
+	userIDs := make([]int, 0, 4)
+	for i := 0; i < 4; i++ {
+		var id int
+		if err := tx.QueryRow(ctx, "insert into users (email, role) values ($1, 'musician') returning id", fmt.Sprintf("friend%d@test.com", i)).Scan(&id); err != nil {
+			t.Fatalf("failed to seed user: %v", err)
+		}
+		userIDs = append(userIDs, id)
+	}
+
+	r, accessToken := testutil.AuthToken(t, ownerID)
+	h := getHandler(tx)
+	r.Post("/api/playlists/{id}/share", h.Share)
+
+	// when
+	requestBody, _ := json.Marshal(map[string][]int{"user_ids": userIDs})
+	req, err := http.NewRequest("POST", fmt.Sprintf("/api/playlists/%d/share", playlistID), bytes.NewBuffer(requestBody))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", accessToken))
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+
+	// then
+	if status := rr.Code; status != http.StatusPaymentRequired {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusPaymentRequired)
+	}
+	var count int
+	tx.QueryRow(ctx, "select count(*) from playlist_user where playlist_id = $1", playlistID).Scan(&count)
+	if count != 0 {
+		t.Errorf("expected no shares to be stored, got %d", count)
+	}
+}
+
 func TestHandler_List_Validation(t *testing.T) {
 	conn := testutil.SetupDB(t)
 	defer conn.Close()
@@ -483,3 +603,287 @@ func TestHandler_UpdateSongs_RemovesSongs(t *testing.T) {
 		}
 	}
 }
+
+func TestHandler_Reorder(t *testing.T) {
+	conn := testutil.SetupDB(t)
+	defer conn.Close()
+
+	ctx := context.Background()
+	tx, _ := conn.Begin(ctx)
+	defer tx.Rollback(ctx)
+
+	// given
+	var userID, otherID, languageID, playlistID int
+	if err := tx.QueryRow(ctx, "insert into users (email, role) values ('order@user.com', 'musician') returning id").Scan(&userID); err != nil {
+		t.Fatalf("failed to insert user: %v", err)
+	}
+	if err := tx.QueryRow(ctx, "insert into users (email, role) values ('other-order@user.com', 'musician') returning id").Scan(&otherID); err != nil {
+		t.Fatalf("failed to insert user: %v", err)
+	}
+	if err := tx.QueryRow(ctx, "insert into languages (name) values ('german') returning id").Scan(&languageID); err != nil {
+		t.Fatalf("failed to insert language: %v", err)
+	}
+	songIDs := make([]int, 4)
+	for i := range songIDs {
+		if err := tx.QueryRow(ctx, "insert into songs (title, created_by, language_id) values ($1, $2, $3) returning id", fmt.Sprintf("order-song-%d", i+1), userID, languageID).Scan(&songIDs[i]); err != nil {
+			t.Fatalf("failed to insert song %d: %v", i+1, err)
+		}
+	}
+	if err := tx.QueryRow(ctx, "insert into playlists (name, user_id) values ('sunday', $1) returning id", userID).Scan(&playlistID); err != nil {
+		t.Fatalf("failed to insert playlist: %v", err)
+	}
+	for i, songID := range songIDs {
+		if _, err := tx.Exec(ctx, "insert into playlist_song (playlist_id, song_id, position) values ($1, $2, $3)", playlistID, songID, i+1); err != nil {
+			t.Fatalf("failed to seed playlist songs: %v", err)
+		}
+	}
+
+	h := getHandler(tx)
+	reorder := func(userID int, songIDs []int) *httptest.ResponseRecorder {
+		r, accessToken := testutil.AuthToken(t, userID)
+		r.Put("/api/playlists/{id}/order", h.Reorder)
+		body, _ := json.Marshal(map[string]any{"song_ids": songIDs})
+		req, err := http.NewRequest("PUT", fmt.Sprintf("/api/playlists/%d/order", playlistID), bytes.NewBuffer(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", accessToken))
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		return rr
+	}
+	order := func() []int {
+		rows, err := tx.Query(ctx, "select song_id from playlist_song where playlist_id = $1 order by position", playlistID)
+		if err != nil {
+			t.Fatalf("failed to query playlist songs: %v", err)
+		}
+		defer rows.Close()
+		var got []int
+		for rows.Next() {
+			var id int
+			rows.Scan(&id)
+			got = append(got, id)
+		}
+		return got
+	}
+
+	testCases := []struct {
+		name               string
+		userID             int
+		songIDs            []int
+		expectedStatusCode int
+		expectedOrder      []int
+	}{
+		{
+			name:               "Full reorder",
+			userID:             userID,
+			songIDs:            []int{songIDs[3], songIDs[2], songIDs[1], songIDs[0]},
+			expectedStatusCode: http.StatusOK,
+			expectedOrder:      []int{songIDs[3], songIDs[2], songIDs[1], songIDs[0]},
+		},
+		{
+			name:               "Partial reorder keeps other songs in place",
+			userID:             userID,
+			songIDs:            []int{songIDs[0], songIDs[2]},
+			expectedStatusCode: http.StatusOK,
+			expectedOrder:      []int{songIDs[3], songIDs[0], songIDs[1], songIDs[2]},
+		},
+		{
+			name:               "Song outside the playlist",
+			userID:             userID,
+			songIDs:            []int{songIDs[0], 999999},
+			expectedStatusCode: http.StatusUnprocessableEntity,
+			expectedOrder:      []int{songIDs[3], songIDs[0], songIDs[1], songIDs[2]},
+		},
+		{
+			name:               "Not the owner",
+			userID:             otherID,
+			songIDs:            []int{songIDs[1], songIDs[0]},
+			expectedStatusCode: http.StatusUnauthorized,
+			expectedOrder:      []int{songIDs[3], songIDs[0], songIDs[1], songIDs[2]},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// when
+			rr := reorder(tc.userID, tc.songIDs)
+
+			// then
+			if status := rr.Code; status != tc.expectedStatusCode {
+				t.Fatalf("unexpected status code: got %d want %d", status, tc.expectedStatusCode)
+			}
+			got := order()
+			if fmt.Sprint(got) != fmt.Sprint(tc.expectedOrder) {
+				t.Errorf("unexpected order: got %v want %v", got, tc.expectedOrder)
+			}
+		})
+	}
+}
+
+func TestHandler_UpdateArrangement(t *testing.T) {
+	conn := testutil.SetupDB(t)
+	defer conn.Close()
+
+	ctx := context.Background()
+	tx, _ := conn.Begin(ctx)
+	defer tx.Rollback(ctx)
+
+	// given
+	var userID, languageID, songID, playlistID int
+	if err := tx.QueryRow(ctx, "insert into users (email, role) values ('arrange@user.com', 'musician') returning id").Scan(&userID); err != nil {
+		t.Fatalf("failed to insert user: %v", err)
+	}
+	if err := tx.QueryRow(ctx, "insert into languages (name) values ('spanish') returning id").Scan(&languageID); err != nil {
+		t.Fatalf("failed to insert language: %v", err)
+	}
+	if err := tx.QueryRow(ctx, "insert into songs (title, language_id) values ('arranged', $1) returning id", languageID).Scan(&songID); err != nil {
+		t.Fatalf("failed to insert song: %v", err)
+	}
+	if err := tx.QueryRow(ctx, "insert into playlists (name, user_id) values ('evening', $1) returning id", userID).Scan(&playlistID); err != nil {
+		t.Fatalf("failed to insert playlist: %v", err)
+	}
+	if _, err := tx.Exec(ctx, "insert into playlist_song (playlist_id, song_id) values ($1, $2)", playlistID, songID); err != nil {
+		t.Fatalf("failed to seed playlist songs: %v", err)
+	}
+
+	h := getHandler(tx)
+	testCases := []struct {
+		name               string
+		songID             int
+		body               map[string]any
+		expectedStatusCode int
+	}{
+		{
+			name:               "Valid arrangement",
+			songID:             songID,
+			body:               map[string]any{"transpose": -3, "capo": 1, "display_mode": "Inline", "note": " build from verse 2 "},
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "Out of range values",
+			songID:             songID,
+			body:               map[string]any{"transpose": 12, "capo": -1, "display_mode": "pdf"},
+			expectedStatusCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:               "Song not in playlist",
+			songID:             songID + 1000,
+			body:               map[string]any{"transpose": 1},
+			expectedStatusCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// when
+			r, accessToken := testutil.AuthToken(t, userID)
+			r.Put("/api/playlists/{id}/songs/{song_id}/arrangement", h.UpdateArrangement)
+			body, _ := json.Marshal(tc.body)
+			req, err := http.NewRequest("PUT", fmt.Sprintf("/api/playlists/%d/songs/%d/arrangement", playlistID, tc.songID), bytes.NewBuffer(body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", accessToken))
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, req)
+
+			// then
+			if status := rr.Code; status != tc.expectedStatusCode {
+				t.Fatalf("unexpected status code: got %d want %d", status, tc.expectedStatusCode)
+			}
+		})
+	}
+
+	var transpose, capo int
+	var displayMode, note string
+	if err := tx.QueryRow(ctx, "select transpose, capo, display_mode, note from playlist_song where playlist_id = $1 and song_id = $2", playlistID, songID).Scan(&transpose, &capo, &displayMode, &note); err != nil {
+		t.Fatalf("failed to load arrangement: %v", err)
+	}
+	if transpose != -3 || capo != 1 || displayMode != "inline" || note != "build from verse 2" {
+		t.Errorf("unexpected arrangement: %d %d %q %q", transpose, capo, displayMode, note)
+	}
+}
+
+func TestHandler_Roles(t *testing.T) {
+	conn := testutil.SetupDB(t)
+	defer conn.Close()
+
+	ctx := context.Background()
+	tx, _ := conn.Begin(ctx)
+	defer tx.Rollback(ctx)
+
+	// given
+	var ownerID, collaboratorID, languageID, songID, playlistID int
+	if err := tx.QueryRow(ctx, "insert into users (email, role) values ('owner-role@user.com', 'musician') returning id").Scan(&ownerID); err != nil {
+		t.Fatalf("failed to insert user: %v", err)
+	}
+	if err := tx.QueryRow(ctx, "insert into users (email, role) values ('collab-role@user.com', 'musician') returning id").Scan(&collaboratorID); err != nil {
+		t.Fatalf("failed to insert user: %v", err)
+	}
+	if err := tx.QueryRow(ctx, "insert into languages (name) values ('italian') returning id").Scan(&languageID); err != nil {
+		t.Fatalf("failed to insert language: %v", err)
+	}
+	if err := tx.QueryRow(ctx, "insert into songs (title, language_id) values ('shared song', $1) returning id", languageID).Scan(&songID); err != nil {
+		t.Fatalf("failed to insert song: %v", err)
+	}
+	if err := tx.QueryRow(ctx, "insert into playlists (name, user_id) values ('band', $1) returning id", ownerID).Scan(&playlistID); err != nil {
+		t.Fatalf("failed to insert playlist: %v", err)
+	}
+	if _, err := tx.Exec(ctx, "insert into playlist_user (playlist_id, user_id) values ($1, $2)", playlistID, collaboratorID); err != nil {
+		t.Fatalf("failed to share playlist: %v", err)
+	}
+
+	h := getHandler(tx)
+	send := func(userID int, method, path string, pattern string, fn http.HandlerFunc, body map[string]any) int {
+		r, accessToken := testutil.AuthToken(t, userID)
+		r.Method(method, pattern, fn)
+		payload, _ := json.Marshal(body)
+		req, err := http.NewRequest(method, path, bytes.NewBuffer(payload))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", accessToken))
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		return rr.Code
+	}
+	addSong := func() int {
+		return send(collaboratorID, "POST", fmt.Sprintf("/api/playlists/%d/songs", playlistID), "/api/playlists/{playlist_id}/songs", h.UpdateSongs,
+			map[string]any{"song_ids": []int{songID}, "action": "add"})
+	}
+	rolePath := fmt.Sprintf("/api/playlists/%d/users/%d/role", playlistID, collaboratorID)
+	rolePattern := "/api/playlists/{id}/users/{user_id}/role"
+
+	// when / then
+	if status := addSong(); status != http.StatusUnauthorized {
+		t.Fatalf("viewer adding songs: got %d want %d", status, http.StatusUnauthorized)
+	}
+	if status := send(collaboratorID, "PUT", rolePath, rolePattern, h.UpdateRole, map[string]any{"role": "editor"}); status != http.StatusUnauthorized {
+		t.Fatalf("collaborator changing roles: got %d want %d", status, http.StatusUnauthorized)
+	}
+	if status := send(ownerID, "PUT", rolePath, rolePattern, h.UpdateRole, map[string]any{"role": "owner"}); status != http.StatusUnprocessableEntity {
+		t.Fatalf("granting ownership: got %d want %d", status, http.StatusUnprocessableEntity)
+	}
+	if status := send(ownerID, "PUT", rolePath, rolePattern, h.UpdateRole, map[string]any{"role": "editor"}); status != http.StatusOK {
+		t.Fatalf("owner promoting collaborator: got %d want %d", status, http.StatusOK)
+	}
+	if status := addSong(); status != http.StatusOK {
+		t.Fatalf("editor adding songs: got %d want %d", status, http.StatusOK)
+	}
+	if status := send(collaboratorID, "PUT", fmt.Sprintf("/api/playlists/%d", playlistID), "/api/playlists/{id}", h.Update, map[string]any{"name": "renamed"}); status == http.StatusOK {
+		t.Fatal("editor should not be able to rename the playlist")
+	}
+
+	// resharing keeps the editor role
+	if status := send(ownerID, "POST", fmt.Sprintf("/api/playlists/%d/share", playlistID), "/api/playlists/{id}/share", h.Share, map[string]any{"user_ids": []int{collaboratorID}}); status != http.StatusOK {
+		t.Fatalf("resharing: got %d want %d", status, http.StatusOK)
+	}
+	var role string
+	if err := tx.QueryRow(ctx, "select role from playlist_user where playlist_id = $1 and user_id = $2", playlistID, collaboratorID).Scan(&role); err != nil {
+		t.Fatalf("failed to load role: %v", err)
+	}
+	if role != "editor" {
+		t.Errorf("expected editor role to survive resharing, got %q", role)
+	}
+}
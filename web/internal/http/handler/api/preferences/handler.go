@@ -0,0 +1,59 @@
+package preferences
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/lyricapp/lyric/web/internal/apperror"
+	"github.com/lyricapp/lyric/web/internal/http/handler"
+	"github.com/lyricapp/lyric/web/internal/http/handler/api/util"
+	preferencesvc "github.com/lyricapp/lyric/web/internal/services/preferences"
+)
+
+// Handler serves the current user's preferences.
+type Handler struct {
+	svc preferencesvc.Service
+}
+
+// New constructs a preferences handler.
+func New(svc preferencesvc.Service) Handler {
+	return Handler{svc: svc}
+}
+
+// Show returns the user's preferences, or the defaults when none are saved.
+func (h Handler) Show(w http.ResponseWriter, r *http.Request) {
+	userID, err := util.CurrentUserID(r)
+	if err != nil {
+		handler.Error(w, err)
+		return
+	}
+
+	prefs, err := h.svc.Get(r.Context(), userID)
+	if err != nil {
+		handler.Error(w, err)
+		return
+	}
+	handler.Success(w, http.StatusOK, prefs)
+}
+
+// Update changes the supplied preferences and leaves the rest as they are.
+func (h Handler) Update(w http.ResponseWriter, r *http.Request) {
+	userID, err := util.CurrentUserID(r)
+	if err != nil {
+		handler.Error(w, err)
+		return
+	}
+
+	var patch preferencesvc.Patch
+	if err := json.NewDecoder(r.Body).Decode(&patch); err != nil {
+		handler.Error(w, apperror.BadRequest("invalid JSON payload"))
+		return
+	}
+
+	prefs, err := h.svc.Update(r.Context(), userID, patch)
+	if err != nil {
+		handler.Error(w, err)
+		return
+	}
+	handler.Success(w, http.StatusOK, prefs)
+}
@@ -0,0 +1,102 @@
+package preferences_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/lyricapp/lyric/web/internal/http/handler"
+	"github.com/lyricapp/lyric/web/internal/http/handler/api/preferences"
+	preferencesvc "github.com/lyricapp/lyric/web/internal/services/preferences"
+	"github.com/lyricapp/lyric/web/internal/storage"
+	preferencerepo "github.com/lyricapp/lyric/web/internal/storage/postgres/preferences"
+	"github.com/lyricapp/lyric/web/internal/testutil"
+)
+
+func getHandler(conn storage.Querier) preferences.Handler {
+	return preferences.New(preferencesvc.NewService(preferencerepo.NewRepository(conn)))
+}
+
+func TestHandler_Preferences(t *testing.T) {
+	conn := testutil.SetupDB(t)
+	defer conn.Close()
+
+	ctx := context.Background()
+	tx, _ := conn.Begin(ctx)
+	defer tx.Rollback(ctx)
+
+	var userID int
+	if err := tx.QueryRow(ctx, "insert into users (email, role) values ('prefs@test.com', 'musician') returning id").Scan(&userID); err != nil {
+		t.Fatalf("failed to seed user: %v", err)
+	}
+
+	h := getHandler(tx)
+	r, accessToken := testutil.AuthToken(t, userID)
+	r.Get("/api/me/preferences", h.Show)
+	r.Patch("/api/me/preferences", h.Update)
+
+	send := func(method string, body any) *httptest.ResponseRecorder {
+		var payload bytes.Buffer
+		if body != nil {
+			_ = json.NewEncoder(&payload).Encode(body)
+		}
+		req, err := http.NewRequest(method, "/api/me/preferences", &payload)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", accessToken))
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		return rr
+	}
+	decode := func(rr *httptest.ResponseRecorder) preferencesvc.Preferences {
+		var res handler.ResponseMessage[preferencesvc.Preferences]
+		if err := json.NewDecoder(rr.Body).Decode(&res); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		return res.Data
+	}
+
+	t.Run("defaults", func(t *testing.T) {
+		rr := send("GET", nil)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("unexpected status code: got %d want %d", rr.Code, http.StatusOK)
+		}
+		prefs := decode(rr)
+		if prefs.Theme != "system" || prefs.FontSize != 14 || prefs.DisplayMode != "overlay" || prefs.UpdatedAt != nil {
+			t.Errorf("expected the defaults, got %+v", prefs)
+		}
+	})
+
+	t.Run("partial update", func(t *testing.T) {
+		rr := send("PATCH", map[string]any{"theme": "dark", "font_size": 20})
+		if rr.Code != http.StatusOK {
+			t.Fatalf("unexpected status code: got %d want %d: %s", rr.Code, http.StatusOK, rr.Body.String())
+		}
+		prefs := decode(rr)
+		if prefs.Theme != "dark" || prefs.FontSize != 20 || prefs.DisplayMode != "overlay" || prefs.UpdatedAt == nil {
+			t.Errorf("unexpected preferences: %+v", prefs)
+		}
+
+		prefs = decode(send("GET", nil))
+		if prefs.Theme != "dark" || prefs.FontSize != 20 {
+			t.Errorf("expected the update to be saved, got %+v", prefs)
+		}
+	})
+
+	t.Run("invalid values", func(t *testing.T) {
+		rr := send("PATCH", map[string]any{"display_mode": "karaoke", "font_size": 40})
+		if rr.Code != http.StatusUnprocessableEntity {
+			t.Fatalf("unexpected status code: got %d want %d", rr.Code, http.StatusUnprocessableEntity)
+		}
+
+		prefs := decode(send("GET", nil))
+		if prefs.FontSize != 20 {
+			t.Errorf("expected the invalid update to be rejected, got %+v", prefs)
+		}
+	})
+}
@@ -12,17 +12,21 @@ import (
     "github.com/lyricapp/lyric/web/internal/apperror"
     "github.com/lyricapp/lyric/web/internal/http/handler"
     "github.com/lyricapp/lyric/web/internal/http/handler/api/util"
+    auditsvc "github.com/lyricapp/lyric/web/internal/services/audit"
+    "github.com/lyricapp/lyric/web/internal/services/permissions"
     songsvc "github.com/lyricapp/lyric/web/internal/services/songs"
 )
 
 // Handler exposes song catalogue endpoints.
 type Handler struct {
-	svc songsvc.Service
+	svc   songsvc.Service
+	audit auditsvc.Service
 }
 
-// New wires the songs service into an HTTP handler.
-func New(svc songsvc.Service) Handler {
-	return Handler{svc: svc}
+// New wires the songs service into an HTTP handler. Changes made to other
+// people's songs, and reviews, are recorded in the audit log.
+func New(svc songsvc.Service, audit auditsvc.Service) Handler {
+	return Handler{svc: svc, audit: audit}
 }
 
 type songPayload struct {
@@ -35,6 +39,11 @@ type songPayload struct {
 	ArtistIDs   []int  `json:"artist_ids"`
 	WriterIDs   []int  `json:"writer_ids"`
 	Lyric       string `json:"lyric"`
+
+	BPM             *int    `json:"bpm"`
+	TimeSignature   *string `json:"time_signature"`
+	DurationSeconds *int    `json:"duration_seconds"`
+	Capo            *int    `json:"capo"`
 }
 
 func decodeSongPayload(r *http.Request) (songPayload, error) {
@@ -112,6 +121,11 @@ func (p songPayload) toMutationParams() (songsvc.MutationParams, error) {
 		WriterIDs:  writerIDs,
 		LevelID:    p.LevelID,
 		LanguageID: p.LanguageID,
+
+		BPM:             p.BPM,
+		TimeSignature:   p.TimeSignature,
+		DurationSeconds: p.DurationSeconds,
+		Capo:            p.Capo,
 	}
 
 	if key := strings.TrimSpace(p.Key); key != "" {
@@ -151,6 +165,7 @@ func (h Handler) List(w http.ResponseWriter, r *http.Request) {
 	params.PlaylistID = util.ParseOptionalPositiveInt(query.Get("playlist_id"), "playlist_id", validationErrors)
 	params.UserID = util.ParseOptionalPositiveInt(query.Get("user_id"), "user_id", validationErrors)
 	params.LevelID = util.ParseOptionalPositiveInt(query.Get("level_id"), "level_id", validationErrors)
+	params.LevelSource = strings.TrimSpace(query.Get("level_source"))
 
 	rawLanguageIDs := strings.TrimSpace(query.Get("language_ids"))
 	log.Println(rawLanguageIDs)
@@ -177,9 +192,33 @@ func (h Handler) List(w http.ResponseWriter, r *http.Request) {
 	if strings.TrimSpace(query.Get("is_trending")) == "1" {
 		params.IsTrending = true
 	}
+	if strings.TrimSpace(query.Get("favorite")) == "1" {
+		params.Favorite = true
+	}
 
 	params.Search = util.ParseOptionalSearch(query.Get("search"))
 
+	// ?cursor switches to keyset pagination; it is sent empty for the first page.
+	if query.Has("cursor") {
+		params.Keyset = true
+		params.Cursor = strings.TrimSpace(query.Get("cursor"))
+	}
+	switch strings.TrimSpace(query.Get("total")) {
+	case "":
+	case "1", "true":
+		params.IncludeTotal = true
+	case "0", "false":
+	default:
+		validationErrors["total"] = "total must be 1 or 0"
+	}
+	if query.Has("fields") {
+		fields, err := songsvc.ParseFields(query.Get("fields"))
+		if err != nil {
+			validationErrors["fields"] = err.Error()
+		}
+		params.Fields = fields
+	}
+
 	if len(validationErrors) > 0 {
 		handler.Error(w, apperror.Validation("failed validation", validationErrors))
 		return
@@ -191,11 +230,34 @@ func (h Handler) List(w http.ResponseWriter, r *http.Request) {
 	}
 	params.AuthenticatedUserID = &userID
 
+	// Trending moves with time and playlists with their members' edits, so
+	// only plain anonymous listings get a Last-Modified.
+	if params.PlaylistID == nil && !params.IsTrending {
+		if err := h.lastModified(w, r, userID); err != nil {
+			handler.Error(w, err)
+			return
+		}
+	}
+
 	result, err := h.svc.List(r.Context(), params)
 	if err != nil {
 		handler.Error(w, err)
 		return
 	}
+	if params.Keyset {
+		page := handler.CursorResponse{
+			Data:    result.Data,
+			PerPage: result.PerPage,
+		}
+		if result.NextCursor != "" {
+			page.NextCursor = &result.NextCursor
+		}
+		if params.IncludeTotal {
+			page.Total = &result.Total
+		}
+		handler.Success(w, http.StatusOK, page)
+		return
+	}
 	page := handler.PaginationResponse{
 		Data:    result.Data,
 		Page:    result.Page,
@@ -205,6 +267,66 @@ func (h Handler) List(w http.ResponseWriter, r *http.Request) {
 	handler.Success(w, http.StatusOK, page)
 }
 
+// Show responds with a single song, with the caller's level vote and
+// favourite when they are signed in. Plays are recorded by Play.
+func (h Handler) Show(w http.ResponseWriter, r *http.Request) {
+	rawID := strings.TrimSpace(chi.URLParam(r, "id"))
+	songID, err := strconv.Atoi(rawID)
+	if err != nil || songID <= 0 {
+		handler.Error(w, apperror.BadRequest("Invalid song id"))
+		return
+	}
+	userID, _ := util.CurrentUserID(r)
+	if err := h.lastModified(w, r, userID); err != nil {
+		handler.Error(w, err)
+		return
+	}
+
+	song, err := h.svc.Open(r.Context(), songID, userID)
+	if err != nil {
+		handler.Error(w, err)
+		return
+	}
+	handler.Success(w, http.StatusOK, song)
+}
+
+// lastModified sends when the songs last changed to anonymous readers.
+// Signed-in readers see their own votes, favourites and playlists, whose
+// changes the songs' timestamps miss, so they are left to the ETag.
+func (h Handler) lastModified(w http.ResponseWriter, r *http.Request, userID int) error {
+	if userID > 0 {
+		return nil
+	}
+	modified, err := h.svc.LastModified(r.Context())
+	if err != nil {
+		return err
+	}
+	handler.LastModified(w, modified)
+	return nil
+}
+
+// Play records that the current user played a song.
+func (h Handler) Play(w http.ResponseWriter, r *http.Request) {
+	userID, authErr := util.CurrentUserID(r)
+	if authErr != nil {
+		handler.Error(w, authErr)
+		return
+	}
+	songID, err := strconv.Atoi(strings.TrimSpace(chi.URLParam(r, "id")))
+	if err != nil || songID <= 0 {
+		handler.Error(w, apperror.BadRequest("Invalid song id"))
+		return
+	}
+
+	if err := h.svc.RecordPlay(r.Context(), songID, userID); err != nil {
+		handler.Error(w, err)
+		return
+	}
+	handler.Success(w, http.StatusOK, map[string]string{
+		"message": "Play recorded",
+	})
+}
+
 // Create stores a new song using the shared admin schema.
 func (h Handler) Create(w http.ResponseWriter, r *http.Request) {
 	userID, authErr := util.CurrentUserID(r)
@@ -238,9 +360,10 @@ func (h Handler) Create(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
-// Update mutates an existing song using the shared admin schema.
+// Update mutates an existing song using the shared admin schema. Users edit the
+// songs they added; editors may fix any song.
 func (h Handler) Update(w http.ResponseWriter, r *http.Request) {
-	userID, authErr := util.CurrentUserID(r)
+	principal, authErr := util.CurrentPrincipal(r)
 	if authErr != nil {
 		handler.Error(w, authErr)
 		return
@@ -264,22 +387,28 @@ func (h Handler) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	ownerID := principal.OwnerScope(permissions.EditAnySong)
+	before := h.unscoped(r, ownerID, songID)
 	if err := h.svc.Update(r.Context(), songID, songsvc.UpdateParams{
 		MutationParams: mutation,
-		UserID:         userID,
+		UserID:         principal.UserID,
+		OwnerID:        ownerID,
 	}); err != nil {
 		handler.Error(w, err)
 		return
 	}
+	if before != nil && !addedBy(*before, principal.UserID) {
+		h.record(r, principal, auditsvc.ActionSongUpdate, *before, true)
+	}
 
 	handler.Success(w, http.StatusOK, map[string]any{
 		"message": "Song updated successfully",
 	})
 }
 
-// Delete removes a song owned by the authenticated user.
+// Delete removes a song owned by the authenticated user, or any song for admins.
 func (h Handler) Delete(w http.ResponseWriter, r *http.Request) {
-	userID, authErr := util.CurrentUserID(r)
+	principal, authErr := util.CurrentPrincipal(r)
 	if authErr != nil {
 		handler.Error(w, authErr)
 		return
@@ -292,20 +421,25 @@ func (h Handler) Delete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	params := songsvc.DeleteParams{UserID: &userID}
+	params := songsvc.DeleteParams{UserID: principal.OwnerScope(permissions.DeleteAnySong)}
+	before := h.unscoped(r, params.UserID, songID)
 	if err := h.svc.Delete(r.Context(), songID, params); err != nil {
 		handler.Error(w, err)
 		return
 	}
+	if before != nil && !addedBy(*before, principal.UserID) {
+		h.record(r, principal, auditsvc.ActionSongDelete, *before, false)
+	}
 
 	handler.Success(w, http.StatusOK, map[string]any{
 		"message": "Song deleted successfully",
 	})
 }
 
-// UpdateStatus updates the workflow status for a song owned by the authenticated user.
+// UpdateStatus updates the workflow status for a song owned by the authenticated
+// user. Reviewers may also approve or decline any song.
 func (h Handler) UpdateStatus(w http.ResponseWriter, r *http.Request) {
-	userID, authErr := util.CurrentUserID(r)
+	principal, authErr := util.CurrentPrincipal(r)
 	if authErr != nil {
 		handler.Error(w, authErr)
 		return
@@ -324,16 +458,68 @@ func (h Handler) UpdateStatus(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.svc.UpdateStatus(r.Context(), songID, statusParam, userID); err != nil {
+	ownerID := principal.OwnerScope(permissions.EditAnySong)
+	reviewing := false
+	switch strings.ToLower(statusParam) {
+	case songsvc.StatusApproved, songsvc.StatusDeclined:
+		if !principal.Can(permissions.ApproveSong) {
+			handler.Error(w, apperror.Forbidden("only reviewers can approve or decline songs"))
+			return
+		}
+		ownerID = nil
+		reviewing = true
+	}
+
+	before := h.unscoped(r, ownerID, songID)
+	if err := h.svc.UpdateStatus(r.Context(), songID, statusParam, ownerID); err != nil {
 		handler.Error(w, err)
 		return
 	}
+	if before != nil && (reviewing || !addedBy(*before, principal.UserID)) {
+		h.record(r, principal, auditsvc.ActionSongStatus, *before, true)
+	}
 
 	handler.Success(w, http.StatusOK, map[string]any{
 		"message": "Song status updated successfully",
 	})
 }
 
+// unscoped loads the song ahead of a change not limited to the caller's own
+// songs, so the change can be audited. It returns nil for owner-scoped changes
+// and for songs that cannot be loaded, which the change itself then reports.
+func (h Handler) unscoped(r *http.Request, ownerID *int, songID int) *songsvc.Song {
+	if ownerID != nil {
+		return nil
+	}
+	song, err := h.svc.Get(r.Context(), songID)
+	if err != nil {
+		return nil
+	}
+	return &song
+}
+
+// record audits a change to before, snapshotting the song again unless it was
+// deleted.
+func (h Handler) record(r *http.Request, principal permissions.Principal, action string, before songsvc.Song, reload bool) {
+	params := auditsvc.RecordParams{
+		ActorID:    principal.UserID,
+		Action:     action,
+		EntityType: auditsvc.EntitySong,
+		EntityID:   before.ID,
+		Before:     before,
+	}
+	if reload {
+		if after, err := h.svc.Get(r.Context(), before.ID); err == nil {
+			params.After = after
+		}
+	}
+	handler.Audit(r, h.audit, params)
+}
+
+func addedBy(song songsvc.Song, userID int) bool {
+	return song.Created != nil && song.Created.ID == userID
+}
+
 // AssignLevel associates a level with the specified song.
 func (h Handler) AssignLevel(w http.ResponseWriter, r *http.Request) {
 	songIDValue := chi.URLParam(r, "song_id")
@@ -365,6 +551,25 @@ func (h Handler) AssignLevel(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// LevelVotes responds with the community's difficulty votes on a song and its
+// consensus level.
+func (h Handler) LevelVotes(w http.ResponseWriter, r *http.Request) {
+	rawID := strings.TrimSpace(chi.URLParam(r, "id"))
+	songID, err := strconv.Atoi(rawID)
+	if err != nil || songID <= 0 {
+		handler.Error(w, apperror.BadRequest("Invalid song id"))
+		return
+	}
+	userID, _ := util.CurrentUserID(r)
+
+	votes, err := h.svc.LevelVotes(r.Context(), songID, userID)
+	if err != nil {
+		handler.Error(w, err)
+		return
+	}
+	handler.Success(w, http.StatusOK, votes)
+}
+
 // SyncPlaylists updates the playlists associated with a song.
 func (h Handler) SyncPlaylists(w http.ResponseWriter, r *http.Request) {
 	userID, authErr := util.CurrentUserID(r)
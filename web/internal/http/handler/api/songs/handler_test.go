@@ -11,8 +11,13 @@ import (
 
 	"github.com/lyricapp/lyric/web/internal/http/handler"
 	"github.com/lyricapp/lyric/web/internal/http/handler/api/songs"
+	authmw "github.com/lyricapp/lyric/web/internal/http/middleware/auth"
+	auditsvc "github.com/lyricapp/lyric/web/internal/services/audit"
+	"github.com/lyricapp/lyric/web/internal/services/permissions"
 	songsvc "github.com/lyricapp/lyric/web/internal/services/songs"
 	"github.com/lyricapp/lyric/web/internal/storage"
+	auditrepo "github.com/lyricapp/lyric/web/internal/storage/postgres/audit"
+	permissionrepo "github.com/lyricapp/lyric/web/internal/storage/postgres/permissions"
 	songrepo "github.com/lyricapp/lyric/web/internal/storage/postgres/songs"
 	"github.com/lyricapp/lyric/web/internal/testutil"
 )
@@ -20,7 +25,12 @@ import (
 func getHandler(conn storage.Querier) songs.Handler {
 	repo := songrepo.NewRepository(conn)
 	svc := songsvc.NewService(repo)
-	return songs.New(svc)
+	return songs.New(svc, auditsvc.NewService(auditrepo.NewRepository(conn)))
+}
+
+// submit is the permission middleware guarding song mutations in the router.
+func submit(conn storage.Querier) func(http.Handler) http.Handler {
+	return authmw.Require(permissions.NewService(permissionrepo.NewRepository(conn)), permissions.SubmitSong)
 }
 
 func TestHandler_List(t *testing.T) {
@@ -131,6 +141,21 @@ func TestHandler_List_Validation(t *testing.T) {
 			queryParams: "user_id=abc",
 			expectedKey: "user_id",
 		},
+		{
+			name:        "unknown field",
+			queryParams: "fields=lyric,bogus",
+			expectedKey: "fields",
+		},
+		{
+			name:        "invalid total",
+			queryParams: "cursor=&total=maybe",
+			expectedKey: "total",
+		},
+		{
+			name:        "invalid cursor",
+			queryParams: "cursor=not-a-cursor",
+			expectedKey: "cursor",
+		},
 	}
 
 	h := getHandler(tx)
@@ -788,6 +813,10 @@ func TestHandler_Create_Fail(t *testing.T) {
 		{"invalid album_ids", map[string]any{"title": "t", "level_id": 1, "language_id": 1, "lyric": "lyric", "album_ids": []int{0}}, "album_ids"},
 		{"invalid artist_ids", map[string]any{"title": "t", "level_id": 1, "language_id": 1, "lyric": "lyric", "artist_ids": []int{0}}, "artist_ids"},
 		{"invalid writer_ids", map[string]any{"title": "t", "level_id": 1, "language_id": 1, "lyric": "lyric", "writer_ids": []int{0}}, "writer_ids"},
+		{"invalid bpm", map[string]any{"title": "t", "level_id": 1, "language_id": 1, "lyric": "lyric", "bpm": 10}, "bpm"},
+		{"invalid time_signature", map[string]any{"title": "t", "level_id": 1, "language_id": 1, "lyric": "lyric", "time_signature": "4/5"}, "time_signature"},
+		{"invalid duration_seconds", map[string]any{"title": "t", "level_id": 1, "language_id": 1, "lyric": "lyric", "duration_seconds": 0}, "duration_seconds"},
+		{"invalid capo", map[string]any{"title": "t", "level_id": 1, "language_id": 1, "lyric": "lyric", "capo": 13}, "capo"},
 	}
 
 	for _, tc := range testCases {
@@ -873,7 +902,7 @@ func TestHandler_Update_Success(t *testing.T) {
 
 	r, accessToken := testutil.AuthToken(t, userID)
 	h := getHandler(tx)
-	r.Put("/api/songs/{id}", h.Update)
+	r.With(submit(tx)).Put("/api/songs/{id}", h.Update)
 
 	req, err := http.NewRequest("PUT", fmt.Sprintf("/api/songs/%d", songID), bytes.NewBuffer(body))
 	if err != nil {
@@ -923,7 +952,7 @@ func TestHandler_Update_Fail(t *testing.T) {
 
 	r, accessToken := testutil.AuthToken(t, userID)
 	h := getHandler(tx)
-	r.Put("/api/songs/{id}", h.Update)
+	r.With(submit(tx)).Put("/api/songs/{id}", h.Update)
 
 	testCases := []struct {
 		name               string
@@ -985,7 +1014,7 @@ func TestHandler_Delete_Success(t *testing.T) {
 
 	r, accessToken := testutil.AuthToken(t, userID)
 	h := getHandler(tx)
-	r.Delete("/api/songs/{id}", h.Delete)
+	r.With(submit(tx)).Delete("/api/songs/{id}", h.Delete)
 
 	req, err := http.NewRequest("DELETE", fmt.Sprintf("/api/songs/%d", songID), nil)
 	if err != nil {
@@ -1039,7 +1068,7 @@ func TestHandler_Delete_Fail(t *testing.T) {
 
 	r, ownerToken := testutil.AuthToken(t, ownerID)
 	h := getHandler(tx)
-	r.Delete("/api/songs/{id}", h.Delete)
+	r.With(submit(tx)).Delete("/api/songs/{id}", h.Delete)
 
 	_, otherToken := testutil.AuthToken(t, otherUserID)
 
@@ -1119,7 +1148,7 @@ func TestHandler_UpdateStatus_Success(t *testing.T) {
 
 	r, accessToken := testutil.AuthToken(t, userID)
 	h := getHandler(tx)
-	r.Post("/api/songs/{id}/status/{status}", h.UpdateStatus)
+	r.With(submit(tx)).Post("/api/songs/{id}/status/{status}", h.UpdateStatus)
 
 	req, err := http.NewRequest("POST", fmt.Sprintf("/api/songs/%d/status/pending", songID), nil)
 	if err != nil {
@@ -1177,7 +1206,7 @@ func TestHandler_UpdateStatus_Fail(t *testing.T) {
 
 	r, ownerToken := testutil.AuthToken(t, ownerID)
 	h := getHandler(tx)
-	r.Post("/api/songs/{id}/status/{status}", h.UpdateStatus)
+	r.With(submit(tx)).Post("/api/songs/{id}/status/{status}", h.UpdateStatus)
 
 	_, otherToken := testutil.AuthToken(t, otherUserID)
 
@@ -1205,10 +1234,17 @@ func TestHandler_UpdateStatus_Fail(t *testing.T) {
 		{
 			name:           "invalid status",
 			targetID:       fmt.Sprintf("%d", songID),
-			status:         "approved",
+			status:         "published",
 			token:          &ownerToken,
 			expectedStatus: http.StatusBadRequest,
 		},
+		{
+			name:           "approve own song",
+			targetID:       fmt.Sprintf("%d", songID),
+			status:         "approved",
+			token:          &ownerToken,
+			expectedStatus: http.StatusForbidden,
+		},
 		{
 			name:           "not owner",
 			targetID:       fmt.Sprintf("%d", songID),
@@ -1238,6 +1274,114 @@ func TestHandler_UpdateStatus_Fail(t *testing.T) {
 	}
 }
 
+func TestHandler_RolePermissions(t *testing.T) {
+	conn := testutil.SetupDB(t)
+	defer conn.Close()
+
+	ctx := context.Background()
+	tx, _ := conn.Begin(ctx)
+	defer tx.Rollback(ctx)
+
+	// given
+	users := map[string]int{}
+	for _, role := range []string{"contributor", "editor", "admin"} {
+		var id int
+		if err := tx.QueryRow(ctx, "insert into users (email, role) values ($1, $2) returning id", role+"@user.com", role).Scan(&id); err != nil {
+			t.Fatalf("failed to insert %s: %v", role, err)
+		}
+		users[role] = id
+	}
+	var languageID, levelID, ownSongID, otherSongID int
+	if err := tx.QueryRow(ctx, "insert into languages (name) values ('english') returning id").Scan(&languageID); err != nil {
+		t.Fatalf("failed to insert language: %v", err)
+	}
+	if err := tx.QueryRow(ctx, "insert into levels (name) values ('beginner') returning id").Scan(&levelID); err != nil {
+		t.Fatalf("failed to insert level: %v", err)
+	}
+	if err := tx.QueryRow(ctx, "insert into songs (title, created_by, language_id) values ('contributed', $1, $2) returning id", users["contributor"], languageID).Scan(&ownSongID); err != nil {
+		t.Fatalf("failed to insert song: %v", err)
+	}
+	if err := tx.QueryRow(ctx, "insert into songs (title, created_by, language_id) values ('someone else''s', $1, $2) returning id", users["admin"], languageID).Scan(&otherSongID); err != nil {
+		t.Fatalf("failed to insert song: %v", err)
+	}
+
+	h := getHandler(tx)
+	send := func(role, method, path string, payload any) int {
+		r, token := testutil.AuthToken(t, users[role])
+		r.With(submit(tx)).Put("/api/songs/{id}", h.Update)
+		r.With(submit(tx)).Delete("/api/songs/{id}", h.Delete)
+		r.With(submit(tx)).Post("/api/songs/{id}/status/{status}", h.UpdateStatus)
+
+		body, _ := json.Marshal(payload)
+		req, _ := http.NewRequest(method, path, bytes.NewBuffer(body))
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		return rr.Code
+	}
+	edit := map[string]any{"title": "fixed", "level_id": levelID, "language_id": languageID, "lyric": "fixed lyric"}
+
+	testCases := []struct {
+		name           string
+		role           string
+		method         string
+		path           string
+		payload        any
+		expectedStatus int
+	}{
+		{"contributor edits own song", "contributor", "PUT", fmt.Sprintf("/api/songs/%d", ownSongID), edit, http.StatusOK},
+		{"contributor cannot edit others' songs", "contributor", "PUT", fmt.Sprintf("/api/songs/%d", otherSongID), edit, http.StatusNotFound},
+		{"editor fixes any song", "editor", "PUT", fmt.Sprintf("/api/songs/%d", otherSongID), edit, http.StatusOK},
+		{"contributor cannot approve", "contributor", "POST", fmt.Sprintf("/api/songs/%d/status/approved", ownSongID), nil, http.StatusForbidden},
+		{"editor approves any song", "editor", "POST", fmt.Sprintf("/api/songs/%d/status/approved", ownSongID), nil, http.StatusOK},
+		{"editor cannot delete others' songs", "editor", "DELETE", fmt.Sprintf("/api/songs/%d", ownSongID), nil, http.StatusNotFound},
+		{"admin deletes any song", "admin", "DELETE", fmt.Sprintf("/api/songs/%d", ownSongID), nil, http.StatusOK},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// when
+			status := send(tc.role, tc.method, tc.path, tc.payload)
+
+			// then
+			if status != tc.expectedStatus {
+				t.Fatalf("unexpected status: got %d want %d", status, tc.expectedStatus)
+			}
+		})
+	}
+
+	var title string
+	if err := tx.QueryRow(ctx, "select title from songs where id = $1", otherSongID).Scan(&title); err != nil || title != "fixed" {
+		t.Errorf("expected the editor's fix to be saved, got %q (%v)", title, err)
+	}
+
+	// Only changes to other people's songs and reviews are audited.
+	rows, err := tx.Query(ctx, "select action, actor_id, entity_id from audit_log where entity_type = 'song' order by id")
+	if err != nil {
+		t.Fatalf("failed to read audit log: %v", err)
+	}
+	defer rows.Close()
+	var audited []string
+	for rows.Next() {
+		var (
+			action, entityID string
+			actorID          int
+		)
+		if err := rows.Scan(&action, &actorID, &entityID); err != nil {
+			t.Fatalf("failed to scan audit entry: %v", err)
+		}
+		audited = append(audited, fmt.Sprintf("%s %d %s", action, actorID, entityID))
+	}
+	expected := []string{
+		fmt.Sprintf("%s %d %d", auditsvc.ActionSongUpdate, users["editor"], otherSongID),
+		fmt.Sprintf("%s %d %d", auditsvc.ActionSongStatus, users["editor"], ownSongID),
+		fmt.Sprintf("%s %d %d", auditsvc.ActionSongDelete, users["admin"], ownSongID),
+	}
+	if fmt.Sprint(audited) != fmt.Sprint(expected) {
+		t.Errorf("unexpected audit log: got %v want %v", audited, expected)
+	}
+}
+
 func TestHandler_SyncPlaylists_ReplacesState(t *testing.T) {
 	conn := testutil.SetupDB(t)
 	defer conn.Close()
@@ -1561,3 +1705,405 @@ func TestHandler_AssignLevel_Fail(t *testing.T) {
 		})
 	}
 }
+
+func TestHandler_List_PlaylistOrderAndArrangement(t *testing.T) {
+	conn := testutil.SetupDB(t)
+	defer conn.Close()
+
+	ctx := context.Background()
+	tx, _ := conn.Begin(ctx)
+	defer tx.Rollback(ctx)
+
+	// given
+	var userID, languageID, firstSongID, secondSongID, playlistID int
+	if err := tx.QueryRow(ctx, "insert into users (email, role) values ('setlist@user.com', 'musician') returning id").Scan(&userID); err != nil {
+		t.Fatalf("failed to insert user: %v", err)
+	}
+	if err := tx.QueryRow(ctx, "insert into languages (name) values ('english') returning id").Scan(&languageID); err != nil {
+		t.Fatalf("failed to insert language: %v", err)
+	}
+	if err := tx.QueryRow(ctx, "insert into songs (title, key, lyric, language_id) values ('opener', 'G', '[G]Amazing [D/F#]grace', $1) returning id", languageID).Scan(&firstSongID); err != nil {
+		t.Fatalf("failed to insert song: %v", err)
+	}
+	if err := tx.QueryRow(ctx, "insert into songs (title, language_id) values ('closer', $1) returning id", languageID).Scan(&secondSongID); err != nil {
+		t.Fatalf("failed to insert song: %v", err)
+	}
+	if err := tx.QueryRow(ctx, "insert into playlists (name, user_id) values ('sunday', $1) returning id", userID).Scan(&playlistID); err != nil {
+		t.Fatalf("failed to insert playlist: %v", err)
+	}
+	if _, err := tx.Exec(ctx, `
+		insert into playlist_song (playlist_id, song_id, position, transpose, capo, note)
+		values ($1, $2, 1, 2, 2, 'slow intro'), ($1, $3, 2, 0, 0, null)
+	`, playlistID, firstSongID, secondSongID); err != nil {
+		t.Fatalf("failed to seed playlist songs: %v", err)
+	}
+
+	r, _ := testutil.AuthToken(t, userID)
+	h := getHandler(tx)
+	r.Get("/api/songs", h.List)
+
+	// when
+	req, _ := http.NewRequest("GET", fmt.Sprintf("/api/songs?playlist_id=%d", playlistID), nil)
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+
+	// then
+	if status := rr.Code; status != http.StatusOK {
+		t.Fatalf("unexpected status code: got %d want %d", status, http.StatusOK)
+	}
+	var res handler.PageResponse[songsvc.Song]
+	if err := json.NewDecoder(rr.Body).Decode(&res); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(res.Data) != 2 || res.Data[0].ID != firstSongID || res.Data[1].ID != secondSongID {
+		t.Fatalf("expected songs in playlist order, got %+v", res.Data)
+	}
+
+	opener := res.Data[0]
+	if opener.Arrangement == nil || opener.Arrangement.Transpose != 2 || opener.Arrangement.Capo != 2 {
+		t.Fatalf("expected arrangement to be returned, got %+v", opener.Arrangement)
+	}
+	if opener.Key == nil || *opener.Key != "A" {
+		t.Errorf("expected sounding key A, got %v", opener.Key)
+	}
+	// Up two and capo two: the shapes played are the original ones.
+	if opener.Lyric == nil || *opener.Lyric != "[G]Amazing [D/F#]grace" {
+		t.Errorf("unexpected lyric: %v", opener.Lyric)
+	}
+}
+
+func TestHandler_List_PlaylistHiddenFromStrangers(t *testing.T) {
+	conn := testutil.SetupDB(t)
+	defer conn.Close()
+
+	ctx := context.Background()
+	tx, _ := conn.Begin(ctx)
+	defer tx.Rollback(ctx)
+
+	// given
+	var ownerID, strangerID, languageID, songID, playlistID int
+	if err := tx.QueryRow(ctx, "insert into users (email, role) values ('owner@setlist.com', 'musician') returning id").Scan(&ownerID); err != nil {
+		t.Fatalf("failed to insert user: %v", err)
+	}
+	if err := tx.QueryRow(ctx, "insert into users (email, role) values ('stranger@setlist.com', 'musician') returning id").Scan(&strangerID); err != nil {
+		t.Fatalf("failed to insert user: %v", err)
+	}
+	if err := tx.QueryRow(ctx, "insert into languages (name) values ('english') returning id").Scan(&languageID); err != nil {
+		t.Fatalf("failed to insert language: %v", err)
+	}
+	if err := tx.QueryRow(ctx, "insert into songs (title, language_id) values ('opener', $1) returning id", languageID).Scan(&songID); err != nil {
+		t.Fatalf("failed to insert song: %v", err)
+	}
+	if err := tx.QueryRow(ctx, "insert into playlists (name, user_id) values ('private', $1) returning id", ownerID).Scan(&playlistID); err != nil {
+		t.Fatalf("failed to insert playlist: %v", err)
+	}
+	if _, err := tx.Exec(ctx, "insert into playlist_song (playlist_id, song_id, transpose, note) values ($1, $2, 3, 'secret')", playlistID, songID); err != nil {
+		t.Fatalf("failed to seed playlist song: %v", err)
+	}
+
+	r, _ := testutil.AuthToken(t, strangerID)
+	h := getHandler(tx)
+	r.Get("/api/songs", h.List)
+
+	// when
+	req, _ := http.NewRequest("GET", fmt.Sprintf("/api/songs?playlist_id=%d", playlistID), nil)
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+
+	// then
+	if status := rr.Code; status != http.StatusNotFound {
+		t.Fatalf("unexpected status code: got %d want %d", status, http.StatusNotFound)
+	}
+}
+
+func TestHandler_List_Keyset(t *testing.T) {
+	conn := testutil.SetupDB(t)
+	defer conn.Close()
+
+	ctx := context.Background()
+	tx, _ := conn.Begin(ctx)
+	defer tx.Rollback(ctx)
+
+	// given
+	var langID, artistID int
+	if err := tx.QueryRow(ctx, "insert into languages (name) values ('keyset') returning id").Scan(&langID); err != nil {
+		t.Fatalf("failed to insert language: %v", err)
+	}
+	if err := tx.QueryRow(ctx, "insert into artists (name) values ('keyset artist') returning id").Scan(&artistID); err != nil {
+		t.Fatalf("failed to insert artist: %v", err)
+	}
+	want := map[int]bool{}
+	for i := 0; i < 5; i++ {
+		var songID int
+		if err := tx.QueryRow(ctx, "insert into songs (title, language_id, lyric, bpm) values ($1, $2, 'line one\nline two', 100) returning id", fmt.Sprintf("keyset %d", i), langID).Scan(&songID); err != nil {
+			t.Fatalf("failed to insert song: %v", err)
+		}
+		if _, err := tx.Exec(ctx, "insert into artist_song (artist_id, song_id) values ($1, $2)", artistID, songID); err != nil {
+			t.Fatalf("failed to link artist: %v", err)
+		}
+		want[songID] = true
+	}
+
+	h := getHandler(tx)
+	list := func(query string) (int, map[string]json.RawMessage) {
+		req, err := http.NewRequest("GET", "/api/songs?"+query, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rr := httptest.NewRecorder()
+		h.List(rr, req)
+		var res map[string]json.RawMessage
+		if err := json.Unmarshal(rr.Body.Bytes(), &res); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		return rr.Code, res
+	}
+
+	// when: walking the artist's songs two at a time without lyrics
+	seen := map[int]bool{}
+	cursor := ""
+	for page := 0; ; page++ {
+		if page > len(want) {
+			t.Fatal("keyset pages did not end")
+		}
+		query := fmt.Sprintf("artist_id=%d&per_page=2&fields=artists,auto_scroll&cursor=%s", artistID, cursor)
+		if page == 0 {
+			query += "&total=1"
+		}
+		status, res := list(query)
+		if status != http.StatusOK {
+			t.Fatalf("keyset page: got %d want %d", status, http.StatusOK)
+		}
+		if _, ok := res["total"]; ok != (page == 0) {
+			t.Fatalf("page %d: total present %v", page, ok)
+		}
+
+		var data []map[string]json.RawMessage
+		if err := json.Unmarshal(res["data"], &data); err != nil {
+			t.Fatalf("failed to decode songs: %v", err)
+		}
+		for _, song := range data {
+			var id int
+			_ = json.Unmarshal(song["id"], &id)
+			if seen[id] {
+				t.Fatalf("song %d returned twice", id)
+			}
+			seen[id] = true
+			if _, ok := song["lyric"]; ok {
+				t.Fatalf("lyric returned without being selected")
+			}
+			if string(song["writers"]) != "null" || string(song["artists"]) == "null" || string(song["auto_scroll"]) == "null" {
+				t.Fatalf("unexpected field selection: %s %s %s", song["writers"], song["artists"], song["auto_scroll"])
+			}
+		}
+
+		var next *string
+		_ = json.Unmarshal(res["next_cursor"], &next)
+		if next == nil {
+			break
+		}
+		cursor = *next
+	}
+
+	// then
+	if len(seen) != len(want) {
+		t.Fatalf("keyset pages returned %d songs, want %d", len(seen), len(want))
+	}
+	for id := range want {
+		if !seen[id] {
+			t.Fatalf("song %d missing from keyset pages", id)
+		}
+	}
+}
+
+func TestHandler_LevelVotes_Consensus(t *testing.T) {
+	conn := testutil.SetupDB(t)
+	defer conn.Close()
+
+	ctx := context.Background()
+	tx, _ := conn.Begin(ctx)
+	defer tx.Rollback(ctx)
+
+	// given: an author who rated their song hard, and four voters
+	insertUser := func(email, role string) int {
+		var id int
+		if err := tx.QueryRow(ctx, "insert into users (email, role) values ($1, $2) returning id", email, role).Scan(&id); err != nil {
+			t.Fatalf("failed to insert user: %v", err)
+		}
+		return id
+	}
+	authorID := insertUser("author@test.com", "contributor")
+	first := insertUser("first@test.com", "musician")
+	second := insertUser("second@test.com", "musician")
+	editor := insertUser("editor@test.com", "editor")
+	third := insertUser("third@test.com", "musician")
+
+	var langID, easyID, hardID, songID int
+	if err := tx.QueryRow(ctx, "insert into languages (name) values ('english') returning id").Scan(&langID); err != nil {
+		t.Fatalf("failed to insert language: %v", err)
+	}
+	if err := tx.QueryRow(ctx, "insert into levels (name) values ('easy') returning id").Scan(&easyID); err != nil {
+		t.Fatalf("failed to insert level: %v", err)
+	}
+	if err := tx.QueryRow(ctx, "insert into levels (name) values ('hard') returning id").Scan(&hardID); err != nil {
+		t.Fatalf("failed to insert level: %v", err)
+	}
+	if err := tx.QueryRow(ctx, "insert into songs (title, created_by, language_id, level_id) values ('voted', $1, $2, $3) returning id", authorID, langID, hardID).Scan(&songID); err != nil {
+		t.Fatalf("failed to insert song: %v", err)
+	}
+
+	svc := songsvc.NewService(songrepo.NewRepository(tx))
+	vote := func(userID, levelID int) {
+		t.Helper()
+		if err := svc.AssignLevel(ctx, songID, levelID, userID); err != nil {
+			t.Fatalf("vote: %v", err)
+		}
+	}
+
+	r, accessToken := testutil.AuthToken(t, first)
+	h := getHandler(tx)
+	r.Get("/api/songs/{id}/levels", h.LevelVotes)
+	r.Get("/api/songs", h.List)
+	levelVotes := func() songsvc.LevelVotes {
+		t.Helper()
+		req, err := http.NewRequest("GET", fmt.Sprintf("/api/songs/%d/levels", songID), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", accessToken))
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("unexpected status code: got %d want %d: %s", rr.Code, http.StatusOK, rr.Body.String())
+		}
+		var res handler.ResponseMessage[songsvc.LevelVotes]
+		if err := json.NewDecoder(rr.Body).Decode(&res); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		return res.Data
+	}
+
+	// when: two votes are below the minimum
+	vote(first, easyID)
+	vote(second, easyID)
+
+	// then
+	if got := levelVotes(); got.ConsensusLevel != nil || got.Votes != 2 || got.MinVotes != songsvc.MinLevelVotes {
+		t.Fatalf("expected no consensus below the minimum, got %+v", got)
+	}
+
+	// when: an editor, who counts twice, and another user vote hard; the first
+	// user changes their mind
+	vote(editor, hardID)
+	vote(third, hardID)
+	vote(first, hardID)
+	vote(first, easyID)
+
+	// then: hard outweighs easy, and each user is counted once
+	got := levelVotes()
+	if got.Votes != 4 || got.ConsensusLevel == nil || got.ConsensusLevel.ID != hardID {
+		t.Fatalf("expected a hard consensus from four voters, got %+v", got)
+	}
+	if got.UserLevelID == nil || *got.UserLevelID != easyID {
+		t.Errorf("expected the caller's vote to be marked, got %v", got.UserLevelID)
+	}
+	weights := map[int][2]int{}
+	for _, count := range got.Distribution {
+		weights[count.ID] = [2]int{count.Votes, count.Weight}
+	}
+	if weights[easyID] != [2]int{2, 2} || weights[hardID] != [2]int{2, 3} {
+		t.Errorf("unexpected distribution: %+v", got.Distribution)
+	}
+	if got.AuthorLevel == nil || got.AuthorLevel.ID != hardID {
+		t.Errorf("expected votes to leave the author's level alone, got %+v", got.AuthorLevel)
+	}
+
+	// when: a voter flips the consensus and the trending chart lists by it
+	vote(third, easyID)
+	req, err := http.NewRequest("GET", fmt.Sprintf("/api/songs?level_id=%d&level_source=consensus", easyID), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", accessToken))
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+
+	// then
+	var res handler.PageResponse[songsvc.Song]
+	if err := json.NewDecoder(rr.Body).Decode(&res); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if res.Total != 1 || res.Data[0].ID != songID || res.Data[0].ConsensusLevel == nil || res.Data[0].Level.ID != hardID {
+		t.Errorf("expected the song to be listed by its consensus level, got %+v", res.Data)
+	}
+}
+
+func TestHandler_LevelVotes_ConsensusFollowsRoleChanges(t *testing.T) {
+	conn := testutil.SetupDB(t)
+	defer conn.Close()
+
+	ctx := context.Background()
+	tx, _ := conn.Begin(ctx)
+	defer tx.Rollback(ctx)
+
+	// given: three musicians voted easy and two voted hard
+	var langID, easyID, hardID, songID int
+	if err := tx.QueryRow(ctx, "insert into languages (name) values ('english') returning id").Scan(&langID); err != nil {
+		t.Fatalf("failed to insert language: %v", err)
+	}
+	if err := tx.QueryRow(ctx, "insert into levels (name) values ('easy') returning id").Scan(&easyID); err != nil {
+		t.Fatalf("failed to insert level: %v", err)
+	}
+	if err := tx.QueryRow(ctx, "insert into levels (name) values ('hard') returning id").Scan(&hardID); err != nil {
+		t.Fatalf("failed to insert level: %v", err)
+	}
+	if err := tx.QueryRow(ctx, "insert into songs (title, language_id) values ('voted', $1) returning id", langID).Scan(&songID); err != nil {
+		t.Fatalf("failed to insert song: %v", err)
+	}
+
+	svc := songsvc.NewService(songrepo.NewRepository(tx))
+	var hardVoters []int
+	for i, levelID := range []int{easyID, easyID, easyID, hardID, hardID} {
+		var userID int
+		if err := tx.QueryRow(ctx, "insert into users (email, role) values ($1, 'musician') returning id", fmt.Sprintf("voter%d@test.com", i)).Scan(&userID); err != nil {
+			t.Fatalf("failed to insert user: %v", err)
+		}
+		if err := svc.AssignLevel(ctx, songID, levelID, userID); err != nil {
+			t.Fatalf("vote: %v", err)
+		}
+		if levelID == hardID {
+			hardVoters = append(hardVoters, userID)
+		}
+	}
+	consensus := func() int {
+		t.Helper()
+		var levelID int
+		if err := tx.QueryRow(ctx, "select consensus_level_id from songs where id = $1", songID).Scan(&levelID); err != nil {
+			t.Fatalf("failed to read consensus: %v", err)
+		}
+		return levelID
+	}
+	if got := consensus(); got != easyID {
+		t.Fatalf("expected an easy consensus, got %d", got)
+	}
+
+	// when: both hard voters become editors, who count twice
+	if _, err := tx.Exec(ctx, "update users set role = 'editor' where id = any($1)", hardVoters); err != nil {
+		t.Fatalf("failed to promote voters: %v", err)
+	}
+
+	// then
+	if got := consensus(); got != hardID {
+		t.Fatalf("expected promotion to flip the consensus to hard, got %d", got)
+	}
+
+	// when: they are demoted again
+	if _, err := tx.Exec(ctx, "update users set role = 'musician' where id = any($1)", hardVoters); err != nil {
+		t.Fatalf("failed to demote voters: %v", err)
+	}
+
+	// then
+	if got := consensus(); got != easyID {
+		t.Fatalf("expected demotion to restore the easy consensus, got %d", got)
+	}
+}
@@ -0,0 +1,71 @@
+package subscriptions
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/lyricapp/lyric/web/internal/apperror"
+	"github.com/lyricapp/lyric/web/internal/http/handler"
+	"github.com/lyricapp/lyric/web/internal/http/handler/api/util"
+	subscriptionsvc "github.com/lyricapp/lyric/web/internal/services/subscriptions"
+)
+
+// Handler exposes purchase verification and entitlements to the app.
+type Handler struct {
+	svc subscriptionsvc.Service
+}
+
+// New constructs a subscriptions handler.
+func New(svc subscriptionsvc.Service) Handler {
+	return Handler{svc: svc}
+}
+
+// Entitlements returns the current user's plan and active purchases.
+func (h Handler) Entitlements(w http.ResponseWriter, r *http.Request) {
+	userID, authErr := util.CurrentUserID(r)
+	if authErr != nil {
+		handler.Error(w, authErr)
+		return
+	}
+
+	entitlements, err := h.svc.Entitlements(r.Context(), userID)
+	if err != nil {
+		handler.Error(w, err)
+		return
+	}
+
+	handler.Success(w, http.StatusOK, entitlements)
+}
+
+// Purchase verifies a store receipt and returns the updated entitlements.
+func (h Handler) Purchase(w http.ResponseWriter, r *http.Request) {
+	userID, authErr := util.CurrentUserID(r)
+	if authErr != nil {
+		handler.Error(w, authErr)
+		return
+	}
+
+	var payload struct {
+		Provider  string `json:"provider"`
+		ProductID string `json:"product_id"`
+		Receipt   string `json:"receipt"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		handler.Error(w, apperror.BadRequest("invalid JSON payload"))
+		return
+	}
+
+	entitlements, err := h.svc.Verify(r.Context(), subscriptionsvc.VerifyParams{
+		UserID:    userID,
+		Provider:  subscriptionsvc.ProviderName(payload.Provider),
+		ProductID: payload.ProductID,
+		Receipt:   payload.Receipt,
+	})
+	if err != nil {
+		handler.Error(w, err)
+		return
+	}
+
+	handler.Success(w, http.StatusOK, entitlements)
+}
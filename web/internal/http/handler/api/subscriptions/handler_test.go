@@ -0,0 +1,172 @@
+package subscriptions_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/lyricapp/lyric/web/internal/http/handler"
+	"github.com/lyricapp/lyric/web/internal/http/handler/api/subscriptions"
+	plansvc "github.com/lyricapp/lyric/web/internal/services/plans"
+	subscriptionsvc "github.com/lyricapp/lyric/web/internal/services/subscriptions"
+	"github.com/lyricapp/lyric/web/internal/storage"
+	planrepo "github.com/lyricapp/lyric/web/internal/storage/postgres/plans"
+	subscriptionrepo "github.com/lyricapp/lyric/web/internal/storage/postgres/subscriptions"
+	"github.com/lyricapp/lyric/web/internal/testutil"
+)
+
+func getHandler(db storage.Querier) subscriptions.Handler {
+	plans := plansvc.NewService(planrepo.NewRepository(db), plansvc.Config{
+		Free: plansvc.Limits{Playlists: 3, Shares: 3},
+	})
+	svc := subscriptionsvc.NewService(subscriptionrepo.NewRepository(db), plans, subscriptionsvc.NewStubProvider())
+	return subscriptions.New(svc)
+}
+
+func purchase(t *testing.T, h subscriptions.Handler, userID int, body map[string]string) *httptest.ResponseRecorder {
+	t.Helper()
+	r, accessToken := testutil.AuthToken(t, userID)
+	r.Post("/api/me/purchases", h.Purchase)
+
+	requestBody, _ := json.Marshal(body)
+	req, err := http.NewRequest("POST", "/api/me/purchases", bytes.NewBuffer(requestBody))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", accessToken))
+
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+	return rr
+}
+
+func TestHandler_Entitlements_Free(t *testing.T) {
+	conn := testutil.SetupDB(t)
+	defer conn.Close()
+
+	ctx := context.Background()
+	tx, _ := conn.Begin(ctx)
+	defer tx.Rollback(ctx)
+
+	var userID int
+	if err := tx.QueryRow(ctx, "insert into users (email, role) values ('free@user.com', 'musician') returning id").Scan(&userID); err != nil {
+		t.Fatalf("failed to insert users: %v", err)
+	}
+	h := getHandler(tx)
+
+	r, accessToken := testutil.AuthToken(t, userID)
+	r.Get("/api/me/entitlements", h.Entitlements)
+	req, _ := http.NewRequest("GET", "/api/me/entitlements", nil)
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", accessToken))
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Fatalf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+	}
+	var response handler.ResponseMessage[subscriptionsvc.Entitlements]
+	if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if response.Data.Plan != plansvc.PlanFree || response.Data.Limits.Playlists != 3 {
+		t.Errorf("expected free plan with 3 playlists, got %+v", response.Data)
+	}
+	if response.Data.ActiveUntil != nil {
+		t.Errorf("expected no active period, got %v", response.Data.ActiveUntil)
+	}
+}
+
+func TestHandler_Purchase(t *testing.T) {
+	conn := testutil.SetupDB(t)
+	defer conn.Close()
+
+	ctx := context.Background()
+	tx, _ := conn.Begin(ctx)
+	defer tx.Rollback(ctx)
+
+	// given
+	var buyer, other int
+	if err := tx.QueryRow(ctx, "insert into users (email, role) values ('buyer@user.com', 'musician') returning id").Scan(&buyer); err != nil {
+		t.Fatalf("failed to insert users: %v", err)
+	}
+	if err := tx.QueryRow(ctx, "insert into users (email, role) values ('other@user.com', 'musician') returning id").Scan(&other); err != nil {
+		t.Fatalf("failed to insert users: %v", err)
+	}
+	h := getHandler(tx)
+
+	testCases := []struct {
+		name               string
+		userID             int
+		body               map[string]string
+		expectedStatusCode int
+		expectedPlan       plansvc.Plan
+		expectedUnlocks    int
+	}{
+		{
+			name:               "Time-boxed unlock grants premium",
+			userID:             buyer,
+			body:               map[string]string{"provider": "stub", "product_id": "unlock_3h", "receipt": "stub:unlock-1"},
+			expectedStatusCode: http.StatusOK,
+			expectedPlan:       plansvc.PlanPremium,
+			expectedUnlocks:    1,
+		},
+		{
+			name:               "Resubmitting the same receipt is idempotent",
+			userID:             buyer,
+			body:               map[string]string{"provider": "stub", "product_id": "unlock_3h", "receipt": "stub:unlock-1"},
+			expectedStatusCode: http.StatusOK,
+			expectedPlan:       plansvc.PlanPremium,
+			expectedUnlocks:    1,
+		},
+		{
+			name:               "Receipt claimed by another account",
+			userID:             other,
+			body:               map[string]string{"provider": "stub", "product_id": "unlock_3h", "receipt": "stub:unlock-1"},
+			expectedStatusCode: http.StatusForbidden,
+		},
+		{
+			name:               "Unknown product and provider",
+			userID:             buyer,
+			body:               map[string]string{"provider": "paypal", "product_id": "lifetime", "receipt": "stub:x"},
+			expectedStatusCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:               "Invalid receipt",
+			userID:             buyer,
+			body:               map[string]string{"provider": "stub", "product_id": "premium_monthly", "receipt": "forged"},
+			expectedStatusCode: http.StatusUnprocessableEntity,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// when
+			rr := purchase(t, h, tc.userID, tc.body)
+
+			// then
+			if status := rr.Code; status != tc.expectedStatusCode {
+				t.Fatalf("handler returned wrong status code: got %v want %v", status, tc.expectedStatusCode)
+			}
+			if tc.expectedStatusCode != http.StatusOK {
+				return
+			}
+			var response handler.ResponseMessage[subscriptionsvc.Entitlements]
+			if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if response.Data.Plan != tc.expectedPlan {
+				t.Errorf("expected plan %q, got %q", tc.expectedPlan, response.Data.Plan)
+			}
+			if len(response.Data.Unlocks) != tc.expectedUnlocks {
+				t.Errorf("expected %d unlocks, got %d", tc.expectedUnlocks, len(response.Data.Unlocks))
+			}
+			if response.Data.ActiveUntil == nil {
+				t.Error("expected an active period")
+			}
+		})
+	}
+}
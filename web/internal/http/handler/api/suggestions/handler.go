@@ -0,0 +1,212 @@
+package suggestions
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/lyricapp/lyric/web/internal/apperror"
+	"github.com/lyricapp/lyric/web/internal/http/handler"
+	"github.com/lyricapp/lyric/web/internal/http/handler/api/util"
+	auditsvc "github.com/lyricapp/lyric/web/internal/services/audit"
+	"github.com/lyricapp/lyric/web/internal/services/permissions"
+	songsvc "github.com/lyricapp/lyric/web/internal/services/songs"
+	suggestionsvc "github.com/lyricapp/lyric/web/internal/services/suggestions"
+)
+
+// Handler serves suggested corrections to song lyrics.
+type Handler struct {
+	svc   suggestionsvc.Service
+	songs songsvc.Service
+	audit auditsvc.Service
+}
+
+// New constructs a suggestion handler. Editors accepting suggestions on other
+// people's songs are recorded in the audit log, like any other edit.
+func New(svc suggestionsvc.Service, songs songsvc.Service, audit auditsvc.Service) Handler {
+	return Handler{svc: svc, songs: songs, audit: audit}
+}
+
+// Create records the current user's suggested lyric for a song.
+func (h Handler) Create(w http.ResponseWriter, r *http.Request) {
+	userID, authErr := util.CurrentUserID(r)
+	if authErr != nil {
+		handler.Error(w, authErr)
+		return
+	}
+	songID, err := strconv.Atoi(strings.TrimSpace(chi.URLParam(r, "id")))
+	if err != nil || songID <= 0 {
+		handler.Error(w, apperror.BadRequest("Invalid song id"))
+		return
+	}
+
+	var payload struct {
+		Lyric   string `json:"lyric"`
+		Comment string `json:"comment"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		handler.Error(w, apperror.BadRequest("invalid JSON payload"))
+		return
+	}
+
+	suggestion, err := h.svc.Create(r.Context(), suggestionsvc.CreateParams{
+		SongID:  songID,
+		UserID:  userID,
+		Lyric:   payload.Lyric,
+		Comment: payload.Comment,
+	})
+	if err != nil {
+		handler.Error(w, err)
+		return
+	}
+	handler.Success(w, http.StatusCreated, suggestion)
+}
+
+// List returns a song's suggestions to its owner or an editor.
+func (h Handler) List(w http.ResponseWriter, r *http.Request) {
+	principal, authErr := util.CurrentPrincipal(r)
+	if authErr != nil {
+		handler.Error(w, authErr)
+		return
+	}
+	songID, err := strconv.Atoi(strings.TrimSpace(chi.URLParam(r, "id")))
+	if err != nil || songID <= 0 {
+		handler.Error(w, apperror.BadRequest("Invalid song id"))
+		return
+	}
+
+	suggestions, err := h.svc.List(r.Context(), suggestionsvc.ListParams{
+		SongID:  songID,
+		Status:  strings.ToLower(strings.TrimSpace(r.URL.Query().Get("status"))),
+		UserID:  principal.UserID,
+		OwnerID: principal.OwnerScope(permissions.EditAnySong),
+	})
+	if err != nil {
+		handler.Error(w, err)
+		return
+	}
+	handler.Success(w, http.StatusOK, suggestions)
+}
+
+// Show returns a suggestion with its diff to the suggester or a reviewer.
+func (h Handler) Show(w http.ResponseWriter, r *http.Request) {
+	principal, authErr := util.CurrentPrincipal(r)
+	if authErr != nil {
+		handler.Error(w, authErr)
+		return
+	}
+	id, err := suggestionID(r)
+	if err != nil {
+		handler.Error(w, err)
+		return
+	}
+
+	suggestion, err := h.svc.Get(r.Context(), id, principal.UserID, principal.OwnerScope(permissions.EditAnySong))
+	if err != nil {
+		handler.Error(w, err)
+		return
+	}
+	handler.Success(w, http.StatusOK, suggestion)
+}
+
+// Accept applies a suggestion to its song.
+func (h Handler) Accept(w http.ResponseWriter, r *http.Request) {
+	principal, params, id, err := reviewRequest(r)
+	if err != nil {
+		handler.Error(w, err)
+		return
+	}
+
+	var before *songsvc.Song
+	if params.OwnerID == nil {
+		before = h.songBefore(r, principal.UserID, id)
+	}
+	suggestion, err := h.svc.Accept(r.Context(), id, params)
+	if err != nil {
+		handler.Error(w, err)
+		return
+	}
+	if before != nil && (before.Created == nil || before.Created.ID != principal.UserID) {
+		audit := auditsvc.RecordParams{
+			ActorID:    principal.UserID,
+			Action:     auditsvc.ActionSongUpdate,
+			EntityType: auditsvc.EntitySong,
+			EntityID:   before.ID,
+			Before:     *before,
+		}
+		if after, err := h.songs.Get(r.Context(), before.ID); err == nil {
+			audit.After = after
+		}
+		handler.Audit(r, h.audit, audit)
+	}
+	handler.Success(w, http.StatusOK, suggestion)
+}
+
+// Reject closes a suggestion without applying it and notifies the suggester.
+func (h Handler) Reject(w http.ResponseWriter, r *http.Request) {
+	_, params, id, err := reviewRequest(r)
+	if err != nil {
+		handler.Error(w, err)
+		return
+	}
+
+	suggestion, err := h.svc.Reject(r.Context(), id, params)
+	if err != nil {
+		handler.Error(w, err)
+		return
+	}
+	handler.Success(w, http.StatusOK, suggestion)
+}
+
+// songBefore loads the song an editor is about to change through a
+// suggestion, so the change can be audited. It returns nil when either cannot
+// be loaded, which Accept then reports.
+func (h Handler) songBefore(r *http.Request, userID, id int) *songsvc.Song {
+	suggestion, err := h.svc.Get(r.Context(), id, userID, nil)
+	if err != nil {
+		return nil
+	}
+	song, err := h.songs.Get(r.Context(), suggestion.SongID)
+	if err != nil {
+		return nil
+	}
+	return &song
+}
+
+// reviewRequest reads the reviewer, their optional note and the suggestion id.
+func reviewRequest(r *http.Request) (permissions.Principal, suggestionsvc.ReviewParams, int, error) {
+	principal, err := util.CurrentPrincipal(r)
+	if err != nil {
+		return permissions.Principal{}, suggestionsvc.ReviewParams{}, 0, err
+	}
+	id, err := suggestionID(r)
+	if err != nil {
+		return permissions.Principal{}, suggestionsvc.ReviewParams{}, 0, err
+	}
+
+	var payload struct {
+		Note string `json:"note"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil && !errors.Is(err, io.EOF) {
+		return permissions.Principal{}, suggestionsvc.ReviewParams{}, 0, apperror.BadRequest("invalid JSON payload")
+	}
+
+	return principal, suggestionsvc.ReviewParams{
+		UserID:  principal.UserID,
+		OwnerID: principal.OwnerScope(permissions.EditAnySong),
+		Note:    payload.Note,
+	}, id, nil
+}
+
+func suggestionID(r *http.Request) (int, error) {
+	id, err := strconv.Atoi(strings.TrimSpace(chi.URLParam(r, "id")))
+	if err != nil || id <= 0 {
+		return 0, apperror.BadRequest("Invalid suggestion id")
+	}
+	return id, nil
+}
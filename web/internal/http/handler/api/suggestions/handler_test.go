@@ -0,0 +1,341 @@
+package suggestions_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/lyricapp/lyric/web/internal/http/handler"
+	"github.com/lyricapp/lyric/web/internal/http/handler/api/suggestions"
+	authmw "github.com/lyricapp/lyric/web/internal/http/middleware/auth"
+	auditsvc "github.com/lyricapp/lyric/web/internal/services/audit"
+	"github.com/lyricapp/lyric/web/internal/services/permissions"
+	songsvc "github.com/lyricapp/lyric/web/internal/services/songs"
+	suggestionsvc "github.com/lyricapp/lyric/web/internal/services/suggestions"
+	"github.com/lyricapp/lyric/web/internal/storage"
+	auditrepo "github.com/lyricapp/lyric/web/internal/storage/postgres/audit"
+	permissionrepo "github.com/lyricapp/lyric/web/internal/storage/postgres/permissions"
+	songrepo "github.com/lyricapp/lyric/web/internal/storage/postgres/songs"
+	suggestionrepo "github.com/lyricapp/lyric/web/internal/storage/postgres/suggestions"
+	"github.com/lyricapp/lyric/web/internal/testutil"
+)
+
+type recordingNotifier struct {
+	sent map[string]string
+}
+
+func (n *recordingNotifier) SuggestionRejected(_ context.Context, email, _, note string) error {
+	n.sent[email] = note
+	return nil
+}
+
+type fixture struct {
+	owner, suggester, editor, stranger int
+	songID                             int
+}
+
+const lyric = "[G]Amazing grace\n[C]How sweet the sound"
+
+func seed(t *testing.T, tx storage.Querier) fixture {
+	t.Helper()
+	ctx := context.Background()
+	var f fixture
+	for _, user := range []struct {
+		id    *int
+		email string
+		role  string
+	}{
+		{&f.owner, "owner@test.com", "musician"},
+		{&f.suggester, "suggester@test.com", "musician"},
+		{&f.editor, "editor@test.com", "editor"},
+		{&f.stranger, "stranger@test.com", "musician"},
+	} {
+		if err := tx.QueryRow(ctx, "insert into users (email, role) values ($1, $2) returning id", user.email, user.role).Scan(user.id); err != nil {
+			t.Fatalf("failed to seed user: %v", err)
+		}
+	}
+	var langID int
+	if err := tx.QueryRow(ctx, "insert into languages (name) values ('english') returning id").Scan(&langID); err != nil {
+		t.Fatalf("failed to seed language: %v", err)
+	}
+	if err := tx.QueryRow(ctx, "insert into songs (title, language_id, lyric, created_by) values ('grace', $1, $2, $3) returning id", langID, lyric, f.owner).Scan(&f.songID); err != nil {
+		t.Fatalf("failed to seed song: %v", err)
+	}
+	return f
+}
+
+// serve sends the request as the user through the routes as the router
+// mounts them.
+func serve(t *testing.T, tx storage.Querier, h suggestions.Handler, userID int, method, url string, body any) *httptest.ResponseRecorder {
+	t.Helper()
+	r, accessToken := testutil.AuthToken(t, userID)
+	r.Post("/api/songs/{id}/suggestions", h.Create)
+	submit := r.With(authmw.Require(permissions.NewService(permissionrepo.NewRepository(tx)), permissions.SubmitSong))
+	submit.Get("/api/songs/{id}/suggestions", h.List)
+	submit.Get("/api/suggestions/{id}", h.Show)
+	submit.Post("/api/suggestions/{id}/accept", h.Accept)
+	submit.Post("/api/suggestions/{id}/reject", h.Reject)
+
+	var payload bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&payload).Encode(body); err != nil {
+			t.Fatal(err)
+		}
+	}
+	req, err := http.NewRequest(method, url, &payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", accessToken))
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+	return rr
+}
+
+func getHandler(tx storage.Querier) (suggestions.Handler, songsvc.Service, *recordingNotifier) {
+	notifier := &recordingNotifier{sent: map[string]string{}}
+	songs := songsvc.NewService(songrepo.NewRepository(tx))
+	svc := suggestionsvc.NewService(suggestionrepo.NewRepository(tx), songs, notifier, func(ctx context.Context, fn func(suggestionsvc.Repository, suggestionsvc.Songs) error) error {
+		return storage.InTx(ctx, tx, func(tx storage.Querier) error {
+			return fn(suggestionrepo.NewRepository(tx), songsvc.NewService(songrepo.NewRepository(tx)))
+		})
+	})
+	return suggestions.New(svc, songs, auditsvc.NewService(auditrepo.NewRepository(tx))), songs, notifier
+}
+
+func decode[T any](t *testing.T, rr *httptest.ResponseRecorder) T {
+	t.Helper()
+	var res handler.ResponseMessage[T]
+	if err := json.NewDecoder(rr.Body).Decode(&res); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	return res.Data
+}
+
+func TestHandler_Accept(t *testing.T) {
+	conn := testutil.SetupDB(t)
+	defer conn.Close()
+
+	ctx := context.Background()
+	tx, _ := conn.Begin(ctx)
+	defer tx.Rollback(ctx)
+
+	f := seed(t, tx)
+	h, songs, _ := getHandler(tx)
+	corrected := "[G]Amazing grace\n[G7]How sweet the sound"
+
+	// given
+	rr := serve(t, tx, h, f.suggester, "POST", fmt.Sprintf("/api/songs/%d/suggestions", f.songID), map[string]string{
+		"lyric":   corrected,
+		"comment": "The second line is a G7",
+	})
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("create: got %d want %d: %s", rr.Code, http.StatusCreated, rr.Body.String())
+	}
+	created := decode[suggestionsvc.Suggestion](t, rr)
+
+	// when
+	list := serve(t, tx, h, f.owner, "GET", fmt.Sprintf("/api/songs/%d/suggestions?status=pending", f.songID), nil)
+	show := serve(t, tx, h, f.owner, "GET", fmt.Sprintf("/api/suggestions/%d", created.ID), nil)
+	forbidden := serve(t, tx, h, f.stranger, "GET", fmt.Sprintf("/api/songs/%d/suggestions", f.songID), nil)
+	denied := serve(t, tx, h, f.suggester, "POST", fmt.Sprintf("/api/suggestions/%d/accept", created.ID), nil)
+	accept := serve(t, tx, h, f.owner, "POST", fmt.Sprintf("/api/suggestions/%d/accept", created.ID), nil)
+	again := serve(t, tx, h, f.editor, "POST", fmt.Sprintf("/api/suggestions/%d/reject", created.ID), nil)
+
+	// then
+	if list.Code != http.StatusOK {
+		t.Fatalf("list: got %d want %d", list.Code, http.StatusOK)
+	}
+	if pending := decode[[]suggestionsvc.Suggestion](t, list); len(pending) != 1 || pending[0].ID != created.ID || pending[0].UserID != f.suggester {
+		t.Errorf("unexpected pending suggestions: %+v", pending)
+	}
+	if show.Code != http.StatusOK {
+		t.Fatalf("show: got %d want %d", show.Code, http.StatusOK)
+	}
+	want := []suggestionsvc.DiffLine{
+		{Op: suggestionsvc.DiffEqual, Text: "[G]Amazing grace"},
+		{Op: suggestionsvc.DiffDelete, Text: "[C]How sweet the sound"},
+		{Op: suggestionsvc.DiffInsert, Text: "[G7]How sweet the sound"},
+	}
+	if diff := decode[suggestionsvc.Suggestion](t, show).Diff; fmt.Sprint(diff) != fmt.Sprint(want) {
+		t.Errorf("unexpected diff: got %+v want %+v", diff, want)
+	}
+	if forbidden.Code != http.StatusForbidden {
+		t.Errorf("stranger list: got %d want %d", forbidden.Code, http.StatusForbidden)
+	}
+	if denied.Code != http.StatusForbidden {
+		t.Errorf("suggester accept: got %d want %d", denied.Code, http.StatusForbidden)
+	}
+	if accept.Code != http.StatusOK {
+		t.Fatalf("accept: got %d want %d: %s", accept.Code, http.StatusOK, accept.Body.String())
+	}
+	if accepted := decode[suggestionsvc.Suggestion](t, accept); accepted.Status != suggestionsvc.StatusAccepted || accepted.ReviewedBy == nil || *accepted.ReviewedBy != f.owner {
+		t.Errorf("unexpected accepted suggestion: %+v", accepted)
+	}
+	if again.Code != http.StatusUnprocessableEntity {
+		t.Errorf("review twice: got %d want %d", again.Code, http.StatusUnprocessableEntity)
+	}
+
+	song, err := songs.Get(ctx, f.songID)
+	if err != nil {
+		t.Fatalf("failed to load song: %v", err)
+	}
+	if song.Lyric == nil || *song.Lyric != corrected {
+		t.Errorf("expected the suggested lyric, got %v", song.Lyric)
+	}
+	if len(song.Contributors) != 1 || song.Contributors[0].ID != f.suggester || song.Contributors[0].Email != "****@test.com" {
+		t.Errorf("expected the suggester to be credited, got %+v", song.Contributors)
+	}
+}
+
+func TestHandler_Accept_FailedUpdateStaysPending(t *testing.T) {
+	conn := testutil.SetupDB(t)
+	defer conn.Close()
+
+	ctx := context.Background()
+	tx, _ := conn.Begin(ctx)
+	defer tx.Rollback(ctx)
+
+	f := seed(t, tx)
+	h, _, _ := getHandler(tx)
+
+	// given: a suggestion on a song the songs service will refuse to update
+	rr := serve(t, tx, h, f.suggester, "POST", fmt.Sprintf("/api/songs/%d/suggestions", f.songID), map[string]string{
+		"lyric":   "[G]Amazing grace\n[G7]How sweet the sound",
+		"comment": "The second line is a G7",
+	})
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("create: got %d want %d: %s", rr.Code, http.StatusCreated, rr.Body.String())
+	}
+	created := decode[suggestionsvc.Suggestion](t, rr)
+	if _, err := tx.Exec(ctx, "update songs set title = ' ' where id = $1", f.songID); err != nil {
+		t.Fatalf("failed to blank the title: %v", err)
+	}
+
+	// when
+	accept := serve(t, tx, h, f.owner, "POST", fmt.Sprintf("/api/suggestions/%d/accept", created.ID), nil)
+
+	// then
+	if accept.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("accept: got %d want %d: %s", accept.Code, http.StatusUnprocessableEntity, accept.Body.String())
+	}
+	var status string
+	if err := tx.QueryRow(ctx, "select status from song_suggestions where id = $1", created.ID).Scan(&status); err != nil {
+		t.Fatalf("failed to read suggestion: %v", err)
+	}
+	if status != suggestionsvc.StatusPending {
+		t.Errorf("expected the suggestion to stay pending, got %q", status)
+	}
+}
+
+func TestHandler_Reject(t *testing.T) {
+	conn := testutil.SetupDB(t)
+	defer conn.Close()
+
+	ctx := context.Background()
+	tx, _ := conn.Begin(ctx)
+	defer tx.Rollback(ctx)
+
+	f := seed(t, tx)
+	h, _, notifier := getHandler(tx)
+
+	// given
+	rr := serve(t, tx, h, f.suggester, "POST", fmt.Sprintf("/api/songs/%d/suggestions", f.songID), map[string]string{
+		"lyric":   "[G]Amazing grace\n[D]How sweet the sound",
+		"comment": "It is a D",
+	})
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("create: got %d want %d: %s", rr.Code, http.StatusCreated, rr.Body.String())
+	}
+	created := decode[suggestionsvc.Suggestion](t, rr)
+
+	// when
+	hidden := serve(t, tx, h, f.stranger, "GET", fmt.Sprintf("/api/suggestions/%d", created.ID), nil)
+	reject := serve(t, tx, h, f.editor, "POST", fmt.Sprintf("/api/suggestions/%d/reject", created.ID), map[string]string{"note": "The recording plays a C"})
+	show := serve(t, tx, h, f.suggester, "GET", fmt.Sprintf("/api/suggestions/%d", created.ID), nil)
+
+	// then
+	if hidden.Code != http.StatusNotFound {
+		t.Errorf("stranger show: got %d want %d", hidden.Code, http.StatusNotFound)
+	}
+	if reject.Code != http.StatusOK {
+		t.Fatalf("reject: got %d want %d: %s", reject.Code, http.StatusOK, reject.Body.String())
+	}
+	if note, ok := notifier.sent["suggester@test.com"]; !ok || note != "The recording plays a C" {
+		t.Errorf("expected the suggester to be notified, got %+v", notifier.sent)
+	}
+	if show.Code != http.StatusOK {
+		t.Fatalf("show: got %d want %d", show.Code, http.StatusOK)
+	}
+	if got := decode[suggestionsvc.Suggestion](t, show); got.Status != suggestionsvc.StatusRejected || got.ReviewNote == nil {
+		t.Errorf("unexpected rejected suggestion: %+v", got)
+	}
+
+	var notified bool
+	if err := tx.QueryRow(ctx, "select notified_at is not null from song_suggestions where id = $1", created.ID).Scan(&notified); err != nil {
+		t.Fatalf("failed to read suggestion: %v", err)
+	}
+	if !notified {
+		t.Error("expected notified_at to be set")
+	}
+
+	var lyricNow string
+	if err := tx.QueryRow(ctx, "select lyric from songs where id = $1", f.songID).Scan(&lyricNow); err != nil {
+		t.Fatalf("failed to read song: %v", err)
+	}
+	if lyricNow != lyric {
+		t.Errorf("expected the lyric to be kept, got %q", lyricNow)
+	}
+}
+
+func TestHandler_Create_Validation(t *testing.T) {
+	conn := testutil.SetupDB(t)
+	defer conn.Close()
+
+	ctx := context.Background()
+	tx, _ := conn.Begin(ctx)
+	defer tx.Rollback(ctx)
+
+	f := seed(t, tx)
+	h, _, _ := getHandler(tx)
+
+	testCases := []struct {
+		name   string
+		userID int
+		songID int
+		body   map[string]string
+		status int
+		key    string
+	}{
+		{"missing comment", f.suggester, f.songID, map[string]string{"lyric": "new"}, http.StatusUnprocessableEntity, "comment"},
+		{"same lyric", f.suggester, f.songID, map[string]string{"lyric": lyric + "\n", "comment": "typo"}, http.StatusUnprocessableEntity, "lyric"},
+		{"own song", f.owner, f.songID, map[string]string{"lyric": "new", "comment": "typo"}, http.StatusUnprocessableEntity, "song_id"},
+		{"unknown song", f.suggester, f.songID + 1000, map[string]string{"lyric": "new", "comment": "typo"}, http.StatusNotFound, ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// when
+			rr := serve(t, tx, h, tc.userID, "POST", fmt.Sprintf("/api/songs/%d/suggestions", tc.songID), tc.body)
+
+			// then
+			if rr.Code != tc.status {
+				t.Fatalf("got %d want %d: %s", rr.Code, tc.status, rr.Body.String())
+			}
+			if tc.key == "" {
+				return
+			}
+			var res handler.ErrorResponse[map[string]string]
+			if err := json.NewDecoder(rr.Body).Decode(&res); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if _, ok := res.Errors[tc.key]; !ok {
+				t.Errorf("expected an error for %s, got %+v", tc.key, res.Errors)
+			}
+		})
+	}
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/go-chi/jwtauth/v5"
 	"github.com/lyricapp/lyric/web/internal/apperror"
+	"github.com/lyricapp/lyric/web/internal/services/permissions"
 )
 
 // CurrentUserID extracts the authenticated user's ID from the request context.
@@ -41,3 +42,31 @@ func CurrentUserID(r *http.Request) (int, error) {
 		return 0, apperror.Unauthorized("Unauthorized user")
 	}
 }
+
+// CurrentSessionID returns the device session the access token was issued for,
+// or 0 for tokens that predate sessions.
+func CurrentSessionID(r *http.Request) int64 {
+	_, claims, err := jwtauth.FromContext(r.Context())
+	if err != nil {
+		return 0
+	}
+	raw, ok := claims["sid"].(string)
+	if !ok {
+		return 0
+	}
+	id, err := strconv.ParseInt(strings.TrimSpace(raw), 10, 64)
+	if err != nil || id <= 0 {
+		return 0
+	}
+	return id
+}
+
+// CurrentPrincipal returns the caller and their role, as resolved by the
+// permission middleware.
+func CurrentPrincipal(r *http.Request) (permissions.Principal, error) {
+	principal, ok := permissions.FromContext(r.Context())
+	if !ok {
+		return permissions.Principal{}, apperror.Unauthorized("Unauthorized user")
+	}
+	return principal, nil
+}
@@ -0,0 +1,20 @@
+package handler
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/go-chi/chi/v5/middleware"
+
+	auditsvc "github.com/lyricapp/lyric/web/internal/services/audit"
+)
+
+// Audit records a privileged change made while serving r, tagged with the
+// request ID. The change has already been applied, so a failure to record it
+// is logged rather than reported to the caller.
+func Audit(r *http.Request, recorder auditsvc.Service, params auditsvc.RecordParams) {
+	params.RequestID = middleware.GetReqID(r.Context())
+	if err := recorder.Record(r.Context(), params); err != nil {
+		log.Printf("audit: record %s on %s %d: %v", params.Action, params.EntityType, params.EntityID, err)
+	}
+}
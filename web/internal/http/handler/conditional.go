@@ -0,0 +1,105 @@
+package handler
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// CachePolicy describes how successful GET responses of a route group may be
+// cached. CacheControl is sent verbatim and Vary lists the request headers the
+// response depends on.
+type CachePolicy struct {
+	CacheControl string
+	Vary         []string
+}
+
+// Conditional wraps GET and HEAD requests so Success sends the policy's headers
+// with an ETag, answering If-None-Match with 304 Not Modified. Handlers that
+// know when their data last changed report it with LastModified, which adds
+// Last-Modified and answers If-Modified-Since when there is no If-None-Match.
+// Other responses are untouched.
+func Conditional(policy CachePolicy) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodGet && r.Method != http.MethodHead {
+				next.ServeHTTP(w, r)
+				return
+			}
+			next.ServeHTTP(&conditionalWriter{ResponseWriter: w, r: r, policy: policy}, r)
+		})
+	}
+}
+
+type conditionalWriter struct {
+	http.ResponseWriter
+	r            *http.Request
+	policy       CachePolicy
+	lastModified time.Time
+}
+
+// LastModified records when the data behind the response last changed. It
+// does nothing outside a Conditional route, or when at is zero.
+func LastModified(w http.ResponseWriter, at time.Time) {
+	if cw, ok := w.(*conditionalWriter); ok {
+		cw.lastModified = at
+	}
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController.
+func (cw *conditionalWriter) Unwrap() http.ResponseWriter {
+	return cw.ResponseWriter
+}
+
+// writeOK sends a 200 body, or 304 when the client's copy is still current.
+func (cw *conditionalWriter) writeOK(body []byte) {
+	sum := sha256.Sum256(body)
+	etag := `"` + base64.RawURLEncoding.EncodeToString(sum[:18]) + `"`
+
+	header := cw.Header()
+	if cw.policy.CacheControl != "" {
+		header.Set("Cache-Control", cw.policy.CacheControl)
+	}
+	for _, name := range cw.policy.Vary {
+		header.Add("Vary", name)
+	}
+	header.Set("ETag", etag)
+	if !cw.lastModified.IsZero() {
+		header.Set("Last-Modified", cw.lastModified.UTC().Format(http.TimeFormat))
+	}
+
+	if notModified(cw.r, etag, cw.lastModified) {
+		cw.WriteHeader(http.StatusNotModified)
+		return
+	}
+	header.Set("Content-Type", "application/json")
+	cw.WriteHeader(http.StatusOK)
+	if cw.r.Method != http.MethodHead {
+		_, _ = cw.Write(body)
+	}
+}
+
+// notModified applies RFC 9110 If-None-Match, comparing ETags weakly, and
+// falls back to If-Modified-Since only when the client sent no ETag.
+func notModified(r *http.Request, etag string, lastModified time.Time) bool {
+	if match := r.Header.Get("If-None-Match"); match != "" {
+		for _, candidate := range strings.Split(match, ",") {
+			candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+			if candidate == "*" || candidate == etag {
+				return true
+			}
+		}
+		return false
+	}
+	if lastModified.IsZero() {
+		return false
+	}
+	since, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+	// HTTP dates have whole seconds.
+	return !lastModified.Truncate(time.Second).After(since)
+}
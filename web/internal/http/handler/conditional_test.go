@@ -0,0 +1,138 @@
+package handler_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/lyricapp/lyric/web/internal/http/handler"
+)
+
+func TestConditional(t *testing.T) {
+	payload := []string{"english", "burmese"}
+	h := handler.Conditional(handler.CachePolicy{CacheControl: "public, max-age=60", Vary: []string{"Authorization"}})(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			handler.Success(w, http.StatusOK, payload)
+		}),
+	)
+	get := func(headers map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/languages", nil)
+		for name, value := range headers {
+			req.Header.Set(name, value)
+		}
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+		return rr
+	}
+
+	// given
+	first := get(nil)
+	etag := first.Header().Get("ETag")
+	if first.Code != http.StatusOK || etag == "" {
+		t.Fatalf("expected 200 with an etag, got %d etag %q", first.Code, etag)
+	}
+	if first.Header().Get("Last-Modified") != "" {
+		t.Fatalf("expected no last-modified, got %q", first.Header().Get("Last-Modified"))
+	}
+	if first.Header().Get("Cache-Control") != "public, max-age=60" || first.Header().Get("Vary") != "Authorization" {
+		t.Fatalf("policy headers missing: %v", first.Header())
+	}
+
+	tests := []struct {
+		name    string
+		headers map[string]string
+		want    int
+	}{
+		{"matching etag", map[string]string{"If-None-Match": etag}, http.StatusNotModified},
+		{"weak etag in a list", map[string]string{"If-None-Match": `"other", W/` + etag}, http.StatusNotModified},
+		{"stale etag", map[string]string{"If-None-Match": `"other"`}, http.StatusOK},
+		{"dates need a last-modified", map[string]string{"If-Modified-Since": "Sun, 18 Oct 2099 00:00:00 GMT"}, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// when
+			rr := get(tt.headers)
+
+			// then
+			if rr.Code != tt.want {
+				t.Fatalf("got %d want %d", rr.Code, tt.want)
+			}
+			if rr.Header().Get("ETag") != etag {
+				t.Fatalf("etag changed: %q", rr.Header().Get("ETag"))
+			}
+			if tt.want == http.StatusNotModified && rr.Body.Len() != 0 {
+				t.Fatalf("304 carried a body: %q", rr.Body.String())
+			}
+		})
+	}
+
+	// a changed payload gets a new etag
+	payload = append(payload, "thai")
+	if rr := get(map[string]string{"If-None-Match": etag}); rr.Code != http.StatusOK || rr.Header().Get("ETag") == etag {
+		t.Fatalf("changed payload: got %d etag %q", rr.Code, rr.Header().Get("ETag"))
+	}
+}
+
+func TestConditional_LastModified(t *testing.T) {
+	changed := time.Date(2026, 10, 1, 12, 30, 15, 500, time.UTC)
+	h := handler.Conditional(handler.CachePolicy{CacheControl: "public, max-age=60"})(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			handler.LastModified(w, changed)
+			handler.Success(w, http.StatusOK, []string{"easy"})
+		}),
+	)
+	get := func(headers map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/levels", nil)
+		for name, value := range headers {
+			req.Header.Set(name, value)
+		}
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+		return rr
+	}
+
+	// given
+	first := get(nil)
+	if got := first.Header().Get("Last-Modified"); got != "Thu, 01 Oct 2026 12:30:15 GMT" {
+		t.Fatalf("unexpected last-modified %q", got)
+	}
+	etag := first.Header().Get("ETag")
+
+	tests := []struct {
+		name    string
+		headers map[string]string
+		want    int
+	}{
+		{"same second", map[string]string{"If-Modified-Since": "Thu, 01 Oct 2026 12:30:15 GMT"}, http.StatusNotModified},
+		{"later", map[string]string{"If-Modified-Since": "Fri, 02 Oct 2026 00:00:00 GMT"}, http.StatusNotModified},
+		{"earlier", map[string]string{"If-Modified-Since": "Thu, 01 Oct 2026 12:30:14 GMT"}, http.StatusOK},
+		{"unparsable", map[string]string{"If-Modified-Since": "yesterday"}, http.StatusOK},
+		{"etag wins over a current date", map[string]string{"If-None-Match": `"other"`, "If-Modified-Since": "Fri, 02 Oct 2026 00:00:00 GMT"}, http.StatusOK},
+		{"etag wins over a stale date", map[string]string{"If-None-Match": etag, "If-Modified-Since": "Thu, 01 Jan 2026 00:00:00 GMT"}, http.StatusNotModified},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// when
+			rr := get(tt.headers)
+
+			// then
+			if rr.Code != tt.want {
+				t.Fatalf("got %d want %d", rr.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestConditional_OnlyOK(t *testing.T) {
+	h := handler.Conditional(handler.CachePolicy{CacheControl: "public, max-age=60"})(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			handler.Success(w, http.StatusCreated, map[string]int{"id": 1})
+		}),
+	)
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rr.Code != http.StatusCreated || rr.Header().Get("ETag") != "" || rr.Header().Get("Cache-Control") != "" {
+		t.Fatalf("expected an uncached 201, got %d %v", rr.Code, rr.Header())
+	}
+}
@@ -1,23 +1,54 @@
 package library
 
 import (
+	"log"
 	"net/http"
 
 	"github.com/a-h/templ"
 
+	adminctx "github.com/lyricapp/lyric/web/internal/http/context/admin"
+	librarysvc "github.com/lyricapp/lyric/web/internal/services/library"
 	"github.com/lyricapp/lyric/web/internal/web/components"
 )
 
+// recentOnPage caps the recently viewed songs listed on the page.
+const recentOnPage = 10
+
 // Handler renders the library page using templ components.
-type Handler struct{}
+type Handler struct {
+	library librarysvc.Service
+}
 
-// New constructs a handler for the library surface.
-func New() *Handler {
-	return &Handler{}
+// New constructs a handler for the library surface. Readers signed in to the
+// web also see their favourites and recently viewed songs; the web only has
+// staff sign-in so far, so app users see the shared library only.
+func New(library librarysvc.Service) *Handler {
+	return &Handler{library: library}
 }
 
 // ServeHTTP delegates rendering to templ's HTTP adapter.
 func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	props := components.BuildLibraryProps()
+	props := components.BuildLibraryProps(h.history(r))
 	templ.Handler(components.Library(props)).ServeHTTP(w, r)
 }
+
+// history loads the favourites and recently viewed songs of the reader signed
+// in through the admin session, the web's only sign-in. The page still renders
+// without them when they fail to load.
+func (h *Handler) history(r *http.Request) *components.LibraryHistory {
+	user, ok := adminctx.FromContext(r.Context())
+	if !ok || h.library == nil {
+		return nil
+	}
+	recent, err := h.library.Recent(r.Context(), user.ID, recentOnPage)
+	if err != nil {
+		log.Printf("library: load recent songs for user %d: %v", user.ID, err)
+		return nil
+	}
+	favorites, err := h.library.Favorites(r.Context(), user.ID)
+	if err != nil {
+		log.Printf("library: load favourites for user %d: %v", user.ID, err)
+		return nil
+	}
+	return &components.LibraryHistory{Recent: recent, Favorites: favorites}
+}
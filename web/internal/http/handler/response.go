@@ -16,6 +16,15 @@ type PaginationResponse struct {
 	Total   int `json:"total"`
 }
 
+// CursorResponse is a keyset-paginated page. NextCursor is null on the last page
+// and Total is only present when it was counted.
+type CursorResponse struct {
+	Data       any     `json:"data"`
+	PerPage    int     `json:"per_page"`
+	NextCursor *string `json:"next_cursor"`
+	Total      *int    `json:"total,omitempty"`
+}
+
 type Response[T any] struct {
 	Data []T `json:"data"`
 }
@@ -62,11 +71,21 @@ func Success(w http.ResponseWriter, code int, payload any) {
 	case string:
 		envelope = map[string]any{"message": v}
 		return
-	case PaginationResponse:
+	case PaginationResponse, CursorResponse:
 		envelope = payload
 	default:
 		envelope = map[string]any{"data": payload}
 	}
+	if cw, ok := w.(*conditionalWriter); ok && code == http.StatusOK {
+		body, err := json.Marshal(envelope)
+		if err != nil {
+			log.Printf("ERROR: could not marshal JSON response: %v", err)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+		cw.writeOK(body)
+		return
+	}
 	toJSON(w, code, envelope)
 }
 
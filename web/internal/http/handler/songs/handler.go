@@ -7,19 +7,26 @@ import (
 	"github.com/a-h/templ"
 	"github.com/go-chi/chi/v5"
 
+	adminctx "github.com/lyricapp/lyric/web/internal/http/context/admin"
+	preferencesvc "github.com/lyricapp/lyric/web/internal/services/preferences"
 	"github.com/lyricapp/lyric/web/internal/web/components"
 	"github.com/lyricapp/lyric/web/internal/web/data"
 )
 
 // Handler renders song detail pages with chord display modes.
-type Handler struct{}
+type Handler struct {
+	prefs preferencesvc.Service
+}
 
-// New constructs a song detail handler.
-func New() *Handler {
-	return &Handler{}
+// New constructs a song detail handler. Readers signed in to the web see the
+// page with their saved preferences; the web only has staff sign-in so far, so
+// app users get the defaults.
+func New(prefs preferencesvc.Service) *Handler {
+	return &Handler{prefs: prefs}
 }
 
-// ServeHTTP looks up the song and renders the requested view mode.
+// ServeHTTP looks up the song and renders the requested view mode, falling
+// back to the reader's preferred one.
 func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	songID := chi.URLParam(r, "id")
 	song, ok := data.GetSongByID(songID)
@@ -29,10 +36,7 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 
 	query := r.URL.Query()
-	view := components.SongModeOverlay
-	if supplied := query.Get("view"); supplied != "" {
-		view = components.SongDetailMode(supplied)
-	}
+	view := components.SongDetailMode(query.Get("view"))
 
 	transpose := 0
 	if raw := query.Get("transpose"); raw != "" {
@@ -62,6 +66,25 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	props := components.BuildSongDetailProps(song, view, transpose, overGap, lineGap, columns)
+	props := components.BuildSongDetailProps(song, view, transpose, overGap, lineGap, columns, h.preferences(r))
 	templ.Handler(components.SongDetail(props)).ServeHTTP(w, r)
 }
+
+// preferences loads the song page settings of the reader signed in through the
+// admin session, the web's only sign-in. Everyone else, and readers whose
+// preferences fail to load, get the defaults.
+func (h *Handler) preferences(r *http.Request) components.SongDetailPreferences {
+	user, ok := adminctx.FromContext(r.Context())
+	if !ok || h.prefs == nil {
+		return components.SongDetailPreferences{}
+	}
+	prefs, err := h.prefs.Get(r.Context(), user.ID)
+	if err != nil {
+		return components.SongDetailPreferences{}
+	}
+	return components.SongDetailPreferences{
+		Mode:     components.SongDetailMode(prefs.DisplayMode),
+		FontSize: prefs.FontSize,
+		Theme:    prefs.Theme,
+	}
+}
@@ -1,22 +1,38 @@
 package adminauth
 
 import (
+	"context"
+	"errors"
 	"net/http"
+	"time"
 
+	"github.com/lyricapp/lyric/web/internal/apperror"
 	adminsession "github.com/lyricapp/lyric/web/internal/auth/admin"
 	adminctx "github.com/lyricapp/lyric/web/internal/http/context/admin"
+	"github.com/lyricapp/lyric/web/internal/services/permissions"
 )
 
-// Middleware wires admin session validation into HTTP handlers.
+// Middleware wires admin session validation into HTTP handlers. With
+// Permissions set, Require reloads the user's role on every request so a
+// demoted or banned user loses access without waiting for the session to end.
+// With SignOuts set, sessions issued before an admin signed the user out of
+// every device are rejected.
 type Middleware struct {
-	Sessions  *adminsession.Manager
-	LoginPath string
+	Sessions    *adminsession.Manager
+	Permissions permissions.Service
+	SignOuts    SignOuts
+	LoginPath   string
+}
+
+// SignOuts checks a session against the user's last forced sign-out.
+type SignOuts interface {
+	CheckIssued(ctx context.Context, userID int, issuedAt time.Time) error
 }
 
 // WithUser attaches the admin user to the request context when a valid session exists.
 func (m Middleware) WithUser(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if claims, err := m.Sessions.Validate(r); err == nil {
+		if claims, err := m.validate(r); err == nil {
 			ctx := adminctx.WithUser(r.Context(), adminctx.User{ID: claims.ID, Username: claims.Username, Role: claims.Role})
 			next.ServeHTTP(w, r.WithContext(ctx))
 			return
@@ -31,8 +47,12 @@ func (m Middleware) WithUser(next http.Handler) http.Handler {
 // Require blocks access unless the request contains a valid admin session.
 func (m Middleware) Require(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		claims, err := m.Sessions.Validate(r)
+		claims, err := m.validate(r)
 		if err != nil {
+			if err != adminsession.ErrNoSession && err != adminsession.ErrInvalidSession {
+				http.Error(w, "unable to check session", http.StatusInternalServerError)
+				return
+			}
 			if err == adminsession.ErrInvalidSession {
 				m.Sessions.Clear(w)
 			}
@@ -40,11 +60,63 @@ func (m Middleware) Require(next http.Handler) http.Handler {
 			return
 		}
 
-		ctx := adminctx.WithUser(r.Context(), adminctx.User{ID: claims.ID, Username: claims.Username, Role: claims.Role})
+		user := adminctx.User{ID: claims.ID, Username: claims.Username, Role: claims.Role}
+		if m.Permissions != nil {
+			principal, err := m.Permissions.Require(r.Context(), claims.ID, permissions.AccessAdmin)
+			var appErr *apperror.AppError
+			if err != nil && !errors.As(err, &appErr) {
+				http.Error(w, "unable to check permissions", http.StatusInternalServerError)
+				return
+			}
+			if err != nil {
+				m.Sessions.Clear(w)
+				http.Redirect(w, r, m.loginPath(), http.StatusFound)
+				return
+			}
+			user.Role = string(principal.Role)
+		}
+
+		ctx := adminctx.WithUser(r.Context(), user)
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
 
+// Can blocks the request with 403 unless the admin user's role grants the
+// capability. It must run after Require.
+func (m Middleware) Can(capability permissions.Capability) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user, ok := adminctx.FromContext(r.Context())
+			if !ok {
+				http.Redirect(w, r, m.loginPath(), http.StatusFound)
+				return
+			}
+			if !user.Can(capability) {
+				http.Error(w, "forbidden", http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// validate reads the session cookie and, with SignOuts set, treats one issued
+// before the user was signed out as invalid.
+func (m Middleware) validate(r *http.Request) (adminsession.Claims, error) {
+	claims, err := m.Sessions.Validate(r)
+	if err != nil || m.SignOuts == nil {
+		return claims, err
+	}
+	if err := m.SignOuts.CheckIssued(r.Context(), claims.ID, time.Unix(claims.IssuedAt, 0)); err != nil {
+		var appErr *apperror.AppError
+		if errors.As(err, &appErr) {
+			return adminsession.Claims{}, adminsession.ErrInvalidSession
+		}
+		return adminsession.Claims{}, err
+	}
+	return claims, nil
+}
+
 func (m Middleware) loginPath() string {
 	if m.LoginPath != "" {
 		return m.LoginPath
@@ -1,25 +1,79 @@
 package auth
 
 import (
+	"context"
+	"errors"
 	"net/http"
 
 	"github.com/go-chi/jwtauth/v5"
 
 	"github.com/lyricapp/lyric/web/internal/apperror"
 	"github.com/lyricapp/lyric/web/internal/http/handler"
+	"github.com/lyricapp/lyric/web/internal/http/handler/api/util"
 )
 
+// Sessions confirms that the device session an access token was issued for
+// has not been signed out.
+type Sessions interface {
+	CheckSession(ctx context.Context, userID int, sessionID int64) error
+}
+
 // Authenticator returns middleware that enforces JWT presence and validity,
-// responding with a JSON error when authentication fails.
-func Authenticator(ja *jwtauth.JWTAuth) func(http.Handler) http.Handler {
+// responding with a JSON error when authentication fails. With sessions set,
+// tokens of revoked sessions or inactive accounts are rejected too.
+func Authenticator(ja *jwtauth.JWTAuth, sessions Sessions) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token, _, err := jwtauth.FromContext(r.Context())
+			if err != nil || token == nil {
+				handler.Error(w, apperror.Unauthorized("unauthorized"))
+				return
+			}
+
+			if sessions != nil {
+				userID, err := util.CurrentUserID(r)
+				if err != nil {
+					handler.Error(w, err)
+					return
+				}
+				if err := sessions.CheckSession(r.Context(), userID, util.CurrentSessionID(r)); err != nil {
+					handler.Error(w, err)
+					return
+				}
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Identify returns middleware for routes open to anonymous callers. Requests
+// without a token pass through; a token that fails verification, or belongs
+// to a revoked session or an inactive account, is rejected as Authenticator
+// would, so a signed-out device never reads as its user.
+func Identify(sessions Sessions) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			token, _, err := jwtauth.FromContext(r.Context())
+			if errors.Is(err, jwtauth.ErrNoTokenFound) {
+				next.ServeHTTP(w, r)
+				return
+			}
 			if err != nil || token == nil {
 				handler.Error(w, apperror.Unauthorized("unauthorized"))
 				return
 			}
 
+			userID, err := util.CurrentUserID(r)
+			if err != nil {
+				handler.Error(w, err)
+				return
+			}
+			if err := sessions.CheckSession(r.Context(), userID, util.CurrentSessionID(r)); err != nil {
+				handler.Error(w, err)
+				return
+			}
+
 			next.ServeHTTP(w, r)
 		})
 	}
@@ -0,0 +1,30 @@
+package auth
+
+import (
+	"net/http"
+
+	"github.com/lyricapp/lyric/web/internal/http/handler"
+	"github.com/lyricapp/lyric/web/internal/http/handler/api/util"
+	"github.com/lyricapp/lyric/web/internal/services/permissions"
+)
+
+// Require returns middleware that loads the authenticated user's current role
+// and responds with 403 unless it grants the capability. Handlers read the
+// principal with util.CurrentPrincipal. It must run after Authenticator.
+func Require(svc permissions.Service, capability permissions.Capability) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			userID, err := util.CurrentUserID(r)
+			if err != nil {
+				handler.Error(w, err)
+				return
+			}
+			principal, err := svc.Require(r.Context(), userID, capability)
+			if err != nil {
+				handler.Error(w, err)
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(permissions.WithPrincipal(r.Context(), principal)))
+		})
+	}
+}
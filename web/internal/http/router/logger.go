@@ -0,0 +1,45 @@
+package router
+
+import (
+	"log"
+	"net/http"
+	"os"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// queryTokenParam is the query parameter event streams read the access token
+// from, since EventSource cannot send headers.
+const queryTokenParam = "jwt"
+
+// requestLogger logs requests like middleware.Logger, with any access token in
+// the query string masked so it never reaches the logs.
+func requestLogger() func(http.Handler) http.Handler {
+	return middleware.RequestLogger(redactingFormatter{
+		next: &middleware.DefaultLogFormatter{Logger: log.New(os.Stdout, "", log.LstdFlags)},
+	})
+}
+
+type redactingFormatter struct {
+	next middleware.LogFormatter
+}
+
+func (f redactingFormatter) NewLogEntry(r *http.Request) middleware.LogEntry {
+	return f.next.NewLogEntry(withoutQueryToken(r))
+}
+
+// withoutQueryToken returns a copy of r to log, with the token replaced. The
+// request served keeps its token.
+func withoutQueryToken(r *http.Request) *http.Request {
+	query := r.URL.Query()
+	if !query.Has(queryTokenParam) {
+		return r
+	}
+	query.Set(queryTokenParam, "REDACTED")
+	redacted := r.WithContext(r.Context())
+	url := *r.URL
+	url.RawQuery = query.Encode()
+	redacted.URL = &url
+	redacted.RequestURI = url.RequestURI()
+	return redacted
+}
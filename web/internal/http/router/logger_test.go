@@ -0,0 +1,25 @@
+package router
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWithoutQueryToken(t *testing.T) {
+	// given
+	req := httptest.NewRequest("GET", "/api/playlists/1/session/events?jwt=secret-token&x=1", nil)
+
+	// when
+	redacted := withoutQueryToken(req)
+
+	// then
+	if redacted.RequestURI != "/api/playlists/1/session/events?jwt=REDACTED&x=1" {
+		t.Errorf("unexpected logged URI %q", redacted.RequestURI)
+	}
+	if got := req.URL.Query().Get("jwt"); got != "secret-token" {
+		t.Errorf("served request lost its token, got %q", got)
+	}
+	if plain := httptest.NewRequest("GET", "/api/songs?page=2", nil); withoutQueryToken(plain) != plain {
+		t.Errorf("expected requests without a token to be logged as is")
+	}
+}
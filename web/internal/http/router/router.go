@@ -10,19 +10,31 @@ import (
 	"github.com/go-chi/jwtauth/v5"
 
 	"github.com/lyricapp/lyric/web/internal/app"
+	"github.com/lyricapp/lyric/web/internal/http/handler"
+	adminaudithandler "github.com/lyricapp/lyric/web/internal/http/handler/admin/audit"
+	adminchordrequesthandler "github.com/lyricapp/lyric/web/internal/http/handler/admin/chordrequests"
 	adminloginhandler "github.com/lyricapp/lyric/web/internal/http/handler/admin/login"
 	adminsonghandler "github.com/lyricapp/lyric/web/internal/http/handler/admin/song"
 	adminuserhandler "github.com/lyricapp/lyric/web/internal/http/handler/admin/users"
+	accountapi "github.com/lyricapp/lyric/web/internal/http/handler/api/account"
 	albumsapi "github.com/lyricapp/lyric/web/internal/http/handler/api/albums"
 	artistsapi "github.com/lyricapp/lyric/web/internal/http/handler/api/artists"
+	chordrequestsapi "github.com/lyricapp/lyric/web/internal/http/handler/api/chordrequests"
 	chordsapi "github.com/lyricapp/lyric/web/internal/http/handler/api/chords"
+	deltasyncapi "github.com/lyricapp/lyric/web/internal/http/handler/api/deltasync"
 	feedbackapi "github.com/lyricapp/lyric/web/internal/http/handler/api/feedback"
 	languagesapi "github.com/lyricapp/lyric/web/internal/http/handler/api/languages"
 	levelsapi "github.com/lyricapp/lyric/web/internal/http/handler/api/levels"
+	libraryapi "github.com/lyricapp/lyric/web/internal/http/handler/api/library"
+	livesessionsapi "github.com/lyricapp/lyric/web/internal/http/handler/api/livesessions"
 	loginapi "github.com/lyricapp/lyric/web/internal/http/handler/api/login"
+	playlistinvitesapi "github.com/lyricapp/lyric/web/internal/http/handler/api/playlistinvites"
 	playlistsapi "github.com/lyricapp/lyric/web/internal/http/handler/api/playlists"
+	preferencesapi "github.com/lyricapp/lyric/web/internal/http/handler/api/preferences"
 	releaseyearapi "github.com/lyricapp/lyric/web/internal/http/handler/api/releaseyear"
 	songsapi "github.com/lyricapp/lyric/web/internal/http/handler/api/songs"
+	subscriptionsapi "github.com/lyricapp/lyric/web/internal/http/handler/api/subscriptions"
+	suggestionsapi "github.com/lyricapp/lyric/web/internal/http/handler/api/suggestions"
 	trendingapi "github.com/lyricapp/lyric/web/internal/http/handler/api/trending"
 	usersapi "github.com/lyricapp/lyric/web/internal/http/handler/api/users"
 	writersapi "github.com/lyricapp/lyric/web/internal/http/handler/api/writers"
@@ -30,10 +42,11 @@ import (
 	healthhandler "github.com/lyricapp/lyric/web/internal/http/handler/health"
 	homehandler "github.com/lyricapp/lyric/web/internal/http/handler/home"
 	libraryhandler "github.com/lyricapp/lyric/web/internal/http/handler/library"
-	searchhandler "github.com/lyricapp/lyric/web/internal/http/handler/songs/search"
 	songspagehandler "github.com/lyricapp/lyric/web/internal/http/handler/songs"
+	searchhandler "github.com/lyricapp/lyric/web/internal/http/handler/songs/search"
 	adminmw "github.com/lyricapp/lyric/web/internal/http/middleware/adminauth"
 	authmw "github.com/lyricapp/lyric/web/internal/http/middleware/auth"
+	"github.com/lyricapp/lyric/web/internal/services/permissions"
 )
 
 // New instantiates the HTTP router and wires up handlers and middleware.
@@ -56,7 +69,7 @@ func New(application *app.Application) chi.Router {
 
 	r.Use(middleware.RequestID)
 	r.Use(middleware.RealIP)
-	r.Use(middleware.Logger)
+	r.Use(requestLogger())
 	r.Use(middleware.Recoverer)
 
 	r.Handle("/static/*", http.StripPrefix("/static/", http.FileServer(http.Dir("static"))))
@@ -73,16 +86,19 @@ func New(application *app.Application) chi.Router {
 	songsSearchHandler := searchhandler.New()
 	r.Handle("/songs", songsSearchHandler)
 
-	library := libraryhandler.New()
-	r.Handle("/library", library)
+	adminMiddleware := adminmw.Middleware{Sessions: application.AdminSessions, Permissions: application.Services.Permissions, SignOuts: application.Services.Login, LoginPath: "/admin/login"}
 
-	songs := songspagehandler.New()
-	r.Handle("/songs/{id}", songs)
+	library := libraryhandler.New(application.Services.Library)
+	r.With(adminMiddleware.WithUser).Handle("/library", library)
+
+	songs := songspagehandler.New(application.Services.Preferences)
+	r.With(adminMiddleware.WithUser).Handle("/songs/{id}", songs)
 
 	adminLogin := adminloginhandler.New(application.Services.Login, application.AdminSessions)
-	adminSong := adminsonghandler.New(application.Services.Songs, application.Services.Albums, application.Services.Artists, application.Services.Writers, application.Services.Levels, application.Services.Languages)
-	adminUser := adminuserhandler.New(application.Services.Users)
-	adminMiddleware := adminmw.Middleware{Sessions: application.AdminSessions, LoginPath: "/admin/login"}
+	adminSong := adminsonghandler.New(application.Services.Songs, application.Services.Albums, application.Services.Artists, application.Services.Writers, application.Services.Levels, application.Services.Languages, application.Services.Audit)
+	adminUser := adminuserhandler.New(application.Services.Users, application.Services.Audit)
+	adminChordRequests := adminchordrequesthandler.New(application.Services.ChordRequests, application.Services.Chords, application.Services.Audit)
+	adminAudit := adminaudithandler.New(application.Services.Audit)
 
 	r.Route("/admin", func(admin chi.Router) {
 		admin.Use(adminMiddleware.WithUser)
@@ -94,65 +110,147 @@ func New(application *app.Application) chi.Router {
 		admin.Group(func(protected chi.Router) {
 			protected.Use(adminMiddleware.Require)
 			protected.Get("/songs", adminSong.Index)
-			protected.Get("/users", adminUser.Index)
 			protected.Get("/songs/create", adminSong.Show)
 			protected.Post("/songs/create", adminSong.Create)
 			protected.Get("/songs/{id}/edit", adminSong.Edit)
 			protected.Post("/songs/{id}/edit", adminSong.Update)
-			protected.Post("/songs/{id}/delete", adminSong.Delete)
+			protected.With(adminMiddleware.Can(permissions.DeleteAnySong)).Post("/songs/{id}/delete", adminSong.Delete)
+			protected.With(adminMiddleware.Can(permissions.ManageCatalogue)).Get("/chord-requests", adminChordRequests.Index)
+			protected.With(adminMiddleware.Can(permissions.ManageCatalogue)).Post("/chords", adminChordRequests.AddChord)
+			protected.Group(func(users chi.Router) {
+				users.Use(adminMiddleware.Can(permissions.ManageUsers))
+				users.Get("/users", adminUser.Index)
+				users.Get("/users/{id}", adminUser.Show)
+				users.Post("/users/{id}/role", adminUser.ChangeRole)
+				users.Post("/users/{id}/ban", adminUser.Ban)
+				users.Post("/users/{id}/unban", adminUser.Unban)
+				users.Post("/users/{id}/logout", adminUser.Logout)
+			})
+			protected.With(adminMiddleware.Can(permissions.ViewAuditLog)).Get("/audit", adminAudit.Index)
 			protected.Post("/logout", adminLogin.Logout)
 		})
 	})
 
-	apiSongs := songsapi.New(application.Services.Songs)
+	apiSongs := songsapi.New(application.Services.Songs, application.Services.Audit)
 	apiAlbums := albumsapi.New(application.Services.Albums)
 	apiArtists := artistsapi.New(application.Services.Artists)
 	apiWriters := writersapi.New(application.Services.Writers)
 	apiReleaseYear := releaseyearapi.New(application.Services.ReleaseYear)
 	apiPlaylists := playlistsapi.New(application.Services.Playlists)
+	apiInvites := playlistinvitesapi.New(application.Services.Invites)
+	apiLiveSessions := livesessionsapi.New(application.Services.LiveSessions)
+	apiSync := deltasyncapi.New(application.Services.Sync)
 	apiTrending := trendingapi.New(application.Services.Trendings)
 	apiLevels := levelsapi.New(application.Services.Levels)
 	apiLanguages := languagesapi.New(application.Services.Languages)
-	apiChords := chordsapi.New(application.Services.Chords)
+	apiChords := chordsapi.New(application.Services.Chords, application.Services.Songs)
+	apiChordRequests := chordrequestsapi.New(application.Services.ChordRequests)
 	apiFeedback := feedbackapi.New(application.Services.Feedback)
-	apiLogin := loginapi.New(application.Services.Login)
+	apiLogin := loginapi.New(application.Services.Login, application.Services.Plans)
+	apiSubscriptions := subscriptionsapi.New(application.Services.Subscriptions)
 	apiUsers := usersapi.New(application.Services.Users)
+	apiAccount := accountapi.New(application.Services.Account)
+	apiPreferences := preferencesapi.New(application.Services.Preferences)
+	apiLibrary := libraryapi.New(application.Services.Library)
+	apiSuggestions := suggestionsapi.New(application.Services.Suggestions, application.Services.Songs, application.Services.Audit)
 	tokenAuth := application.Services.Login.TokenAuth()
+
+	// Catalogue reads are shared by every caller and may be reused for a minute;
+	// anything that depends on the caller is revalidated on each use.
+	catalogueCache := handler.CachePolicy{CacheControl: "public, max-age=60"}
+	personalCache := handler.CachePolicy{CacheControl: "private, no-cache", Vary: []string{"Authorization"}}
 	r.Route("/api", func(api chi.Router) {
 		api.Use(jwtauth.Verifier(tokenAuth))
 		api.Post("/login", apiLogin.Request)
 		api.Post("/code", apiLogin.Verify)
+		api.Post("/login/oidc", apiLogin.SignIn)
+		api.Post("/token/refresh", apiLogin.Refresh)
 		api.Group(func(protected chi.Router) {
-			protected.Use(authmw.Authenticator(tokenAuth))
+			protected.Use(authmw.Authenticator(tokenAuth, application.Services.Login))
+			protected.Use(handler.Conditional(personalCache))
 			protected.Post("/me", apiLogin.Me)
+			protected.Get("/me/sessions", apiLogin.Sessions)
+			protected.Delete("/me/sessions", apiLogin.RevokeOtherSessions)
+			protected.Delete("/me/sessions/{id}", apiLogin.RevokeSession)
+			protected.Get("/me/entitlements", apiSubscriptions.Entitlements)
+			protected.Post("/me/purchases", apiSubscriptions.Purchase)
+			protected.Get("/me/export", apiAccount.Export)
+			protected.Get("/me/preferences", apiPreferences.Show)
+			protected.Patch("/me/preferences", apiPreferences.Update)
+			protected.Get("/me/favorites", apiLibrary.Favorites)
+			protected.Put("/me/favorites/{kind}/{id}", apiLibrary.AddFavorite)
+			protected.Delete("/me/favorites/{kind}/{id}", apiLibrary.RemoveFavorite)
+			protected.Get("/me/recent", apiLibrary.Recent)
 			protected.Delete("/user", apiLogin.Delete)
-			protected.Post("/songs", apiSongs.Create)
-			protected.Put("/songs/{id}", apiSongs.Update)
-			protected.Delete("/songs/{id}", apiSongs.Delete)
-			protected.Post("/songs/{id}/status/{status}", apiSongs.UpdateStatus)
+			protected.Group(func(submit chi.Router) {
+				submit.Use(authmw.Require(application.Services.Permissions, permissions.SubmitSong))
+				submit.Post("/songs", apiSongs.Create)
+				submit.Put("/songs/{id}", apiSongs.Update)
+				submit.Delete("/songs/{id}", apiSongs.Delete)
+				submit.Post("/songs/{id}/status/{status}", apiSongs.UpdateStatus)
+				submit.Get("/songs/{id}/suggestions", apiSuggestions.List)
+				submit.Get("/suggestions/{id}", apiSuggestions.Show)
+				submit.Post("/suggestions/{id}/accept", apiSuggestions.Accept)
+				submit.Post("/suggestions/{id}/reject", apiSuggestions.Reject)
+			})
 			protected.Get("/playlists", apiPlaylists.List)
 			protected.Post("/playlists/create", apiPlaylists.Create)
 			protected.Put("/playlists/{id}", apiPlaylists.Update)
 			protected.Delete("/playlists/{id}", apiPlaylists.Delete)
 			protected.Post("/playlists/{id}/share", apiPlaylists.Share)
+			protected.Put("/playlists/{id}/users/{user_id}/role", apiPlaylists.UpdateRole)
+			protected.Get("/playlists/{id}/invites", apiInvites.List)
+			protected.Post("/playlists/{id}/invites", apiInvites.Create)
+			protected.Delete("/playlists/{id}/invites/{invite_id}", apiInvites.Revoke)
+			protected.Post("/invites/{token}/accept", apiInvites.Accept)
+			protected.Post("/playlists/{id}/session", apiLiveSessions.Open)
+			protected.Get("/playlists/{id}/session", apiLiveSessions.Show)
+			protected.Put("/playlists/{id}/session", apiLiveSessions.Update)
+			protected.Delete("/playlists/{id}/session", apiLiveSessions.End)
 			protected.Post("/playlists/{id}/leave", apiPlaylists.Leave)
+			protected.Put("/playlists/{id}/order", apiPlaylists.Reorder)
+			protected.Put("/playlists/{id}/songs/{song_id}/arrangement", apiPlaylists.UpdateArrangement)
 			protected.Post("/playlists/{playlist_id}/songs", apiPlaylists.UpdateSongs)
+			protected.Get("/sync", apiSync.Changes)
 			protected.Post("/users", apiUsers.Search)
 			protected.Post("/feedback", apiFeedback.Create)
+			protected.Post("/chord-requests", apiChordRequests.Create)
 			protected.Post("/songs/{song_id}/playlists", apiSongs.SyncPlaylists)
 			protected.Post("/songs/{song_id}/levels/{level_id}", apiSongs.AssignLevel)
+			protected.Post("/songs/{id}/suggestions", apiSuggestions.Create)
+			protected.Post("/songs/{id}/plays", apiSongs.Play)
+		})
+		api.Group(func(stream chi.Router) {
+			// EventSource cannot send headers, so the stream also accepts ?jwt=;
+			// requestLogger masks it.
+			stream.Use(jwtauth.Verify(tokenAuth, jwtauth.TokenFromHeader, jwtauth.TokenFromQuery))
+			stream.Use(authmw.Authenticator(tokenAuth, application.Services.Login))
+			stream.Get("/playlists/{id}/session/events", apiLiveSessions.Events)
+		})
+		api.Group(func(personal chi.Router) {
+			// Song listings mark the caller's own playlists and levels, and
+			// playlist-scoped reads carry its private arrangement.
+			// Anonymous callers are welcome; tokens of revoked sessions are not.
+			personal.Use(authmw.Identify(application.Services.Login))
+			personal.Use(handler.Conditional(personalCache))
+			personal.Get("/songs", apiSongs.List)
+			personal.Get("/songs/{id}", apiSongs.Show)
+			personal.Get("/songs/{id}/levels", apiSongs.LevelVotes)
+			personal.Get("/songs/{id}/chords", apiChords.Song)
+		})
+		api.Group(func(catalogue chi.Router) {
+			catalogue.Use(handler.Conditional(catalogueCache))
+			catalogue.Get("/albums", apiAlbums.List)
+			catalogue.Get("/artists", apiArtists.List)
+			catalogue.Get("/writers", apiWriters.List)
+			catalogue.Get("/release-year", apiReleaseYear.List)
+			catalogue.Get("/trending-songs", apiTrending.List)
+			catalogue.Get("/trending-albums", apiTrending.Albums)
+			catalogue.Get("/trending-artists", apiTrending.Artists)
+			catalogue.Get("/levels", apiLevels.List)
+			catalogue.Get("/languages", apiLanguages.List)
+			catalogue.Get("/chords/{name}", apiChords.Show)
 		})
-		api.Get("/songs", apiSongs.List)
-		api.Get("/albums", apiAlbums.List)
-		api.Get("/artists", apiArtists.List)
-		api.Get("/writers", apiWriters.List)
-		api.Get("/release-year", apiReleaseYear.List)
-		api.Get("/trending-songs", apiTrending.List)
-		api.Get("/trending-albums", apiTrending.Albums)
-		api.Get("/trending-artists", apiTrending.Artists)
-		api.Get("/levels", apiLevels.List)
-		api.Get("/languages", apiLanguages.List)
-		api.Get("/chords/{name}", apiChords.Show)
 	})
 
 	return r
@@ -26,6 +26,12 @@ func New(cfg config.Config, handler http.Handler) *Server {
 	}
 }
 
+// OnShutdown registers fn to run when shutdown begins. Long-lived streams use it
+// to close their connections, which Shutdown would otherwise wait on.
+func (s *Server) OnShutdown(fn func()) {
+	s.httpServer.RegisterOnShutdown(fn)
+}
+
 // Start begins serving HTTP traffic and blocks until the context is cancelled.
 func (s *Server) Start(ctx context.Context) error {
 	errCh := make(chan error, 1)
@@ -0,0 +1,313 @@
+package account
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"time"
+
+	"github.com/lyricapp/lyric/web/internal/apperror"
+)
+
+// purgeBatch caps how many accounts one purge run loads at a time.
+const purgeBatch = 100
+
+// Service exports and purges user accounts.
+type Service interface {
+	// Export gathers everything stored about the user.
+	Export(ctx context.Context, userID int) (Export, error)
+	// Purge permanently removes the accounts whose deletion grace period has
+	// ended, reporting how many were removed.
+	Purge(ctx context.Context) (int, error)
+}
+
+// Repository abstracts persistence for account exports and purges.
+type Repository interface {
+	Export(ctx context.Context, userID int) (Export, bool, error)
+	// DuePurges lists deleted users whose purge time is at or before at.
+	DuePurges(ctx context.Context, at time.Time, limit int) ([]int, error)
+	// Purge removes a deleted user due by at along with their private data,
+	// reporting false when the user was restored or already purged.
+	Purge(ctx context.Context, userID int, at time.Time) (bool, error)
+}
+
+// Export is a user's data archive. Login codes are left out: they are
+// short-lived secrets.
+type Export struct {
+	ExportedAt        time.Time          `json:"exported_at"`
+	Profile           Profile            `json:"profile"`
+	Preferences       *Preferences       `json:"preferences"`
+	Songs             []Song             `json:"songs"`
+	Playlists         []Playlist         `json:"playlists"`
+	SharedPlaylists   []SharedPlaylist   `json:"shared_playlists"`
+	PlaylistInvites   []PlaylistInvite   `json:"playlist_invites"`
+	Feedback          []Feedback         `json:"feedback"`
+	Favorites         []Favorite         `json:"favorites"`
+	LevelVotes        []LevelVote        `json:"level_votes"`
+	ChordRequestVotes []ChordRequestVote `json:"chord_request_votes"`
+	Suggestions       []Suggestion       `json:"suggestions"`
+	Plays             []Play             `json:"plays"`
+	Subscriptions     []Subscription     `json:"subscriptions"`
+	Sessions          []Session          `json:"sessions"`
+	Identities        []Identity         `json:"identities"`
+}
+
+// Profile is the user's account row.
+type Profile struct {
+	ID         int        `json:"id"`
+	Email      string     `json:"email"`
+	Role       string     `json:"role"`
+	Plan       string     `json:"plan"`
+	Status     string     `json:"status"`
+	PurgeAfter *time.Time `json:"purge_after"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// Preferences are the user's saved display settings; nil when they never
+// changed the defaults.
+type Preferences struct {
+	Theme       string    `json:"theme"`
+	Language    string    `json:"language"`
+	FontSize    int       `json:"font_size"`
+	DisplayMode string    `json:"display_mode"`
+	Instrument  string    `json:"instrument"`
+	ScrollSpeed int       `json:"scroll_speed"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// Song is a song the user added.
+type Song struct {
+	ID          int       `json:"id"`
+	Title       string    `json:"title"`
+	Key         *string   `json:"key"`
+	Lyric       *string   `json:"lyric"`
+	Status      string    `json:"status"`
+	ReleaseYear *int      `json:"release_year"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// Playlist is a playlist the user owns, with its songs in order.
+type Playlist struct {
+	ID        int            `json:"id"`
+	Name      string         `json:"name"`
+	Songs     []PlaylistSong `json:"songs"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+}
+
+// PlaylistSong is a song's place and arrangement in a playlist.
+type PlaylistSong struct {
+	SongID      int     `json:"song_id"`
+	Title       string  `json:"title"`
+	Position    int     `json:"position"`
+	Transpose   int     `json:"transpose"`
+	Capo        int     `json:"capo"`
+	DisplayMode *string `json:"display_mode"`
+	Note        *string `json:"note"`
+}
+
+// SharedPlaylist is someone else's playlist the user collaborates on.
+type SharedPlaylist struct {
+	PlaylistID int       `json:"playlist_id"`
+	Name       string    `json:"name"`
+	Role       string    `json:"role"`
+	JoinedAt   time.Time `json:"joined_at"`
+}
+
+// PlaylistInvite is a share link the user created.
+type PlaylistInvite struct {
+	ID         int        `json:"id"`
+	PlaylistID int        `json:"playlist_id"`
+	Role       string     `json:"role"`
+	MaxUses    *int       `json:"max_uses"`
+	Uses       int        `json:"uses"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// Feedback is a message the user sent.
+type Feedback struct {
+	ID        int       `json:"id"`
+	Message   string    `json:"message"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Favorite is a song, album or artist the user favourited.
+type Favorite struct {
+	Kind      string    `json:"kind"`
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// LevelVote is the difficulty the user voted for a song.
+type LevelVote struct {
+	SongID    int       `json:"song_id"`
+	LevelID   int       `json:"level_id"`
+	Level     string    `json:"level"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ChordRequestVote is a missing chord the user asked for.
+type ChordRequestVote struct {
+	ChordRequestID int        `json:"chord_request_id"`
+	Name           string     `json:"name"`
+	SongID         *int       `json:"song_id"`
+	NotifiedAt     *time.Time `json:"notified_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// Suggestion is a correction the user suggested for a song's lyric.
+type Suggestion struct {
+	ID         int        `json:"id"`
+	SongID     int        `json:"song_id"`
+	Lyric      string     `json:"lyric"`
+	Comment    string     `json:"comment"`
+	Status     string     `json:"status"`
+	ReviewNote *string    `json:"review_note"`
+	ReviewedAt *time.Time `json:"reviewed_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// Play is a recorded play of a song.
+type Play struct {
+	SongID    int       `json:"song_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Subscription is a purchase the user made.
+type Subscription struct {
+	ID        int       `json:"id"`
+	Provider  string    `json:"provider"`
+	ProductID string    `json:"product_id"`
+	Kind      string    `json:"kind"`
+	StartsAt  time.Time `json:"starts_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Session is a device the user signed in on.
+type Session struct {
+	ID         int64      `json:"id"`
+	DeviceName *string    `json:"device_name"`
+	UserAgent  *string    `json:"user_agent"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt time.Time  `json:"last_used_at"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+}
+
+// Identity is a linked Google or Apple account.
+type Identity struct {
+	Provider    string    `json:"provider"`
+	Email       *string   `json:"email"`
+	LastLoginAt time.Time `json:"last_login_at"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type service struct {
+	repo Repository
+	now  func() time.Time
+}
+
+// NewService builds the default account service.
+func NewService(repo Repository) Service {
+	return &service{repo: repo, now: time.Now}
+}
+
+// Export gathers the user's profile and everything they created.
+func (s *service) Export(ctx context.Context, userID int) (Export, error) {
+	if userID <= 0 {
+		return Export{}, apperror.Unauthorized("Unauthorized")
+	}
+	export, ok, err := s.repo.Export(ctx, userID)
+	if err != nil {
+		return Export{}, err
+	}
+	if !ok {
+		return Export{}, apperror.NotFound("user not found")
+	}
+	export.ExportedAt = s.now().UTC()
+	return export, nil
+}
+
+// Purge removes every account due for purging. A failure on one account is
+// logged and the rest are still purged; it is retried on the next run.
+func (s *service) Purge(ctx context.Context) (int, error) {
+	now := s.now()
+	purged := 0
+	failed := map[int]bool{}
+	for {
+		ids, err := s.repo.DuePurges(ctx, now, purgeBatch+len(failed))
+		if err != nil {
+			return purged, fmt.Errorf("list due purges: %w", err)
+		}
+		progressed := false
+		for _, id := range ids {
+			if failed[id] {
+				continue
+			}
+			ok, err := s.repo.Purge(ctx, id, now)
+			if err != nil {
+				log.Printf("account: purge user %d: %v", id, err)
+				failed[id] = true
+				continue
+			}
+			progressed = true
+			if ok {
+				purged++
+			}
+		}
+		if !progressed {
+			return purged, nil
+		}
+	}
+}
+
+// WriteZip writes the export as a ZIP archive with one JSON file per section.
+func (e Export) WriteZip(w io.Writer) error {
+	archive := zip.NewWriter(w)
+	files := []struct {
+		name string
+		data any
+	}{
+		{"profile.json", map[string]any{"exported_at": e.ExportedAt, "profile": e.Profile, "preferences": e.Preferences}},
+		{"songs.json", e.Songs},
+		{"playlists.json", e.Playlists},
+		{"shared_playlists.json", e.SharedPlaylists},
+		{"playlist_invites.json", e.PlaylistInvites},
+		{"feedback.json", e.Feedback},
+		{"favorites.json", e.Favorites},
+		{"level_votes.json", e.LevelVotes},
+		{"chord_request_votes.json", e.ChordRequestVotes},
+		{"suggestions.json", e.Suggestions},
+		{"plays.json", e.Plays},
+		{"subscriptions.json", e.Subscriptions},
+		{"sessions.json", e.Sessions},
+		{"identities.json", e.Identities},
+	}
+	for _, file := range files {
+		body, err := json.MarshalIndent(file.data, "", "  ")
+		if err != nil {
+			return fmt.Errorf("encode %s: %w", file.name, err)
+		}
+		entry, err := archive.CreateHeader(&zip.FileHeader{
+			Name:     file.name,
+			Method:   zip.Deflate,
+			Modified: e.ExportedAt,
+		})
+		if err != nil {
+			return fmt.Errorf("add %s: %w", file.name, err)
+		}
+		if _, err := entry.Write(body); err != nil {
+			return fmt.Errorf("write %s: %w", file.name, err)
+		}
+	}
+	return archive.Close()
+}
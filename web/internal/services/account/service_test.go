@@ -0,0 +1,87 @@
+package account_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/lyricapp/lyric/web/internal/services/account"
+	accountrepo "github.com/lyricapp/lyric/web/internal/storage/postgres/account"
+	"github.com/lyricapp/lyric/web/internal/testutil"
+)
+
+func TestService_Purge(t *testing.T) {
+	conn := testutil.SetupDB(t)
+	defer conn.Close()
+
+	ctx := context.Background()
+	tx, _ := conn.Begin(ctx)
+	defer tx.Rollback(ctx)
+
+	// given
+	var dueID, pendingID, langID int
+	if err := tx.QueryRow(ctx, `insert into users (email, status, purge_after) values ('due@lyric.test', 'deleted', now() - interval '1 day') returning id`).Scan(&dueID); err != nil {
+		t.Fatalf("insert due user: %v", err)
+	}
+	if err := tx.QueryRow(ctx, `insert into users (email, status, purge_after) values ('pending@lyric.test', 'deleted', now() + interval '1 day') returning id`).Scan(&pendingID); err != nil {
+		t.Fatalf("insert pending user: %v", err)
+	}
+	if err := tx.QueryRow(ctx, `insert into languages (name) values ('english') returning id`).Scan(&langID); err != nil {
+		t.Fatalf("insert language: %v", err)
+	}
+	var approvedID, draftID int
+	if err := tx.QueryRow(ctx, `insert into songs (title, created_by, language_id, status) values ('published', $1, $2, 'approved') returning id`, dueID, langID).Scan(&approvedID); err != nil {
+		t.Fatalf("insert approved song: %v", err)
+	}
+	if err := tx.QueryRow(ctx, `insert into songs (title, created_by, language_id, status) values ('draft', $1, $2, 'created') returning id`, dueID, langID).Scan(&draftID); err != nil {
+		t.Fatalf("insert draft song: %v", err)
+	}
+	if _, err := tx.Exec(ctx, `insert into plays (song_id, user_id) values ($1, $2)`, approvedID, dueID); err != nil {
+		t.Fatalf("insert play: %v", err)
+	}
+	if _, err := tx.Exec(ctx, `insert into playlists (name, user_id) values ('setlist', $1)`, dueID); err != nil {
+		t.Fatalf("insert playlist: %v", err)
+	}
+	if _, err := tx.Exec(ctx, `insert into feedbacks (user_id, message) values ($1, 'hello')`, dueID); err != nil {
+		t.Fatalf("insert feedback: %v", err)
+	}
+
+	svc := account.NewService(accountrepo.NewRepository(tx))
+
+	// when
+	purged, err := svc.Purge(ctx)
+
+	// then
+	if err != nil {
+		t.Fatalf("purge: %v", err)
+	}
+	if purged < 1 {
+		t.Fatalf("expected the due account to be purged, got %d", purged)
+	}
+
+	count := func(query string, args ...any) int {
+		t.Helper()
+		var n int
+		if err := tx.QueryRow(ctx, query, args...).Scan(&n); err != nil {
+			t.Fatalf("count %q: %v", query, err)
+		}
+		return n
+	}
+	if n := count(`select count(*) from users where id = $1`, dueID); n != 0 {
+		t.Errorf("expected the due user to be removed")
+	}
+	if n := count(`select count(*) from users where id = $1`, pendingID); n != 1 {
+		t.Errorf("expected the user still in their grace period to be kept")
+	}
+	if n := count(`select count(*) from songs where id = $1 and created_by is null`, approvedID); n != 1 {
+		t.Errorf("expected the approved song to be kept without an author")
+	}
+	if n := count(`select count(*) from songs where id = $1`, draftID); n != 0 {
+		t.Errorf("expected the draft song to be removed")
+	}
+	if n := count(`select count(*) from plays where song_id = $1 and user_id is null`, approvedID); n != 1 {
+		t.Errorf("expected the play to be kept without the user")
+	}
+	if n := count(`select count(*) from playlists where user_id = $1`, dueID) + count(`select count(*) from feedbacks where user_id = $1`, dueID); n != 0 {
+		t.Errorf("expected playlists and feedback to be removed")
+	}
+}
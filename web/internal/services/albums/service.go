@@ -2,6 +2,7 @@ package albums
 
 import (
 	"context"
+	"time"
 
 	"github.com/lyricapp/lyric/web/pkg/pagination"
 )
@@ -9,6 +10,9 @@ import (
 // Service exposes album collection behaviours to HTTP handlers.
 type Service interface {
 	List(ctx context.Context, params ListParams) (ListResult, error)
+	// LastModified returns when an album, or a song, artist or writer albums
+	// list, last changed or was deleted.
+	LastModified(ctx context.Context) (time.Time, error)
 }
 
 // ListParams defines the supported filters for listing albums.
@@ -51,6 +55,7 @@ type Album struct {
 // Repository abstracts data access for albums.
 type Repository interface {
 	List(ctx context.Context, params ListParams) (ListResult, error)
+	LastModified(ctx context.Context) (time.Time, error)
 }
 
 type service struct {
@@ -68,3 +73,7 @@ func (s *service) List(ctx context.Context, params ListParams) (ListResult, erro
 
 	return s.repo.List(ctx, params)
 }
+
+func (s *service) LastModified(ctx context.Context) (time.Time, error) {
+	return s.repo.LastModified(ctx)
+}
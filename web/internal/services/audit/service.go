@@ -0,0 +1,207 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lyricapp/lyric/web/internal/apperror"
+	"github.com/lyricapp/lyric/web/pkg/pagination"
+)
+
+// Entity types.
+const (
+	EntitySong  = "song"
+	EntityUser  = "user"
+	EntityChord = "chord"
+)
+
+// Actions, named after the entity they change.
+const (
+	ActionSongCreate  = "song.create"
+	ActionSongUpdate  = "song.update"
+	ActionSongDelete  = "song.delete"
+	ActionSongStatus  = "song.status"
+	ActionUserRole    = "user.role"
+	ActionUserBan     = "user.ban"
+	ActionUserUnban   = "user.unban"
+	ActionUserLogout  = "user.logout"
+	ActionChordCreate = "chord.create"
+)
+
+// Actions lists every action, grouped by entity.
+var Actions = []string{
+	ActionSongCreate, ActionSongUpdate, ActionSongDelete, ActionSongStatus,
+	ActionUserRole, ActionUserBan, ActionUserUnban, ActionUserLogout,
+	ActionChordCreate,
+}
+
+// Service records privileged changes and lets admins browse them. Entries are
+// never changed or removed once written, so they hold no email addresses:
+// actors are kept by id and emails are dropped from snapshots.
+type Service interface {
+	Record(ctx context.Context, params RecordParams) error
+	List(ctx context.Context, params ListParams) (ListResult, error)
+}
+
+// RecordParams describes one change. Before and After are snapshots of the
+// entity, marshalled to JSON; Before is nil for creations and After for
+// deletions.
+type RecordParams struct {
+	ActorID    int
+	Action     string
+	EntityType string
+	EntityID   int
+	Before     any
+	After      any
+	RequestID  string
+}
+
+// Entry is a recorded change. ActorEmail is the actor's current address, read
+// when listing; it is nil once their account is purged.
+type Entry struct {
+	ID         int64           `json:"id"`
+	ActorID    *int            `json:"actor_id"`
+	ActorEmail *string         `json:"actor_email"`
+	Action     string          `json:"action"`
+	EntityType string          `json:"entity_type"`
+	EntityID   string          `json:"entity_id"`
+	Before     json.RawMessage `json:"before"`
+	After      json.RawMessage `json:"after"`
+	RequestID  *string         `json:"request_id"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+// ListParams filters the log. Actor matches part of the actor's email; the
+// other filters match exactly when set.
+type ListParams struct {
+	Actor      string
+	Action     string
+	EntityType string
+	EntityID   string
+	Page       int
+	PerPage    int
+}
+
+// ListResult wraps a page of entries, newest first.
+type ListResult struct {
+	Data    []Entry `json:"data"`
+	Page    int     `json:"page"`
+	PerPage int     `json:"per_page"`
+	Total   int     `json:"total"`
+}
+
+// Repository appends to and reads the audit log.
+type Repository interface {
+	Insert(ctx context.Context, entry Entry) error
+	List(ctx context.Context, params ListParams) (ListResult, error)
+}
+
+type service struct {
+	repo Repository
+}
+
+// NewService constructs an audit service.
+func NewService(repo Repository) Service {
+	return &service{repo: repo}
+}
+
+// Record appends a change to the log.
+func (s *service) Record(ctx context.Context, params RecordParams) error {
+	if params.Action == "" || params.EntityType == "" || params.EntityID <= 0 {
+		return fmt.Errorf("audit: action, entity type and entity id are required")
+	}
+
+	entry := Entry{
+		Action:     params.Action,
+		EntityType: params.EntityType,
+		EntityID:   fmt.Sprintf("%d", params.EntityID),
+	}
+	if params.ActorID > 0 {
+		actorID := params.ActorID
+		entry.ActorID = &actorID
+	}
+	if requestID := strings.TrimSpace(params.RequestID); requestID != "" {
+		entry.RequestID = &requestID
+	}
+
+	var err error
+	if entry.Before, err = snapshot(params.Before); err != nil {
+		return err
+	}
+	if entry.After, err = snapshot(params.After); err != nil {
+		return err
+	}
+
+	return s.repo.Insert(ctx, entry)
+}
+
+// List returns a page of entries matching the filters.
+func (s *service) List(ctx context.Context, params ListParams) (ListResult, error) {
+	params.Actor = strings.TrimSpace(params.Actor)
+	params.EntityID = strings.TrimSpace(params.EntityID)
+	if params.Action != "" && !knownAction(params.Action) {
+		return ListResult{}, apperror.Validation("msg", map[string]string{"action": "action is not recognised"})
+	}
+	switch params.EntityType {
+	case "", EntitySong, EntityUser, EntityChord:
+	default:
+		return ListResult{}, apperror.Validation("msg", map[string]string{"entity_type": "entity type must be song, user or chord"})
+	}
+	params.Page = pagination.NormalisePage(params.Page)
+	params.PerPage = pagination.NormalisePerPage(params.PerPage)
+
+	return s.repo.List(ctx, params)
+}
+
+// snapshot marshals an entity for the log without any "email" field, since
+// entries outlive the accounts they mention.
+func snapshot(value any) (json.RawMessage, error) {
+	if value == nil {
+		return nil, nil
+	}
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("audit: marshal snapshot: %w", err)
+	}
+	var decoded any
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	if err := decoder.Decode(&decoded); err != nil {
+		return nil, fmt.Errorf("audit: decode snapshot: %w", err)
+	}
+	if decoded == nil {
+		return nil, nil
+	}
+	if raw, err = json.Marshal(withoutEmails(decoded)); err != nil {
+		return nil, fmt.Errorf("audit: marshal snapshot: %w", err)
+	}
+	return raw, nil
+}
+
+func withoutEmails(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		delete(v, "email")
+		for key, item := range v {
+			v[key] = withoutEmails(item)
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = withoutEmails(item)
+		}
+	}
+	return value
+}
+
+func knownAction(action string) bool {
+	for _, known := range Actions {
+		if action == known {
+			return true
+		}
+	}
+	return false
+}
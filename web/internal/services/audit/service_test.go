@@ -0,0 +1,65 @@
+package audit_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/lyricapp/lyric/web/internal/services/audit"
+)
+
+type recordingRepo struct {
+	entries []audit.Entry
+}
+
+func (r *recordingRepo) Insert(_ context.Context, entry audit.Entry) error {
+	r.entries = append(r.entries, entry)
+	return nil
+}
+
+func (r *recordingRepo) List(context.Context, audit.ListParams) (audit.ListResult, error) {
+	return audit.ListResult{}, nil
+}
+
+func TestService_Record_DropsEmails(t *testing.T) {
+	// given
+	repo := &recordingRepo{}
+	svc := audit.NewService(repo)
+	type creator struct {
+		ID    int    `json:"id"`
+		Email string `json:"email"`
+	}
+	before := map[string]any{
+		"id":           9007199254740993,
+		"email":        "owner@example.com",
+		"created":      creator{ID: 3, Email: "author@example.com"},
+		"contributors": []creator{{ID: 4, Email: "fixer@example.com"}},
+	}
+
+	// when
+	err := svc.Record(context.Background(), audit.RecordParams{
+		ActorID:    1,
+		Action:     audit.ActionUserRole,
+		EntityType: audit.EntityUser,
+		EntityID:   2,
+		Before:     before,
+	})
+
+	// then
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(repo.entries) != 1 {
+		t.Fatalf("expected one entry, got %d", len(repo.entries))
+	}
+	got := string(repo.entries[0].Before)
+	if strings.Contains(got, "email") || strings.Contains(got, "@example.com") {
+		t.Errorf("snapshot kept an email: %s", got)
+	}
+	if !strings.Contains(got, `"id":9007199254740993`) || !strings.Contains(got, `"created":{"id":3}`) {
+		t.Errorf("snapshot lost other fields: %s", got)
+	}
+	if repo.entries[0].After != nil {
+		t.Errorf("expected no after snapshot, got %s", repo.entries[0].After)
+	}
+}
@@ -0,0 +1,49 @@
+package chordrequests
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	loginsvc "github.com/lyricapp/lyric/web/internal/services/login"
+)
+
+type consoleNotifier struct {
+	from string
+}
+
+// NewConsoleNotifier logs chord availability notices instead of sending emails.
+func NewConsoleNotifier(from string) Notifier {
+	return &consoleNotifier{from: from}
+}
+
+func (n *consoleNotifier) ChordAvailable(_ context.Context, email, chordName string) error {
+	from := n.from
+	if strings.TrimSpace(from) == "" {
+		from = "no-reply@localhost"
+	}
+	log.Printf("[mailer] chord available chord=%s to=%s from=%s", chordName, email, from)
+	return nil
+}
+
+type smtpNotifier struct {
+	settings loginsvc.SMTPSettings
+}
+
+// NewSMTPNotifier emails requesters through the same relay used for login codes.
+func NewSMTPNotifier(settings loginsvc.SMTPSettings) Notifier {
+	return &smtpNotifier{settings: settings}
+}
+
+func (n *smtpNotifier) ChordAvailable(_ context.Context, email, chordName string) error {
+	if strings.TrimSpace(email) == "" {
+		return nil
+	}
+
+	body := fmt.Sprintf("Good news: the %s chord you asked for has been added. Open the app to see its diagrams.", chordName)
+	if err := n.settings.Send(email, fmt.Sprintf("%s is now in the chord library", chordName), body); err != nil {
+		return fmt.Errorf("smtp notifier: %w", err)
+	}
+	return nil
+}
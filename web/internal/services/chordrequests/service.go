@@ -0,0 +1,182 @@
+package chordrequests
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lyricapp/lyric/web/internal/apperror"
+	chordsvc "github.com/lyricapp/lyric/web/internal/services/chords"
+	"github.com/lyricapp/lyric/web/pkg/chordtheory"
+	"github.com/lyricapp/lyric/web/pkg/pagination"
+)
+
+// Request statuses.
+const (
+	StatusOpen      = "open"
+	StatusFulfilled = "fulfilled"
+)
+
+// Service manages requests for chords missing from the library. It also listens
+// for chords being added so matching requests close on their own.
+type Service interface {
+	Create(ctx context.Context, params CreateParams) (Request, error)
+	Queue(ctx context.Context, params ListParams) (ListResult, error)
+	ChordAdded(ctx context.Context, chord chordsvc.Chord) error
+	Wait()
+}
+
+// CreateParams captures a user's vote for a chord.
+type CreateParams struct {
+	UserID int
+	Name   string
+	SongID *int
+}
+
+// ListParams pages through the open request queue.
+type ListParams struct {
+	Page    int
+	PerPage int
+}
+
+// ListResult wraps a page of requests.
+type ListResult struct {
+	Data    []Request `json:"data"`
+	Page    int       `json:"page"`
+	PerPage int       `json:"per_page"`
+	Total   int       `json:"total"`
+}
+
+// Request aggregates every vote for one canonical chord name.
+type Request struct {
+	ID          int        `json:"id"`
+	Name        string     `json:"name"`
+	Status      string     `json:"status"`
+	Votes       int        `json:"votes"`
+	Songs       []Song     `json:"songs"`
+	CreatedAt   time.Time  `json:"created_at"`
+	FulfilledAt *time.Time `json:"fulfilled_at"`
+}
+
+// Song references a song the chord was requested for.
+type Song struct {
+	ID    int    `json:"id"`
+	Title string `json:"title"`
+}
+
+// Requester is a user waiting to hear that a chord was added.
+type Requester struct {
+	UserID int
+	Email  string
+}
+
+// Repository abstracts chord request persistence.
+type Repository interface {
+	InLibrary(ctx context.Context, canonical string) (bool, error)
+	Vote(ctx context.Context, canonical string, userID int, songID *int) (Request, error)
+	Queue(ctx context.Context, params ListParams) (ListResult, error)
+	Fulfil(ctx context.Context, canonical string, chordID int) (Request, []Requester, error)
+	MarkNotified(ctx context.Context, requestID int, userIDs []int) error
+}
+
+// Notifier tells requesters that their chord is now available.
+type Notifier interface {
+	ChordAvailable(ctx context.Context, email, chordName string) error
+}
+
+type service struct {
+	repo     Repository
+	notifier Notifier
+	sending  sync.WaitGroup
+}
+
+// NewService constructs a chord request service.
+func NewService(repo Repository, notifier Notifier) Service {
+	return &service{repo: repo, notifier: notifier}
+}
+
+// Create records the user's vote on the open request for the chord, opening
+// one if needed. Voting twice for the same chord counts once.
+func (s *service) Create(ctx context.Context, params CreateParams) (Request, error) {
+	if params.UserID <= 0 {
+		return Request{}, apperror.Unauthorized("Unauthorized user")
+	}
+
+	name := strings.TrimSpace(params.Name)
+	if name == "" {
+		return Request{}, apperror.Validation("msg", map[string]string{"name": "name is required"})
+	}
+	canonical, err := chordtheory.Normalize(name)
+	if err != nil {
+		return Request{}, apperror.Validation("msg", map[string]string{"name": "name is not a recognised chord symbol"})
+	}
+	if params.SongID != nil && *params.SongID <= 0 {
+		return Request{}, apperror.Validation("msg", map[string]string{"song_id": "song_id must be positive"})
+	}
+
+	inLibrary, err := s.repo.InLibrary(ctx, canonical)
+	if err != nil {
+		return Request{}, err
+	}
+	if inLibrary {
+		return Request{}, apperror.Validation("msg", map[string]string{"name": fmt.Sprintf("%s is already in the library", canonical)})
+	}
+
+	return s.repo.Vote(ctx, canonical, params.UserID, params.SongID)
+}
+
+// Queue lists open requests, most requested first.
+func (s *service) Queue(ctx context.Context, params ListParams) (ListResult, error) {
+	params.Page = pagination.NormalisePage(params.Page)
+	params.PerPage = pagination.NormalisePerPage(params.PerPage)
+
+	return s.repo.Queue(ctx, params)
+}
+
+// ChordAdded closes the open request for the chord and emails its requesters
+// in the background, so the admin adding the chord never waits on SMTP.
+func (s *service) ChordAdded(ctx context.Context, chord chordsvc.Chord) error {
+	if chord.CanonicalName == "" {
+		return nil
+	}
+
+	request, requesters, err := s.repo.Fulfil(ctx, chord.CanonicalName, chord.ID)
+	if err != nil {
+		return err
+	}
+	if len(requesters) == 0 {
+		return nil
+	}
+
+	s.sending.Add(1)
+	go func() {
+		defer s.sending.Done()
+		s.notify(context.WithoutCancel(ctx), request, requesters)
+	}()
+	return nil
+}
+
+// Wait blocks until every notification already started has been sent.
+func (s *service) Wait() {
+	s.sending.Wait()
+}
+
+// notify emails each requester and records who was reached. Failed sends are
+// logged and left unmarked.
+func (s *service) notify(ctx context.Context, request Request, requesters []Requester) {
+	notified := make([]int, 0, len(requesters))
+	for _, requester := range requesters {
+		if err := s.notifier.ChordAvailable(ctx, requester.Email, request.Name); err != nil {
+			log.Printf("chordrequests: notify user %d of request %d: %v", requester.UserID, request.ID, err)
+			continue
+		}
+		notified = append(notified, requester.UserID)
+	}
+
+	if err := s.repo.MarkNotified(ctx, request.ID, notified); err != nil {
+		log.Printf("chordrequests: mark request %d notified: %v", request.ID, err)
+	}
+}
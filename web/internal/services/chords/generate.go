@@ -0,0 +1,53 @@
+package chords
+
+import (
+	"github.com/lyricapp/lyric/web/pkg/chordtheory"
+)
+
+// frettedInstruments maps string instruments to their tuning and search bounds.
+var frettedInstruments = map[Instrument]struct {
+	tuning chordtheory.Tuning
+	opts   chordtheory.VoicingOptions
+}{
+	InstrumentGuitar:   {tuning: chordtheory.StandardGuitar, opts: chordtheory.DefaultVoicingOptions()},
+	InstrumentUkulele:  {tuning: chordtheory.StandardUkulele, opts: chordtheory.VoicingOptions{MaxFret: 12, MaxSpan: 4, MinStrings: 4, Limit: 5}},
+	InstrumentMandolin: {tuning: chordtheory.StandardMandolin, opts: chordtheory.VoicingOptions{MaxFret: 12, MaxSpan: 5, MinStrings: 4, Limit: 5}},
+	InstrumentBass:     {tuning: chordtheory.StandardBass, opts: chordtheory.VoicingOptions{MaxFret: 12, MaxSpan: 4, MinStrings: 1, Limit: 3}},
+}
+
+// generate computes positions for chord symbols missing from the library.
+func generate(name string, instrument Instrument) ([]Position, bool) {
+	parsed, err := chordtheory.Parse(name)
+	if err != nil {
+		return nil, false
+	}
+
+	if instrument == InstrumentPiano {
+		return []Position{{Keys: chordtheory.PianoKeys(parsed)}}, true
+	}
+
+	fretted, ok := frettedInstruments[instrument]
+	if !ok {
+		return nil, false
+	}
+
+	if instrument == InstrumentBass {
+		parsed = parsed.BassDyad()
+		fretted.opts.MinStrings = len(parsed.Intervals)
+	}
+
+	voicings := chordtheory.Voicings(parsed, fretted.tuning, fretted.opts)
+	if len(voicings) == 0 {
+		return nil, false
+	}
+
+	positions := make([]Position, 0, len(voicings))
+	for _, voicing := range voicings {
+		positions = append(positions, Position{
+			BaseFret: voicing.BaseFret,
+			Frets:    voicing.Frets,
+			Fingers:  voicing.Fingers,
+		})
+	}
+	return positions, true
+}
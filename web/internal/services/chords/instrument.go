@@ -0,0 +1,50 @@
+package chords
+
+import (
+	"strings"
+
+	"github.com/lyricapp/lyric/web/internal/apperror"
+)
+
+// Instrument identifies which diagram family a chord position belongs to.
+type Instrument string
+
+const (
+	InstrumentGuitar   Instrument = "guitar"
+	InstrumentUkulele  Instrument = "ukulele"
+	InstrumentMandolin Instrument = "mandolin"
+	InstrumentBass     Instrument = "bass"
+	InstrumentPiano    Instrument = "piano"
+)
+
+// DefaultInstrument is used when a request does not name one.
+const DefaultInstrument = InstrumentGuitar
+
+var instruments = []Instrument{
+	InstrumentGuitar,
+	InstrumentUkulele,
+	InstrumentMandolin,
+	InstrumentBass,
+	InstrumentPiano,
+}
+
+// ParseInstrument validates a user supplied instrument, defaulting to guitar when blank.
+func ParseInstrument(raw string) (Instrument, error) {
+	value := Instrument(strings.ToLower(strings.TrimSpace(raw)))
+	if value == "" {
+		return DefaultInstrument, nil
+	}
+	for _, instrument := range instruments {
+		if value == instrument {
+			return value, nil
+		}
+	}
+
+	names := make([]string, 0, len(instruments))
+	for _, instrument := range instruments {
+		names = append(names, string(instrument))
+	}
+	return "", apperror.Validation("msg", map[string]string{
+		"instrument": "instrument must be one of " + strings.Join(names, ", "),
+	})
+}
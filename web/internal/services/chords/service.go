@@ -1,41 +1,209 @@
 package chords
 
-import "context"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/lyricapp/lyric/web/internal/apperror"
+	"github.com/lyricapp/lyric/web/pkg/chordtheory"
+)
 
 // Service retrieves chord definitions.
 type Service interface {
-	Find(ctx context.Context, name string) (Chord, error)
+	Find(ctx context.Context, name string, instrument Instrument) (Chord, error)
+	FindAll(ctx context.Context, lyric string, instrument Instrument) ([]Chord, error)
+	Create(ctx context.Context, params CreateParams) (Chord, error)
+}
+
+// CreateParams describes diagrams being added to the library. When Positions is
+// empty the generated voicings for the instrument are stored instead.
+type CreateParams struct {
+	Name       string
+	Instrument Instrument
+	Positions  []Position
+}
+
+// Listener is told about chords once they have been added to the library.
+type Listener interface {
+	ChordAdded(ctx context.Context, chord Chord) error
 }
 
-// Chord describes a chord with its playable positions. 
+// Chord describes a chord with its playable positions for one instrument.
+// Generated is true when the positions were computed rather than read from the library.
+// Requested echoes the symbol as asked for; CanonicalName is its normalised spelling.
 type Chord struct {
-	ID        int        `json:"id"`
-	Name      string     `json:"name"`
-	Positions []Position `json:"positions"`
+	ID            int        `json:"id"`
+	Name          string     `json:"name"`
+	CanonicalName string     `json:"canonical_name"`
+	Requested     string     `json:"requested"`
+	Instrument    Instrument `json:"instrument"`
+	Generated     bool       `json:"generated"`
+	Positions     []Position `json:"positions"`
 }
 
-// Position captures a chord fingering.
+// Position captures a chord fingering. Fretted instruments fill BaseFret, Frets
+// and Fingers; piano positions list MIDI note numbers in Keys instead.
 type Position struct {
 	ID       int    `json:"id"`
-	BaseFret int    `json:"base_fret"`
-	Frets    []int  `json:"frets"`
-	Fingers  []*int `json:"fingers"`
+	BaseFret int    `json:"base_fret,omitempty"`
+	Frets    []int  `json:"frets,omitempty"`
+	Fingers  []*int `json:"fingers,omitempty"`
+	Keys     []int  `json:"keys,omitempty"`
 }
 
 // Repository isolates chord persistence.
 type Repository interface {
-	Find(ctx context.Context, name string) (Chord, error)
+	Find(ctx context.Context, name, canonical string, instrument Instrument) (Chord, error)
+	Create(ctx context.Context, canonical string, instrument Instrument, positions []Position) (Chord, error)
 }
 
 type service struct {
-	repo Repository
+	repo      Repository
+	listeners []Listener
 }
 
-// NewService creates a chord service backed by a repository.
-func NewService(repo Repository) Service {
-	return &service{repo: repo}
+// NewService creates a chord service backed by a repository. Listeners are
+// notified, in order, whenever a chord is added to the library.
+func NewService(repo Repository, listeners ...Listener) Service {
+	return &service{repo: repo, listeners: listeners}
+}
+
+// Find returns the library chord for the instrument, falling back to generated
+// positions when the chord, or its diagrams for that instrument, have not been
+// entered by hand. Names are matched by canonical spelling, so "A#M7" finds "Bbmaj7".
+func (s *service) Find(ctx context.Context, name string, instrument Instrument) (Chord, error) {
+	if instrument == "" {
+		instrument = DefaultInstrument
+	}
+
+	requested := strings.TrimSpace(name)
+	// Symbols the parser does not understand are still looked up verbatim.
+	canonical, _ := chordtheory.Normalize(requested)
+
+	chord, err := s.repo.Find(ctx, requested, canonical, instrument)
+	if err != nil {
+		var appErr *apperror.AppError
+		if !errors.As(err, &appErr) || appErr.Status != http.StatusNotFound {
+			return Chord{}, err
+		}
+		chord = Chord{Name: canonical, Instrument: instrument}
+	}
+	chord.Requested = requested
+	if chord.CanonicalName == "" {
+		chord.CanonicalName = canonical
+	}
+	if len(chord.Positions) > 0 {
+		return chord, nil
+	}
+
+	positions, ok := generate(name, instrument)
+	if !ok {
+		return Chord{}, apperror.NotFound("chord not found")
+	}
+	chord.Generated = true
+	chord.Positions = positions
+	return chord, nil
 }
 
-func (s *service) Find(ctx context.Context, name string) (Chord, error) {
-	return s.repo.Find(ctx, name)
+// FindAll resolves every distinct chord used in a ChordPro lyric, skipping
+// symbols that can neither be found nor generated.
+func (s *service) FindAll(ctx context.Context, lyric string, instrument Instrument) ([]Chord, error) {
+	symbols := chordtheory.ExtractSymbols(lyric)
+	chords := make([]Chord, 0, len(symbols))
+	for _, symbol := range symbols {
+		chord, err := s.Find(ctx, symbol, instrument)
+		if err != nil {
+			var appErr *apperror.AppError
+			if errors.As(err, &appErr) && appErr.Status == http.StatusNotFound {
+				continue
+			}
+			return nil, err
+		}
+		chords = append(chords, chord)
+	}
+	return chords, nil
+}
+
+// Create stores positions for a chord under its canonical name, adding the chord
+// to the library when it is new.
+func (s *service) Create(ctx context.Context, params CreateParams) (Chord, error) {
+	if params.Instrument == "" {
+		params.Instrument = DefaultInstrument
+	}
+
+	requested := strings.TrimSpace(params.Name)
+	canonical, err := chordtheory.Normalize(requested)
+	if err != nil {
+		return Chord{}, apperror.Validation("msg", map[string]string{"name": "name is not a recognised chord symbol"})
+	}
+
+	positions := params.Positions
+	if len(positions) == 0 {
+		generated, ok := generate(canonical, params.Instrument)
+		if !ok {
+			return Chord{}, apperror.Validation("msg", map[string]string{"positions": "positions are required for this chord"})
+		}
+		positions = generated
+	}
+	if details := validatePositions(params.Instrument, positions); len(details) > 0 {
+		return Chord{}, apperror.Validation("msg", details)
+	}
+
+	chord, err := s.repo.Create(ctx, canonical, params.Instrument, positions)
+	if err != nil {
+		return Chord{}, err
+	}
+	chord.Requested = requested
+
+	for _, listener := range s.listeners {
+		// The chord is already stored, so follow-up failures are logged rather than returned.
+		if err := listener.ChordAdded(ctx, chord); err != nil {
+			log.Printf("chords: listener failed for %q: %v", chord.CanonicalName, err)
+		}
+	}
+	return chord, nil
+}
+
+func validatePositions(instrument Instrument, positions []Position) map[string]string {
+	if instrument == InstrumentPiano {
+		for _, pos := range positions {
+			if len(pos.Keys) == 0 {
+				return map[string]string{"keys": "keys are required"}
+			}
+			for _, key := range pos.Keys {
+				if key < 0 || key > 127 {
+					return map[string]string{"keys": "keys must be midi notes between 0 and 127"}
+				}
+			}
+		}
+		return nil
+	}
+
+	stringCount := len(frettedInstruments[instrument].tuning)
+	for _, pos := range positions {
+		if pos.BaseFret < 1 || pos.BaseFret > 24 {
+			return map[string]string{"base_fret": "base_fret must be between 1 and 24"}
+		}
+		if len(pos.Frets) != stringCount {
+			return map[string]string{"frets": fmt.Sprintf("frets must list %d strings", stringCount)}
+		}
+		for _, fret := range pos.Frets {
+			if fret < -1 || fret > 24 {
+				return map[string]string{"frets": "frets must be between -1 and 24"}
+			}
+		}
+		if len(pos.Fingers) > 0 && len(pos.Fingers) != stringCount {
+			return map[string]string{"fingers": fmt.Sprintf("fingers must list %d strings", stringCount)}
+		}
+		for _, finger := range pos.Fingers {
+			if finger != nil && (*finger < 1 || *finger > 4) {
+				return map[string]string{"fingers": "fingers must be between 1 and 4"}
+			}
+		}
+	}
+	return nil
 }
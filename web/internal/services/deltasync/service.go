@@ -0,0 +1,319 @@
+package deltasync
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/lyricapp/lyric/web/internal/apperror"
+)
+
+// Page size bounds for a single sync request. Limits count every change and
+// deletion in the response together.
+const (
+	DefaultLimit = 200
+	MaxLimit     = 1000
+)
+
+// Entity names a synced collection.
+type Entity string
+
+const (
+	EntityLanguages Entity = "languages"
+	EntityLevels    Entity = "levels"
+	EntityArtists   Entity = "artists"
+	EntityWriters   Entity = "writers"
+	EntityAlbums    Entity = "albums"
+	EntitySongs     Entity = "songs"
+	EntityPlaylists Entity = "playlists"
+)
+
+// Entities lists the synced collections in the order they are sent, lookups
+// first so a device can apply each page as it arrives. Deletions follow them.
+var Entities = []Entity{
+	EntityLanguages,
+	EntityLevels,
+	EntityArtists,
+	EntityWriters,
+	EntityAlbums,
+	EntitySongs,
+	EntityPlaylists,
+}
+
+// Named is a language, level, artist or writer.
+type Named struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Album is a synced album.
+type Album struct {
+	ID          int       `json:"id"`
+	Name        string    `json:"name"`
+	ReleaseYear *int      `json:"release_year"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// Song is a synced song. Related records are referenced by id and synced in
+// their own collections.
+type Song struct {
+	ID               int       `json:"id"`
+	Title            string    `json:"title"`
+	Key              *string   `json:"key"`
+	Lyric            *string   `json:"lyric"`
+	LevelID          *int      `json:"level_id"`
+	ConsensusLevelID *int      `json:"consensus_level_id"`
+	LanguageID       *int      `json:"language_id"`
+	ReleaseYear      *int      `json:"release_year"`
+	BPM              *int      `json:"bpm"`
+	TimeSignature    *string   `json:"time_signature"`
+	DurationSeconds  *int      `json:"duration_seconds"`
+	Capo             *int      `json:"capo"`
+	Status           *string   `json:"status"`
+	ArtistIDs        []int     `json:"artist_ids"`
+	WriterIDs        []int     `json:"writer_ids"`
+	AlbumIDs         []int     `json:"album_ids"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+// Playlist is one of the user's own or shared playlists with its songs in order.
+type Playlist struct {
+	ID        int             `json:"id"`
+	Name      string          `json:"name"`
+	Role      string          `json:"role"`
+	Songs     []PlaylistEntry `json:"songs"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+// PlaylistEntry is a song's place and arrangement in a playlist.
+type PlaylistEntry struct {
+	SongID      int     `json:"song_id"`
+	Position    int     `json:"position"`
+	Transpose   int     `json:"transpose"`
+	Capo        int     `json:"capo"`
+	DisplayMode *string `json:"display_mode"`
+	Note        *string `json:"note"`
+}
+
+// Changes holds the upserts of each collection; collections without changes
+// are empty.
+type Changes struct {
+	Languages []Named    `json:"languages"`
+	Levels    []Named    `json:"levels"`
+	Artists   []Named    `json:"artists"`
+	Writers   []Named    `json:"writers"`
+	Albums    []Album    `json:"albums"`
+	Songs     []Song     `json:"songs"`
+	Playlists []Playlist `json:"playlists"`
+}
+
+// Deleted holds the ids removed from each collection.
+type Deleted map[Entity][]int
+
+// Delta is one page of changes. Cursor is passed back as ?since= for the next
+// page; once HasMore is false the device is current and keeps the cursor for
+// its next sync.
+type Delta struct {
+	Changes Changes `json:"changes"`
+	Deleted Deleted `json:"deleted"`
+	Cursor  string  `json:"cursor"`
+	HasMore bool    `json:"has_more"`
+}
+
+// Position orders changes within a collection.
+type Position struct {
+	At time.Time
+	ID int
+}
+
+// Window bounds the changes read in one pass: after From and no later than Until.
+type Window struct {
+	From  Position
+	Until time.Time
+}
+
+// Tombstone records a deletion.
+type Tombstone struct {
+	Position
+	Entity   Entity
+	EntityID int
+}
+
+// Service builds delta sync pages.
+type Service interface {
+	Changes(ctx context.Context, userID int, since string, limit int) (Delta, error)
+}
+
+// Repository reads changed rows using their updated_at columns.
+type Repository interface {
+	// Clock returns the database time, lagged by settle so rows written by
+	// transactions still in flight are picked up by the next pass.
+	Clock(ctx context.Context, settle time.Duration) (time.Time, error)
+	// Changes appends up to limit changed rows of the collection to into, returning
+	// their positions in order.
+	Changes(ctx context.Context, entity Entity, userID int, window Window, limit int, into *Changes) ([]Position, error)
+	Tombstones(ctx context.Context, userID int, window Window, limit int) ([]Tombstone, error)
+}
+
+// Config tunes the sync window.
+type Config struct {
+	// Settle is how far behind the database clock a pass stops. It should exceed
+	// the longest write transaction.
+	Settle time.Duration
+}
+
+type service struct {
+	repo Repository
+	cfg  Config
+}
+
+// NewService constructs a delta sync service.
+func NewService(repo Repository, cfg Config) Service {
+	return &service{repo: repo, cfg: cfg}
+}
+
+// cursor is the decoded form of the opaque sync cursor. A pass reads every
+// collection for changes in (Since, Until]; Step is the collection being read,
+// with len(Entities) meaning deletions, and At/ID the last position sent. A
+// finished pass has no Until and starts the next one from Since.
+type cursor struct {
+	Since int64 `json:"s"`
+	Until int64 `json:"u,omitempty"`
+	Step  int   `json:"e,omitempty"`
+	At    int64 `json:"a,omitempty"`
+	ID    int   `json:"i,omitempty"`
+}
+
+func decodeCursor(raw string) (cursor, error) {
+	var c cursor
+	if raw == "" {
+		return c, nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return c, err
+	}
+	if err := json.Unmarshal(data, &c); err != nil {
+		return c, err
+	}
+	if c.Since < 0 || c.Step < 0 || c.Step > len(Entities) || (c.Until != 0 && c.Until < c.Since) {
+		return c, fmt.Errorf("cursor out of range")
+	}
+	return c, nil
+}
+
+func (c cursor) encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func fromMicros(micros int64) time.Time {
+	if micros == 0 {
+		return time.Time{}
+	}
+	return time.UnixMicro(micros).UTC()
+}
+
+func toMicros(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixMicro()
+}
+
+// Changes returns up to limit changes after the cursor. An empty cursor starts a
+// full download, which carries no deletions.
+func (s *service) Changes(ctx context.Context, userID int, since string, limit int) (Delta, error) {
+	if userID <= 0 {
+		return Delta{}, apperror.Unauthorized("Unauthorized user")
+	}
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+	if limit > MaxLimit {
+		limit = MaxLimit
+	}
+
+	c, err := decodeCursor(since)
+	if err != nil {
+		return Delta{}, apperror.Validation("msg", map[string]string{"since": "since is not a valid sync cursor"})
+	}
+	if c.Until == 0 {
+		until, err := s.repo.Clock(ctx, s.cfg.Settle)
+		if err != nil {
+			return Delta{}, err
+		}
+		c = cursor{Since: c.Since, Until: toMicros(until)}
+		if c.Until < c.Since {
+			c.Until = c.Since
+		}
+	}
+
+	delta := Delta{
+		Changes: Changes{
+			Languages: []Named{},
+			Levels:    []Named{},
+			Artists:   []Named{},
+			Writers:   []Named{},
+			Albums:    []Album{},
+			Songs:     []Song{},
+			Playlists: []Playlist{},
+		},
+		Deleted: Deleted{},
+	}
+	for _, entity := range Entities {
+		delta.Deleted[entity] = []int{}
+	}
+
+	remaining := limit
+	for remaining > 0 && c.Step <= len(Entities) {
+		window := Window{
+			From:  Position{At: fromMicros(c.At), ID: c.ID},
+			Until: fromMicros(c.Until),
+		}
+		if c.At == 0 {
+			window.From.At = fromMicros(c.Since)
+		}
+
+		var positions []Position
+		if c.Step == len(Entities) {
+			// A full download has nothing to delete.
+			if c.Since != 0 {
+				tombstones, err := s.repo.Tombstones(ctx, userID, window, remaining)
+				if err != nil {
+					return Delta{}, err
+				}
+				for _, tombstone := range tombstones {
+					delta.Deleted[tombstone.Entity] = append(delta.Deleted[tombstone.Entity], tombstone.EntityID)
+					positions = append(positions, tombstone.Position)
+				}
+			}
+		} else {
+			positions, err = s.repo.Changes(ctx, Entities[c.Step], userID, window, remaining, &delta.Changes)
+			if err != nil {
+				return Delta{}, err
+			}
+		}
+
+		remaining -= len(positions)
+		if remaining == 0 && len(positions) > 0 {
+			// The page is full; resume after the last position sent.
+			last := positions[len(positions)-1]
+			c.At, c.ID = toMicros(last.At), last.ID
+			break
+		}
+		c.Step++
+		c.At, c.ID = 0, 0
+	}
+
+	if c.Step > len(Entities) {
+		delta.Cursor = cursor{Since: c.Until}.encode()
+		return delta, nil
+	}
+	delta.Cursor = c.encode()
+	delta.HasMore = true
+	return delta, nil
+}
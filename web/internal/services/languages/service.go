@@ -2,11 +2,14 @@ package languages
 
 import (
 	"context"
+	"time"
 )
 
 // Service exposes language catalogue functionality.
 type Service interface {
 	List(ctx context.Context) ([]Language, error)
+	// LastModified returns when a language last changed or was deleted.
+	LastModified(ctx context.Context) (time.Time, error)
 }
 
 // Language represents a language entry.
@@ -18,6 +21,7 @@ type Language  struct {
 // Repository abstracts persistence for languages.
 type Repository interface {
 	List(ctx context.Context) ([]Language, error)
+	LastModified(ctx context.Context) (time.Time, error)
 }
 
 type service struct {
@@ -33,3 +37,7 @@ func NewService(repo Repository) Service {
 func (s *service) List(ctx context.Context) ([]Language, error) {
 	return s.repo.List(ctx)
 }
+
+func (s *service) LastModified(ctx context.Context) (time.Time, error) {
+	return s.repo.LastModified(ctx)
+}
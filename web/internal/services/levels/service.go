@@ -1,10 +1,15 @@
 package levels
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 // Service exposes level catalogue functionality.
 type Service interface {
 	List(ctx context.Context) ([]Level, error)
+	// LastModified returns when a level last changed.
+	LastModified(ctx context.Context) (time.Time, error)
 }
 
 // Level represents a difficulty level entry.
@@ -16,6 +21,7 @@ type Level struct {
 // Repository abstracts persistence for levels.
 type Repository interface {
 	List(ctx context.Context) ([]Level, error)
+	LastModified(ctx context.Context) (time.Time, error)
 }
 
 type service struct {
@@ -31,3 +37,7 @@ func NewService(repo Repository) Service {
 func (s *service) List(ctx context.Context) ([]Level, error) {
 	return s.repo.List(ctx)
 }
+
+func (s *service) LastModified(ctx context.Context) (time.Time, error) {
+	return s.repo.LastModified(ctx)
+}
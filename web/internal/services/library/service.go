@@ -0,0 +1,163 @@
+package library
+
+import (
+	"context"
+	"time"
+
+	"github.com/lyricapp/lyric/web/internal/apperror"
+)
+
+// Recently viewed listings return DefaultRecentLimit songs unless asked for
+// fewer, and never more than MaxRecentLimit.
+const (
+	DefaultRecentLimit = 20
+	MaxRecentLimit     = 50
+)
+
+// Kind is the type of catalogue entry a user can favourite, as it appears in
+// the URL.
+type Kind string
+
+// Favourite kinds.
+const (
+	KindSong   Kind = "songs"
+	KindAlbum  Kind = "albums"
+	KindArtist Kind = "artists"
+)
+
+// ParseKind validates a favourite kind from the URL.
+func ParseKind(raw string) (Kind, error) {
+	switch kind := Kind(raw); kind {
+	case KindSong, KindAlbum, KindArtist:
+		return kind, nil
+	}
+	return "", apperror.NotFound("favourite type not found")
+}
+
+// Service manages each user's favourites and recently viewed songs.
+type Service interface {
+	// Favorites lists the user's favourite songs, albums and artists, newest
+	// first.
+	Favorites(ctx context.Context, userID int) (Favorites, error)
+	// AddFavorite favourites the entry; adding it twice is not an error.
+	AddFavorite(ctx context.Context, userID int, kind Kind, id int) error
+	// RemoveFavorite removes the entry from the user's favourites.
+	RemoveFavorite(ctx context.Context, userID int, kind Kind, id int) error
+	// Recent lists the songs the user opened most recently, once each.
+	Recent(ctx context.Context, userID, limit int) ([]RecentSong, error)
+}
+
+// Repository abstracts persistence for favourites and the play history.
+type Repository interface {
+	Favorites(ctx context.Context, userID int) (Favorites, error)
+	// AddFavorite reports false when the entry does not exist.
+	AddFavorite(ctx context.Context, userID int, kind Kind, id int) (bool, error)
+	RemoveFavorite(ctx context.Context, userID int, kind Kind, id int) error
+	Recent(ctx context.Context, userID, limit int) ([]RecentSong, error)
+}
+
+// Favorites are a user's favourite catalogue entries.
+type Favorites struct {
+	Songs   []FavoriteSong `json:"songs"`
+	Albums  []Favorite     `json:"albums"`
+	Artists []Favorite     `json:"artists"`
+}
+
+// FavoriteSong is a favourite song with the names of its artists.
+type FavoriteSong struct {
+	ID          int       `json:"id"`
+	Title       string    `json:"title"`
+	Key         *string   `json:"key"`
+	Artists     []string  `json:"artists"`
+	FavoritedAt time.Time `json:"favorited_at"`
+}
+
+// Favorite is a favourite album or artist.
+type Favorite struct {
+	ID          int       `json:"id"`
+	Name        string    `json:"name"`
+	FavoritedAt time.Time `json:"favorited_at"`
+}
+
+// RecentSong is a song the user opened, with when they last opened it and how
+// many times they have.
+type RecentSong struct {
+	ID       int       `json:"id"`
+	Title    string    `json:"title"`
+	Key      *string   `json:"key"`
+	Artists  []string  `json:"artists"`
+	ViewedAt time.Time `json:"viewed_at"`
+	Views    int       `json:"views"`
+}
+
+type service struct {
+	repo Repository
+}
+
+// NewService builds the default library service.
+func NewService(repo Repository) Service {
+	return &service{repo: repo}
+}
+
+func (s *service) Favorites(ctx context.Context, userID int) (Favorites, error) {
+	if userID <= 0 {
+		return Favorites{}, apperror.Unauthorized("Unauthorized")
+	}
+	return s.repo.Favorites(ctx, userID)
+}
+
+func (s *service) AddFavorite(ctx context.Context, userID int, kind Kind, id int) error {
+	if userID <= 0 {
+		return apperror.Unauthorized("Unauthorized")
+	}
+	if _, err := ParseKind(string(kind)); err != nil {
+		return err
+	}
+	if id <= 0 {
+		return notFound(kind)
+	}
+	ok, err := s.repo.AddFavorite(ctx, userID, kind, id)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return notFound(kind)
+	}
+	return nil
+}
+
+func (s *service) RemoveFavorite(ctx context.Context, userID int, kind Kind, id int) error {
+	if userID <= 0 {
+		return apperror.Unauthorized("Unauthorized")
+	}
+	if _, err := ParseKind(string(kind)); err != nil {
+		return err
+	}
+	if id <= 0 {
+		return notFound(kind)
+	}
+	return s.repo.RemoveFavorite(ctx, userID, kind, id)
+}
+
+func (s *service) Recent(ctx context.Context, userID, limit int) ([]RecentSong, error) {
+	if userID <= 0 {
+		return nil, apperror.Unauthorized("Unauthorized")
+	}
+	if limit <= 0 {
+		limit = DefaultRecentLimit
+	}
+	if limit > MaxRecentLimit {
+		limit = MaxRecentLimit
+	}
+	return s.repo.Recent(ctx, userID, limit)
+}
+
+func notFound(kind Kind) error {
+	switch kind {
+	case KindAlbum:
+		return apperror.NotFound("album not found")
+	case KindArtist:
+		return apperror.NotFound("artist not found")
+	}
+	return apperror.NotFound("song not found")
+}
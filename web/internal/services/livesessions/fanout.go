@@ -0,0 +1,103 @@
+package livesessions
+
+import "sync"
+
+// FanOut delivers session events to every subscriber of a playlist. The local
+// implementation serves a single instance; a shared broker can implement it so
+// leaders and members connected to different instances see each other.
+type FanOut interface {
+	Publish(playlistID int, event Event)
+	// Subscribe returns a channel of events and a func that releases it. The
+	// channel is closed on release or when the fan-out is closed.
+	Subscribe(playlistID int) (<-chan Event, func())
+	// Subscribers counts the playlist's unreleased subscriptions.
+	Subscribers(playlistID int) int
+	Close()
+}
+
+// subscriberBuffer bounds how far a slow subscriber may fall behind. Events carry
+// the full state, so dropping stale ones is safe.
+const subscriberBuffer = 16
+
+type localFanOut struct {
+	mu     sync.Mutex
+	subs   map[int]map[chan Event]struct{}
+	closed bool
+}
+
+// NewLocalFanOut constructs an in-process fan-out.
+func NewLocalFanOut() FanOut {
+	return &localFanOut{subs: make(map[int]map[chan Event]struct{})}
+}
+
+func (f *localFanOut) Publish(playlistID int, event Event) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for ch := range f.subs[playlistID] {
+		select {
+		case ch <- event:
+		default:
+			// Drop the oldest pending event so the latest state always gets through.
+			select {
+			case <-ch:
+			default:
+			}
+			ch <- event
+		}
+	}
+}
+
+func (f *localFanOut) Subscribe(playlistID int) (<-chan Event, func()) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	ch := make(chan Event, subscriberBuffer)
+	if f.closed {
+		close(ch)
+		return ch, func() {}
+	}
+	if f.subs[playlistID] == nil {
+		f.subs[playlistID] = make(map[chan Event]struct{})
+	}
+	f.subs[playlistID][ch] = struct{}{}
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			f.mu.Lock()
+			defer f.mu.Unlock()
+			if _, ok := f.subs[playlistID][ch]; !ok {
+				return
+			}
+			delete(f.subs[playlistID], ch)
+			if len(f.subs[playlistID]) == 0 {
+				delete(f.subs, playlistID)
+			}
+			close(ch)
+		})
+	}
+}
+
+func (f *localFanOut) Subscribers(playlistID int) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return len(f.subs[playlistID])
+}
+
+func (f *localFanOut) Close() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.closed {
+		return
+	}
+	f.closed = true
+	for playlistID, subs := range f.subs {
+		for ch := range subs {
+			close(ch)
+		}
+		delete(f.subs, playlistID)
+	}
+}
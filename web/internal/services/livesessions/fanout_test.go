@@ -0,0 +1,50 @@
+package livesessions_test
+
+import (
+	"testing"
+
+	"github.com/lyricapp/lyric/web/internal/services/livesessions"
+)
+
+func TestLocalFanOut(t *testing.T) {
+	// given
+	fanOut := livesessions.NewLocalFanOut()
+	events, release := fanOut.Subscribe(1)
+	other, releaseOther := fanOut.Subscribe(2)
+	defer releaseOther()
+
+	// when
+	for version := int64(1); version <= 20; version++ {
+		fanOut.Publish(1, livesessions.Event{
+			Type:    livesessions.EventState,
+			Session: livesessions.Session{PlaylistID: 1, State: livesessions.State{Version: version}},
+		})
+	}
+
+	// then
+	var last int64
+	for len(events) > 0 {
+		last = (<-events).Session.State.Version
+	}
+	if last != 20 {
+		t.Errorf("expected a slow subscriber to keep the latest event, got version %d", last)
+	}
+	if len(other) != 0 {
+		t.Errorf("expected no events for another playlist, got %d", len(other))
+	}
+
+	release()
+	release()
+	if _, open := <-events; open {
+		t.Error("expected released channel to be closed")
+	}
+
+	fanOut.Close()
+	if _, open := <-other; open {
+		t.Error("expected close to end every subscription")
+	}
+	late, _ := fanOut.Subscribe(1)
+	if _, open := <-late; open {
+		t.Error("expected subscriptions after close to be closed")
+	}
+}
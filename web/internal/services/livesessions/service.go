@@ -0,0 +1,334 @@
+package livesessions
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/lyricapp/lyric/web/internal/apperror"
+	playlistsvc "github.com/lyricapp/lyric/web/internal/services/playlists"
+)
+
+// Live session limits.
+const (
+	// IdleTimeout is how long a session may go without an update before another
+	// member can take over as leader.
+	IdleTimeout      = 6 * time.Hour
+	MaxSectionLength = 100
+)
+
+// sweepInterval is how often sessions left without End are looked for.
+const sweepInterval = 15 * time.Minute
+
+// State is what the leader is currently showing. Scroll is the fraction of the
+// song scrolled, from 0 at the top to 1 at the end, so it holds across screen sizes.
+type State struct {
+	SongID    *int      `json:"song_id"`
+	Section   string    `json:"section"`
+	Transpose int       `json:"transpose"`
+	Scroll    float64   `json:"scroll"`
+	Version   int64     `json:"version"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Session is a live performance of a playlist led by one member.
+type Session struct {
+	PlaylistID int       `json:"playlist_id"`
+	LeaderID   int       `json:"leader_id"`
+	StartedAt  time.Time `json:"started_at"`
+	State      State     `json:"state"`
+}
+
+// EventType names the events streamed to session members.
+type EventType string
+
+const (
+	EventState EventType = "state"
+	EventEnded EventType = "ended"
+)
+
+// Event is a change to a live session.
+type Event struct {
+	Type    EventType `json:"type"`
+	Session Session   `json:"session"`
+}
+
+// UpdateParams changes part of the session state; nil fields keep their value.
+type UpdateParams struct {
+	SongID    *int
+	Section   *string
+	Transpose *int
+	Scroll    *float64
+}
+
+// Service runs live sessions on shared playlists.
+type Service interface {
+	Open(ctx context.Context, playlistID, leaderID int) (Session, error)
+	Get(ctx context.Context, playlistID, userID int) (Session, error)
+	Update(ctx context.Context, playlistID, leaderID int, params UpdateParams) (Session, error)
+	End(ctx context.Context, playlistID, userID int) error
+	// Join returns the current session and a stream of later events. Release must
+	// be called once the member disconnects.
+	Join(ctx context.Context, playlistID, userID int) (session Session, events <-chan Event, release func(), err error)
+	// Shutdown stops the idle sweep and ends every stream so open connections
+	// can drain.
+	Shutdown()
+}
+
+// Repository answers playlist access questions for live sessions.
+type Repository interface {
+	// Role returns the user's role on the playlist, or nil without access.
+	Role(ctx context.Context, playlistID, userID int) (*playlistsvc.Role, error)
+	HasSong(ctx context.Context, playlistID, songID int) (bool, error)
+}
+
+type service struct {
+	repo   Repository
+	fanOut FanOut
+	now    func() time.Time
+
+	mu       sync.Mutex
+	sessions map[int]*Session
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewService constructs the live session hub. Session state lives in memory;
+// events are delivered through fanOut. Sessions nobody follows are dropped
+// once idle past IdleTimeout, until Shutdown.
+func NewService(repo Repository, fanOut FanOut) Service {
+	s := &service{
+		repo:     repo,
+		fanOut:   fanOut,
+		now:      time.Now,
+		sessions: make(map[int]*Session),
+		stop:     make(chan struct{}),
+	}
+	go s.sweepEvery(sweepInterval)
+	return s
+}
+
+func (s *service) role(ctx context.Context, playlistID, userID int) (playlistsvc.Role, error) {
+	if userID <= 0 {
+		return "", apperror.Unauthorized("Unauthorized user")
+	}
+	if playlistID <= 0 {
+		return "", apperror.NotFound("playlist not found")
+	}
+	role, err := s.repo.Role(ctx, playlistID, userID)
+	if err != nil {
+		return "", err
+	}
+	if role == nil {
+		return "", apperror.NotFound("playlist not found")
+	}
+	return *role, nil
+}
+
+// Open starts a session led by the caller, who must own or edit the playlist.
+// Opening again as the leader returns the running session.
+func (s *service) Open(ctx context.Context, playlistID, leaderID int) (Session, error) {
+	role, err := s.role(ctx, playlistID, leaderID)
+	if err != nil {
+		return Session{}, err
+	}
+	if role == playlistsvc.RoleViewer {
+		return Session{}, apperror.Forbidden("only the owner or an editor can lead a session")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if current, ok := s.sessions[playlistID]; ok {
+		if current.LeaderID == leaderID {
+			return *current, nil
+		}
+		if now.Sub(current.State.UpdatedAt) < IdleTimeout {
+			return Session{}, apperror.New(http.StatusConflict, "a session is already live on this playlist", nil)
+		}
+		s.fanOut.Publish(playlistID, Event{Type: EventEnded, Session: *current})
+	}
+
+	session := &Session{
+		PlaylistID: playlistID,
+		LeaderID:   leaderID,
+		StartedAt:  now,
+		State:      State{Version: 1, UpdatedAt: now},
+	}
+	s.sessions[playlistID] = session
+	s.fanOut.Publish(playlistID, Event{Type: EventState, Session: *session})
+	return *session, nil
+}
+
+// Get returns the running session for a playlist member.
+func (s *service) Get(ctx context.Context, playlistID, userID int) (Session, error) {
+	if _, err := s.role(ctx, playlistID, userID); err != nil {
+		return Session{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, ok := s.sessions[playlistID]
+	if !ok {
+		return Session{}, apperror.NotFound("no live session on this playlist")
+	}
+	return *session, nil
+}
+
+// Update applies the leader's changes and broadcasts the new state.
+func (s *service) Update(ctx context.Context, playlistID, leaderID int, params UpdateParams) (Session, error) {
+	errorsMap := map[string]string{}
+	if params.SongID != nil && *params.SongID <= 0 {
+		errorsMap["song_id"] = "song_id must be a positive integer"
+	}
+	if params.Section != nil {
+		section := strings.TrimSpace(*params.Section)
+		if utf8.RuneCountInString(section) > MaxSectionLength {
+			errorsMap["section"] = fmt.Sprintf("section must be at most %d characters", MaxSectionLength)
+		}
+		params.Section = &section
+	}
+	if params.Transpose != nil && (*params.Transpose < -playlistsvc.MaxTranspose || *params.Transpose > playlistsvc.MaxTranspose) {
+		errorsMap["transpose"] = fmt.Sprintf("transpose must be between -%d and %d", playlistsvc.MaxTranspose, playlistsvc.MaxTranspose)
+	}
+	if params.Scroll != nil && (math.IsNaN(*params.Scroll) || *params.Scroll < 0 || *params.Scroll > 1) {
+		errorsMap["scroll"] = "scroll must be between 0 and 1"
+	}
+	if len(errorsMap) > 0 {
+		return Session{}, apperror.Validation("msg", errorsMap)
+	}
+
+	// The leader's role is checked on every change, so a leader demoted to
+	// viewer loses control of the session.
+	role, err := s.role(ctx, playlistID, leaderID)
+	if err != nil {
+		return Session{}, err
+	}
+	if role == playlistsvc.RoleViewer {
+		return Session{}, apperror.Forbidden("only the owner or an editor can lead a session")
+	}
+	if params.SongID != nil {
+		found, err := s.repo.HasSong(ctx, playlistID, *params.SongID)
+		if err != nil {
+			return Session{}, err
+		}
+		if !found {
+			return Session{}, apperror.Validation("msg", map[string]string{"song_id": "song is not in the playlist"})
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, ok := s.sessions[playlistID]
+	if !ok {
+		return Session{}, apperror.NotFound("no live session on this playlist")
+	}
+	if session.LeaderID != leaderID {
+		return Session{}, apperror.Forbidden("only the session leader can change the state")
+	}
+
+	state := &session.State
+	if params.SongID != nil {
+		songID := *params.SongID
+		state.SongID = &songID
+	}
+	if params.Section != nil {
+		state.Section = *params.Section
+	}
+	if params.Transpose != nil {
+		state.Transpose = *params.Transpose
+	}
+	if params.Scroll != nil {
+		state.Scroll = *params.Scroll
+	}
+	state.Version++
+	state.UpdatedAt = s.now()
+
+	s.fanOut.Publish(playlistID, Event{Type: EventState, Session: *session})
+	return *session, nil
+}
+
+// End stops the session. The leader or the playlist owner may end it.
+func (s *service) End(ctx context.Context, playlistID, userID int) error {
+	role, err := s.role(ctx, playlistID, userID)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, ok := s.sessions[playlistID]
+	if !ok {
+		return apperror.NotFound("no live session on this playlist")
+	}
+	if session.LeaderID != userID && role != playlistsvc.RoleOwner {
+		return apperror.Forbidden("only the session leader or the owner can end the session")
+	}
+
+	delete(s.sessions, playlistID)
+	s.fanOut.Publish(playlistID, Event{Type: EventEnded, Session: *session})
+	return nil
+}
+
+// Join subscribes a member to the session. The snapshot and subscription are
+// taken together so a late joiner misses no update.
+func (s *service) Join(ctx context.Context, playlistID, userID int) (Session, <-chan Event, func(), error) {
+	if _, err := s.role(ctx, playlistID, userID); err != nil {
+		return Session{}, nil, nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, ok := s.sessions[playlistID]
+	if !ok {
+		return Session{}, nil, nil, apperror.NotFound("no live session on this playlist")
+	}
+	events, release := s.fanOut.Subscribe(playlistID)
+	return *session, events, release, nil
+}
+
+// Shutdown stops the sweep and closes the fan-out, ending every member's
+// stream.
+func (s *service) Shutdown() {
+	s.stopOnce.Do(func() { close(s.stop) })
+	s.fanOut.Close()
+}
+
+func (s *service) sweepEvery(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.sweep()
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+// sweep drops sessions idle past IdleTimeout that nobody follows, which were
+// abandoned without End and would otherwise be kept forever.
+func (s *service) sweep() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	for playlistID, session := range s.sessions {
+		if now.Sub(session.State.UpdatedAt) >= IdleTimeout && s.fanOut.Subscribers(playlistID) == 0 {
+			delete(s.sessions, playlistID)
+		}
+	}
+}
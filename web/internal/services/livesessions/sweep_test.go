@@ -0,0 +1,38 @@
+package livesessions
+
+import (
+	"testing"
+	"time"
+)
+
+func TestService_Sweep(t *testing.T) {
+	now := time.Date(2026, 10, 18, 20, 0, 0, 0, time.UTC)
+	fanOut := NewLocalFanOut()
+	s := &service{
+		fanOut: fanOut,
+		now:    func() time.Time { return now },
+		sessions: map[int]*Session{
+			1: {PlaylistID: 1, State: State{UpdatedAt: now.Add(-IdleTimeout)}},
+			2: {PlaylistID: 2, State: State{UpdatedAt: now.Add(-IdleTimeout)}},
+			3: {PlaylistID: 3, State: State{UpdatedAt: now.Add(-time.Minute)}},
+		},
+	}
+
+	// given: someone still follows the second idle session
+	_, release := fanOut.Subscribe(2)
+	defer release()
+
+	// when
+	s.sweep()
+
+	// then
+	if _, ok := s.sessions[1]; ok {
+		t.Error("expected the abandoned session to be dropped")
+	}
+	if _, ok := s.sessions[2]; !ok {
+		t.Error("expected the followed session to be kept")
+	}
+	if _, ok := s.sessions[3]; !ok {
+		t.Error("expected the active session to be kept")
+	}
+}
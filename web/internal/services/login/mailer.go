@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"mime"
 	"net/smtp"
 	"strings"
 	"time"
@@ -39,61 +40,64 @@ func (m *consoleMailer) SendOTP(_ context.Context, email, code string, expiresAt
 }
 
 type smtpMailer struct {
-	host     string
-	port     int
-	username string
-	password string
-	from     string
+	settings SMTPSettings
 }
 
 // NewSMTPMailer uses the provided SMTP settings to send OTP emails.
 func NewSMTPMailer(settings SMTPSettings) Mailer {
-	return &smtpMailer{
-		host:     settings.Host,
-		port:     settings.Port,
-		username: settings.Username,
-		password: settings.Password,
-		from:     settings.From,
-	}
+	return &smtpMailer{settings: settings}
 }
 
 func (m *smtpMailer) SendOTP(_ context.Context, email, code string, expiresAt time.Time) error {
 	if strings.TrimSpace(email) == "" {
 		return apperror.BadRequest("email is required")
 	}
-
-	from := m.from
-	if strings.TrimSpace(from) == "" {
-		from = m.username
-	}
-	if strings.TrimSpace(from) == "" {
+	if m.settings.sender() == "" {
 		return apperror.BadRequest("from is required")
 	}
 
-	subject := "Your login code"
 	body := fmt.Sprintf("Your login code is %s. It expires at %s.", code, expiresAt.Format(time.RFC1123Z))
+	if err := m.settings.Send(email, "Your login code", body); err != nil {
+		return fmt.Errorf("smtp mailer: %w", err)
+	}
 
-	message := strings.Join([]string{
+	return nil
+}
+
+// Send emails a plain text message to one address through the relay. The
+// subject is Q-encoded, so line breaks in user-supplied text such as a song
+// title cannot add headers or recipients.
+func (s SMTPSettings) Send(to, subject, body string) error {
+	addr := fmt.Sprintf("%s:%d", s.Host, s.Port)
+
+	var auth smtp.Auth
+	if s.Username != "" {
+		auth = smtp.PlainAuth("", s.Username, s.Password, s.Host)
+	}
+
+	if err := smtp.SendMail(addr, auth, s.sender(), []string{to}, message(s.sender(), to, subject, body)); err != nil {
+		return fmt.Errorf("send mail: %w", err)
+	}
+	return nil
+}
+
+func message(from, to, subject, body string) []byte {
+	return []byte(strings.Join([]string{
 		fmt.Sprintf("From: %s", from),
-		fmt.Sprintf("To: %s", email),
+		fmt.Sprintf("To: %s", to),
 		"MIME-Version: 1.0",
 		"Content-Type: text/plain; charset=UTF-8",
-		fmt.Sprintf("Subject: %s", subject),
+		fmt.Sprintf("Subject: %s", mime.QEncoding.Encode("utf-8", subject)),
 		"",
 		body,
 		"",
-	}, "\r\n")
-
-	addr := fmt.Sprintf("%s:%d", m.host, m.port)
-
-	var auth smtp.Auth
-	if m.username != "" {
-		auth = smtp.PlainAuth("", m.username, m.password, m.host)
-	}
+	}, "\r\n"))
+}
 
-	if err := smtp.SendMail(addr, auth, from, []string{email}, []byte(message)); err != nil {
-		return fmt.Errorf("smtp mailer: send mail: %w", err)
+// sender is the From address, falling back to the relay user.
+func (s SMTPSettings) sender() string {
+	if from := strings.TrimSpace(s.From); from != "" {
+		return from
 	}
-
-	return nil
+	return strings.TrimSpace(s.Username)
 }
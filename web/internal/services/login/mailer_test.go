@@ -0,0 +1,22 @@
+package login
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMessage_SubjectCannotAddHeaders(t *testing.T) {
+	// when
+	got := string(message("no-reply@lyric.app", "reader@test.com", "Your suggestion for Grace\r\nBcc: victim@test.com", "body"))
+
+	// then
+	headers, _, _ := strings.Cut(got, "\r\n\r\n")
+	for _, line := range strings.Split(headers, "\r\n") {
+		if strings.HasPrefix(line, "Bcc:") {
+			t.Fatalf("subject injected a header: %q", got)
+		}
+	}
+	if !strings.Contains(headers, "Subject: =?utf-8?q?") {
+		t.Errorf("expected an encoded subject, got %q", headers)
+	}
+}
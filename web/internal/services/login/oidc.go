@@ -0,0 +1,330 @@
+package login
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/lestrrat-go/jwx/v2/jws"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+
+	"github.com/lyricapp/lyric/web/internal/apperror"
+)
+
+// Identity provider names accepted by SignInWithIDToken.
+const (
+	ProviderGoogle = "google"
+	ProviderApple  = "apple"
+)
+
+const (
+	// GoogleJWKSURL and AppleJWKSURL publish the keys signing each issuer's ID
+	// tokens, saving a discovery round trip.
+	GoogleJWKSURL = "https://www.googleapis.com/oauth2/v3/certs"
+	AppleJWKSURL  = "https://appleid.apple.com/auth/keys"
+
+	// keySetMaxAge bounds how long fetched keys are trusted before refetching,
+	// and keySetMinRefresh how often an unknown key id may force a refetch.
+	keySetMaxAge     = 6 * time.Hour
+	keySetMinRefresh = time.Minute
+	idTokenSkew      = time.Minute
+)
+
+// GoogleIssuers and AppleIssuers are the iss values each provider signs with.
+var (
+	GoogleIssuers = []string{"https://accounts.google.com", "accounts.google.com"}
+	AppleIssuers  = []string{"https://appleid.apple.com"}
+)
+
+// Identity is the account an ID token vouches for. Subject is stable per
+// provider; the email may change or be a relay address.
+type Identity struct {
+	Provider      string
+	Subject       string
+	Email         string
+	EmailVerified bool
+}
+
+// IdentityProvider verifies ID tokens from one OpenID Connect issuer.
+type IdentityProvider interface {
+	Name() string
+	Verify(ctx context.Context, idToken, nonce string) (Identity, error)
+}
+
+// OIDCSettings configures an OpenID Connect issuer. Tokens must carry one of
+// Issuers and be addressed to one of ClientIDs. When JWKSURL is empty it is
+// read from the first issuer's discovery document.
+type OIDCSettings struct {
+	Name      string
+	Issuers   []string
+	JWKSURL   string
+	ClientIDs []string
+	Client    *http.Client
+}
+
+type oidcProvider struct {
+	name      string
+	issuers   []string
+	clientIDs []string
+	client    *http.Client
+	now       func() time.Time
+
+	// mu guards the fields below; it is never held across a network call.
+	mu        sync.Mutex
+	jwksURL   string
+	keys      jwk.Set
+	fetchedAt time.Time
+	// fetching is the key fetch in flight, which concurrent sign-ins share.
+	fetching *keyFetch
+}
+
+// keyFetch is one fetch of an issuer's keys; done is closed once keys and err
+// are set.
+type keyFetch struct {
+	done chan struct{}
+	keys jwk.Set
+	err  error
+}
+
+// NewOIDCProvider verifies ID tokens against the issuer's published keys.
+func NewOIDCProvider(settings OIDCSettings) IdentityProvider {
+	client := settings.Client
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &oidcProvider{
+		name:      settings.Name,
+		issuers:   settings.Issuers,
+		clientIDs: settings.ClientIDs,
+		client:    client,
+		now:       time.Now,
+		jwksURL:   settings.JWKSURL,
+	}
+}
+
+func (p *oidcProvider) Name() string {
+	return p.name
+}
+
+// Verify checks the token's signature, issuer, audience, expiry and, when
+// given, nonce. Apple sends email_verified as a string, Google as a boolean.
+func (p *oidcProvider) Verify(ctx context.Context, idToken, nonce string) (Identity, error) {
+	message, err := jws.ParseString(idToken)
+	if err != nil || len(message.Signatures()) != 1 {
+		return Identity{}, apperror.Unauthorized("id token is invalid")
+	}
+	keys, err := p.keySet(ctx, message.Signatures()[0].ProtectedHeaders().KeyID())
+	if err != nil {
+		return Identity{}, err
+	}
+
+	token, err := jwt.ParseString(idToken,
+		jwt.WithKeySet(keys),
+		jwt.WithValidate(true),
+		jwt.WithAcceptableSkew(idTokenSkew),
+		jwt.WithClock(jwt.ClockFunc(p.now)),
+		jwt.WithRequiredClaim(jwt.SubjectKey),
+		jwt.WithRequiredClaim(jwt.ExpirationKey),
+	)
+	if err != nil {
+		return Identity{}, apperror.Unauthorized("id token is invalid")
+	}
+	if !contains(p.issuers, token.Issuer()) {
+		return Identity{}, apperror.Unauthorized("id token was not issued by " + p.name)
+	}
+	if !intersects(p.clientIDs, token.Audience()) {
+		return Identity{}, apperror.Unauthorized("id token was not issued for this app")
+	}
+	if nonce != "" {
+		claimed, _ := token.Get("nonce")
+		if value, ok := claimed.(string); !ok || value != nonce {
+			return Identity{}, apperror.Unauthorized("id token nonce does not match")
+		}
+	}
+
+	identity := Identity{Provider: p.name, Subject: token.Subject()}
+	if email, ok := token.Get("email"); ok {
+		identity.Email, _ = email.(string)
+	}
+	if verified, ok := token.Get("email_verified"); ok {
+		switch v := verified.(type) {
+		case bool:
+			identity.EmailVerified = v
+		case string:
+			identity.EmailVerified = strings.EqualFold(v, "true")
+		}
+	}
+	return identity, nil
+}
+
+// keySet returns the issuer's keys, refetching them when stale or when kid is
+// unknown, as happens right after the issuer rotates its keys. Sign-ins that
+// need a refetch while one is in flight wait for it instead of starting their
+// own, and the lock is only held to read and swap the keys.
+func (p *oidcProvider) keySet(ctx context.Context, kid string) (jwk.Set, error) {
+	p.mu.Lock()
+	now := p.now()
+	if p.keys != nil && now.Sub(p.fetchedAt) < keySetMaxAge {
+		if _, ok := p.keys.LookupKeyID(kid); ok || now.Sub(p.fetchedAt) < keySetMinRefresh {
+			keys := p.keys
+			p.mu.Unlock()
+			return keys, nil
+		}
+	}
+	fetch := p.fetching
+	if fetch == nil {
+		fetch = &keyFetch{done: make(chan struct{})}
+		p.fetching = fetch
+		// The fetch outlives a sign-in that gives up, since others share it;
+		// the client's timeout bounds it.
+		go p.fetch(context.WithoutCancel(ctx), fetch, p.jwksURL, now)
+	}
+	p.mu.Unlock()
+
+	select {
+	case <-fetch.done:
+		return fetch.keys, fetch.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// fetch loads the keys, discovering their URL first when it is not known, and
+// publishes the result. A failed fetch falls back to the keys already held.
+func (p *oidcProvider) fetch(ctx context.Context, fetch *keyFetch, jwksURL string, now time.Time) {
+	keys, err := p.download(ctx, jwksURL)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	defer close(fetch.done)
+
+	p.fetching = nil
+	switch {
+	case err == nil:
+		p.keys = keys
+		p.fetchedAt = now
+		fetch.keys = keys
+	case p.keys != nil:
+		fetch.keys = p.keys
+	default:
+		fetch.err = err
+	}
+}
+
+func (p *oidcProvider) download(ctx context.Context, jwksURL string) (jwk.Set, error) {
+	if jwksURL == "" {
+		url, err := p.discover(ctx)
+		if err != nil {
+			return nil, err
+		}
+		p.mu.Lock()
+		p.jwksURL = url
+		p.mu.Unlock()
+		jwksURL = url
+	}
+	keys, err := jwk.Fetch(ctx, jwksURL, jwk.WithHTTPClient(p.client))
+	if err != nil {
+		return nil, fmt.Errorf("fetch %s keys: %w", p.name, err)
+	}
+	return keys, nil
+}
+
+func (p *oidcProvider) discover(ctx context.Context) (string, error) {
+	if len(p.issuers) == 0 {
+		return "", fmt.Errorf("%s: no issuer configured", p.name)
+	}
+	endpoint := strings.TrimRight(p.issuers[0], "/") + "/.well-known/openid-configuration"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return "", fmt.Errorf("build %s discovery request: %w", p.name, err)
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("%s discovery: %w", p.name, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%s discovery: unexpected status %d", p.name, resp.StatusCode)
+	}
+
+	var document struct {
+		JWKSURI string `json:"jwks_uri"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&document); err != nil {
+		return "", fmt.Errorf("decode %s discovery: %w", p.name, err)
+	}
+	if document.JWKSURI == "" {
+		return "", fmt.Errorf("%s discovery: jwks_uri missing", p.name)
+	}
+	return document.JWKSURI, nil
+}
+
+// SignInWithIDToken signs the device in with an ID token from a configured
+// provider. The provider's subject is linked to a user on first sign in, found
+// or created by the token's email, which the provider must have verified.
+func (s *service) SignInWithIDToken(ctx context.Context, provider, idToken, nonce string, device Device) (VerifyResult, error) {
+	ve := map[string]string{}
+	p, ok := s.providers[strings.ToLower(strings.TrimSpace(provider))]
+	if !ok {
+		ve["provider"] = "provider is not supported"
+	}
+	idToken = strings.TrimSpace(idToken)
+	if idToken == "" {
+		ve["id_token"] = "id_token is required"
+	}
+	if len(ve) > 0 {
+		return VerifyResult{}, apperror.Validation("msg", ve)
+	}
+
+	identity, err := p.Verify(ctx, idToken, nonce)
+	if err != nil {
+		return VerifyResult{}, err
+	}
+
+	user, found, err := s.repo.FindUserByIdentity(ctx, identity.Provider, identity.Subject)
+	if err != nil {
+		return VerifyResult{}, fmt.Errorf("find identity: %w", err)
+	}
+	if !found {
+		email := strings.TrimSpace(strings.ToLower(identity.Email))
+		if !identity.EmailVerified || !isValidEmail(email) {
+			return VerifyResult{}, apperror.Forbidden("a verified email is required to sign in")
+		}
+		if user, err = s.repo.FindOrCreateUser(ctx, email); err != nil {
+			return VerifyResult{}, err
+		}
+	}
+	if !user.CanSignIn(s.now()) {
+		return VerifyResult{}, apperror.Forbidden("account is not active")
+	}
+	if user, err = s.restore(ctx, user); err != nil {
+		return VerifyResult{}, err
+	}
+	if err := s.repo.LinkIdentity(ctx, user.ID, identity, s.now()); err != nil {
+		return VerifyResult{}, fmt.Errorf("link identity: %w", err)
+	}
+	return s.issue(ctx, user, device)
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func intersects(allowed, values []string) bool {
+	for _, value := range values {
+		if contains(allowed, value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,148 @@
+package login_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/lyricapp/lyric/web/internal/apperror"
+	"github.com/lyricapp/lyric/web/internal/services/login"
+	"github.com/lyricapp/lyric/web/internal/testutil"
+)
+
+func TestOIDCProvider_Verify(t *testing.T) {
+	issuer := testutil.NewOIDCIssuer(t)
+	provider := login.NewOIDCProvider(login.OIDCSettings{
+		Name:      login.ProviderApple,
+		Issuers:   []string{issuer.URL},
+		ClientIDs: []string{"app.lyric.ios", "app.lyric.android"},
+	})
+
+	testCases := []struct {
+		name     string
+		token    string
+		nonce    string
+		expected login.Identity
+		fails    bool
+	}{
+		{
+			name: "verified email as a string",
+			token: issuer.IDToken(t, "app.lyric.ios", "apple-1", map[string]any{
+				"email": "singer@privaterelay.appleid.com", "email_verified": "true", "nonce": "n-1",
+			}),
+			nonce:    "n-1",
+			expected: login.Identity{Provider: login.ProviderApple, Subject: "apple-1", Email: "singer@privaterelay.appleid.com", EmailVerified: true},
+		},
+		{
+			name:     "unverified email",
+			token:    issuer.IDToken(t, "app.lyric.android", "apple-2", map[string]any{"email": "singer@mail.com", "email_verified": false}),
+			expected: login.Identity{Provider: login.ProviderApple, Subject: "apple-2", Email: "singer@mail.com"},
+		},
+		{
+			name:  "other audience",
+			token: issuer.IDToken(t, "someone.else", "apple-1", nil),
+			fails: true,
+		},
+		{
+			name:  "other issuer",
+			token: issuer.IDToken(t, "app.lyric.ios", "apple-1", map[string]any{"iss": "https://accounts.google.com"}),
+			fails: true,
+		},
+		{
+			name:  "expired",
+			token: issuer.IDToken(t, "app.lyric.ios", "apple-1", map[string]any{"exp": time.Now().Add(-time.Hour)}),
+			fails: true,
+		},
+		{
+			name:  "nonce mismatch",
+			token: issuer.IDToken(t, "app.lyric.ios", "apple-1", map[string]any{"nonce": "n-1"}),
+			nonce: "n-2",
+			fails: true,
+		},
+		{
+			name:  "signed by another issuer's key",
+			token: testutil.NewOIDCIssuer(t).IDToken(t, "app.lyric.ios", "apple-1", map[string]any{"iss": issuer.URL}),
+			fails: true,
+		},
+		{
+			name:  "malformed",
+			token: "not-a-token",
+			fails: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// when
+			identity, err := provider.Verify(context.Background(), tc.token, tc.nonce)
+
+			// then
+			if tc.fails {
+				var appErr *apperror.AppError
+				if !errors.As(err, &appErr) || appErr.Status != http.StatusUnauthorized {
+					t.Fatalf("expected unauthorized, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("verify: %v", err)
+			}
+			if identity != tc.expected {
+				t.Errorf("expected %+v, got %+v", tc.expected, identity)
+			}
+		})
+	}
+}
+
+// slowKeys delays key downloads and counts them.
+type slowKeys struct {
+	fetches atomic.Int32
+}
+
+func (k *slowKeys) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Path == "/keys" {
+		k.fetches.Add(1)
+		time.Sleep(100 * time.Millisecond)
+	}
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestOIDCProvider_Verify_SharesKeyFetch(t *testing.T) {
+	issuer := testutil.NewOIDCIssuer(t)
+	keys := &slowKeys{}
+	provider := login.NewOIDCProvider(login.OIDCSettings{
+		Name:      login.ProviderGoogle,
+		Issuers:   []string{issuer.URL},
+		ClientIDs: []string{"app.lyric.android"},
+		Client:    &http.Client{Transport: keys},
+	})
+	token := issuer.IDToken(t, "app.lyric.android", "google-1", nil)
+
+	// when: sign-ins arrive together before any keys are loaded
+	var wg sync.WaitGroup
+	errs := make(chan error, 10)
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := provider.Verify(context.Background(), token, "")
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	// then
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("verify: %v", err)
+		}
+	}
+	if got := keys.fetches.Load(); got != 1 {
+		t.Errorf("expected one key fetch, got %d", got)
+	}
+}
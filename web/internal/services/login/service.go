@@ -17,18 +17,30 @@ import (
 )
 
 const (
-	digits              = "0123456789"
-	defaultCodeLength   = 6
-	defaultCodeValidity = 5 * time.Minute
+	digits               = "0123456789"
+	defaultCodeLength    = 6
+	defaultCodeValidity  = 5 * time.Minute
+	defaultDeletionGrace = 30 * 24 * time.Hour
 )
 
 // Service manages OTP login workflows.
 type Service interface {
 	RequestOTP(ctx context.Context, email string) error
-	VerifyCode(ctx context.Context, code any) (VerifyResult, error)
+	ConsumeCode(ctx context.Context, code any) (User, error)
+	VerifyCode(ctx context.Context, code any, device Device) (VerifyResult, error)
+	SignInWithIDToken(ctx context.Context, provider, idToken, nonce string, device Device) (VerifyResult, error)
+	Refresh(ctx context.Context, refreshToken string) (VerifyResult, error)
 	TokenAuth() *jwtauth.JWTAuth
 	CurrentUser(ctx context.Context, userID int) (User, error)
-	DeleteAccount(ctx context.Context, userID int) error
+	DeleteAccount(ctx context.Context, userID int) (time.Time, error)
+	Sessions(ctx context.Context, userID int, currentSessionID int64) ([]Session, error)
+	RevokeSession(ctx context.Context, userID int, sessionID int64) error
+	RevokeOtherSessions(ctx context.Context, userID int, currentSessionID int64) error
+	CheckSession(ctx context.Context, userID int, sessionID int64) error
+	// CheckIssued confirms a credential without a device session, such as an
+	// admin cookie, belongs to an active user and was issued after an admin
+	// last signed them out of every device.
+	CheckIssued(ctx context.Context, userID int, issuedAt time.Time) error
 }
 
 // Repository abstracts persistence needs for OTP login.
@@ -37,7 +49,26 @@ type Repository interface {
 	CreateLoginCode(ctx context.Context, userID int, code string, expiresAt time.Time) error
 	ConsumeLoginCode(ctx context.Context, code string, attemptedAt time.Time) (User, bool, error)
 	FindUserByID(ctx context.Context, userID int) (User, error)
-	UpdateUserStatus(ctx context.Context, userID int, status string) error
+	// ScheduleDeletion marks the user deleted, to be purged after purgeAfter.
+	ScheduleDeletion(ctx context.Context, userID int, purgeAfter time.Time) error
+	// RestoreUser reactivates a user whose deletion is still pending.
+	RestoreUser(ctx context.Context, userID int) error
+	CreateSession(ctx context.Context, session NewSession) (Session, error)
+	// RotateSession swaps a live session's refresh token hash for nextHash,
+	// reporting false when no live session holds currentHash.
+	RotateSession(ctx context.Context, currentHash, nextHash string, at, expiresAt time.Time) (Session, User, bool, error)
+	// RevokeReplayedSession revokes the session whose previous refresh token
+	// hashes to hash.
+	RevokeReplayedSession(ctx context.Context, hash string, at time.Time) (bool, error)
+	ListSessions(ctx context.Context, userID int, at time.Time) ([]Session, error)
+	RevokeSession(ctx context.Context, userID int, sessionID int64, at time.Time) (bool, error)
+	// RevokeSessions revokes every live session of the user except exceptID.
+	RevokeSessions(ctx context.Context, userID int, exceptID int64, at time.Time) error
+	SessionActive(ctx context.Context, userID int, sessionID int64, at time.Time) (bool, error)
+	FindUserByIdentity(ctx context.Context, provider, subject string) (User, bool, error)
+	// LinkIdentity attaches the provider account to the user, or records a fresh
+	// sign in when it is already attached.
+	LinkIdentity(ctx context.Context, userID int, identity Identity, at time.Time) error
 }
 
 // Mailer dispatches OTP codes to users.
@@ -45,20 +76,43 @@ type Mailer interface {
 	SendOTP(ctx context.Context, email, code string, expiresAt time.Time) error
 }
 
-// Config captures service-level settings.
+// Config captures service-level settings. TokenTTL is the access token lifetime
+// and RefreshTTL how long an unused device session stays signed in.
+// DeletionGrace is how long a deleted account can still be restored by signing
+// in before it is purged.
 type Config struct {
-	CodeLength  int
-	TTL         time.Duration
-	TokenSecret string
-	TokenTTL    time.Duration
+	CodeLength    int
+	TTL           time.Duration
+	TokenSecret   string
+	TokenTTL      time.Duration
+	RefreshTTL    time.Duration
+	DeletionGrace time.Duration
 }
 
-// User mirrors the data required from persistence.
+// User mirrors the data required from persistence. PurgeAfter is set while a
+// deleted account waits to be purged.
 type User struct {
-	ID     int
-	Email  string
-	Role   string
-	Status string
+	ID         int
+	Email      string
+	Role       string
+	Status     string
+	PurgeAfter *time.Time
+	// SignedOutAt is when an admin last signed the user out of every device;
+	// only FindUserByID loads it.
+	SignedOutAt *time.Time
+}
+
+// CanSignIn reports whether the user may sign in at the given time: active
+// accounts, and deleted ones still inside their grace period.
+func (u User) CanSignIn(at time.Time) bool {
+	if isActiveStatus(u.Status) {
+		return true
+	}
+	return u.restorable(at)
+}
+
+func (u User) restorable(at time.Time) bool {
+	return strings.EqualFold(strings.TrimSpace(u.Status), "deleted") && u.PurgeAfter != nil && u.PurgeAfter.After(at)
 }
 
 type service struct {
@@ -68,17 +122,25 @@ type service struct {
 	ttl        time.Duration
 	tokenAuth  *jwtauth.JWTAuth
 	tokenTTL   time.Duration
+	refreshTTL time.Duration
+	grace      time.Duration
+	providers  map[string]IdentityProvider
 	now        func() time.Time
 }
 
-// VerifyResult encapsulates the outcome of a successful code verification.
+// VerifyResult encapsulates the outcome of a successful code verification or
+// token refresh.
 type VerifyResult struct {
-	Token string
-	User  User
+	Token        string
+	ExpiresAt    time.Time
+	RefreshToken string
+	SessionID    int64
+	User         User
 }
 
-// NewService assembles the default login OTP workflow.
-func NewService(repo Repository, mailer Mailer, cfg Config) Service {
+// NewService assembles the default login OTP workflow. Each identity provider
+// additionally allows signing in with its ID tokens.
+func NewService(repo Repository, mailer Mailer, cfg Config, providers ...IdentityProvider) Service {
 	length := cfg.CodeLength
 	if length <= 0 {
 		length = defaultCodeLength
@@ -89,6 +151,21 @@ func NewService(repo Repository, mailer Mailer, cfg Config) Service {
 		ttl = defaultCodeValidity
 	}
 
+	refreshTTL := cfg.RefreshTTL
+	if refreshTTL <= 0 {
+		refreshTTL = defaultRefreshTTL
+	}
+
+	grace := cfg.DeletionGrace
+	if grace <= 0 {
+		grace = defaultDeletionGrace
+	}
+
+	byName := make(map[string]IdentityProvider, len(providers))
+	for _, provider := range providers {
+		byName[provider.Name()] = provider
+	}
+
 	return &service{
 		repo:       repo,
 		mailer:     mailer,
@@ -96,6 +173,9 @@ func NewService(repo Repository, mailer Mailer, cfg Config) Service {
 		ttl:        ttl,
 		tokenAuth:  jwtauth.New("HS256", []byte(cfg.TokenSecret), nil),
 		tokenTTL:   cfg.TokenTTL,
+		refreshTTL: refreshTTL,
+		grace:      grace,
+		providers:  byName,
 		now:        time.Now,
 	}
 }
@@ -126,7 +206,7 @@ func (s *service) RequestOTP(ctx context.Context, email string) error {
 		return apperror.NotFound("user not found")
 	}
 	log.Println(user)
-	if !isActiveStatus(user.Status) {
+	if !user.CanSignIn(s.now()) {
 		return apperror.Forbidden("account is not active")
 	}
 
@@ -208,54 +288,60 @@ func normalizeCode(value any) (string, error) {
 	}
 }
 
-// VerifyCode validates an OTP code and issues an access token.
-func (s *service) VerifyCode(ctx context.Context, otp any) (VerifyResult, error) {
-	code, err := normalizeCode(otp)
+// VerifyCode validates an OTP code and signs the device in with a new session.
+func (s *service) VerifyCode(ctx context.Context, otp any, device Device) (VerifyResult, error) {
+	user, err := s.ConsumeCode(ctx, otp)
 	if err != nil {
 		return VerifyResult{}, err
 	}
+	return s.issue(ctx, user, device)
+}
+
+// ConsumeCode validates an OTP code and returns its user without opening a
+// session, for surfaces that keep their own.
+func (s *service) ConsumeCode(ctx context.Context, otp any) (User, error) {
+	code, err := normalizeCode(otp)
+	if err != nil {
+		return User{}, err
+	}
 	ve := map[string]string{}
 	if len(code) != s.codeLength || !isDigits(code) {
 		ve["code"] = "Code is invalid or has the wrong format."
-		return VerifyResult{}, apperror.Validation("msg", ve)
+		return User{}, apperror.Validation("msg", ve)
 	}
 
 	now := s.now()
 	user, ok, err := s.repo.ConsumeLoginCode(ctx, code, now)
 	if err != nil {
-		return VerifyResult{}, fmt.Errorf("consume otp: %w", err)
+		return User{}, fmt.Errorf("consume otp: %w", err)
 	}
 	if !ok {
 		ve["code"] = "Code is invalid."
-		return VerifyResult{}, apperror.Validation("msg", ve)
+		return User{}, apperror.Validation("msg", ve)
 	}
-	if !isActiveStatus(user.Status) {
-		return VerifyResult{}, apperror.Forbidden("account is not active")
+	if !user.CanSignIn(now) {
+		return User{}, apperror.Forbidden("account is not active")
 	}
 
-	claims := map[string]any{
-		"sub":   strconv.Itoa(user.ID),
-		"email": user.Email,
-		"role":  user.Role,
-		"iat":   now.Unix(),
-		"exp":   now.Add(s.resolveTokenTTL()).Unix(),
-		"typ":   "access",
-	}
+	return s.restore(ctx, user)
+}
 
-	_, token, err := s.tokenAuth.Encode(claims)
-	if err != nil {
-		return VerifyResult{}, fmt.Errorf("encode jwt: %w", err)
+// restore cancels a pending deletion when its user signs in again.
+func (s *service) restore(ctx context.Context, user User) (User, error) {
+	if isActiveStatus(user.Status) {
+		return user, nil
 	}
-
-	return VerifyResult{
-		Token: token,
-		User:  user,
-	}, nil
+	if err := s.repo.RestoreUser(ctx, user.ID); err != nil {
+		return User{}, fmt.Errorf("restore user: %w", err)
+	}
+	user.Status = "active"
+	user.PurgeAfter = nil
+	return user, nil
 }
 
 func (s *service) resolveTokenTTL() time.Duration {
 	if s.tokenTTL <= 0 {
-		return 24 * time.Hour
+		return defaultAccessTokenTTL
 	}
 	return s.tokenTTL
 }
@@ -273,12 +359,22 @@ func (s *service) CurrentUser(ctx context.Context, userID int) (User, error) {
 	return s.repo.FindUserByID(ctx, userID)
 }
 
-// DeleteAccount updates the current user's status to deleted.
-func (s *service) DeleteAccount(ctx context.Context, userID int) error {
+// DeleteAccount marks the current user's account as deleted and signs out
+// every device. The account and its data are purged once the grace period
+// ends, unless the user signs in again first; the purge time is returned.
+func (s *service) DeleteAccount(ctx context.Context, userID int) (time.Time, error) {
 	if userID <= 0 {
-		return apperror.Unauthorized("Unauthorized")
+		return time.Time{}, apperror.Unauthorized("Unauthorized")
+	}
+	now := s.now()
+	purgeAfter := now.Add(s.grace)
+	if err := s.repo.ScheduleDeletion(ctx, userID, purgeAfter); err != nil {
+		return time.Time{}, err
+	}
+	if err := s.repo.RevokeSessions(ctx, userID, 0, now); err != nil {
+		return time.Time{}, err
 	}
-	return s.repo.UpdateUserStatus(ctx, userID, "deleted")
+	return purgeAfter, nil
 }
 
 func isDigits(value string) bool {
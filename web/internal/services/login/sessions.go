@@ -0,0 +1,236 @@
+package login
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lyricapp/lyric/web/internal/apperror"
+)
+
+const (
+	refreshTokenLength    = 32
+	defaultAccessTokenTTL = 15 * time.Minute
+	defaultRefreshTTL     = 60 * 24 * time.Hour
+	maxDeviceNameLength   = 100
+	maxUserAgentLength    = 255
+)
+
+// Device describes the client a session is opened for.
+type Device struct {
+	Name      string
+	UserAgent string
+}
+
+// Session is a signed-in device. Each holds one refresh token, replaced every
+// time it is used; access tokens name the session they were issued for.
+type Session struct {
+	ID         int64     `json:"id"`
+	UserID     int       `json:"-"`
+	DeviceName *string   `json:"device_name"`
+	UserAgent  *string   `json:"user_agent"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	Current    bool      `json:"current"`
+}
+
+// NewSession captures a session about to be stored.
+type NewSession struct {
+	UserID    int
+	Device    Device
+	TokenHash string
+	ExpiresAt time.Time
+	StartedAt time.Time
+}
+
+// issue opens a session for the user and signs its first token pair.
+func (s *service) issue(ctx context.Context, user User, device Device) (VerifyResult, error) {
+	refresh, hash, err := newRefreshToken()
+	if err != nil {
+		return VerifyResult{}, err
+	}
+	now := s.now()
+	session, err := s.repo.CreateSession(ctx, NewSession{
+		UserID:    user.ID,
+		Device:    normaliseDevice(device),
+		TokenHash: hash,
+		ExpiresAt: now.Add(s.refreshTTL),
+		StartedAt: now,
+	})
+	if err != nil {
+		return VerifyResult{}, fmt.Errorf("store session: %w", err)
+	}
+	return s.sign(user, session.ID, refresh, now)
+}
+
+func (s *service) sign(user User, sessionID int64, refresh string, now time.Time) (VerifyResult, error) {
+	expiresAt := now.Add(s.resolveTokenTTL())
+	claims := map[string]any{
+		"sub":   strconv.Itoa(user.ID),
+		"sid":   strconv.FormatInt(sessionID, 10),
+		"email": user.Email,
+		"role":  user.Role,
+		"iat":   now.Unix(),
+		"exp":   expiresAt.Unix(),
+		"typ":   "access",
+	}
+
+	_, token, err := s.tokenAuth.Encode(claims)
+	if err != nil {
+		return VerifyResult{}, fmt.Errorf("encode jwt: %w", err)
+	}
+
+	return VerifyResult{
+		Token:        token,
+		ExpiresAt:    expiresAt,
+		RefreshToken: refresh,
+		SessionID:    sessionID,
+		User:         user,
+	}, nil
+}
+
+// Refresh trades a refresh token for a new token pair. Refresh tokens are single
+// use: presenting one that was already traded revokes its session, since either
+// the device or whoever copied the token is replaying it.
+func (s *service) Refresh(ctx context.Context, refreshToken string) (VerifyResult, error) {
+	refreshToken = strings.TrimSpace(refreshToken)
+	if refreshToken == "" {
+		return VerifyResult{}, apperror.Validation("msg", map[string]string{"refresh_token": "refresh_token is required"})
+	}
+
+	next, nextHash, err := newRefreshToken()
+	if err != nil {
+		return VerifyResult{}, err
+	}
+	now := s.now()
+	presented := hashToken(refreshToken)
+	session, user, ok, err := s.repo.RotateSession(ctx, presented, nextHash, now, now.Add(s.refreshTTL))
+	if err != nil {
+		return VerifyResult{}, fmt.Errorf("rotate session: %w", err)
+	}
+	if !ok {
+		if _, err := s.repo.RevokeReplayedSession(ctx, presented, now); err != nil {
+			return VerifyResult{}, fmt.Errorf("revoke replayed session: %w", err)
+		}
+		return VerifyResult{}, apperror.Unauthorized("refresh token is invalid or expired")
+	}
+	if !isActiveStatus(user.Status) {
+		return VerifyResult{}, apperror.Forbidden("account is not active")
+	}
+	return s.sign(user, session.ID, next, now)
+}
+
+// Sessions lists the user's live sessions, flagging the current one.
+func (s *service) Sessions(ctx context.Context, userID int, currentSessionID int64) ([]Session, error) {
+	if userID <= 0 {
+		return nil, apperror.Unauthorized("Unauthorized")
+	}
+	sessions, err := s.repo.ListSessions(ctx, userID, s.now())
+	if err != nil {
+		return nil, err
+	}
+	for i := range sessions {
+		sessions[i].Current = sessions[i].ID == currentSessionID
+	}
+	return sessions, nil
+}
+
+// RevokeSession signs one of the user's devices out.
+func (s *service) RevokeSession(ctx context.Context, userID int, sessionID int64) error {
+	if userID <= 0 {
+		return apperror.Unauthorized("Unauthorized")
+	}
+	revoked, err := s.repo.RevokeSession(ctx, userID, sessionID, s.now())
+	if err != nil {
+		return err
+	}
+	if !revoked {
+		return apperror.NotFound("session not found")
+	}
+	return nil
+}
+
+// RevokeOtherSessions signs out every device but the current one.
+func (s *service) RevokeOtherSessions(ctx context.Context, userID int, currentSessionID int64) error {
+	if userID <= 0 {
+		return apperror.Unauthorized("Unauthorized")
+	}
+	return s.repo.RevokeSessions(ctx, userID, currentSessionID, s.now())
+}
+
+// CheckSession confirms an access token's session is still live. Tokens issued
+// before sessions existed carry none; they need an active account that an
+// admin has not signed out since.
+func (s *service) CheckSession(ctx context.Context, userID int, sessionID int64) error {
+	if userID <= 0 {
+		return apperror.Unauthorized("unauthorized")
+	}
+	if sessionID == 0 {
+		user, err := s.repo.FindUserByID(ctx, userID)
+		if err != nil || !isActiveStatus(user.Status) {
+			return apperror.Unauthorized("unauthorized")
+		}
+		if user.SignedOutAt != nil {
+			return apperror.Unauthorized("session has been signed out")
+		}
+		return nil
+	}
+	active, err := s.repo.SessionActive(ctx, userID, sessionID, s.now())
+	if err != nil {
+		return fmt.Errorf("check session: %w", err)
+	}
+	if !active {
+		return apperror.Unauthorized("session has been signed out")
+	}
+	return nil
+}
+
+func (s *service) CheckIssued(ctx context.Context, userID int, issuedAt time.Time) error {
+	if userID <= 0 {
+		return apperror.Unauthorized("unauthorized")
+	}
+	user, err := s.repo.FindUserByID(ctx, userID)
+	if err != nil || !isActiveStatus(user.Status) {
+		return apperror.Unauthorized("unauthorized")
+	}
+	if user.SignedOutAt != nil && issuedAt.Before(*user.SignedOutAt) {
+		return apperror.Unauthorized("session has been signed out")
+	}
+	return nil
+}
+
+func normaliseDevice(device Device) Device {
+	return Device{
+		Name:      truncate(strings.TrimSpace(device.Name), maxDeviceNameLength),
+		UserAgent: truncate(strings.TrimSpace(device.UserAgent), maxUserAgentLength),
+	}
+}
+
+func truncate(value string, max int) string {
+	runes := []rune(value)
+	if len(runes) <= max {
+		return value
+	}
+	return string(runes[:max])
+}
+
+func newRefreshToken() (token, hash string, err error) {
+	buf := make([]byte, refreshTokenLength)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", fmt.Errorf("generate refresh token: %w", err)
+	}
+	token = base64.RawURLEncoding.EncodeToString(buf)
+	return token, hashToken(token), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,162 @@
+package permissions
+
+import (
+	"context"
+	"strings"
+
+	"github.com/lyricapp/lyric/web/internal/apperror"
+)
+
+// Role is a user's account-wide role, stored in users.role.
+type Role string
+
+const (
+	RoleAdmin       Role = "admin"
+	RoleEditor      Role = "editor"
+	RoleContributor Role = "contributor"
+	RoleMusician    Role = "musician"
+)
+
+// Roles lists every role, most privileged first.
+var Roles = []Role{RoleAdmin, RoleEditor, RoleContributor, RoleMusician}
+
+// ParseRole validates a role name.
+func ParseRole(raw string) (Role, error) {
+	role := Role(strings.ToLower(strings.TrimSpace(raw)))
+	for _, known := range Roles {
+		if role == known {
+			return role, nil
+		}
+	}
+	return "", apperror.Validation("msg", map[string]string{"role": "role must be admin, editor, contributor or musician"})
+}
+
+// Capability is something a role allows.
+type Capability string
+
+const (
+	// SubmitSong allows adding songs and editing, deleting and submitting for
+	// review the songs one added.
+	SubmitSong Capability = "songs.submit"
+	// ApproveSong allows approving or declining submitted songs.
+	ApproveSong Capability = "songs.approve"
+	// EditAnySong allows editing songs added by anyone.
+	EditAnySong Capability = "songs.edit_any"
+	// DeleteAnySong allows deleting songs added by anyone.
+	DeleteAnySong Capability = "songs.delete_any"
+	// ManageCatalogue allows maintaining artists, writers, albums, chords and
+	// chord requests.
+	ManageCatalogue Capability = "catalogue.manage"
+	// ManageUsers allows viewing and changing other accounts.
+	ManageUsers Capability = "users.manage"
+	// AccessAdmin allows signing in to the admin panel.
+	AccessAdmin Capability = "admin.access"
+	// ViewAuditLog allows browsing the record of privileged changes.
+	ViewAuditLog Capability = "audit.view"
+)
+
+var capabilities = map[Role][]Capability{
+	RoleAdmin:       {SubmitSong, ApproveSong, EditAnySong, DeleteAnySong, ManageCatalogue, ManageUsers, AccessAdmin, ViewAuditLog},
+	RoleEditor:      {SubmitSong, ApproveSong, EditAnySong, ManageCatalogue, AccessAdmin},
+	RoleContributor: {SubmitSong},
+	RoleMusician:    {SubmitSong},
+}
+
+// Can reports whether the role grants the capability. Unknown roles grant none.
+func (r Role) Can(capability Capability) bool {
+	for _, granted := range capabilities[r] {
+		if granted == capability {
+			return true
+		}
+	}
+	return false
+}
+
+// Capabilities lists what the role grants.
+func (r Role) Capabilities() []Capability {
+	return append([]Capability(nil), capabilities[r]...)
+}
+
+// Principal is an authenticated user and their current role.
+type Principal struct {
+	UserID int
+	Role   Role
+}
+
+// Can reports whether the principal's role grants the capability.
+func (p Principal) Can(capability Capability) bool {
+	return p.Role.Can(capability)
+}
+
+// OwnerScope restricts a song mutation: nil when the principal holds the
+// capability to act on anyone's songs, otherwise the principal's own id.
+func (p Principal) OwnerScope(anyOwner Capability) *int {
+	if p.Can(anyOwner) {
+		return nil
+	}
+	id := p.UserID
+	return &id
+}
+
+// Service resolves principals and enforces capabilities.
+type Service interface {
+	Principal(ctx context.Context, userID int) (Principal, error)
+	Require(ctx context.Context, userID int, capability Capability) (Principal, error)
+}
+
+// Repository reads the role of an active account.
+type Repository interface {
+	// ActiveRole returns the user's role, reporting false when the user does not
+	// exist or is not active.
+	ActiveRole(ctx context.Context, userID int) (string, bool, error)
+}
+
+type service struct {
+	repo Repository
+}
+
+// NewService constructs a permission service. Roles are read on every check so
+// a role change applies to tokens and admin sessions already issued.
+func NewService(repo Repository) Service {
+	return &service{repo: repo}
+}
+
+// Principal loads the user's current role.
+func (s *service) Principal(ctx context.Context, userID int) (Principal, error) {
+	if userID <= 0 {
+		return Principal{}, apperror.Unauthorized("Unauthorized")
+	}
+	role, ok, err := s.repo.ActiveRole(ctx, userID)
+	if err != nil {
+		return Principal{}, err
+	}
+	if !ok {
+		return Principal{}, apperror.Forbidden("account is not active")
+	}
+	return Principal{UserID: userID, Role: Role(role)}, nil
+}
+
+// Require loads the principal and fails unless its role grants the capability.
+func (s *service) Require(ctx context.Context, userID int, capability Capability) (Principal, error) {
+	principal, err := s.Principal(ctx, userID)
+	if err != nil {
+		return Principal{}, err
+	}
+	if !principal.Can(capability) {
+		return Principal{}, apperror.Forbidden("you do not have permission to do this")
+	}
+	return principal, nil
+}
+
+type principalKey struct{}
+
+// WithPrincipal stores the principal on the context.
+func WithPrincipal(ctx context.Context, principal Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// FromContext returns the principal stored by WithPrincipal.
+func FromContext(ctx context.Context) (Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(Principal)
+	return principal, ok
+}
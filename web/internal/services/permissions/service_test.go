@@ -0,0 +1,58 @@
+package permissions_test
+
+import (
+	"testing"
+
+	"github.com/lyricapp/lyric/web/internal/services/permissions"
+)
+
+func TestRole_Can(t *testing.T) {
+	testCases := []struct {
+		role       permissions.Role
+		capability permissions.Capability
+		expected   bool
+	}{
+		{permissions.RoleMusician, permissions.SubmitSong, true},
+		{permissions.RoleContributor, permissions.SubmitSong, true},
+		{permissions.RoleContributor, permissions.EditAnySong, false},
+		{permissions.RoleEditor, permissions.EditAnySong, true},
+		{permissions.RoleEditor, permissions.ApproveSong, true},
+		{permissions.RoleEditor, permissions.DeleteAnySong, false},
+		{permissions.RoleEditor, permissions.ManageUsers, false},
+		{permissions.RoleAdmin, permissions.ManageUsers, true},
+		{permissions.RoleAdmin, permissions.AccessAdmin, true},
+		{permissions.RoleEditor, permissions.ViewAuditLog, false},
+		{permissions.RoleAdmin, permissions.ViewAuditLog, true},
+		{permissions.Role("owner"), permissions.SubmitSong, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(string(tc.role)+" "+string(tc.capability), func(t *testing.T) {
+			// when
+			got := tc.role.Can(tc.capability)
+
+			// then
+			if got != tc.expected {
+				t.Errorf("expected %v, got %v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestPrincipal_OwnerScope(t *testing.T) {
+	// given
+	editor := permissions.Principal{UserID: 7, Role: permissions.RoleEditor}
+	contributor := permissions.Principal{UserID: 8, Role: permissions.RoleContributor}
+
+	// when
+	editorScope := editor.OwnerScope(permissions.EditAnySong)
+	contributorScope := contributor.OwnerScope(permissions.EditAnySong)
+
+	// then
+	if editorScope != nil {
+		t.Errorf("expected editors to edit any song, got owner %d", *editorScope)
+	}
+	if contributorScope == nil || *contributorScope != 8 {
+		t.Errorf("expected contributors to be limited to their own songs, got %v", contributorScope)
+	}
+}
@@ -0,0 +1,129 @@
+package plans
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/lyricapp/lyric/web/internal/apperror"
+)
+
+// Plan names the tier a user is on.
+type Plan string
+
+const (
+	PlanFree    Plan = "free"
+	PlanPremium Plan = "premium"
+)
+
+// Feature identifies a capability that is capped per plan.
+type Feature string
+
+const (
+	FeaturePlaylists Feature = "playlists"
+	FeatureShares    Feature = "shares"
+)
+
+// ErrorCode is reported in error details so clients can show an upgrade prompt.
+const ErrorCode = "plan_limit_reached"
+
+// Limits caps feature usage for a plan. Zero means unlimited.
+type Limits struct {
+	Playlists int `json:"playlists"`
+	Shares    int `json:"shares"`
+}
+
+// Of returns the cap for a feature.
+func (l Limits) Of(feature Feature) int {
+	switch feature {
+	case FeaturePlaylists:
+		return l.Playlists
+	case FeatureShares:
+		return l.Shares
+	default:
+		return 0
+	}
+}
+
+// Config sets the limits applied to each plan.
+type Config struct {
+	Free    Limits
+	Premium Limits
+}
+
+// Service resolves a user's plan and enforces its limits.
+type Service interface {
+	Plan(ctx context.Context, userID int) (Plan, error)
+	Limits(ctx context.Context, userID int) (Plan, Limits, error)
+	Check(ctx context.Context, userID int, feature Feature, count int) error
+}
+
+// Repository loads plan assignments. Plan reports premium for users on the premium
+// plan or holding a subscription or unlock that is active at the given time.
+type Repository interface {
+	Plan(ctx context.Context, userID int, at time.Time) (Plan, error)
+}
+
+type service struct {
+	repo Repository
+	cfg  Config
+	now  func() time.Time
+}
+
+// NewService constructs a plan service with the configured limits.
+func NewService(repo Repository, cfg Config) Service {
+	return &service{repo: repo, cfg: cfg, now: time.Now}
+}
+
+// Plan returns the user's current plan, treating unknown values as free.
+func (s *service) Plan(ctx context.Context, userID int) (Plan, error) {
+	if userID <= 0 {
+		return "", apperror.Unauthorized("Unauthorized user")
+	}
+	plan, err := s.repo.Plan(ctx, userID, s.now())
+	if err != nil {
+		return "", err
+	}
+	if plan != PlanPremium {
+		plan = PlanFree
+	}
+	return plan, nil
+}
+
+// Limits returns the user's plan together with the limits it grants.
+func (s *service) Limits(ctx context.Context, userID int) (Plan, Limits, error) {
+	plan, err := s.Plan(ctx, userID)
+	if err != nil {
+		return "", Limits{}, err
+	}
+	if plan == PlanPremium {
+		return plan, s.cfg.Premium, nil
+	}
+	return plan, s.cfg.Free, nil
+}
+
+// Check verifies that holding count items of the feature stays within the user's plan.
+func (s *service) Check(ctx context.Context, userID int, feature Feature, count int) error {
+	plan, limits, err := s.Limits(ctx, userID)
+	if err != nil {
+		return err
+	}
+	limit := limits.Of(feature)
+	if limit <= 0 || count <= limit {
+		return nil
+	}
+	return LimitReached(plan, feature, limit)
+}
+
+// LimitReached builds the upgrade prompt error returned when a plan limit is hit.
+func LimitReached(plan Plan, feature Feature, limit int) *apperror.AppError {
+	message := fmt.Sprintf("Your %s plan allows up to %d %s. Upgrade to add more.", plan, limit, feature)
+	return apperror.UpgradeRequired(message, map[string]string{
+		"code":    ErrorCode,
+		"message": message,
+		"plan":    string(plan),
+		"feature": string(feature),
+		"limit":   strconv.Itoa(limit),
+	})
+}
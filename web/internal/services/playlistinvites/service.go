@@ -0,0 +1,222 @@
+package playlistinvites
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/lyricapp/lyric/web/internal/apperror"
+	plansvc "github.com/lyricapp/lyric/web/internal/services/plans"
+	playlistsvc "github.com/lyricapp/lyric/web/internal/services/playlists"
+)
+
+// Invite link limits.
+const (
+	DefaultTTL  = 72 * time.Hour
+	MaxTTL      = 30 * 24 * time.Hour
+	MaxUsesCap  = 1000
+	tokenLength = 32
+)
+
+// Service issues, lists, revokes and redeems playlist invite links.
+type Service interface {
+	Create(ctx context.Context, params CreateParams) (Invite, error)
+	List(ctx context.Context, playlistID, ownerID int) ([]Invite, error)
+	Revoke(ctx context.Context, playlistID, inviteID, ownerID int) error
+	Accept(ctx context.Context, token string, userID int) (Membership, error)
+}
+
+// CreateParams describes a new invite link. A nil MaxUses allows unlimited joins
+// until the link expires; a zero ExpiresIn uses DefaultTTL.
+type CreateParams struct {
+	PlaylistID int
+	OwnerID    int
+	Role       string
+	MaxUses    *int
+	ExpiresIn  time.Duration
+}
+
+// Invite is an outstanding invite link. Token and URL are only known when the link
+// is created; afterwards only the hash is stored.
+type Invite struct {
+	ID         int              `json:"id"`
+	PlaylistID int              `json:"playlist_id"`
+	Role       playlistsvc.Role `json:"role"`
+	MaxUses    *int             `json:"max_uses"`
+	Uses       int              `json:"uses"`
+	ExpiresAt  time.Time        `json:"expires_at"`
+	RevokedAt  *time.Time       `json:"revoked_at"`
+	CreatedAt  time.Time        `json:"created_at"`
+	Token      string           `json:"token,omitempty"`
+	URL        string           `json:"url,omitempty"`
+}
+
+// Membership is the access an accepted invite granted.
+type Membership struct {
+	PlaylistID int              `json:"playlist_id"`
+	Role       playlistsvc.Role `json:"role"`
+}
+
+// Redemption is the state of an invite and its playlist when a user accepts it.
+type Redemption struct {
+	Invite      Invite
+	OwnerID     int
+	CurrentRole *playlistsvc.Role
+	Shared      int
+}
+
+// Repository persists invite links.
+type Repository interface {
+	Create(ctx context.Context, params CreateParams, role playlistsvc.Role, tokenHash string, expiresAt time.Time) (Invite, error)
+	List(ctx context.Context, playlistID, ownerID int, at time.Time) ([]Invite, error)
+	Revoke(ctx context.Context, playlistID, inviteID, ownerID int, at time.Time) error
+	Find(ctx context.Context, tokenHash string, userID int) (Redemption, error)
+	Redeem(ctx context.Context, inviteID, userID int, role playlistsvc.Role, at time.Time) error
+}
+
+// Config controls how invite URLs are built.
+type Config struct {
+	BaseURL string
+}
+
+type service struct {
+	repo  Repository
+	plans plansvc.Service
+	cfg   Config
+	now   func() time.Time
+}
+
+// NewService constructs an invite service. Accepting an invite counts against the
+// playlist owner's sharing limit.
+func NewService(repo Repository, plans plansvc.Service, cfg Config) Service {
+	return &service{repo: repo, plans: plans, cfg: cfg, now: time.Now}
+}
+
+// Create issues a new invite link for a playlist owned by the caller.
+func (s *service) Create(ctx context.Context, params CreateParams) (Invite, error) {
+	if params.PlaylistID <= 0 {
+		return Invite{}, apperror.NotFound("playlist not found")
+	}
+	if params.OwnerID <= 0 {
+		return Invite{}, apperror.Unauthorized("Unauthorized user")
+	}
+
+	errorsMap := map[string]string{}
+	role := playlistsvc.RoleViewer
+	if strings.TrimSpace(params.Role) != "" {
+		parsed, err := playlistsvc.ParseRole(params.Role)
+		if err != nil {
+			errorsMap["role"] = "role must be either viewer or editor"
+		}
+		role = parsed
+	}
+	if params.MaxUses != nil && (*params.MaxUses <= 0 || *params.MaxUses > MaxUsesCap) {
+		errorsMap["max_uses"] = fmt.Sprintf("max_uses must be between 1 and %d", MaxUsesCap)
+	}
+	if params.ExpiresIn == 0 {
+		params.ExpiresIn = DefaultTTL
+	}
+	if params.ExpiresIn < 0 || params.ExpiresIn > MaxTTL {
+		errorsMap["expires_in_hours"] = fmt.Sprintf("expires_in_hours must be between 1 and %d", int(MaxTTL.Hours()))
+	}
+	if len(errorsMap) > 0 {
+		return Invite{}, apperror.Validation("msg", errorsMap)
+	}
+
+	token, err := newToken()
+	if err != nil {
+		return Invite{}, err
+	}
+
+	invite, err := s.repo.Create(ctx, params, role, hashToken(token), s.now().Add(params.ExpiresIn))
+	if err != nil {
+		return Invite{}, err
+	}
+	invite.Token = token
+	if base := strings.TrimRight(s.cfg.BaseURL, "/"); base != "" {
+		invite.URL = base + "/invites/" + token
+	}
+	return invite, nil
+}
+
+// List returns the playlist's invites that can still be used.
+func (s *service) List(ctx context.Context, playlistID, ownerID int) ([]Invite, error) {
+	if playlistID <= 0 {
+		return nil, apperror.NotFound("playlist not found")
+	}
+	if ownerID <= 0 {
+		return nil, apperror.Unauthorized("Unauthorized user")
+	}
+	return s.repo.List(ctx, playlistID, ownerID, s.now())
+}
+
+// Revoke stops an invite link from being accepted.
+func (s *service) Revoke(ctx context.Context, playlistID, inviteID, ownerID int) error {
+	if playlistID <= 0 || inviteID <= 0 {
+		return apperror.NotFound("invite not found")
+	}
+	if ownerID <= 0 {
+		return apperror.Unauthorized("Unauthorized user")
+	}
+	return s.repo.Revoke(ctx, playlistID, inviteID, ownerID, s.now())
+}
+
+// Accept adds the user to the invite's playlist with the invite's role. Members
+// keep their place; an editor invite promotes an existing viewer.
+func (s *service) Accept(ctx context.Context, token string, userID int) (Membership, error) {
+	if userID <= 0 {
+		return Membership{}, apperror.Unauthorized("Unauthorized user")
+	}
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return Membership{}, apperror.NotFound("invite not found")
+	}
+
+	redemption, err := s.repo.Find(ctx, hashToken(token), userID)
+	if err != nil {
+		return Membership{}, err
+	}
+	invite := redemption.Invite
+	now := s.now()
+	if invite.RevokedAt != nil || !invite.ExpiresAt.After(now) ||
+		(invite.MaxUses != nil && invite.Uses >= *invite.MaxUses) {
+		return Membership{}, apperror.New(http.StatusGone, "invite link is no longer valid", nil)
+	}
+
+	if redemption.OwnerID == userID {
+		return Membership{PlaylistID: invite.PlaylistID, Role: playlistsvc.RoleOwner}, nil
+	}
+	if current := redemption.CurrentRole; current != nil &&
+		(*current == playlistsvc.RoleEditor || *current == invite.Role) {
+		return Membership{PlaylistID: invite.PlaylistID, Role: *current}, nil
+	}
+	if redemption.CurrentRole == nil {
+		if err := s.plans.Check(ctx, redemption.OwnerID, plansvc.FeatureShares, redemption.Shared+1); err != nil {
+			return Membership{}, err
+		}
+	}
+
+	if err := s.repo.Redeem(ctx, invite.ID, userID, invite.Role, now); err != nil {
+		return Membership{}, err
+	}
+	return Membership{PlaylistID: invite.PlaylistID, Role: invite.Role}, nil
+}
+
+func newToken() (string, error) {
+	buf := make([]byte, tokenLength)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("generate invite token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
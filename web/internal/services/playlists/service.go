@@ -2,9 +2,13 @@ package playlists
 
 import (
 	"context"
+	"fmt"
+	"slices"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/lyricapp/lyric/web/internal/apperror"
+	plansvc "github.com/lyricapp/lyric/web/internal/services/plans"
 	"github.com/lyricapp/lyric/web/pkg/pagination"
 )
 
@@ -13,12 +17,35 @@ type Service interface {
 	List(ctx context.Context, params ListParams) (ListResult, error)
 	Create(ctx context.Context, params CreateParams) (int, error)
 	UpdateSongs(ctx context.Context, userID int, playlistID int, songIDs []int, action string) error
+	Reorder(ctx context.Context, userID int, playlistID int, songIDs []int) error
+	UpdateArrangement(ctx context.Context, params ArrangementParams) error
 	Update(ctx context.Context, id int, params UpdateParams) error
 	Delete(ctx context.Context, id int, userID int) error
 	Share(ctx context.Context, playlistID int, ownerID int, userIDs []int) error
+	UpdateRole(ctx context.Context, playlistID, ownerID, userID int, role string) error
 	Leave(ctx context.Context, playlistID int, userID int) error
 }
 
+// Role is a user's access level on a playlist. Owners manage the playlist itself;
+// editors may change its songs; viewers only read.
+type Role string
+
+const (
+	RoleOwner  Role = "owner"
+	RoleEditor Role = "editor"
+	RoleViewer Role = "viewer"
+)
+
+// ParseRole validates a collaborator role. Ownership cannot be granted.
+func ParseRole(raw string) (Role, error) {
+	switch role := Role(strings.ToLower(strings.TrimSpace(raw))); role {
+	case RoleEditor, RoleViewer:
+		return role, nil
+	default:
+		return "", apperror.Validation("msg", map[string]string{"role": "role must be either viewer or editor"})
+	}
+}
+
 // ListParams collects filtering options for listing playlists.
 type ListParams struct {
 	Page    int
@@ -38,6 +65,7 @@ type ListResult struct {
 type User struct {
 	ID    int    `json:"id"`
 	Email string `json:"email"`
+	Role  Role   `json:"role"`
 }
 
 // Playlist describes a playlist row for API consumers.
@@ -45,6 +73,7 @@ type Playlist struct {
 	ID         int    `json:"id"`
 	Name       string `json:"name"`
 	IsOwner    bool   `json:"is_owner"`
+	Role       Role   `json:"role"`
 	SharedWith []User `json:"shared_with"`
 	Total      int    `json:"total"`
 }
@@ -53,21 +82,48 @@ type Playlist struct {
 type Repository interface {
 	List(ctx context.Context, params ListParams) (ListResult, error)
 	Create(ctx context.Context, params CreateParams) (int, error)
+	CountOwned(ctx context.Context, userID int) (int, error)
 	AddSongs(ctx context.Context, userID int, playlistID int, songIDs []int) error
 	RemoveSongs(ctx context.Context, userID int, playlistID int, songIDs []int) error
+	Reorder(ctx context.Context, userID int, playlistID int, songIDs []int) error
+	UpdateArrangement(ctx context.Context, params ArrangementParams) error
 	Update(ctx context.Context, id int, params UpdateParams) error
 	Delete(ctx context.Context, id int, userID int) error
 	Share(ctx context.Context, playlistID int, ownerID int, userIDs []int) error
+	UpdateRole(ctx context.Context, playlistID, ownerID, userID int, role Role) error
 	Leave(ctx context.Context, playlistID int, userID int) error
 }
 
+// Arrangement limits.
+const (
+	MaxTranspose  = 11
+	MaxCapo       = 12
+	MaxNoteLength = 280
+)
+
+// DisplayModes lists the chord display modes a playlist entry may pin.
+var DisplayModes = []string{"overlay", "inline", "lyric"}
+
+// ArrangementParams sets how a playlist performs one of its songs.
+type ArrangementParams struct {
+	UserID      int
+	PlaylistID  int
+	SongID      int
+	Transpose   int
+	Capo        int
+	DisplayMode *string
+	Note        *string
+}
+
 type service struct {
-	repo Repository
+	repo  Repository
+	plans plansvc.Service
 }
 
-// NewService builds a playlist service backed by a repository.
-func NewService(repo Repository) Service {
-	return &service{repo: repo}
+// NewService builds a playlist service backed by a repository. Plan limits on
+// playlist count and sharing are enforced through the plan service.
+func NewService(repo Repository, plans plansvc.Service) Service {
+	return &service{repo: repo, plans: plans}
 }
 
 func (s *service) List(ctx context.Context, params ListParams) (ListResult, error) {
@@ -98,6 +154,14 @@ func (s *service) Create(ctx context.Context, params CreateParams) (int, error)
 		return 0, apperror.Unauthorized("Unauthorized error")
 	}
 
+	owned, err := s.repo.CountOwned(ctx, params.UserID)
+	if err != nil {
+		return 0, err
+	}
+	if err := s.plans.Check(ctx, params.UserID, plansvc.FeaturePlaylists, owned+1); err != nil {
+		return 0, err
+	}
+
 	return s.repo.Create(ctx, params)
 }
 
@@ -127,6 +191,70 @@ func (s *service) UpdateSongs(ctx context.Context, userID, playlistID int, songI
 	}
 }
 
+// Reorder arranges playlist songs in the given order. A subset of the songs is
+// rearranged among the slots it already occupies.
+func (s *service) Reorder(ctx context.Context, userID, playlistID int, songIDs []int) error {
+	if playlistID <= 0 {
+		return apperror.NotFound("playlist not found")
+	}
+	if userID <= 0 {
+		return apperror.Unauthorized("Unauthorized user")
+	}
+	filtered := uniquePositive(songIDs)
+	if len(filtered) == 0 || len(filtered) != len(songIDs) {
+		return apperror.Validation("msg", map[string]string{"song_ids": "song_ids must be distinct positive integers"})
+	}
+	return s.repo.Reorder(ctx, userID, playlistID, filtered)
+}
+
+// UpdateArrangement stores the transpose, capo, display mode and note for a playlist song.
+func (s *service) UpdateArrangement(ctx context.Context, params ArrangementParams) error {
+	if params.PlaylistID <= 0 {
+		return apperror.NotFound("playlist not found")
+	}
+	if params.SongID <= 0 {
+		return apperror.NotFound("song not found")
+	}
+	if params.UserID <= 0 {
+		return apperror.Unauthorized("Unauthorized user")
+	}
+
+	errorsMap := map[string]string{}
+	if params.Transpose < -MaxTranspose || params.Transpose > MaxTranspose {
+		errorsMap["transpose"] = fmt.Sprintf("transpose must be between -%d and %d", MaxTranspose, MaxTranspose)
+	}
+	if params.Capo < 0 || params.Capo > MaxCapo {
+		errorsMap["capo"] = fmt.Sprintf("capo must be between 0 and %d", MaxCapo)
+	}
+	if params.DisplayMode != nil {
+		mode := strings.ToLower(strings.TrimSpace(*params.DisplayMode))
+		switch {
+		case mode == "":
+			params.DisplayMode = nil
+		case slices.Contains(DisplayModes, mode):
+			params.DisplayMode = &mode
+		default:
+			errorsMap["display_mode"] = "display_mode must be one of " + strings.Join(DisplayModes, ", ")
+		}
+	}
+	if params.Note != nil {
+		note := strings.TrimSpace(*params.Note)
+		switch {
+		case note == "":
+			params.Note = nil
+		case utf8.RuneCountInString(note) > MaxNoteLength:
+			errorsMap["note"] = fmt.Sprintf("note must be at most %d characters", MaxNoteLength)
+		default:
+			params.Note = &note
+		}
+	}
+	if len(errorsMap) > 0 {
+		return apperror.Validation("msg", errorsMap)
+	}
+
+	return s.repo.UpdateArrangement(ctx, params)
+}
+
 // Update mutates playlist attributes belonging to the provided user.
 func (s *service) Update(ctx context.Context, id int, params UpdateParams) error {
 	if id <= 0 {
@@ -178,9 +306,31 @@ func (s *service) Share(ctx context.Context, playlistID int, ownerID int, userID
 		clean = append(clean, id)
 	}
 
+	if err := s.plans.Check(ctx, ownerID, plansvc.FeatureShares, len(clean)); err != nil {
+		return err
+	}
+
 	return s.repo.Share(ctx, playlistID, ownerID, clean)
 }
 
+// UpdateRole changes a collaborator's role on a playlist owned by ownerID.
+func (s *service) UpdateRole(ctx context.Context, playlistID, ownerID, userID int, role string) error {
+	if playlistID <= 0 {
+		return apperror.NotFound("playlist not found")
+	}
+	if ownerID <= 0 {
+		return apperror.Unauthorized("Unauthorized user")
+	}
+	if userID <= 0 || userID == ownerID {
+		return apperror.Validation("msg", map[string]string{"user_id": "user_id must be a collaborator"})
+	}
+	parsed, err := ParseRole(role)
+	if err != nil {
+		return err
+	}
+	return s.repo.UpdateRole(ctx, playlistID, ownerID, userID, parsed)
+}
+
 // Leave removes the user from the playlist membership.
 func (s *service) Leave(ctx context.Context, playlistID int, userID int) error {
 	if playlistID <= 0 {
@@ -0,0 +1,153 @@
+package preferences
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lyricapp/lyric/web/internal/apperror"
+	"github.com/lyricapp/lyric/web/internal/services/chords"
+)
+
+// Ranges the apps and the web song page clamp their controls to.
+const (
+	MinFontSize    = 12
+	MaxFontSize    = 28
+	MinScrollSpeed = 1
+	MaxScrollSpeed = 100
+)
+
+// Themes, Languages and DisplayModes list the accepted values. DisplayModes
+// are the song page's SongDetailMode values.
+var (
+	Themes       = []string{"system", "light", "dark"}
+	Languages    = []string{"en", "mm"}
+	DisplayModes = []string{"overlay", "inline", "lyric"}
+)
+
+// Defaults are the preferences of a user who has not saved any.
+var Defaults = Preferences{
+	Theme:       "system",
+	Language:    "en",
+	FontSize:    14,
+	DisplayMode: "overlay",
+	Instrument:  string(chords.DefaultInstrument),
+	ScrollSpeed: 30,
+}
+
+// Service reads and updates a user's preferences.
+type Service interface {
+	// Get returns the user's preferences, or the defaults when none are saved.
+	Get(ctx context.Context, userID int) (Preferences, error)
+	// Update applies the supplied fields and returns the saved preferences.
+	Update(ctx context.Context, userID int, patch Patch) (Preferences, error)
+}
+
+// Repository abstracts persistence for preferences.
+type Repository interface {
+	Get(ctx context.Context, userID int) (Preferences, bool, error)
+	Save(ctx context.Context, userID int, prefs Preferences) (Preferences, error)
+}
+
+// Preferences are a user's display settings, shared by their devices.
+type Preferences struct {
+	Theme       string     `json:"theme"`
+	Language    string     `json:"language"`
+	FontSize    int        `json:"font_size"`
+	DisplayMode string     `json:"display_mode"`
+	Instrument  string     `json:"instrument"`
+	ScrollSpeed int        `json:"scroll_speed"`
+	UpdatedAt   *time.Time `json:"updated_at"`
+}
+
+// Patch holds the fields to change; nil fields are left as they are.
+type Patch struct {
+	Theme       *string `json:"theme"`
+	Language    *string `json:"language"`
+	FontSize    *int    `json:"font_size"`
+	DisplayMode *string `json:"display_mode"`
+	Instrument  *string `json:"instrument"`
+	ScrollSpeed *int    `json:"scroll_speed"`
+}
+
+type service struct {
+	repo Repository
+}
+
+// NewService builds the default preferences service.
+func NewService(repo Repository) Service {
+	return &service{repo: repo}
+}
+
+func (s *service) Get(ctx context.Context, userID int) (Preferences, error) {
+	if userID <= 0 {
+		return Preferences{}, apperror.Unauthorized("Unauthorized")
+	}
+	prefs, ok, err := s.repo.Get(ctx, userID)
+	if err != nil {
+		return Preferences{}, err
+	}
+	if !ok {
+		return Defaults, nil
+	}
+	return prefs, nil
+}
+
+func (s *service) Update(ctx context.Context, userID int, patch Patch) (Preferences, error) {
+	prefs, err := s.Get(ctx, userID)
+	if err != nil {
+		return Preferences{}, err
+	}
+
+	ve := map[string]string{}
+	if patch.Theme != nil {
+		prefs.Theme = oneOf(ve, "theme", *patch.Theme, Themes)
+	}
+	if patch.Language != nil {
+		prefs.Language = oneOf(ve, "language", *patch.Language, Languages)
+	}
+	if patch.DisplayMode != nil {
+		prefs.DisplayMode = oneOf(ve, "display_mode", *patch.DisplayMode, DisplayModes)
+	}
+	if patch.Instrument != nil {
+		instrument, err := chords.ParseInstrument(*patch.Instrument)
+		var appErr *apperror.AppError
+		if errors.As(err, &appErr) {
+			ve["instrument"] = appErr.Details["instrument"]
+		} else if err != nil {
+			return Preferences{}, err
+		}
+		prefs.Instrument = string(instrument)
+	}
+	if patch.FontSize != nil {
+		prefs.FontSize = between(ve, "font_size", *patch.FontSize, MinFontSize, MaxFontSize)
+	}
+	if patch.ScrollSpeed != nil {
+		prefs.ScrollSpeed = between(ve, "scroll_speed", *patch.ScrollSpeed, MinScrollSpeed, MaxScrollSpeed)
+	}
+	if len(ve) > 0 {
+		return Preferences{}, apperror.Validation("msg", ve)
+	}
+
+	return s.repo.Save(ctx, userID, prefs)
+}
+
+func oneOf(ve map[string]string, field, value string, allowed []string) string {
+	value = strings.ToLower(strings.TrimSpace(value))
+	for _, candidate := range allowed {
+		if value == candidate {
+			return value
+		}
+	}
+	ve[field] = fmt.Sprintf("%s must be one of %s", field, strings.Join(allowed, ", "))
+	return value
+}
+
+func between(ve map[string]string, field string, value, min, max int) int {
+	if value < min || value > max {
+		ve[field] = fmt.Sprintf("%s must be between %d and %d", field, min, max)
+	}
+	return value
+}
@@ -0,0 +1,85 @@
+package songs
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Optional song list fields. The rest of a song is always returned; these are
+// the costly parts list views may leave out.
+const (
+	FieldLyric       = "lyric"
+	FieldAutoScroll  = "auto_scroll"
+	FieldArtists     = "artists"
+	FieldWriters     = "writers"
+	FieldAlbums      = "albums"
+	FieldPlaylistIDs = "playlist_ids"
+)
+
+// OptionalFields lists the fields accepted by ParseFields.
+var OptionalFields = []string{FieldLyric, FieldAutoScroll, FieldArtists, FieldWriters, FieldAlbums, FieldPlaylistIDs}
+
+// Fields selects the optional fields of a song list. A nil Fields selects all of
+// them, so callers that do not ask keep the full payload.
+type Fields map[string]bool
+
+// Has reports whether the optional field is selected.
+func (f Fields) Has(name string) bool {
+	return f == nil || f[name]
+}
+
+// ParseFields reads a comma separated field list such as "artists,auto_scroll".
+// An empty list selects no optional fields.
+func ParseFields(raw string) (Fields, error) {
+	fields := Fields{}
+	for _, part := range strings.Split(raw, ",") {
+		name := strings.ToLower(strings.TrimSpace(part))
+		if name == "" {
+			continue
+		}
+		known := false
+		for _, optional := range OptionalFields {
+			if name == optional {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return nil, fmt.Errorf("unknown field %q, expected any of %s", name, strings.Join(OptionalFields, ", "))
+		}
+		fields[name] = true
+	}
+	return fields, nil
+}
+
+// ListCursor is the last song of a keyset page. Position is set when listing a
+// playlist, which is ordered by position rather than by id.
+type ListCursor struct {
+	ID       int  `json:"i"`
+	Position *int `json:"p,omitempty"`
+}
+
+func (c ListCursor) encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeListCursor(raw string) (*ListCursor, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return nil, err
+	}
+	var c ListCursor
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, err
+	}
+	if c.ID <= 0 {
+		return nil, fmt.Errorf("cursor out of range")
+	}
+	return &c, nil
+}
@@ -0,0 +1,133 @@
+package songs
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Performance metadata bounds, mirrored by the songs table constraints.
+const (
+	MinBPM             = 20
+	MaxBPM             = 300
+	MaxDurationSeconds = 3600
+	MaxCapo            = 12
+
+	// DefaultBeatsPerBar is assumed when a song has a tempo but no time signature.
+	DefaultBeatsPerBar = 4
+	// barsPerLine is how many bars a lyric line usually spans when the duration has
+	// to be estimated from the tempo.
+	barsPerLine = 2
+	// lyricStartMarker separates the prelude from the performed lyric.
+	lyricStartMarker = "||"
+)
+
+// AutoScroll is the pace the song views scroll at. Lines counts every rendered
+// line of the performed lyric, blank lines included, so clients can scroll
+// LinesPerMinute of them per minute. Estimated is set when the duration was
+// derived from the tempo instead of being recorded.
+type AutoScroll struct {
+	DurationSeconds int     `json:"duration_seconds"`
+	Lines           int     `json:"lines"`
+	LinesPerMinute  float64 `json:"lines_per_minute"`
+	Estimated       bool    `json:"estimated"`
+}
+
+// ComputeAutoScroll returns the song's scroll rate, or nil when it has no lyric or
+// neither a duration nor a tempo to pace it by.
+func ComputeAutoScroll(song Song) *AutoScroll {
+	if song.Lyric == nil {
+		return nil
+	}
+	lines, sung := lyricLines(*song.Lyric)
+	if lines == 0 {
+		return nil
+	}
+
+	scroll := AutoScroll{Lines: lines}
+	switch {
+	case song.DurationSeconds != nil && *song.DurationSeconds > 0:
+		scroll.DurationSeconds = *song.DurationSeconds
+	case song.BPM != nil && *song.BPM > 0:
+		beatsPerBar := DefaultBeatsPerBar
+		if song.TimeSignature != nil {
+			if beats, _, ok := parseTimeSignature(*song.TimeSignature); ok {
+				beatsPerBar = beats
+			}
+		}
+		seconds := float64(sung*barsPerLine*beatsPerBar) * 60 / float64(*song.BPM)
+		scroll.DurationSeconds = int(math.Max(1, math.Round(seconds)))
+		scroll.Estimated = true
+	default:
+		return nil
+	}
+
+	rate := float64(lines) * 60 / float64(scroll.DurationSeconds)
+	scroll.LinesPerMinute = math.Round(rate*100) / 100
+	return &scroll
+}
+
+// lyricLines counts the rendered lines after the prelude marker, and how many of
+// them carry lyric or chords.
+func lyricLines(lyric string) (lines, sung int) {
+	body := strings.ReplaceAll(lyric, "\r\n", "\n")
+	if _, after, found := strings.Cut(body, "\n"+lyricStartMarker+"\n"); found {
+		body = after
+	} else if strings.HasPrefix(body, lyricStartMarker+"\n") {
+		body = strings.TrimPrefix(body, lyricStartMarker+"\n")
+	}
+	body = strings.TrimRight(body, "\n ")
+	if strings.TrimSpace(body) == "" {
+		return 0, 0
+	}
+	for _, line := range strings.Split(body, "\n") {
+		lines++
+		if strings.TrimSpace(line) != "" {
+			sung++
+		}
+	}
+	return lines, sung
+}
+
+// parseTimeSignature reads "beats/unit" such as 3/4 or 6/8.
+func parseTimeSignature(raw string) (beats, unit int, ok bool) {
+	top, bottom, found := strings.Cut(strings.TrimSpace(raw), "/")
+	if !found {
+		return 0, 0, false
+	}
+	beats, err := strconv.Atoi(strings.TrimSpace(top))
+	if err != nil || beats < 1 || beats > 32 {
+		return 0, 0, false
+	}
+	unit, err = strconv.Atoi(strings.TrimSpace(bottom))
+	if err != nil {
+		return 0, 0, false
+	}
+	switch unit {
+	case 1, 2, 4, 8, 16, 32:
+		return beats, unit, true
+	}
+	return 0, 0, false
+}
+
+func normalisePerformance(params *MutationParams, ve map[string]string) {
+	if params.BPM != nil && (*params.BPM < MinBPM || *params.BPM > MaxBPM) {
+		ve["bpm"] = fmt.Sprintf("bpm must be between %d and %d", MinBPM, MaxBPM)
+	}
+	if params.TimeSignature != nil {
+		if strings.TrimSpace(*params.TimeSignature) == "" {
+			params.TimeSignature = nil
+		} else if beats, unit, ok := parseTimeSignature(*params.TimeSignature); ok {
+			params.TimeSignature = ptr(fmt.Sprintf("%d/%d", beats, unit))
+		} else {
+			ve["time_signature"] = "time_signature must look like 4/4, 3/4 or 6/8"
+		}
+	}
+	if params.DurationSeconds != nil && (*params.DurationSeconds <= 0 || *params.DurationSeconds > MaxDurationSeconds) {
+		ve["duration_seconds"] = fmt.Sprintf("duration_seconds must be between 1 and %d", MaxDurationSeconds)
+	}
+	if params.Capo != nil && (*params.Capo < 0 || *params.Capo > MaxCapo) {
+		ve["capo"] = fmt.Sprintf("capo must be between 0 and %d", MaxCapo)
+	}
+}
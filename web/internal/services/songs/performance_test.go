@@ -0,0 +1,56 @@
+package songs_test
+
+import (
+	"testing"
+
+	"github.com/lyricapp/lyric/web/internal/services/songs"
+)
+
+func TestComputeAutoScroll(t *testing.T) {
+	intPtr := func(v int) *int { return &v }
+	strPtr := func(v string) *string { return &v }
+	lyric := "{key: G}\n||\n[G]Amazing grace\n[C]How sweet the sound\n\n[G]That saved a wretch\n[D]Like me\n"
+
+	tests := []struct {
+		name string
+		song songs.Song
+		want *songs.AutoScroll
+	}{
+		{
+			name: "no pace",
+			song: songs.Song{Lyric: strPtr(lyric)},
+			want: nil,
+		},
+		{
+			name: "recorded duration",
+			song: songs.Song{Lyric: strPtr(lyric), DurationSeconds: intPtr(50), BPM: intPtr(90)},
+			want: &songs.AutoScroll{DurationSeconds: 50, Lines: 5, LinesPerMinute: 6},
+		},
+		{
+			name: "estimated from tempo",
+			song: songs.Song{Lyric: strPtr(lyric), BPM: intPtr(96), TimeSignature: strPtr("3/4")},
+			// 4 sung lines x 2 bars x 3 beats at 96 bpm = 15s
+			want: &songs.AutoScroll{DurationSeconds: 15, Lines: 5, LinesPerMinute: 20, Estimated: true},
+		},
+		{
+			name: "empty lyric",
+			song: songs.Song{Lyric: strPtr("\n\n"), DurationSeconds: intPtr(60)},
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// when
+			got := songs.ComputeAutoScroll(tt.song)
+
+			// then
+			if (got == nil) != (tt.want == nil) {
+				t.Fatalf("got %+v want %+v", got, tt.want)
+			}
+			if got != nil && *got != *tt.want {
+				t.Errorf("got %+v want %+v", *got, *tt.want)
+			}
+		})
+	}
+}
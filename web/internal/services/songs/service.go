@@ -4,8 +4,10 @@ import (
 	"context"
 	"log"
 	"strings"
+	"time"
 
 	"github.com/lyricapp/lyric/web/internal/apperror"
+	"github.com/lyricapp/lyric/web/pkg/chordtheory"
 	"github.com/lyricapp/lyric/web/pkg/pagination"
 )
 
@@ -13,30 +15,82 @@ import (
 type Service interface {
 	List(ctx context.Context, params ListParams) (ListResult, error)
 	Get(ctx context.Context, id int) (Song, error)
+	// Open returns a song someone is viewing, marking the level vote and
+	// favourite of a positive userID.
+	Open(ctx context.Context, id, userID int) (Song, error)
+	// RecordPlay logs that the user played the song, which counts towards
+	// trending and their recently viewed songs. Plays of the same song within
+	// PlayWindow count once.
+	RecordPlay(ctx context.Context, id, userID int) error
+	// GetInPlaylist returns the song as the playlist arranges it, to the
+	// playlist's owner and the users it is shared with.
+	GetInPlaylist(ctx context.Context, id, playlistID, userID int) (Song, error)
 	Create(ctx context.Context, params CreateParams) (int, error)
 	Update(ctx context.Context, id int, params UpdateParams) error
 	Delete(ctx context.Context, id int, params DeleteParams) error
 	AssignLevel(ctx context.Context, songID, levelID, userID int) error
+	// LevelVotes returns how the community voted on the song's difficulty,
+	// marking the vote of a positive userID.
+	LevelVotes(ctx context.Context, songID, userID int) (LevelVotes, error)
 	SyncPlaylists(ctx context.Context, songID, userID int, playlistIDs []int) error
-	UpdateStatus(ctx context.Context, id int, status string, userID int) error
+	UpdateStatus(ctx context.Context, id int, status string, ownerID *int) error
+	// LastModified returns when a song, or an artist, writer, album, level,
+	// language or user songs show, last changed or was deleted.
+	LastModified(ctx context.Context) (time.Time, error)
 }
 
+// PlayWindow is how long repeated plays of a song by one user count as one.
+const PlayWindow = 30 * time.Minute
+
+// MinLevelVotes is how many users must vote on a song's difficulty before it
+// has a consensus level. The refresh_level_consensus database function
+// applies it.
+const MinLevelVotes = 3
+
+// Level sources a listing can filter level_id by: the level the author gave
+// the song, or the community's consensus.
+const (
+	LevelSourceAuthor    = "author"
+	LevelSourceConsensus = "consensus"
+)
+
+// Song workflow statuses.
+const (
+	StatusCreated  = "created"
+	StatusPending  = "pending"
+	StatusApproved = "approved"
+	StatusDeclined = "declined"
+)
+
 // ListParams captures filtering options accepted by the list endpoint.
 type ListParams struct {
-	Page                int
-	PerPage             int
-	AlbumID             *int
-	ArtistID            *int
-	LanguageID          *int
-	WriterID            *int
-	ReleaseYear         *int
-	PlaylistID          *int
-	Search              string
-	UserID              *int
-	LevelID             *int
-    IsTrending          bool
-    AuthenticatedUserID *int
-    LanguageIDs         []int
+	Page        int
+	PerPage     int
+	AlbumID     *int
+	ArtistID    *int
+	LanguageID  *int
+	WriterID    *int
+	ReleaseYear *int
+	PlaylistID  *int
+	Search      string
+	UserID      *int
+	LevelID     *int
+	// LevelSource picks the level LevelID matches; empty means the author's.
+	LevelSource         string
+	IsTrending          bool
+	AuthenticatedUserID *int
+	// Favorite limits the listing to the authenticated user's favourite songs.
+	Favorite    bool
+	LanguageIDs []int
+	// Keyset switches to cursor pagination: Page is ignored, the page after
+	// Cursor is returned and the total is only counted with IncludeTotal.
+	Keyset       bool
+	Cursor       string
+	IncludeTotal bool
+	// After is the decoded Cursor, set by the service.
+	After *ListCursor
+	// Fields selects the optional fields; nil returns every field.
+	Fields Fields
 }
 
 // MutationParams captures shared song fields used across create and update flows.
@@ -50,6 +104,11 @@ type MutationParams struct {
 	AlbumIDs    []int
 	ArtistIDs   []int
 	WriterIDs   []int
+	// Performance metadata; all optional.
+	BPM             *int
+	TimeSignature   *string
+	DurationSeconds *int
+	Capo            *int
 }
 
 // CreateParams captures the fields required to create a new song record.
@@ -59,40 +118,110 @@ type CreateParams struct {
 }
 
 // UpdateParams captures the fields required to update an existing song record.
+// UserID is the editor; OwnerID limits the update to a song added by that user
+// and is nil for editors allowed to fix any song.
 type UpdateParams struct {
 	MutationParams
-	UserID int
+	UserID  int
+	OwnerID *int
 }
 
-// DeleteParams captures optional constraints for deleting a song.
+// DeleteParams captures optional constraints for deleting a song. UserID limits
+// the deletion to a song added by that user.
 type DeleteParams struct {
 	UserID *int
 }
 
-// ListResult represents a paginated song collection.
+// ListResult represents a paginated song collection. Keyset listings leave Page
+// unset, and Total too unless it was asked for; NextCursor is empty on the last
+// page.
 type ListResult struct {
-	Data    []Song `json:"data"`
-	Page    int    `json:"page"`
-	PerPage int    `json:"per_page"`
-	Total   int    `json:"total"`
+	Data       []Song `json:"data"`
+	Page       int    `json:"page"`
+	PerPage    int    `json:"per_page"`
+	Total      int    `json:"total"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	// Next is the last song of a keyset page that has more after it, set by the
+	// repository.
+	Next *ListCursor `json:"-"`
 }
 
 // Song describes the API payload for a song list item.
 type Song struct {
-	ID          int      `json:"id"`
-	Title       string   `json:"title"`
-	Level       *Level   `json:"level,omitempty"`
-	UserLevelID *int     `json:"user_level_id"`
-	Key         *string  `json:"key,omitempty"`
-	Lyric       *string  `json:"lyric,omitempty"`
-	ReleaseYear *int     `json:"release_year"`
-	Language    Language `json:"language"`
-	Status      string   `json:"status"`
-	Created     *Creator `json:"created,omitempty"`
-	Artists     []Person `json:"artists"`
-	Writers     []Person `json:"writers"`
-	Albums      []Album  `json:"albums"`
-	PlaylistIDs []int    `json:"playlist_ids"`
+	ID    int    `json:"id"`
+	Title string `json:"title"`
+	Level *Level `json:"level,omitempty"`
+	// ConsensusLevel is the community's vote, set once LevelVotes reaches
+	// MinLevelVotes.
+	ConsensusLevel *Level   `json:"consensus_level"`
+	LevelVotes     int      `json:"level_votes"`
+	UserLevelID    *int     `json:"user_level_id"`
+	IsFavorite     bool     `json:"is_favorite"`
+	Key            *string  `json:"key,omitempty"`
+	Lyric          *string  `json:"lyric,omitempty"`
+	ReleaseYear    *int     `json:"release_year"`
+	Language       Language `json:"language"`
+	Status         string   `json:"status"`
+	Created        *Creator `json:"created,omitempty"`
+	Artists        []Person `json:"artists"`
+	Writers        []Person `json:"writers"`
+	Albums         []Album  `json:"albums"`
+	PlaylistIDs    []int    `json:"playlist_ids"`
+	// BPM, TimeSignature, DurationSeconds and Capo describe how the song is played;
+	// AutoScroll is derived from them for the song views.
+	BPM             *int        `json:"bpm"`
+	TimeSignature   *string     `json:"time_signature"`
+	DurationSeconds *int        `json:"duration_seconds"`
+	Capo            *int        `json:"capo"`
+	AutoScroll      *AutoScroll `json:"auto_scroll"`
+	// Arrangement is set when the song is listed as part of a playlist.
+	Arrangement *Arrangement `json:"arrangement,omitempty"`
+	// Contributors are the users whose suggested corrections were accepted,
+	// with their emails masked; only set on a single song.
+	Contributors []Creator `json:"contributors,omitempty"`
+}
+
+// Mutation returns the song's editable fields, to update some of them while
+// keeping the rest.
+func (s Song) Mutation() MutationParams {
+	params := MutationParams{
+		Title:           s.Title,
+		Key:             s.Key,
+		LanguageID:      s.Language.ID,
+		Lyric:           s.Lyric,
+		ReleaseYear:     s.ReleaseYear,
+		AlbumIDs:        make([]int, 0, len(s.Albums)),
+		ArtistIDs:       make([]int, 0, len(s.Artists)),
+		WriterIDs:       make([]int, 0, len(s.Writers)),
+		BPM:             s.BPM,
+		TimeSignature:   s.TimeSignature,
+		DurationSeconds: s.DurationSeconds,
+		Capo:            s.Capo,
+	}
+	if s.Level != nil {
+		params.LevelID = &s.Level.ID
+	}
+	for _, album := range s.Albums {
+		params.AlbumIDs = append(params.AlbumIDs, album.ID)
+	}
+	for _, artist := range s.Artists {
+		params.ArtistIDs = append(params.ArtistIDs, artist.ID)
+	}
+	for _, writer := range s.Writers {
+		params.WriterIDs = append(params.WriterIDs, writer.ID)
+	}
+	return params
+}
+
+// Arrangement is how a playlist performs a song. Transpose moves the sounding key;
+// with a capo the chords are shown as the shapes played above it.
+type Arrangement struct {
+	Transpose   int     `json:"transpose"`
+	Capo        int     `json:"capo"`
+	DisplayMode *string `json:"display_mode"`
+	Note        *string `json:"note"`
+	// OriginalKey is the song key before the arrangement was applied.
+	OriginalKey *string `json:"original_key"`
 }
 
 // Person represents either an artist or writer.
@@ -114,6 +243,26 @@ type Level struct {
 	Name string `json:"name"`
 }
 
+// LevelVotes is the community's difficulty vote on a song.
+type LevelVotes struct {
+	SongID         int    `json:"song_id"`
+	AuthorLevel    *Level `json:"author_level"`
+	ConsensusLevel *Level `json:"consensus_level"`
+	Votes          int    `json:"votes"`
+	MinVotes       int    `json:"min_votes"`
+	// Distribution lists every level with the votes it got; Weight counts
+	// editors' and admins' votes twice.
+	Distribution []LevelVoteCount `json:"distribution"`
+	UserLevelID  *int             `json:"user_level_id"`
+}
+
+// LevelVoteCount is how many users voted a song to be at a level.
+type LevelVoteCount struct {
+	Level
+	Votes  int `json:"votes"`
+	Weight int `json:"weight"`
+}
+
 type Language struct {
 	ID   int    `json:"id"`
 	Name string `json:"name"`
@@ -130,11 +279,26 @@ type Repository interface {
 	List(ctx context.Context, params ListParams) (ListResult, error)
 	Create(ctx context.Context, params CreateParams) (int, error)
 	Get(ctx context.Context, id int) (Song, error)
+	// RecordPlay logs the user's play of the song unless they played it within
+	// the window.
+	RecordPlay(ctx context.Context, songID, userID int, window time.Duration) error
+	// UserMarks returns the user's level vote for the song and whether it is
+	// one of their favourites.
+	UserMarks(ctx context.Context, songID, userID int) (*int, bool, error)
+	Arrangement(ctx context.Context, playlistID, songID int) (Arrangement, error)
+	// CanViewPlaylist reports whether the user owns the playlist or it is
+	// shared with them.
+	CanViewPlaylist(ctx context.Context, playlistID, userID int) (bool, error)
 	Update(ctx context.Context, id int, params UpdateParams) error
 	Delete(ctx context.Context, id int, params DeleteParams) error
+	// AssignLevel records the user's difficulty vote; the author's vote also
+	// sets the song's level.
 	AssignLevel(ctx context.Context, songID, levelID, userID int) error
+	// LevelVotes returns the song's votes per level without the user's vote.
+	LevelVotes(ctx context.Context, songID int) (LevelVotes, error)
 	SyncPlaylists(ctx context.Context, songID, userID int, playlistIDs []int) error
-	UpdateStatus(ctx context.Context, id int, status string, userID int) error
+	UpdateStatus(ctx context.Context, id int, status string, ownerID *int) error
+	LastModified(ctx context.Context) (time.Time, error)
 }
 
 type service struct {
@@ -149,8 +313,54 @@ func NewService(repo Repository) Service {
 func (s *service) List(ctx context.Context, params ListParams) (ListResult, error) {
 	params.Page = pagination.NormalisePage(params.Page)
 	params.PerPage = pagination.NormalisePerPage(params.PerPage)
+	switch params.LevelSource {
+	case "", LevelSourceAuthor, LevelSourceConsensus:
+	default:
+		return ListResult{}, apperror.Validation("msg", map[string]string{"level_source": "level_source must be author or consensus"})
+	}
+	if params.Favorite && (params.AuthenticatedUserID == nil || *params.AuthenticatedUserID <= 0) {
+		return ListResult{}, apperror.Unauthorized("sign in to list favourite songs")
+	}
+	if params.Keyset {
+		if params.IsTrending && params.LevelID != nil {
+			return ListResult{}, apperror.Validation("msg", map[string]string{"cursor": "cursor pagination is not available for trending songs"})
+		}
+		after, err := decodeListCursor(params.Cursor)
+		if err != nil || (after != nil && (after.Position == nil) != (params.PlaylistID == nil)) {
+			return ListResult{}, apperror.Validation("msg", map[string]string{"cursor": "cursor is not valid for this listing"})
+		}
+		params.Page = 0
+		params.After = after
+	}
+	// Playlists and their arrangements are private to their members.
+	if params.PlaylistID != nil {
+		userID := 0
+		if params.AuthenticatedUserID != nil {
+			userID = *params.AuthenticatedUserID
+		}
+		if err := s.checkPlaylist(ctx, *params.PlaylistID, userID); err != nil {
+			return ListResult{}, err
+		}
+	}
 
-	return s.repo.List(ctx, params)
+	result, err := s.repo.List(ctx, params)
+	if err != nil {
+		return result, err
+	}
+	for i := range result.Data {
+		applyArrangement(&result.Data[i])
+		if params.Fields.Has(FieldAutoScroll) {
+			result.Data[i].AutoScroll = ComputeAutoScroll(result.Data[i])
+		}
+		if !params.Fields.Has(FieldLyric) {
+			// The lyric may have been read only to pace the auto scroll.
+			result.Data[i].Lyric = nil
+		}
+	}
+	if result.Next != nil {
+		result.NextCursor = result.Next.encode()
+	}
+	return result, nil
 }
 
 func (s *service) Create(ctx context.Context, params CreateParams) (int, error) {
@@ -170,7 +380,88 @@ func (s *service) Get(ctx context.Context, id int) (Song, error) {
 	if id <= 0 {
 		return Song{}, apperror.NotFound("song not found")
 	}
-	return s.repo.Get(ctx, id)
+	song, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return Song{}, err
+	}
+	song.AutoScroll = ComputeAutoScroll(song)
+	return song, nil
+}
+
+// Open returns a song with its related data and the viewer's own marks.
+func (s *service) Open(ctx context.Context, id, userID int) (Song, error) {
+	song, err := s.Get(ctx, id)
+	if err != nil {
+		return Song{}, err
+	}
+	if userID > 0 {
+		if song.UserLevelID, song.IsFavorite, err = s.repo.UserMarks(ctx, id, userID); err != nil {
+			return Song{}, err
+		}
+	}
+	return song, nil
+}
+
+// RecordPlay logs a signed-in user's play of the song.
+func (s *service) RecordPlay(ctx context.Context, id, userID int) error {
+	if userID <= 0 {
+		return apperror.Unauthorized("sign in to record plays")
+	}
+	if id <= 0 {
+		return apperror.NotFound("song not found")
+	}
+	return s.repo.RecordPlay(ctx, id, userID, PlayWindow)
+}
+
+// GetInPlaylist returns a song rendered with the arrangement its playlist uses.
+func (s *service) GetInPlaylist(ctx context.Context, id, playlistID, userID int) (Song, error) {
+	if err := s.checkPlaylist(ctx, playlistID, userID); err != nil {
+		return Song{}, err
+	}
+	song, err := s.Get(ctx, id)
+	if err != nil {
+		return Song{}, err
+	}
+	arrangement, err := s.repo.Arrangement(ctx, playlistID, id)
+	if err != nil {
+		return Song{}, err
+	}
+	song.Arrangement = &arrangement
+	applyArrangement(&song)
+	return song, nil
+}
+
+// checkPlaylist reports a playlist the user cannot see as not found, so its
+// existence is not revealed either.
+func (s *service) checkPlaylist(ctx context.Context, playlistID, userID int) error {
+	if playlistID <= 0 || userID <= 0 {
+		return apperror.NotFound("playlist not found")
+	}
+	ok, err := s.repo.CanViewPlaylist(ctx, playlistID, userID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return apperror.NotFound("playlist not found")
+	}
+	return nil
+}
+
+// applyArrangement rewrites the key and lyric chords for the song's arrangement.
+func applyArrangement(song *Song) {
+	if song.Arrangement == nil {
+		return
+	}
+	song.Arrangement.OriginalKey = song.Key
+	if song.Key != nil {
+		if key, err := chordtheory.Transpose(*song.Key, song.Arrangement.Transpose); err == nil {
+			song.Key = &key
+		}
+	}
+	if song.Lyric != nil {
+		lyric := chordtheory.TransposeLyric(*song.Lyric, song.Arrangement.Transpose-song.Arrangement.Capo)
+		song.Lyric = &lyric
+	}
 }
 
 // Update applies new values to an existing song.
@@ -180,6 +471,10 @@ func (s *service) Update(ctx context.Context, id int, params UpdateParams) error
 		return apperror.NotFound("song not found")
 	}
 
+	if params.OwnerID != nil && *params.OwnerID <= 0 {
+		return apperror.Unauthorized("unauthorized user")
+	}
+
 	if err := normaliseMutation(&params.MutationParams); err != nil {
 		return err
 	}
@@ -187,6 +482,10 @@ func (s *service) Update(ctx context.Context, id int, params UpdateParams) error
 	return s.repo.Update(ctx, id, params)
 }
 
+func (s *service) LastModified(ctx context.Context) (time.Time, error) {
+	return s.repo.LastModified(ctx)
+}
+
 // Delete removes a song record.
 func (s *service) Delete(ctx context.Context, id int, params DeleteParams) error {
 	if id <= 0 {
@@ -198,7 +497,7 @@ func (s *service) Delete(ctx context.Context, id int, params DeleteParams) error
 	return s.repo.Delete(ctx, id, params)
 }
 
-// AssignLevel updates the associated level for a song.
+// AssignLevel records the user's difficulty vote for a song.
 func (s *service) AssignLevel(ctx context.Context, songID, levelID, userID int) error {
 	if songID <= 0 {
 		return apperror.NotFound("song not found")
@@ -214,6 +513,24 @@ func (s *service) AssignLevel(ctx context.Context, songID, levelID, userID int)
 	return s.repo.AssignLevel(ctx, songID, levelID, userID)
 }
 
+// LevelVotes returns the song's difficulty votes per level.
+func (s *service) LevelVotes(ctx context.Context, songID, userID int) (LevelVotes, error) {
+	if songID <= 0 {
+		return LevelVotes{}, apperror.NotFound("song not found")
+	}
+	votes, err := s.repo.LevelVotes(ctx, songID)
+	if err != nil {
+		return LevelVotes{}, err
+	}
+	votes.MinVotes = MinLevelVotes
+	if userID > 0 {
+		if votes.UserLevelID, _, err = s.repo.UserMarks(ctx, songID, userID); err != nil {
+			return LevelVotes{}, err
+		}
+	}
+	return votes, nil
+}
+
 // SyncPlaylists replaces the playlists associated with a song for the given user.
 func (s *service) SyncPlaylists(ctx context.Context, songID, userID int, playlistIDs []int) error {
 	if songID <= 0 {
@@ -235,22 +552,30 @@ func (s *service) SyncPlaylists(ctx context.Context, songID, userID int, playlis
 	return s.repo.SyncPlaylists(ctx, songID, userID, filtered)
 }
 
-// UpdateStatus changes the workflow status for the specified song.
-func (s *service) UpdateStatus(ctx context.Context, id int, status string, userID int) error {
+// UpdateStatus changes the workflow status for the specified song. Owners move
+// their songs between created and pending; approving or declining is left to
+// reviewers, who pass a nil ownerID.
+func (s *service) UpdateStatus(ctx context.Context, id int, status string, ownerID *int) error {
 	if id <= 0 {
 		return apperror.NotFound("song not found")
 	}
 
-	if userID <= 0 {
+	if ownerID != nil && *ownerID <= 0 {
 		return apperror.Unauthorized("unauthorized user")
 	}
 
 	normalised := strings.ToLower(strings.TrimSpace(status))
-	if normalised != "created" && normalised != "pending" {
+	switch normalised {
+	case StatusCreated, StatusPending:
+	case StatusApproved, StatusDeclined:
+		if ownerID != nil {
+			return apperror.Forbidden("only reviewers can approve or decline songs")
+		}
+	default:
 		return apperror.BadRequest("invalid status option")
 	}
 
-	return s.repo.UpdateStatus(ctx, id, normalised, userID)
+	return s.repo.UpdateStatus(ctx, id, normalised, ownerID)
 }
 
 func normaliseMutation(params *MutationParams) error {
@@ -292,6 +617,8 @@ func normaliseMutation(params *MutationParams) error {
 		}
 	}
 
+	normalisePerformance(params, ve)
+
 	params.ArtistIDs = uniquePositive(params.ArtistIDs)
 	params.WriterIDs = uniquePositive(params.WriterIDs)
 	params.AlbumIDs = uniquePositive(params.AlbumIDs)
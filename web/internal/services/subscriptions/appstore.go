@@ -0,0 +1,171 @@
+package subscriptions
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/lyricapp/lyric/web/internal/apperror"
+)
+
+const (
+	appStoreProductionURL = "https://buy.itunes.apple.com/verifyReceipt"
+	appStoreSandboxURL    = "https://sandbox.itunes.apple.com/verifyReceipt"
+
+	// appStoreStatusSandboxReceipt is returned when a TestFlight or sandbox receipt
+	// reaches the production endpoint.
+	appStoreStatusSandboxReceipt = 21007
+)
+
+// AppStoreSettings configures receipt verification with Apple.
+type AppStoreSettings struct {
+	SharedSecret string
+	Client       *http.Client
+}
+
+type appStoreProvider struct {
+	sharedSecret  string
+	client        *http.Client
+	productionURL string
+	sandboxURL    string
+}
+
+// NewAppStoreProvider verifies App Store receipts, retrying sandbox receipts
+// against Apple's sandbox endpoint.
+func NewAppStoreProvider(settings AppStoreSettings) Provider {
+	client := settings.Client
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &appStoreProvider{
+		sharedSecret:  settings.SharedSecret,
+		client:        client,
+		productionURL: appStoreProductionURL,
+		sandboxURL:    appStoreSandboxURL,
+	}
+}
+
+func (p *appStoreProvider) Name() ProviderName {
+	return ProviderAppStore
+}
+
+type appStoreTransaction struct {
+	ProductID             string `json:"product_id"`
+	TransactionID         string `json:"transaction_id"`
+	OriginalTransactionID string `json:"original_transaction_id"`
+	PurchaseDateMs        string `json:"purchase_date_ms"`
+	ExpiresDateMs         string `json:"expires_date_ms"`
+	CancellationDateMs    string `json:"cancellation_date_ms"`
+}
+
+type appStoreResponse struct {
+	Status            int                   `json:"status"`
+	LatestReceiptInfo []appStoreTransaction `json:"latest_receipt_info"`
+	Receipt           struct {
+		InApp []appStoreTransaction `json:"in_app"`
+	} `json:"receipt"`
+}
+
+func (p *appStoreProvider) Verify(ctx context.Context, product Product, receipt string) (Purchase, error) {
+	response, err := p.post(ctx, p.productionURL, receipt)
+	if err != nil {
+		return Purchase{}, err
+	}
+	if response.Status == appStoreStatusSandboxReceipt {
+		if response, err = p.post(ctx, p.sandboxURL, receipt); err != nil {
+			return Purchase{}, err
+		}
+	}
+	if response.Status != 0 {
+		return Purchase{}, apperror.Validation("msg", map[string]string{
+			"receipt": fmt.Sprintf("receipt could not be verified (status %d)", response.Status),
+		})
+	}
+
+	// Renewals are listed in latest_receipt_info; consumable unlocks only in the receipt itself.
+	var latest *appStoreTransaction
+	var latestAt time.Time
+	for _, list := range [][]appStoreTransaction{response.LatestReceiptInfo, response.Receipt.InApp} {
+		for i := range list {
+			transaction := list[i]
+			if transaction.ProductID != product.ID || transaction.CancellationDateMs != "" {
+				continue
+			}
+			at := parseMillis(transaction.ExpiresDateMs)
+			if at == nil {
+				at = parseMillis(transaction.PurchaseDateMs)
+			}
+			if at != nil && (latest == nil || at.After(latestAt)) {
+				latest, latestAt = &transaction, *at
+			}
+		}
+	}
+	if latest == nil {
+		return Purchase{}, apperror.Validation("msg", map[string]string{"receipt": "receipt does not contain the product"})
+	}
+
+	transactionID := latest.TransactionID
+	if product.Kind == KindSubscription && latest.OriginalTransactionID != "" {
+		transactionID = latest.OriginalTransactionID
+	}
+
+	purchase := Purchase{
+		TransactionID: transactionID,
+		ProductID:     latest.ProductID,
+		ExpiresAt:     parseMillis(latest.ExpiresDateMs),
+	}
+	if purchasedAt := parseMillis(latest.PurchaseDateMs); purchasedAt != nil {
+		purchase.PurchasedAt = *purchasedAt
+	}
+	return purchase, nil
+}
+
+func (p *appStoreProvider) post(ctx context.Context, url, receipt string) (appStoreResponse, error) {
+	body, err := json.Marshal(map[string]any{
+		"receipt-data":             receipt,
+		"password":                 p.sharedSecret,
+		"exclude-old-transactions": true,
+	})
+	if err != nil {
+		return appStoreResponse{}, fmt.Errorf("app store: encode request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return appStoreResponse{}, fmt.Errorf("app store: build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := p.client.Do(req)
+	if err != nil {
+		return appStoreResponse{}, apperror.Internal("app store is unavailable", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return appStoreResponse{}, apperror.Internal("app store is unavailable", fmt.Errorf("verifyReceipt returned %s", res.Status))
+	}
+
+	var decoded appStoreResponse
+	if err := json.NewDecoder(res.Body).Decode(&decoded); err != nil {
+		return appStoreResponse{}, fmt.Errorf("app store: decode response: %w", err)
+	}
+	return decoded, nil
+}
+
+// parseMillis converts the stores' string millisecond timestamps.
+func parseMillis(value string) *time.Time {
+	if value == "" {
+		return nil
+	}
+	ms, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || ms <= 0 {
+		return nil
+	}
+	t := time.UnixMilli(ms)
+	return &t
+}
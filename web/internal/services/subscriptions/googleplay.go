@@ -0,0 +1,263 @@
+package subscriptions
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lyricapp/lyric/web/internal/apperror"
+)
+
+const (
+	googlePlayAPIURL     = "https://androidpublisher.googleapis.com/androidpublisher/v3/applications"
+	googleTokenURL       = "https://oauth2.googleapis.com/token"
+	googlePublisherScope = "https://www.googleapis.com/auth/androidpublisher"
+)
+
+// GooglePlaySettings configures purchase verification with the Play Developer API.
+// CredentialsJSON is the service account key downloaded from the Cloud console.
+type GooglePlaySettings struct {
+	PackageName     string
+	CredentialsJSON []byte
+	Client          *http.Client
+}
+
+type googleServiceAccount struct {
+	ClientEmail string `json:"client_email"`
+	PrivateKey  string `json:"private_key"`
+	TokenURI    string `json:"token_uri"`
+}
+
+type googlePlayProvider struct {
+	packageName string
+	client      *http.Client
+	apiURL      string
+	email       string
+	key         *rsa.PrivateKey
+	tokenURL    string
+
+	mu          sync.Mutex
+	accessToken string
+	tokenExpiry time.Time
+}
+
+// NewGooglePlayProvider verifies Play purchase tokens using a service account.
+func NewGooglePlayProvider(settings GooglePlaySettings) (Provider, error) {
+	var account googleServiceAccount
+	if err := json.Unmarshal(settings.CredentialsJSON, &account); err != nil {
+		return nil, fmt.Errorf("google play: decode credentials: %w", err)
+	}
+	block, _ := pem.Decode([]byte(account.PrivateKey))
+	if block == nil {
+		return nil, errors.New("google play: credentials contain no private key")
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("google play: parse private key: %w", err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("google play: private key is not RSA")
+	}
+
+	tokenURL := account.TokenURI
+	if tokenURL == "" {
+		tokenURL = googleTokenURL
+	}
+	client := settings.Client
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	return &googlePlayProvider{
+		packageName: settings.PackageName,
+		client:      client,
+		apiURL:      googlePlayAPIURL,
+		email:       account.ClientEmail,
+		key:         key,
+		tokenURL:    tokenURL,
+	}, nil
+}
+
+func (p *googlePlayProvider) Name() ProviderName {
+	return ProviderGooglePlay
+}
+
+type googleSubscriptionPurchase struct {
+	StartTimeMillis  string `json:"startTimeMillis"`
+	ExpiryTimeMillis string `json:"expiryTimeMillis"`
+	PaymentState     *int   `json:"paymentState"`
+}
+
+type googleProductPurchase struct {
+	OrderID            string `json:"orderId"`
+	PurchaseTimeMillis string `json:"purchaseTimeMillis"`
+	PurchaseState      int    `json:"purchaseState"`
+}
+
+// Verify treats the receipt as a purchase token. Subscription tokens persist across
+// renewals, so they identify the stored period; one-time products use their order id.
+func (p *googlePlayProvider) Verify(ctx context.Context, product Product, receipt string) (Purchase, error) {
+	kind := "products"
+	if product.Kind == KindSubscription {
+		kind = "subscriptions"
+	}
+	endpoint := fmt.Sprintf("%s/%s/purchases/%s/%s/tokens/%s",
+		p.apiURL, url.PathEscape(p.packageName), kind, url.PathEscape(product.ID), url.PathEscape(receipt))
+
+	if product.Kind == KindSubscription {
+		var purchase googleSubscriptionPurchase
+		if err := p.get(ctx, endpoint, &purchase); err != nil {
+			return Purchase{}, err
+		}
+		// paymentState 0 means payment is still pending.
+		if purchase.PaymentState != nil && *purchase.PaymentState == 0 {
+			return Purchase{}, apperror.Validation("msg", map[string]string{"receipt": "payment is pending"})
+		}
+		verified := Purchase{
+			TransactionID: receipt,
+			ProductID:     product.ID,
+			ExpiresAt:     parseMillis(purchase.ExpiryTimeMillis),
+		}
+		if startedAt := parseMillis(purchase.StartTimeMillis); startedAt != nil {
+			verified.PurchasedAt = *startedAt
+		}
+		return verified, nil
+	}
+
+	var purchase googleProductPurchase
+	if err := p.get(ctx, endpoint, &purchase); err != nil {
+		return Purchase{}, err
+	}
+	// purchaseState 0 is purchased; 1 canceled, 2 pending.
+	if purchase.PurchaseState != 0 {
+		return Purchase{}, apperror.Validation("msg", map[string]string{"receipt": "purchase is not completed"})
+	}
+	verified := Purchase{
+		TransactionID: purchase.OrderID,
+		ProductID:     product.ID,
+	}
+	if verified.TransactionID == "" {
+		verified.TransactionID = receipt
+	}
+	if purchasedAt := parseMillis(purchase.PurchaseTimeMillis); purchasedAt != nil {
+		verified.PurchasedAt = *purchasedAt
+	}
+	return verified, nil
+}
+
+func (p *googlePlayProvider) get(ctx context.Context, endpoint string, dest any) error {
+	token, err := p.token(ctx)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return fmt.Errorf("google play: build request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	res, err := p.client.Do(req)
+	if err != nil {
+		return apperror.Internal("google play is unavailable", err)
+	}
+	defer res.Body.Close()
+
+	switch {
+	case res.StatusCode == http.StatusNotFound || res.StatusCode == http.StatusBadRequest:
+		return apperror.Validation("msg", map[string]string{"receipt": "receipt could not be verified"})
+	case res.StatusCode != http.StatusOK:
+		return apperror.Internal("google play is unavailable", fmt.Errorf("purchases api returned %s", res.Status))
+	}
+
+	if err := json.NewDecoder(res.Body).Decode(dest); err != nil {
+		return fmt.Errorf("google play: decode response: %w", err)
+	}
+	return nil
+}
+
+// token exchanges a signed service account assertion for an access token, caching
+// it until shortly before it expires.
+func (p *googlePlayProvider) token(ctx context.Context) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.accessToken != "" && time.Now().Before(p.tokenExpiry) {
+		return p.accessToken, nil
+	}
+
+	assertion, err := p.assertion(time.Now())
+	if err != nil {
+		return "", err
+	}
+	form := url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {assertion},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("google play: build token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	res, err := p.client.Do(req)
+	if err != nil {
+		return "", apperror.Internal("google play is unavailable", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return "", apperror.Internal("google play is unavailable", fmt.Errorf("token endpoint returned %s", res.Status))
+	}
+
+	var decoded struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&decoded); err != nil {
+		return "", fmt.Errorf("google play: decode token: %w", err)
+	}
+
+	p.accessToken = decoded.AccessToken
+	p.tokenExpiry = time.Now().Add(time.Duration(decoded.ExpiresIn)*time.Second - time.Minute)
+	return p.accessToken, nil
+}
+
+func (p *googlePlayProvider) assertion(now time.Time) (string, error) {
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	if err != nil {
+		return "", err
+	}
+	claims, err := json.Marshal(map[string]any{
+		"iss":   p.email,
+		"scope": googlePublisherScope,
+		"aud":   p.tokenURL,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	encoding := base64.RawURLEncoding
+	unsigned := encoding.EncodeToString(header) + "." + encoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(unsigned))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, p.key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("google play: sign assertion: %w", err)
+	}
+	return unsigned + "." + encoding.EncodeToString(signature), nil
+}
@@ -0,0 +1,221 @@
+package subscriptions
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lyricapp/lyric/web/internal/apperror"
+	plansvc "github.com/lyricapp/lyric/web/internal/services/plans"
+)
+
+// ProviderName identifies a store that issues purchase receipts.
+type ProviderName string
+
+const (
+	ProviderAppStore   ProviderName = "app_store"
+	ProviderGooglePlay ProviderName = "google_play"
+	ProviderStub       ProviderName = "stub"
+)
+
+// Kind distinguishes renewing subscriptions from one-time, time-boxed unlocks.
+type Kind string
+
+const (
+	KindSubscription Kind = "subscription"
+	KindUnlock       Kind = "unlock"
+)
+
+// Product identifiers sold in the apps.
+const (
+	ProductPremiumMonthly = "premium_monthly"
+	ProductPremiumAnnual  = "premium_annual"
+	ProductUnlockHourly   = "unlock_3h"
+)
+
+// Product describes a store product. Period is used when the store does not
+// report an expiry itself, which is always the case for unlocks.
+type Product struct {
+	ID     string
+	Kind   Kind
+	Period time.Duration
+}
+
+// DefaultCatalog lists the products configured in App Store Connect and Play Console.
+func DefaultCatalog() map[string]Product {
+	return map[string]Product{
+		ProductPremiumMonthly: {ID: ProductPremiumMonthly, Kind: KindSubscription, Period: 31 * 24 * time.Hour},
+		ProductPremiumAnnual:  {ID: ProductPremiumAnnual, Kind: KindSubscription, Period: 366 * 24 * time.Hour},
+		ProductUnlockHourly:   {ID: ProductUnlockHourly, Kind: KindUnlock, Period: 3 * time.Hour},
+	}
+}
+
+// Purchase is what a provider learned from a verified receipt. TransactionID must
+// stay stable across renewals so a renewed subscription updates its period.
+type Purchase struct {
+	TransactionID string
+	ProductID     string
+	PurchasedAt   time.Time
+	ExpiresAt     *time.Time
+}
+
+// Provider verifies receipts with a store.
+type Provider interface {
+	Name() ProviderName
+	Verify(ctx context.Context, product Product, receipt string) (Purchase, error)
+}
+
+// Entitlement is a stored period of premium access.
+type Entitlement struct {
+	ID        int          `json:"id"`
+	Provider  ProviderName `json:"provider"`
+	ProductID string       `json:"product_id"`
+	Kind      Kind         `json:"kind"`
+	StartsAt  time.Time    `json:"starts_at"`
+	ExpiresAt time.Time    `json:"expires_at"`
+}
+
+// Entitlements summarises what the user can currently access.
+type Entitlements struct {
+	Plan          plansvc.Plan   `json:"plan"`
+	Limits        plansvc.Limits `json:"limits"`
+	ActiveUntil   *time.Time     `json:"active_until"`
+	Subscriptions []Entitlement  `json:"subscriptions"`
+	Unlocks       []Entitlement  `json:"unlocks"`
+}
+
+// VerifyParams carries a receipt submitted by the app.
+type VerifyParams struct {
+	UserID    int
+	Provider  ProviderName
+	ProductID string
+	Receipt   string
+}
+
+// Service verifies purchases and reports entitlements.
+type Service interface {
+	Verify(ctx context.Context, params VerifyParams) (Entitlements, error)
+	Entitlements(ctx context.Context, userID int) (Entitlements, error)
+}
+
+// Repository persists entitlement periods.
+type Repository interface {
+	Upsert(ctx context.Context, userID int, transactionID string, entitlement Entitlement) (Entitlement, error)
+	Active(ctx context.Context, userID int, at time.Time) ([]Entitlement, error)
+}
+
+type service struct {
+	repo      Repository
+	plans     plansvc.Service
+	providers map[ProviderName]Provider
+	catalog   map[string]Product
+	now       func() time.Time
+}
+
+// NewService constructs a subscription service for the given store providers.
+func NewService(repo Repository, plans plansvc.Service, providers ...Provider) Service {
+	byName := make(map[ProviderName]Provider, len(providers))
+	for _, provider := range providers {
+		byName[provider.Name()] = provider
+	}
+	return &service{
+		repo:      repo,
+		plans:     plans,
+		providers: byName,
+		catalog:   DefaultCatalog(),
+		now:       time.Now,
+	}
+}
+
+// Verify checks the receipt with its store and records the resulting access period.
+func (s *service) Verify(ctx context.Context, params VerifyParams) (Entitlements, error) {
+	if params.UserID <= 0 {
+		return Entitlements{}, apperror.Unauthorized("Unauthorized user")
+	}
+
+	errorsMap := map[string]string{}
+	provider, ok := s.providers[params.Provider]
+	if !ok {
+		errorsMap["provider"] = "provider is not supported"
+	}
+	product, ok := s.catalog[strings.TrimSpace(params.ProductID)]
+	if !ok {
+		errorsMap["product_id"] = "product_id is unknown"
+	}
+	receipt := strings.TrimSpace(params.Receipt)
+	if receipt == "" {
+		errorsMap["receipt"] = "receipt is required"
+	}
+	if len(errorsMap) > 0 {
+		return Entitlements{}, apperror.Validation("msg", errorsMap)
+	}
+
+	purchase, err := provider.Verify(ctx, product, receipt)
+	if err != nil {
+		return Entitlements{}, err
+	}
+	if purchase.ProductID != "" && purchase.ProductID != product.ID {
+		return Entitlements{}, apperror.Validation("msg", map[string]string{"product_id": "receipt is for a different product"})
+	}
+
+	startsAt := purchase.PurchasedAt
+	if startsAt.IsZero() {
+		startsAt = s.now()
+	}
+	expiresAt := startsAt.Add(product.Period)
+	if product.Kind == KindSubscription && purchase.ExpiresAt != nil {
+		expiresAt = *purchase.ExpiresAt
+	}
+	if !expiresAt.After(s.now()) {
+		return Entitlements{}, apperror.Validation("msg", map[string]string{"receipt": "purchase has expired"})
+	}
+
+	if _, err := s.repo.Upsert(ctx, params.UserID, purchase.TransactionID, Entitlement{
+		Provider:  provider.Name(),
+		ProductID: product.ID,
+		Kind:      product.Kind,
+		StartsAt:  startsAt,
+		ExpiresAt: expiresAt,
+	}); err != nil {
+		return Entitlements{}, err
+	}
+
+	return s.Entitlements(ctx, params.UserID)
+}
+
+// Entitlements returns the user's plan with the subscriptions and unlocks active now.
+func (s *service) Entitlements(ctx context.Context, userID int) (Entitlements, error) {
+	plan, limits, err := s.plans.Limits(ctx, userID)
+	if err != nil {
+		return Entitlements{}, err
+	}
+
+	active, err := s.repo.Active(ctx, userID, s.now())
+	if err != nil {
+		return Entitlements{}, err
+	}
+
+	result := Entitlements{
+		Plan:          plan,
+		Limits:        limits,
+		Subscriptions: make([]Entitlement, 0),
+		Unlocks:       make([]Entitlement, 0),
+	}
+	for _, entitlement := range active {
+		if entitlement.Kind == KindUnlock {
+			result.Unlocks = append(result.Unlocks, entitlement)
+		} else {
+			result.Subscriptions = append(result.Subscriptions, entitlement)
+		}
+		if result.ActiveUntil == nil || entitlement.ExpiresAt.After(*result.ActiveUntil) {
+			expiresAt := entitlement.ExpiresAt
+			result.ActiveUntil = &expiresAt
+		}
+	}
+	sort.SliceStable(result.Unlocks, func(i, j int) bool {
+		return result.Unlocks[i].ExpiresAt.Before(result.Unlocks[j].ExpiresAt)
+	})
+
+	return result, nil
+}
@@ -0,0 +1,38 @@
+package subscriptions
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/lyricapp/lyric/web/internal/apperror"
+)
+
+// StubReceiptPrefix marks receipts the stub provider accepts, e.g. "stub:order-1".
+const StubReceiptPrefix = "stub:"
+
+type stubProvider struct {
+	now func() time.Time
+}
+
+// NewStubProvider accepts any receipt starting with StubReceiptPrefix as a purchase
+// made just now. It stands in for the real stores in tests and local development.
+func NewStubProvider() Provider {
+	return &stubProvider{now: time.Now}
+}
+
+func (p *stubProvider) Name() ProviderName {
+	return ProviderStub
+}
+
+func (p *stubProvider) Verify(_ context.Context, product Product, receipt string) (Purchase, error) {
+	transactionID, ok := strings.CutPrefix(receipt, StubReceiptPrefix)
+	if !ok || transactionID == "" {
+		return Purchase{}, apperror.Validation("msg", map[string]string{"receipt": "receipt could not be verified"})
+	}
+	return Purchase{
+		TransactionID: transactionID,
+		ProductID:     product.ID,
+		PurchasedAt:   p.now(),
+	}, nil
+}
@@ -0,0 +1,68 @@
+package suggestions_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/lyricapp/lyric/web/internal/services/suggestions"
+)
+
+func TestDiff(t *testing.T) {
+	eq := func(text string) suggestions.DiffLine {
+		return suggestions.DiffLine{Op: suggestions.DiffEqual, Text: text}
+	}
+	ins := func(text string) suggestions.DiffLine {
+		return suggestions.DiffLine{Op: suggestions.DiffInsert, Text: text}
+	}
+	del := func(text string) suggestions.DiffLine {
+		return suggestions.DiffLine{Op: suggestions.DiffDelete, Text: text}
+	}
+
+	tests := []struct {
+		name     string
+		from, to string
+		want     []suggestions.DiffLine
+	}{
+		{
+			name: "unchanged",
+			from: "[G]Amazing grace\n[C]How sweet the sound\n",
+			to:   "[G]Amazing grace\r\n[C]How sweet the sound",
+			want: []suggestions.DiffLine{eq("[G]Amazing grace"), eq("[C]How sweet the sound")},
+		},
+		{
+			name: "changed chord",
+			from: "[G]Amazing grace\n[C]How sweet the sound\n[G]That saved a wretch",
+			to:   "[G]Amazing grace\n[G7]How sweet the sound\n[G]That saved a wretch",
+			want: []suggestions.DiffLine{
+				eq("[G]Amazing grace"),
+				del("[C]How sweet the sound"),
+				ins("[G7]How sweet the sound"),
+				eq("[G]That saved a wretch"),
+			},
+		},
+		{
+			name: "added and removed lines",
+			from: "intro\nverse\nchorus",
+			to:   "verse\nbridge\nchorus\noutro",
+			want: []suggestions.DiffLine{del("intro"), eq("verse"), ins("bridge"), eq("chorus"), ins("outro")},
+		},
+		{
+			name: "from nothing",
+			from: "",
+			to:   "verse",
+			want: []suggestions.DiffLine{ins("verse")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// when
+			got := suggestions.Diff(tt.from, tt.to)
+
+			// then
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package suggestions
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	loginsvc "github.com/lyricapp/lyric/web/internal/services/login"
+)
+
+type consoleNotifier struct {
+	from string
+}
+
+// NewConsoleNotifier logs rejection notices instead of sending emails.
+func NewConsoleNotifier(from string) Notifier {
+	return &consoleNotifier{from: from}
+}
+
+func (n *consoleNotifier) SuggestionRejected(_ context.Context, email, songTitle, _ string) error {
+	from := n.from
+	if strings.TrimSpace(from) == "" {
+		from = "no-reply@localhost"
+	}
+	log.Printf("[mailer] suggestion rejected song=%s to=%s from=%s", songTitle, email, from)
+	return nil
+}
+
+type smtpNotifier struct {
+	settings loginsvc.SMTPSettings
+}
+
+// NewSMTPNotifier emails suggesters through the same relay used for login codes.
+func NewSMTPNotifier(settings loginsvc.SMTPSettings) Notifier {
+	return &smtpNotifier{settings: settings}
+}
+
+func (n *smtpNotifier) SuggestionRejected(_ context.Context, email, songTitle, note string) error {
+	if strings.TrimSpace(email) == "" {
+		return nil
+	}
+
+	body := fmt.Sprintf("Thanks for suggesting a correction to %s. It was reviewed and not applied this time.", songTitle)
+	if note = strings.TrimSpace(note); note != "" {
+		body += "\r\n\r\nThe reviewer said: " + note
+	}
+	if err := n.settings.Send(email, fmt.Sprintf("Your suggestion for %s", songTitle), body); err != nil {
+		return fmt.Errorf("smtp notifier: %w", err)
+	}
+	return nil
+}
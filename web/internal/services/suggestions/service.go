@@ -0,0 +1,387 @@
+package suggestions
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/lyricapp/lyric/web/internal/apperror"
+	songsvc "github.com/lyricapp/lyric/web/internal/services/songs"
+)
+
+// Suggestion statuses.
+const (
+	StatusPending  = "pending"
+	StatusAccepted = "accepted"
+	StatusRejected = "rejected"
+)
+
+// MaxCommentLength caps the suggester's comment and the reviewer's note.
+const MaxCommentLength = 1000
+
+// Service lets any signed-in user propose a corrected lyric for a song, and
+// the song's owner or an editor accept or reject it. Reviewers pass the
+// owner scope of the songs API: nil for editors, who may review suggestions
+// on any song.
+type Service interface {
+	Create(ctx context.Context, params CreateParams) (Suggestion, error)
+	// List returns the song's suggestions, newest first.
+	List(ctx context.Context, params ListParams) ([]Suggestion, error)
+	// Get returns a suggestion with its diff to the lyric it would replace.
+	// Suggesters may read their own suggestions as well as reviewers.
+	Get(ctx context.Context, id, userID int, ownerID *int) (Suggestion, error)
+	// Accept applies the suggested lyric to the song and credits the
+	// suggester as a contributor.
+	Accept(ctx context.Context, id int, params ReviewParams) (Suggestion, error)
+	// Reject closes the suggestion and emails the suggester the reviewer's
+	// note.
+	Reject(ctx context.Context, id int, params ReviewParams) (Suggestion, error)
+}
+
+// CreateParams captures a proposed lyric for a song.
+type CreateParams struct {
+	SongID  int
+	UserID  int
+	Lyric   string
+	Comment string
+}
+
+// ListParams selects the suggestions of a song. Status is empty for every
+// status; OwnerID limits the listing to a song added by that user.
+type ListParams struct {
+	SongID  int
+	Status  string
+	UserID  int
+	OwnerID *int
+}
+
+// ReviewParams identifies the reviewer. UserID is the reviewer; OwnerID is
+// nil for editors allowed to review suggestions on any song.
+type ReviewParams struct {
+	UserID  int
+	OwnerID *int
+	Note    string
+}
+
+// Suggestion is a proposed lyric. List leaves out Lyric and Diff.
+type Suggestion struct {
+	ID        int    `json:"id"`
+	SongID    int    `json:"song_id"`
+	SongTitle string `json:"song_title"`
+	UserID    int    `json:"user_id"`
+	Comment   string `json:"comment"`
+	Status    string `json:"status"`
+	Lyric     string `json:"lyric,omitempty"`
+	// Diff compares a pending suggestion with the song's current lyric and a
+	// reviewed one with the lyric it was made against.
+	Diff []DiffLine `json:"diff,omitempty"`
+	// Stale reports that the song's lyric changed after the suggestion was
+	// made; accepting it replaces those changes too.
+	Stale      bool       `json:"stale"`
+	ReviewedBy *int       `json:"reviewed_by"`
+	ReviewNote *string    `json:"review_note"`
+	ReviewedAt *time.Time `json:"reviewed_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+
+	// BaseLyric is the song's lyric when the suggestion was made.
+	BaseLyric *string `json:"-"`
+	// SongOwnerID is the user who added the song.
+	SongOwnerID *int `json:"-"`
+	// Email is the suggester's address while their account is active.
+	Email string `json:"-"`
+}
+
+// Repository persists suggestions.
+type Repository interface {
+	Create(ctx context.Context, params CreateParams, baseLyric *string) (int, error)
+	// Get reports false when there is no such suggestion.
+	Get(ctx context.Context, id int) (Suggestion, bool, error)
+	List(ctx context.Context, songID int, status string) ([]Suggestion, error)
+	// Resolve reviews a pending suggestion, reporting false when it was no
+	// longer pending.
+	Resolve(ctx context.Context, id int, status string, reviewerID int, note *string) (bool, error)
+	MarkNotified(ctx context.Context, id int) error
+}
+
+// Songs is the part of the songs service suggestions are applied through.
+type Songs interface {
+	Get(ctx context.Context, id int) (songsvc.Song, error)
+	Update(ctx context.Context, id int, params songsvc.UpdateParams) error
+}
+
+// Tx runs fn with a repository and songs service bound to one transaction,
+// committing when fn returns nil and rolling both back otherwise.
+type Tx func(ctx context.Context, fn func(repo Repository, songs Songs) error) error
+
+// Notifier tells suggesters their suggestion was rejected.
+type Notifier interface {
+	SuggestionRejected(ctx context.Context, email, songTitle, note string) error
+}
+
+type service struct {
+	repo     Repository
+	songs    Songs
+	notifier Notifier
+	tx       Tx
+}
+
+// NewService constructs a suggestion service. Accepting a suggestion runs
+// through tx, which should bind the same songs service as songs.
+func NewService(repo Repository, songs Songs, notifier Notifier, tx Tx) Service {
+	return &service{repo: repo, songs: songs, notifier: notifier, tx: tx}
+}
+
+// Create records a suggested lyric against the song's current one.
+func (s *service) Create(ctx context.Context, params CreateParams) (Suggestion, error) {
+	if params.UserID <= 0 {
+		return Suggestion{}, apperror.Unauthorized("Unauthorized user")
+	}
+	if params.SongID <= 0 {
+		return Suggestion{}, apperror.NotFound("song not found")
+	}
+
+	ve := map[string]string{}
+	if strings.TrimSpace(params.Lyric) == "" {
+		ve["lyric"] = "lyric is required"
+	}
+	params.Comment = strings.TrimSpace(params.Comment)
+	if params.Comment == "" {
+		ve["comment"] = "comment is required"
+	} else if utf8.RuneCountInString(params.Comment) > MaxCommentLength {
+		ve["comment"] = "comment must be at most 1000 characters"
+	}
+	if len(ve) > 0 {
+		return Suggestion{}, apperror.Validation("msg", ve)
+	}
+
+	song, err := s.songs.Get(ctx, params.SongID)
+	if err != nil {
+		return Suggestion{}, err
+	}
+	if song.Created != nil && song.Created.ID == params.UserID {
+		return Suggestion{}, apperror.Validation("msg", map[string]string{"song_id": "edit your own song instead of suggesting a change"})
+	}
+	if song.Lyric != nil && sameLyric(*song.Lyric, params.Lyric) {
+		return Suggestion{}, apperror.Validation("msg", map[string]string{"lyric": "lyric is the same as the song's"})
+	}
+
+	id, err := s.repo.Create(ctx, params, song.Lyric)
+	if err != nil {
+		return Suggestion{}, err
+	}
+	return s.show(ctx, id)
+}
+
+func (s *service) List(ctx context.Context, params ListParams) ([]Suggestion, error) {
+	if params.UserID <= 0 || (params.OwnerID != nil && *params.OwnerID <= 0) {
+		return nil, apperror.Unauthorized("Unauthorized user")
+	}
+	switch params.Status {
+	case "", StatusPending, StatusAccepted, StatusRejected:
+	default:
+		return nil, apperror.Validation("msg", map[string]string{"status": "status must be pending, accepted or rejected"})
+	}
+	if params.SongID <= 0 {
+		return nil, apperror.NotFound("song not found")
+	}
+
+	song, err := s.songs.Get(ctx, params.SongID)
+	if err != nil {
+		return nil, err
+	}
+	if !canReview(song.Created, params.OwnerID) {
+		return nil, apperror.Forbidden("only the song's owner or an editor can review suggestions")
+	}
+
+	return s.repo.List(ctx, params.SongID, params.Status)
+}
+
+func (s *service) Get(ctx context.Context, id, userID int, ownerID *int) (Suggestion, error) {
+	if userID <= 0 {
+		return Suggestion{}, apperror.Unauthorized("Unauthorized user")
+	}
+	suggestion, err := s.load(ctx, id)
+	if err != nil {
+		return Suggestion{}, err
+	}
+	if suggestion.UserID != userID && !canReviewSong(suggestion.SongOwnerID, ownerID) {
+		return Suggestion{}, apperror.NotFound("suggestion not found")
+	}
+	return s.withDiff(ctx, suggestion)
+}
+
+// Accept claims the suggestion and applies the suggested lyric as the
+// reviewer in one transaction, so concurrent reviews apply it at most once;
+// the suggester is credited through the accepted suggestion.
+func (s *service) Accept(ctx context.Context, id int, params ReviewParams) (Suggestion, error) {
+	suggestion, note, err := s.review(ctx, id, params)
+	if err != nil {
+		return Suggestion{}, err
+	}
+
+	err = s.tx(ctx, func(repo Repository, songs Songs) error {
+		// The claim locks the suggestion, so a concurrent review waits for
+		// this transaction and then finds it reviewed.
+		ok, err := repo.Resolve(ctx, id, StatusAccepted, params.UserID, note)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return alreadyReviewed()
+		}
+
+		song, err := songs.Get(ctx, suggestion.SongID)
+		if err != nil {
+			return err
+		}
+		mutation := song.Mutation()
+		mutation.Lyric = &suggestion.Lyric
+		return songs.Update(ctx, song.ID, songsvc.UpdateParams{
+			MutationParams: mutation,
+			UserID:         params.UserID,
+			OwnerID:        params.OwnerID,
+		})
+	})
+	if err != nil {
+		return Suggestion{}, err
+	}
+	return s.show(ctx, id)
+}
+
+func (s *service) Reject(ctx context.Context, id int, params ReviewParams) (Suggestion, error) {
+	suggestion, note, err := s.review(ctx, id, params)
+	if err != nil {
+		return Suggestion{}, err
+	}
+	if err := s.resolve(ctx, id, StatusRejected, params.UserID, note); err != nil {
+		return Suggestion{}, err
+	}
+
+	// The rejection stands even when the email cannot be sent.
+	if suggestion.Email != "" {
+		if err := s.notifier.SuggestionRejected(ctx, suggestion.Email, suggestion.SongTitle, deref(note)); err != nil {
+			log.Printf("suggestions: notify rejection of suggestion %d: %v", id, err)
+		} else if err := s.repo.MarkNotified(ctx, id); err != nil {
+			return Suggestion{}, err
+		}
+	}
+	return s.show(ctx, id)
+}
+
+// review loads a pending suggestion the reviewer may act on and validates
+// their note.
+func (s *service) review(ctx context.Context, id int, params ReviewParams) (Suggestion, *string, error) {
+	if params.UserID <= 0 || (params.OwnerID != nil && *params.OwnerID <= 0) {
+		return Suggestion{}, nil, apperror.Unauthorized("Unauthorized user")
+	}
+	var note *string
+	if value := strings.TrimSpace(params.Note); value != "" {
+		if utf8.RuneCountInString(value) > MaxCommentLength {
+			return Suggestion{}, nil, apperror.Validation("msg", map[string]string{"note": "note must be at most 1000 characters"})
+		}
+		note = &value
+	}
+
+	suggestion, err := s.load(ctx, id)
+	if err != nil {
+		return Suggestion{}, nil, err
+	}
+	if !canReviewSong(suggestion.SongOwnerID, params.OwnerID) {
+		if suggestion.UserID == params.UserID {
+			return Suggestion{}, nil, apperror.Forbidden("only the song's owner or an editor can review suggestions")
+		}
+		return Suggestion{}, nil, apperror.NotFound("suggestion not found")
+	}
+	if suggestion.Status != StatusPending {
+		return Suggestion{}, nil, apperror.Validation("msg", map[string]string{"status": "suggestion was already " + suggestion.Status})
+	}
+	return suggestion, note, nil
+}
+
+func (s *service) resolve(ctx context.Context, id int, status string, reviewerID int, note *string) error {
+	ok, err := s.repo.Resolve(ctx, id, status, reviewerID, note)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return alreadyReviewed()
+	}
+	return nil
+}
+
+func alreadyReviewed() error {
+	return apperror.Validation("msg", map[string]string{"status": "suggestion was already reviewed"})
+}
+
+func (s *service) load(ctx context.Context, id int) (Suggestion, error) {
+	if id <= 0 {
+		return Suggestion{}, apperror.NotFound("suggestion not found")
+	}
+	suggestion, ok, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return Suggestion{}, err
+	}
+	if !ok {
+		return Suggestion{}, apperror.NotFound("suggestion not found")
+	}
+	return suggestion, nil
+}
+
+// show loads a suggestion with its diff.
+func (s *service) show(ctx context.Context, id int) (Suggestion, error) {
+	suggestion, err := s.load(ctx, id)
+	if err != nil {
+		return Suggestion{}, err
+	}
+	return s.withDiff(ctx, suggestion)
+}
+
+// withDiff compares a pending suggestion with the song as it is now, and a
+// reviewed one with the lyric it was made against.
+func (s *service) withDiff(ctx context.Context, suggestion Suggestion) (Suggestion, error) {
+	base := deref(suggestion.BaseLyric)
+	if suggestion.Status == StatusPending {
+		song, err := s.songs.Get(ctx, suggestion.SongID)
+		if err != nil {
+			return Suggestion{}, err
+		}
+		current := deref(song.Lyric)
+		suggestion.Stale = !sameLyric(current, base)
+		base = current
+	}
+	suggestion.Diff = Diff(base, suggestion.Lyric)
+	return suggestion, nil
+}
+
+// canReview reports whether a reviewer with the owner scope may review
+// suggestions on a song added by creator.
+func canReview(creator *songsvc.Creator, ownerID *int) bool {
+	if creator == nil {
+		return ownerID == nil
+	}
+	id := creator.ID
+	return canReviewSong(&id, ownerID)
+}
+
+func canReviewSong(songOwnerID, ownerID *int) bool {
+	if ownerID == nil {
+		return true
+	}
+	return songOwnerID != nil && *songOwnerID == *ownerID
+}
+
+func sameLyric(a, b string) bool {
+	normalise := func(lyric string) string {
+		return strings.TrimSpace(strings.ReplaceAll(lyric, "\r\n", "\n"))
+	}
+	return normalise(a) == normalise(b)
+}
+
+func deref(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
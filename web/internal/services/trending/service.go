@@ -16,6 +16,9 @@ type Trending struct {
 	LevelID     *int    `json:"level_id,omitempty"`
 	Level       *string `json:"level,omitempty"`
 	Description *string `json:"description,omitempty"`
+	// LevelSource is the level_source to list the chart's songs with: the
+	// author's level or the community's consensus.
+	LevelSource string `json:"level_source"`
 }
 
 type Artist struct {
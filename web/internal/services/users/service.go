@@ -2,12 +2,41 @@ package users
 
 import (
 	"context"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/lyricapp/lyric/web/internal/apperror"
+	"github.com/lyricapp/lyric/web/internal/services/permissions"
+	"github.com/lyricapp/lyric/web/pkg/pagination"
+)
+
+// Account statuses, stored in users.status.
+const (
+	StatusActive  = "active"
+	StatusBanned  = "banned"
+	StatusDeleted = "deleted"
 )
 
+// Statuses lists every account status.
+var Statuses = []string{StatusActive, StatusBanned, StatusDeleted}
+
+// MaxBanReasonLength matches users.ban_reason.
+const MaxBanReasonLength = 500
+
+// detailLimit caps each list on the user detail page.
+const detailLimit = 50
+
 // Service exposes user related functionality.
 type Service interface {
-	List(ctx context.Context) ([]User, error)
+	List(ctx context.Context, params ListParams) (ListResult, error)
 	SearchByEmail(ctx context.Context, email string) ([]User, error)
+	Get(ctx context.Context, id int) (Account, error)
+	Detail(ctx context.Context, id int) (Detail, error)
+	ChangeRole(ctx context.Context, params RoleParams) (Account, error)
+	Ban(ctx context.Context, params BanParams) (Account, error)
+	Unban(ctx context.Context, actorID, id int) (Account, error)
+	ForceLogout(ctx context.Context, id int) (int, error)
 }
 
 // User represents a user entry.
@@ -17,27 +46,279 @@ type User struct {
 	Role  string `json:"role"`
 }
 
+// Account is a user as seen by the people managing them.
+type Account struct {
+	ID         int        `json:"id"`
+	Email      string     `json:"email"`
+	Role       string     `json:"role"`
+	Status     string     `json:"status"`
+	BanReason  *string    `json:"ban_reason"`
+	BannedAt   *time.Time `json:"banned_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastSeenAt *time.Time `json:"last_seen_at"`
+}
+
+// ListParams filters and pages the account list. Query matches part of the
+// email address; Role and Status match exactly when set.
+type ListParams struct {
+	Query   string
+	Role    string
+	Status  string
+	Page    int
+	PerPage int
+}
+
+// ListResult wraps a page of accounts.
+type ListResult struct {
+	Data    []Account `json:"data"`
+	Page    int       `json:"page"`
+	PerPage int       `json:"per_page"`
+	Total   int       `json:"total"`
+}
+
+// Detail gathers what a user has contributed and where they signed in,
+// most recent first.
+type Detail struct {
+	Account
+	Songs      []Song     `json:"songs"`
+	Playlists  []Playlist `json:"playlists"`
+	Feedback   []Feedback `json:"feedback"`
+	Logins     []Login    `json:"logins"`
+	Identities []Identity `json:"identities"`
+}
+
+// Song is a song the user submitted.
+type Song struct {
+	ID        int       `json:"id"`
+	Title     string    `json:"title"`
+	Status    string    `json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Playlist is a playlist the user owns.
+type Playlist struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	Songs     int       `json:"songs"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Feedback is a message the user sent.
+type Feedback struct {
+	ID        int       `json:"id"`
+	Message   string    `json:"message"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Login is a device session the user signed in with.
+type Login struct {
+	ID         int64      `json:"id"`
+	DeviceName *string    `json:"device_name"`
+	UserAgent  *string    `json:"user_agent"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt time.Time  `json:"last_used_at"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+}
+
+// Active reports whether the session can still be refreshed at now.
+func (l Login) Active(now time.Time) bool {
+	return l.RevokedAt == nil && l.ExpiresAt.After(now)
+}
+
+// Identity is a Google or Apple account linked to the user.
+type Identity struct {
+	Provider    string    `json:"provider"`
+	Email       *string   `json:"email"`
+	LastLoginAt time.Time `json:"last_login_at"`
+}
+
+// RoleParams changes a user's role. ActorID is the user making the change.
+type RoleParams struct {
+	ActorID int
+	UserID  int
+	Role    string
+}
+
+// BanParams bans a user. ActorID is the user issuing the ban.
+type BanParams struct {
+	ActorID int
+	UserID  int
+	Reason  string
+}
+
 // Repository abstracts persistence for users.
 type Repository interface {
-	List(ctx context.Context) ([]User, error)
+	List(ctx context.Context, params ListParams) (ListResult, error)
 	SearchByEmail(ctx context.Context, email string) ([]User, error)
+	// Account returns the user, reporting false when they do not exist.
+	Account(ctx context.Context, id int) (Account, bool, error)
+	// Detail returns the account with up to limit entries in each list,
+	// reporting false when the user does not exist.
+	Detail(ctx context.Context, id, limit int) (Detail, bool, error)
+	// SetRole, Ban and Unban report false when the user does not exist or has
+	// been deleted.
+	SetRole(ctx context.Context, id int, role string) (Account, bool, error)
+	Ban(ctx context.Context, id int, reason string, bannedBy int, at time.Time) (Account, bool, error)
+	Unban(ctx context.Context, id int) (Account, bool, error)
+	// RevokeSessions revokes every live device session of the user, records
+	// when they were signed out and returns how many sessions there were.
+	RevokeSessions(ctx context.Context, userID int, at time.Time) (int, error)
 }
 
 type service struct {
 	repo Repository
+	now  func() time.Time
 }
 
 // NewService constructs a user service using the supplied repository.
 func NewService(repo Repository) Service {
-	return &service{repo: repo}
+	return &service{repo: repo, now: time.Now}
 }
 
-// List returns all available users without pagination.
-func (s *service) List(ctx context.Context) ([]User, error) {
-	return s.repo.List(ctx)
+// List returns a page of accounts matching the filters, oldest first.
+func (s *service) List(ctx context.Context, params ListParams) (ListResult, error) {
+	params.Query = strings.TrimSpace(params.Query)
+	if params.Role != "" {
+		role, err := permissions.ParseRole(params.Role)
+		if err != nil {
+			return ListResult{}, err
+		}
+		params.Role = string(role)
+	}
+	if params.Status != "" && !validStatus(params.Status) {
+		return ListResult{}, apperror.Validation("msg", map[string]string{"status": "status must be active, banned or deleted"})
+	}
+	params.Page = pagination.NormalisePage(params.Page)
+	params.PerPage = pagination.NormalisePerPage(params.PerPage)
+
+	return s.repo.List(ctx, params)
 }
 
 // SearchByEmail returns all available users without pagination.
 func (s *service) SearchByEmail(ctx context.Context, email string) ([]User, error) {
 	return s.repo.SearchByEmail(ctx, email)
-}
\ No newline at end of file
+}
+
+// Get loads a user's account.
+func (s *service) Get(ctx context.Context, id int) (Account, error) {
+	if id <= 0 {
+		return Account{}, apperror.NotFound("user not found")
+	}
+	account, ok, err := s.repo.Account(ctx, id)
+	if err != nil {
+		return Account{}, err
+	}
+	if !ok {
+		return Account{}, apperror.NotFound("user not found")
+	}
+	return account, nil
+}
+
+// Detail loads a user with their songs, playlists, feedback and sign ins.
+func (s *service) Detail(ctx context.Context, id int) (Detail, error) {
+	if id <= 0 {
+		return Detail{}, apperror.NotFound("user not found")
+	}
+	detail, ok, err := s.repo.Detail(ctx, id, detailLimit)
+	if err != nil {
+		return Detail{}, err
+	}
+	if !ok {
+		return Detail{}, apperror.NotFound("user not found")
+	}
+	return detail, nil
+}
+
+// ChangeRole sets a user's role. Nobody can change their own role, so the last
+// admin cannot lock everyone out by accident.
+func (s *service) ChangeRole(ctx context.Context, params RoleParams) (Account, error) {
+	role, err := permissions.ParseRole(params.Role)
+	if err != nil {
+		return Account{}, err
+	}
+	if params.UserID <= 0 {
+		return Account{}, apperror.NotFound("user not found")
+	}
+	if params.UserID == params.ActorID {
+		return Account{}, apperror.Forbidden("you cannot change your own role")
+	}
+
+	account, ok, err := s.repo.SetRole(ctx, params.UserID, string(role))
+	if err != nil {
+		return Account{}, err
+	}
+	if !ok {
+		return Account{}, apperror.NotFound("user not found")
+	}
+	return account, nil
+}
+
+// Ban blocks a user from signing in and signs them out of every device.
+func (s *service) Ban(ctx context.Context, params BanParams) (Account, error) {
+	reason := strings.TrimSpace(params.Reason)
+	if reason == "" {
+		return Account{}, apperror.Validation("msg", map[string]string{"reason": "reason is required"})
+	}
+	if utf8.RuneCountInString(reason) > MaxBanReasonLength {
+		return Account{}, apperror.Validation("msg", map[string]string{"reason": "reason must be at most 500 characters"})
+	}
+	if params.UserID <= 0 {
+		return Account{}, apperror.NotFound("user not found")
+	}
+	if params.UserID == params.ActorID {
+		return Account{}, apperror.Forbidden("you cannot ban yourself")
+	}
+
+	now := s.now()
+	account, ok, err := s.repo.Ban(ctx, params.UserID, reason, params.ActorID, now)
+	if err != nil {
+		return Account{}, err
+	}
+	if !ok {
+		return Account{}, apperror.NotFound("user not found")
+	}
+	if _, err := s.repo.RevokeSessions(ctx, params.UserID, now); err != nil {
+		return Account{}, err
+	}
+	return account, nil
+}
+
+// Unban lets a banned user sign in again. Their devices stay signed out.
+func (s *service) Unban(ctx context.Context, actorID, id int) (Account, error) {
+	if id <= 0 {
+		return Account{}, apperror.NotFound("user not found")
+	}
+	if id == actorID {
+		return Account{}, apperror.Forbidden("you cannot unban yourself")
+	}
+
+	account, ok, err := s.repo.Unban(ctx, id)
+	if err != nil {
+		return Account{}, err
+	}
+	if !ok {
+		return Account{}, apperror.NotFound("user not found")
+	}
+	return account, nil
+}
+
+// ForceLogout signs the user out of every device and returns how many device
+// sessions were revoked. From the next request on, the authenticator rejects
+// access tokens of those sessions and tokens from before sessions, and the
+// admin pages reject cookies issued before the sign-out.
+func (s *service) ForceLogout(ctx context.Context, id int) (int, error) {
+	if id <= 0 {
+		return 0, apperror.NotFound("user not found")
+	}
+	return s.repo.RevokeSessions(ctx, id, s.now())
+}
+
+func validStatus(status string) bool {
+	for _, known := range Statuses {
+		if status == known {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,146 @@
+package users_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/lyricapp/lyric/web/internal/apperror"
+	"github.com/lyricapp/lyric/web/internal/services/login"
+	"github.com/lyricapp/lyric/web/internal/services/users"
+	loginrepo "github.com/lyricapp/lyric/web/internal/storage/postgres/login"
+	userrepo "github.com/lyricapp/lyric/web/internal/storage/postgres/users"
+	"github.com/lyricapp/lyric/web/internal/testutil"
+)
+
+func TestService_Moderation(t *testing.T) {
+	conn := testutil.SetupDB(t)
+	defer conn.Close()
+
+	ctx := context.Background()
+	tx, _ := conn.Begin(ctx)
+	defer tx.Rollback(ctx)
+
+	var adminID, userID int
+	if err := tx.QueryRow(ctx, `insert into users (email, role) values ('admin@lyric.test', 'admin') returning id`).Scan(&adminID); err != nil {
+		t.Fatalf("insert admin: %v", err)
+	}
+	if err := tx.QueryRow(ctx, `insert into users (email, role) values ('spammer@lyric.test', 'musician') returning id`).Scan(&userID); err != nil {
+		t.Fatalf("insert user: %v", err)
+	}
+	if _, err := tx.Exec(ctx, `
+		insert into user_sessions (user_id, device_name, refresh_token_hash, expires_at)
+		values ($1, 'Phone', 'hash-1', now() + interval '30 days'), ($1, 'Tablet', 'hash-2', now() + interval '30 days')
+	`, userID); err != nil {
+		t.Fatalf("insert sessions: %v", err)
+	}
+
+	svc := users.NewService(userrepo.NewRepository(tx))
+
+	t.Run("ban requires a reason", func(t *testing.T) {
+		// when
+		_, err := svc.Ban(ctx, users.BanParams{ActorID: adminID, UserID: userID, Reason: "  "})
+
+		// then
+		assertStatus(t, err, http.StatusUnprocessableEntity)
+	})
+
+	t.Run("admins cannot ban themselves", func(t *testing.T) {
+		// when
+		_, err := svc.Ban(ctx, users.BanParams{ActorID: adminID, UserID: adminID, Reason: "testing"})
+
+		// then
+		assertStatus(t, err, http.StatusForbidden)
+	})
+
+	t.Run("ban signs the user out", func(t *testing.T) {
+		// when
+		account, err := svc.Ban(ctx, users.BanParams{ActorID: adminID, UserID: userID, Reason: "Posting spam"})
+
+		// then
+		if err != nil {
+			t.Fatalf("ban: %v", err)
+		}
+		if account.Status != users.StatusBanned || account.BanReason == nil || *account.BanReason != "Posting spam" {
+			t.Errorf("expected banned account with reason, got %+v", account)
+		}
+		detail, err := svc.Detail(ctx, userID)
+		if err != nil {
+			t.Fatalf("detail: %v", err)
+		}
+		if len(detail.Logins) != 2 {
+			t.Fatalf("expected 2 sign ins, got %d", len(detail.Logins))
+		}
+		for _, login := range detail.Logins {
+			if login.RevokedAt == nil {
+				t.Errorf("expected session %d to be revoked", login.ID)
+			}
+		}
+	})
+
+	t.Run("list filters by status", func(t *testing.T) {
+		// when
+		result, err := svc.List(ctx, users.ListParams{Query: "lyric.test", Status: users.StatusBanned})
+
+		// then
+		if err != nil {
+			t.Fatalf("list: %v", err)
+		}
+		if result.Total != 1 || result.Data[0].ID != userID {
+			t.Errorf("expected only the banned user, got %+v", result)
+		}
+	})
+
+	t.Run("unban and change role", func(t *testing.T) {
+		// when
+		if _, err := svc.Unban(ctx, adminID, userID); err != nil {
+			t.Fatalf("unban: %v", err)
+		}
+		account, err := svc.ChangeRole(ctx, users.RoleParams{ActorID: adminID, UserID: userID, Role: "Editor"})
+
+		// then
+		if err != nil {
+			t.Fatalf("change role: %v", err)
+		}
+		if account.Status != users.StatusActive || account.BanReason != nil || account.Role != "editor" {
+			t.Errorf("expected active editor, got %+v", account)
+		}
+	})
+
+	t.Run("unknown role", func(t *testing.T) {
+		// when
+		_, err := svc.ChangeRole(ctx, users.RoleParams{ActorID: adminID, UserID: userID, Role: "owner"})
+
+		// then
+		assertStatus(t, err, http.StatusUnprocessableEntity)
+	})
+
+	t.Run("force logout reaches credentials without a session", func(t *testing.T) {
+		sessions := login.NewService(loginrepo.NewRepository(tx), login.NewConsoleMailer(""), login.Config{TokenSecret: "secret"})
+		if err := sessions.CheckSession(ctx, userID, 0); err != nil {
+			t.Fatalf("expected a legacy token to pass before the sign-out, got %v", err)
+		}
+
+		// when
+		if _, err := svc.ForceLogout(ctx, userID); err != nil {
+			t.Fatalf("force logout: %v", err)
+		}
+
+		// then
+		assertStatus(t, sessions.CheckSession(ctx, userID, 0), http.StatusUnauthorized)
+		assertStatus(t, sessions.CheckIssued(ctx, userID, time.Now().Add(-time.Minute)), http.StatusUnauthorized)
+		if err := sessions.CheckIssued(ctx, userID, time.Now().Add(time.Minute)); err != nil {
+			t.Errorf("expected a cookie issued after the sign-out to pass, got %v", err)
+		}
+	})
+}
+
+func assertStatus(t *testing.T, err error, status int) {
+	t.Helper()
+	var appErr *apperror.AppError
+	if !errors.As(err, &appErr) || appErr.Status != status {
+		t.Fatalf("expected status %d, got %v", status, err)
+	}
+}
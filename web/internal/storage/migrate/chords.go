@@ -0,0 +1,65 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/lyricapp/lyric/web/pkg/chordtheory"
+)
+
+// backfillChordCanonicalNames sets canonical_name on chords added before it
+// existed. A chord whose spelling normalises to one already in the library is
+// merged into it: its positions and requests move over and the duplicate is
+// removed. Names that are not chord symbols keep their trimmed spelling.
+func backfillChordCanonicalNames(ctx context.Context, tx pgx.Tx) error {
+	type legacy struct {
+		id   int
+		name string
+	}
+	rows, err := tx.Query(ctx, `select id, name from chords where canonical_name is null order by id`)
+	if err != nil {
+		return fmt.Errorf("list legacy chords: %w", err)
+	}
+	chords, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (legacy, error) {
+		var chord legacy
+		err := row.Scan(&chord.id, &chord.name)
+		return chord, err
+	})
+	if err != nil {
+		return fmt.Errorf("scan legacy chords: %w", err)
+	}
+
+	for _, chord := range chords {
+		canonical, err := chordtheory.Normalize(chord.name)
+		if err != nil {
+			canonical = strings.TrimSpace(chord.name)
+		}
+
+		var keep int
+		err = tx.QueryRow(ctx, `select id from chords where canonical_name = $1`, canonical).Scan(&keep)
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			if _, err := tx.Exec(ctx, `update chords set canonical_name = $2 where id = $1`, chord.id, canonical); err != nil {
+				return fmt.Errorf("set canonical name of chord %d: %w", chord.id, err)
+			}
+			continue
+		case err != nil:
+			return fmt.Errorf("find chord %s: %w", canonical, err)
+		}
+
+		if _, err := tx.Exec(ctx, `update chord_positions set chord_id = $2 where chord_id = $1`, chord.id, keep); err != nil {
+			return fmt.Errorf("move positions of chord %d: %w", chord.id, err)
+		}
+		if _, err := tx.Exec(ctx, `update chord_requests set chord_id = $2 where chord_id = $1`, chord.id, keep); err != nil {
+			return fmt.Errorf("move requests of chord %d: %w", chord.id, err)
+		}
+		if _, err := tx.Exec(ctx, `delete from chords where id = $1`, chord.id); err != nil {
+			return fmt.Errorf("delete duplicate chord %d: %w", chord.id, err)
+		}
+	}
+	return nil
+}
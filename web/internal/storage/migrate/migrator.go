@@ -16,6 +16,15 @@ import (
 
 const migrationsTable = "schema_migrations"
 
+// Step is Go code a migration needs, for data changes SQL cannot express.
+type Step func(ctx context.Context, tx pgx.Tx) error
+
+// steps run before the SQL of the migration file they are keyed by, in the
+// same transaction.
+var steps = map[string]Step{
+	"000022_chord_canonical_names_not_null.sql": backfillChordCanonicalNames,
+}
+
 // EnsureTable creates the bookkeeping table required to track applied migrations.
 func EnsureTable(ctx context.Context, pool *pgxpool.Pool) error {
 	_, err := pool.Exec(ctx, `
@@ -32,7 +41,8 @@ func EnsureTable(ctx context.Context, pool *pgxpool.Pool) error {
 
 // Apply executes unapplied .sql files found in dir, ordered lexicographically.
 // Each file is executed inside a transaction; files should contain a single SQL
-// statement compatible with PostgreSQL's extended protocol.
+// statement compatible with PostgreSQL's extended protocol. A file with a Go
+// step runs it first, in the same transaction.
 func Apply(ctx context.Context, pool *pgxpool.Pool, dir string) ([]string, error) {
 	entries, err := os.ReadDir(dir)
 	if err != nil {
@@ -71,7 +81,7 @@ func Apply(ctx context.Context, pool *pgxpool.Pool, dir string) ([]string, error
 			return applied, fmt.Errorf("read %s: %w", name, err)
 		}
 		statement := strings.TrimSpace(string(contents))
-		if statement == "" {
+		if statement == "" && steps[name] == nil {
 			if err := recordMigration(ctx, pool, name); err != nil {
 				return applied, err
 			}
@@ -105,8 +115,15 @@ func runMigration(ctx context.Context, pool *pgxpool.Pool, name, statement strin
 	}
 	defer tx.Rollback(ctx) //nolint:errcheck - safe to ignore rollback errors
 
-	if _, err := tx.Exec(ctx, statement); err != nil {
-		return fmt.Errorf("exec migration %s: %w", name, err)
+	if step := steps[name]; step != nil {
+		if err := step(ctx, tx); err != nil {
+			return fmt.Errorf("step for migration %s: %w", name, err)
+		}
+	}
+	if statement != "" {
+		if _, err := tx.Exec(ctx, statement); err != nil {
+			return fmt.Errorf("exec migration %s: %w", name, err)
+		}
 	}
 
 	if err := recordMigrationTx(ctx, tx, name); err != nil {
@@ -0,0 +1,298 @@
+package account
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	accountsvc "github.com/lyricapp/lyric/web/internal/services/account"
+	"github.com/lyricapp/lyric/web/internal/storage"
+)
+
+// Repository provides Postgres-backed account exports and purges.
+type Repository struct {
+	db storage.Querier
+}
+
+// NewRepository constructs a Repository instance.
+func NewRepository(db storage.Querier) *Repository {
+	return &Repository{db: db}
+}
+
+// Export loads everything stored about the user, reporting false when there
+// is no such user.
+func (r *Repository) Export(ctx context.Context, userID int) (accountsvc.Export, bool, error) {
+	var export accountsvc.Export
+	profile := &export.Profile
+	err := r.db.QueryRow(ctx, `
+		select id, email, role, plan, status, purge_after, created_at, updated_at
+		from users
+		where id = $1
+	`, userID).Scan(&profile.ID, &profile.Email, &profile.Role, &profile.Plan, &profile.Status,
+		&profile.PurgeAfter, &profile.CreatedAt, &profile.UpdatedAt)
+	if err == pgx.ErrNoRows {
+		return accountsvc.Export{}, false, nil
+	}
+	if err != nil {
+		return accountsvc.Export{}, false, fmt.Errorf("select export profile: %w", err)
+	}
+
+	var prefs accountsvc.Preferences
+	err = r.db.QueryRow(ctx, `
+		select theme, language, font_size, display_mode, instrument, scroll_speed, updated_at
+		from user_preferences
+		where user_id = $1
+	`, userID).Scan(&prefs.Theme, &prefs.Language, &prefs.FontSize, &prefs.DisplayMode,
+		&prefs.Instrument, &prefs.ScrollSpeed, &prefs.UpdatedAt)
+	switch {
+	case err == nil:
+		export.Preferences = &prefs
+	case err != pgx.ErrNoRows:
+		return accountsvc.Export{}, false, fmt.Errorf("export preferences: %w", err)
+	}
+
+	if export.Songs, err = storage.QueryAll(ctx, r.db, func(rows pgx.Rows, song *accountsvc.Song) error {
+		return rows.Scan(&song.ID, &song.Title, &song.Key, &song.Lyric, &song.Status, &song.ReleaseYear, &song.CreatedAt, &song.UpdatedAt)
+	}, `
+		select id, title, key, lyric, coalesce(status, 'created'), release_year, created_at, updated_at
+		from songs
+		where created_by = $1
+		order by id
+	`, userID); err != nil {
+		return accountsvc.Export{}, false, fmt.Errorf("export songs: %w", err)
+	}
+
+	if export.Playlists, err = storage.QueryAll(ctx, r.db, func(rows pgx.Rows, playlist *accountsvc.Playlist) error {
+		playlist.Songs = make([]accountsvc.PlaylistSong, 0)
+		return rows.Scan(&playlist.ID, &playlist.Name, &playlist.CreatedAt, &playlist.UpdatedAt)
+	}, `
+		select id, name, created_at, updated_at
+		from playlists
+		where user_id = $1
+		order by id
+	`, userID); err != nil {
+		return accountsvc.Export{}, false, fmt.Errorf("export playlists: %w", err)
+	}
+	for i := range export.Playlists {
+		playlist := &export.Playlists[i]
+		if playlist.Songs, err = storage.QueryAll(ctx, r.db, func(rows pgx.Rows, song *accountsvc.PlaylistSong) error {
+			return rows.Scan(&song.SongID, &song.Title, &song.Position, &song.Transpose, &song.Capo, &song.DisplayMode, &song.Note)
+		}, `
+			select ps.song_id, s.title, ps.position, ps.transpose::int, ps.capo::int, ps.display_mode, ps.note
+			from playlist_song ps
+			join songs s on s.id = ps.song_id
+			where ps.playlist_id = $1
+			order by ps.position, ps.song_id
+		`, playlist.ID); err != nil {
+			return accountsvc.Export{}, false, fmt.Errorf("export playlist songs: %w", err)
+		}
+	}
+
+	if export.SharedPlaylists, err = storage.QueryAll(ctx, r.db, func(rows pgx.Rows, shared *accountsvc.SharedPlaylist) error {
+		return rows.Scan(&shared.PlaylistID, &shared.Name, &shared.Role, &shared.JoinedAt)
+	}, `
+		select p.id, p.name, pu.role, pu.created_at
+		from playlist_user pu
+		join playlists p on p.id = pu.playlist_id
+		where pu.user_id = $1
+		order by pu.created_at, p.id
+	`, userID); err != nil {
+		return accountsvc.Export{}, false, fmt.Errorf("export shared playlists: %w", err)
+	}
+
+	if export.PlaylistInvites, err = storage.QueryAll(ctx, r.db, func(rows pgx.Rows, invite *accountsvc.PlaylistInvite) error {
+		return rows.Scan(&invite.ID, &invite.PlaylistID, &invite.Role, &invite.MaxUses, &invite.Uses, &invite.ExpiresAt, &invite.RevokedAt, &invite.CreatedAt)
+	}, `
+		select id, playlist_id, role, max_uses, uses, expires_at, revoked_at, created_at
+		from playlist_invites
+		where created_by = $1
+		order by id
+	`, userID); err != nil {
+		return accountsvc.Export{}, false, fmt.Errorf("export playlist invites: %w", err)
+	}
+
+	if export.Feedback, err = storage.QueryAll(ctx, r.db, func(rows pgx.Rows, feedback *accountsvc.Feedback) error {
+		return rows.Scan(&feedback.ID, &feedback.Message, &feedback.CreatedAt)
+	}, `
+		select id, coalesce(message, ''), created_at
+		from feedbacks
+		where user_id = $1
+		order by id
+	`, userID); err != nil {
+		return accountsvc.Export{}, false, fmt.Errorf("export feedback: %w", err)
+	}
+
+	if export.Favorites, err = storage.QueryAll(ctx, r.db, func(rows pgx.Rows, favorite *accountsvc.Favorite) error {
+		return rows.Scan(&favorite.Kind, &favorite.ID, &favorite.Name, &favorite.CreatedAt)
+	}, `
+		select 'song', s.id, s.title, f.created_at
+		from favorite_songs f
+		join songs s on s.id = f.song_id
+		where f.user_id = $1
+		union all
+		select 'album', a.id, a.name, f.created_at
+		from favorite_albums f
+		join albums a on a.id = f.album_id
+		where f.user_id = $1
+		union all
+		select 'artist', a.id, a.name, f.created_at
+		from favorite_artists f
+		join artists a on a.id = f.artist_id
+		where f.user_id = $1
+		order by 4, 1, 2
+	`, userID); err != nil {
+		return accountsvc.Export{}, false, fmt.Errorf("export favourites: %w", err)
+	}
+
+	if export.LevelVotes, err = storage.QueryAll(ctx, r.db, func(rows pgx.Rows, vote *accountsvc.LevelVote) error {
+		return rows.Scan(&vote.SongID, &vote.LevelID, &vote.Level, &vote.CreatedAt)
+	}, `
+		select ls.song_id, ls.level_id, l.name, ls.created_at
+		from level_song ls
+		join levels l on l.id = ls.level_id
+		where ls.user_id = $1
+		order by ls.id
+	`, userID); err != nil {
+		return accountsvc.Export{}, false, fmt.Errorf("export level votes: %w", err)
+	}
+
+	if export.ChordRequestVotes, err = storage.QueryAll(ctx, r.db, func(rows pgx.Rows, vote *accountsvc.ChordRequestVote) error {
+		return rows.Scan(&vote.ChordRequestID, &vote.Name, &vote.SongID, &vote.NotifiedAt, &vote.CreatedAt)
+	}, `
+		select v.chord_request_id, cr.name, v.song_id, v.notified_at, v.created_at
+		from chord_request_votes v
+		join chord_requests cr on cr.id = v.chord_request_id
+		where v.user_id = $1
+		order by v.created_at, v.chord_request_id
+	`, userID); err != nil {
+		return accountsvc.Export{}, false, fmt.Errorf("export chord request votes: %w", err)
+	}
+
+	if export.Suggestions, err = storage.QueryAll(ctx, r.db, func(rows pgx.Rows, suggestion *accountsvc.Suggestion) error {
+		return rows.Scan(&suggestion.ID, &suggestion.SongID, &suggestion.Lyric, &suggestion.Comment, &suggestion.Status,
+			&suggestion.ReviewNote, &suggestion.ReviewedAt, &suggestion.CreatedAt)
+	}, `
+		select id, song_id, lyric, comment, status, review_note, reviewed_at, created_at
+		from song_suggestions
+		where user_id = $1
+		order by id
+	`, userID); err != nil {
+		return accountsvc.Export{}, false, fmt.Errorf("export suggestions: %w", err)
+	}
+
+	if export.Plays, err = storage.QueryAll(ctx, r.db, func(rows pgx.Rows, play *accountsvc.Play) error {
+		return rows.Scan(&play.SongID, &play.CreatedAt)
+	}, `
+		select song_id, created_at
+		from plays
+		where user_id = $1
+		order by id
+	`, userID); err != nil {
+		return accountsvc.Export{}, false, fmt.Errorf("export plays: %w", err)
+	}
+
+	if export.Subscriptions, err = storage.QueryAll(ctx, r.db, func(rows pgx.Rows, subscription *accountsvc.Subscription) error {
+		return rows.Scan(&subscription.ID, &subscription.Provider, &subscription.ProductID, &subscription.Kind,
+			&subscription.StartsAt, &subscription.ExpiresAt, &subscription.CreatedAt)
+	}, `
+		select id, provider, product_id, kind, starts_at, expires_at, created_at
+		from subscriptions
+		where user_id = $1
+		order by id
+	`, userID); err != nil {
+		return accountsvc.Export{}, false, fmt.Errorf("export subscriptions: %w", err)
+	}
+
+	if export.Sessions, err = storage.QueryAll(ctx, r.db, func(rows pgx.Rows, session *accountsvc.Session) error {
+		return rows.Scan(&session.ID, &session.DeviceName, &session.UserAgent, &session.CreatedAt,
+			&session.LastUsedAt, &session.ExpiresAt, &session.RevokedAt)
+	}, `
+		select id, device_name, user_agent, created_at, last_used_at, expires_at, revoked_at
+		from user_sessions
+		where user_id = $1
+		order by id
+	`, userID); err != nil {
+		return accountsvc.Export{}, false, fmt.Errorf("export sessions: %w", err)
+	}
+
+	if export.Identities, err = storage.QueryAll(ctx, r.db, func(rows pgx.Rows, identity *accountsvc.Identity) error {
+		return rows.Scan(&identity.Provider, &identity.Email, &identity.LastLoginAt, &identity.CreatedAt)
+	}, `
+		select provider, email, last_login_at, created_at
+		from user_identities
+		where user_id = $1
+		order by id
+	`, userID); err != nil {
+		return accountsvc.Export{}, false, fmt.Errorf("export identities: %w", err)
+	}
+
+	return export, true, nil
+}
+
+// DuePurges lists deleted users whose grace period ended by at, oldest first.
+func (r *Repository) DuePurges(ctx context.Context, at time.Time, limit int) ([]int, error) {
+	ids, err := storage.QueryAll(ctx, r.db, func(rows pgx.Rows, id *int) error {
+		return rows.Scan(id)
+	}, `
+		select id
+		from users
+		where status = 'deleted'
+		  and purge_after <= $1
+		order by purge_after, id
+		limit $2
+	`, at.UTC(), limit)
+	if err != nil {
+		return nil, fmt.Errorf("list due purges: %w", err)
+	}
+	return ids, nil
+}
+
+// Purge deletes the user. Songs they never got approved are removed with them;
+// approved songs stay in the catalogue without an author, and their plays are
+// kept without the user. Everything else they own is removed by the foreign
+// key cascades. Audit log entries are kept: they refer to the user by id
+// only and hold no email, so nothing identifying is left behind.
+func (r *Repository) Purge(ctx context.Context, userID int, at time.Time) (bool, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("begin purge tx: %w", err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	var id int
+	err = tx.QueryRow(ctx, `
+		select id
+		from users
+		where id = $1
+		  and status = 'deleted'
+		  and purge_after <= $2
+		for update
+	`, userID, at.UTC()).Scan(&id)
+	if err == pgx.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("lock purged user: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, `
+		delete from songs
+		where created_by = $1
+		  and coalesce(status, 'created') <> 'approved'
+	`, userID); err != nil {
+		return false, fmt.Errorf("delete unpublished songs: %w", err)
+	}
+	if _, err := tx.Exec(ctx, `delete from users where id = $1`, userID); err != nil {
+		return false, fmt.Errorf("delete user: %w", err)
+	}
+	// The user's own playlist tombstones are only read by their devices.
+	if _, err := tx.Exec(ctx, `delete from sync_tombstones where user_id = $1`, userID); err != nil {
+		return false, fmt.Errorf("delete user tombstones: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("commit purge tx: %w", err)
+	}
+	return true, nil
+}
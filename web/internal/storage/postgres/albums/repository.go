@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"fmt"
 	"strings"
+	"time"
 
 	albumsvc "github.com/lyricapp/lyric/web/internal/services/albums"
 	"github.com/lyricapp/lyric/web/internal/storage"
@@ -175,3 +176,12 @@ func offset(page, perPage int) int {
 	}
 	return (page - 1) * perPage
 }
+
+// LastModified returns when albums or the songs, artists and writers they list last changed.
+func (r *Repository) LastModified(ctx context.Context) (time.Time, error) {
+	at, err := storage.LastModified(ctx, r.db, "albums", "songs", "artists", "writers")
+	if err != nil {
+		return time.Time{}, fmt.Errorf("albums last modified: %w", err)
+	}
+	return at, nil
+}
@@ -0,0 +1,126 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	auditsvc "github.com/lyricapp/lyric/web/internal/services/audit"
+	"github.com/lyricapp/lyric/web/internal/storage"
+	"github.com/lyricapp/lyric/web/pkg/pagination"
+)
+
+// Repository appends to and reads the audit_log table.
+type Repository struct {
+	db storage.Querier
+}
+
+// NewRepository constructs a Repository instance.
+func NewRepository(db storage.Querier) *Repository {
+	return &Repository{db: db}
+}
+
+// Insert appends an entry. The actor is stored by id only.
+func (r *Repository) Insert(ctx context.Context, entry auditsvc.Entry) error {
+	_, err := r.db.Exec(ctx, `
+		insert into audit_log (actor_id, action, entity_type, entity_id, before, after, request_id)
+		values ($1, $2, $3, $4, $5::jsonb, $6::jsonb, $7)
+	`, entry.ActorID, entry.Action, entry.EntityType, entry.EntityID, nullableJSON(entry.Before), nullableJSON(entry.After), entry.RequestID)
+	if err != nil {
+		return fmt.Errorf("insert audit entry: %w", err)
+	}
+	return nil
+}
+
+// List returns a page of entries matching the filters, newest first. Actors are
+// shown with their current email while their account exists.
+func (r *Repository) List(ctx context.Context, params auditsvc.ListParams) (auditsvc.ListResult, error) {
+	result := auditsvc.ListResult{
+		Data:    make([]auditsvc.Entry, 0),
+		Page:    params.Page,
+		PerPage: params.PerPage,
+	}
+
+	var (
+		conditions []string
+		args       []any
+	)
+	if params.Actor != "" {
+		args = append(args, "%"+params.Actor+"%")
+		conditions = append(conditions, fmt.Sprintf("u.email ilike $%d", len(args)))
+	}
+	if params.Action != "" {
+		args = append(args, params.Action)
+		conditions = append(conditions, fmt.Sprintf("a.action = $%d", len(args)))
+	}
+	if params.EntityType != "" {
+		args = append(args, params.EntityType)
+		conditions = append(conditions, fmt.Sprintf("a.entity_type = $%d", len(args)))
+	}
+	if params.EntityID != "" {
+		args = append(args, params.EntityID)
+		conditions = append(conditions, fmt.Sprintf("a.entity_id = $%d", len(args)))
+	}
+	where := ""
+	if len(conditions) > 0 {
+		where = "where " + strings.Join(conditions, " and ")
+	}
+
+	const from = `from audit_log a left join users u on u.id = a.actor_id `
+	if err := r.db.QueryRow(ctx, `select count(*) `+from+where, args...).Scan(&result.Total); err != nil {
+		return auditsvc.ListResult{}, fmt.Errorf("count audit entries: %w", err)
+	}
+	if result.Total == 0 {
+		return result, nil
+	}
+
+	args = append(args, params.PerPage, pagination.Offset(params.Page, params.PerPage))
+	rows, err := r.db.Query(ctx, fmt.Sprintf(`
+		select a.id, a.actor_id, u.email, a.action, a.entity_type, a.entity_id, a.before, a.after, a.request_id, a.created_at
+		%s%s
+		order by a.id desc
+		limit $%d offset $%d
+	`, from, where, len(args)-1, len(args)), args...)
+	if err != nil {
+		return auditsvc.ListResult{}, fmt.Errorf("list audit entries: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			entry         auditsvc.Entry
+			before, after []byte
+		)
+		if err := rows.Scan(
+			&entry.ID,
+			&entry.ActorID,
+			&entry.ActorEmail,
+			&entry.Action,
+			&entry.EntityType,
+			&entry.EntityID,
+			&before,
+			&after,
+			&entry.RequestID,
+			&entry.CreatedAt,
+		); err != nil {
+			return auditsvc.ListResult{}, fmt.Errorf("scan audit entry: %w", err)
+		}
+		entry.Before = json.RawMessage(before)
+		entry.After = json.RawMessage(after)
+		result.Data = append(result.Data, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return auditsvc.ListResult{}, fmt.Errorf("iterate audit entries: %w", err)
+	}
+
+	return result, nil
+}
+
+func nullableJSON(raw json.RawMessage) *string {
+	if len(raw) == 0 {
+		return nil
+	}
+	value := string(raw)
+	return &value
+}
@@ -0,0 +1,253 @@
+package chordrequests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/lyricapp/lyric/web/internal/apperror"
+	chordrequestsvc "github.com/lyricapp/lyric/web/internal/services/chordrequests"
+	"github.com/lyricapp/lyric/web/internal/storage"
+	"github.com/lyricapp/lyric/web/pkg/pagination"
+)
+
+// Repository provides Postgres-backed chord request persistence.
+type Repository struct {
+	db storage.Querier
+}
+
+// NewRepository constructs a Repository instance.
+func NewRepository(db storage.Querier) *Repository {
+	return &Repository{db: db}
+}
+
+const requestColumns = `
+    cr.id, cr.name, cr.status, cr.created_at, cr.fulfilled_at,
+    (select count(*) from chord_request_votes v where v.chord_request_id = cr.id) as votes
+`
+
+// InLibrary reports whether the chord already has hand-entered diagrams.
+func (r *Repository) InLibrary(ctx context.Context, canonical string) (bool, error) {
+	var exists bool
+	if err := r.db.QueryRow(ctx, `
+        select exists (
+            select 1
+            from chords c
+            join chord_positions p on p.chord_id = c.id
+            where c.canonical_name = $1
+        )
+    `, canonical).Scan(&exists); err != nil {
+		return false, fmt.Errorf("check chord library: %w", err)
+	}
+	return exists, nil
+}
+
+// Vote adds the user's vote to the open request for the chord, creating it when missing.
+func (r *Repository) Vote(ctx context.Context, canonical string, userID int, songID *int) (chordrequestsvc.Request, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return chordrequestsvc.Request{}, fmt.Errorf("begin chord request tx: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var requestID int
+	if err := tx.QueryRow(ctx, `
+        insert into chord_requests (name)
+        values ($1)
+        on conflict (name) where status = 'open'
+        do update set updated_at = now()
+        returning id
+    `, canonical).Scan(&requestID); err != nil {
+		return chordrequestsvc.Request{}, fmt.Errorf("upsert chord request: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, `
+        insert into chord_request_votes (chord_request_id, user_id, song_id)
+        values ($1, $2, $3)
+        on conflict (chord_request_id, user_id)
+        do update set song_id = coalesce(excluded.song_id, chord_request_votes.song_id)
+    `, requestID, userID, songID); err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.ForeignKeyViolation {
+			return chordrequestsvc.Request{}, apperror.BadRequest("invalid song_id")
+		}
+		return chordrequestsvc.Request{}, fmt.Errorf("insert chord request vote: %w", err)
+	}
+
+	request, err := scanRequest(tx.QueryRow(ctx, `select `+requestColumns+` from chord_requests cr where cr.id = $1`, requestID))
+	if err != nil {
+		return chordrequestsvc.Request{}, err
+	}
+
+	requests := []chordrequestsvc.Request{request}
+	if err := attachSongs(ctx, tx, requests); err != nil {
+		return chordrequestsvc.Request{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return chordrequestsvc.Request{}, fmt.Errorf("commit chord request tx: %w", err)
+	}
+	return requests[0], nil
+}
+
+// Queue returns open requests ordered by vote count, oldest first on ties.
+func (r *Repository) Queue(ctx context.Context, params chordrequestsvc.ListParams) (chordrequestsvc.ListResult, error) {
+	result := chordrequestsvc.ListResult{
+		Data:    make([]chordrequestsvc.Request, 0),
+		Page:    params.Page,
+		PerPage: params.PerPage,
+	}
+
+	if err := r.db.QueryRow(ctx, `select count(*) from chord_requests where status = 'open'`).Scan(&result.Total); err != nil {
+		return chordrequestsvc.ListResult{}, fmt.Errorf("count chord requests: %w", err)
+	}
+	if result.Total == 0 {
+		return result, nil
+	}
+
+	rows, err := r.db.Query(ctx, `
+        select `+requestColumns+`
+        from chord_requests cr
+        where cr.status = 'open'
+        order by votes desc, cr.created_at asc, cr.id asc
+        limit $1 offset $2
+    `, params.PerPage, pagination.Offset(params.Page, params.PerPage))
+	if err != nil {
+		return chordrequestsvc.ListResult{}, fmt.Errorf("list chord requests: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		request, err := scanRequest(rows)
+		if err != nil {
+			return chordrequestsvc.ListResult{}, err
+		}
+		result.Data = append(result.Data, request)
+	}
+	if err := rows.Err(); err != nil {
+		return chordrequestsvc.ListResult{}, fmt.Errorf("iterate chord requests: %w", err)
+	}
+
+	if err := attachSongs(ctx, r.db, result.Data); err != nil {
+		return chordrequestsvc.ListResult{}, err
+	}
+	return result, nil
+}
+
+// Fulfil closes the open request for the chord and returns the requesters not yet notified.
+// A chord nobody asked for yields an empty request and no requesters.
+func (r *Repository) Fulfil(ctx context.Context, canonical string, chordID int) (chordrequestsvc.Request, []chordrequestsvc.Requester, error) {
+	request, err := scanRequest(r.db.QueryRow(ctx, `
+        update chord_requests cr
+        set status = 'fulfilled', chord_id = $2, fulfilled_at = now()
+        where cr.name = $1 and cr.status = 'open'
+        returning `+requestColumns,
+		canonical, chordID))
+	if err != nil {
+		var appErr *apperror.AppError
+		if errors.As(err, &appErr) {
+			return chordrequestsvc.Request{}, nil, nil
+		}
+		return chordrequestsvc.Request{}, nil, err
+	}
+
+	rows, err := r.db.Query(ctx, `
+        select u.id, u.email
+        from chord_request_votes v
+        join users u on u.id = v.user_id
+        where v.chord_request_id = $1
+            and v.notified_at is null
+            and u.status = 'active'
+        order by v.created_at asc
+    `, request.ID)
+	if err != nil {
+		return chordrequestsvc.Request{}, nil, fmt.Errorf("list chord requesters: %w", err)
+	}
+	defer rows.Close()
+
+	requesters := make([]chordrequestsvc.Requester, 0)
+	for rows.Next() {
+		var requester chordrequestsvc.Requester
+		if err := rows.Scan(&requester.UserID, &requester.Email); err != nil {
+			return chordrequestsvc.Request{}, nil, fmt.Errorf("scan chord requester: %w", err)
+		}
+		requesters = append(requesters, requester)
+	}
+	if err := rows.Err(); err != nil {
+		return chordrequestsvc.Request{}, nil, fmt.Errorf("iterate chord requesters: %w", err)
+	}
+
+	return request, requesters, nil
+}
+
+// MarkNotified stamps the votes whose requesters have been told about the chord.
+func (r *Repository) MarkNotified(ctx context.Context, requestID int, userIDs []int) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+	if _, err := r.db.Exec(ctx, `
+        update chord_request_votes
+        set notified_at = now()
+        where chord_request_id = $1 and user_id = any($2)
+    `, requestID, userIDs); err != nil {
+		return fmt.Errorf("mark chord requesters notified: %w", err)
+	}
+	return nil
+}
+
+func scanRequest(row pgx.Row) (chordrequestsvc.Request, error) {
+	request := chordrequestsvc.Request{Songs: make([]chordrequestsvc.Song, 0)}
+	if err := row.Scan(&request.ID, &request.Name, &request.Status, &request.CreatedAt, &request.FulfilledAt, &request.Votes); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return chordrequestsvc.Request{}, apperror.NotFound("chord request not found")
+		}
+		return chordrequestsvc.Request{}, fmt.Errorf("scan chord request: %w", err)
+	}
+	return request, nil
+}
+
+func attachSongs(ctx context.Context, db storage.Querier, requests []chordrequestsvc.Request) error {
+	if len(requests) == 0 {
+		return nil
+	}
+
+	index := make(map[int]int, len(requests))
+	ids := make([]int, 0, len(requests))
+	for i, request := range requests {
+		index[request.ID] = i
+		ids = append(ids, request.ID)
+	}
+
+	rows, err := db.Query(ctx, `
+        select distinct v.chord_request_id, s.id, s.title
+        from chord_request_votes v
+        join songs s on s.id = v.song_id
+        where v.chord_request_id = any($1)
+        order by s.title asc
+    `, ids)
+	if err != nil {
+		return fmt.Errorf("list chord request songs: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			requestID int
+			song      chordrequestsvc.Song
+		)
+		if err := rows.Scan(&requestID, &song.ID, &song.Title); err != nil {
+			return fmt.Errorf("scan chord request song: %w", err)
+		}
+		if i, ok := index[requestID]; ok {
+			requests[i].Songs = append(requests[i].Songs, song)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("iterate chord request songs: %w", err)
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package chords
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -24,25 +25,32 @@ func NewRepository(db storage.Querier) *Repository {
 	return &Repository{db: db}
 }
 
-// Find locates a chord by name and loads its positions.
-func (r *Repository) Find(ctx context.Context, name string) (chords.Chord, error) {
-	chord := chords.Chord{}
+// Find locates a chord by canonical name, falling back to a case-insensitive match
+// on the stored name for names that are not chord symbols, and loads its
+// positions for the instrument.
+func (r *Repository) Find(ctx context.Context, name, canonical string, instrument chords.Instrument) (chords.Chord, error) {
+	chord := chords.Chord{Instrument: instrument}
+	var canonicalName sql.NullString
 
 	row := r.db.QueryRow(ctx, `
-        select id, name
+        select id, name, canonical_name
         from chords
-        where lower(name) = lower($1)
+        where canonical_name = $2 or lower(name) = lower($1)
+        order by (canonical_name = $2) desc nulls last, id asc
         limit 1
-    `, strings.TrimSpace(name))
+    `, strings.TrimSpace(name), canonical)
 
-	if err := row.Scan(&chord.ID, &chord.Name); err != nil {
+	if err := row.Scan(&chord.ID, &chord.Name, &canonicalName); err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return chords.Chord{}, apperror.NotFound("chord not found")
 		}
 		return chords.Chord{}, fmt.Errorf("find chord: %w", err)
 	}
+	if canonicalName.Valid {
+		chord.CanonicalName = canonicalName.String
+	}
 
-	positions, err := r.fetchPositions(ctx, chord.ID)
+	positions, err := r.fetchPositions(ctx, chord.ID, instrument)
 	if err != nil {
 		return chords.Chord{}, err
 	}
@@ -51,13 +59,13 @@ func (r *Repository) Find(ctx context.Context, name string) (chords.Chord, error
 	return chord, nil
 }
 
-func (r *Repository) fetchPositions(ctx context.Context, chordID int) ([]chords.Position, error) {
+func (r *Repository) fetchPositions(ctx context.Context, chordID int, instrument chords.Instrument) ([]chords.Position, error) {
 	rows, err := r.db.Query(ctx, `
-        select id, base_fret, frets, fingers
+        select id, base_fret, frets, fingers, keys
         from chord_positions
-        where chord_id = $1
+        where chord_id = $1 and instrument = $2
         order by id asc
-    `, chordID)
+    `, chordID, string(instrument))
 	if err != nil {
 		return nil, fmt.Errorf("list chord positions: %w", err)
 	}
@@ -68,13 +76,18 @@ func (r *Repository) fetchPositions(ctx context.Context, chordID int) ([]chords.
 	for rows.Next() {
 		var (
 			pos         chords.Position
+			baseFret    sql.NullInt32
 			fretsJSON   []byte
 			fingersJSON []byte
+			keysJSON    []byte
 		)
 
-		if err := rows.Scan(&pos.ID, &pos.BaseFret, &fretsJSON, &fingersJSON); err != nil {
+		if err := rows.Scan(&pos.ID, &baseFret, &fretsJSON, &fingersJSON, &keysJSON); err != nil {
 			return nil, fmt.Errorf("scan chord position: %w", err)
 		}
+		if baseFret.Valid {
+			pos.BaseFret = int(baseFret.Int32)
+		}
 
 		var frets []int
 		if len(fretsJSON) > 0 {
@@ -92,6 +105,14 @@ func (r *Repository) fetchPositions(ctx context.Context, chordID int) ([]chords.
 		}
 		pos.Fingers = fingers
 
+		var keys []int
+		if len(keysJSON) > 0 {
+			if err := json.Unmarshal(keysJSON, &keys); err != nil {
+				return nil, fmt.Errorf("decode chord keys: %w", err)
+			}
+		}
+		pos.Keys = keys
+
 		positions = append(positions, pos)
 	}
 
@@ -101,3 +122,64 @@ func (r *Repository) fetchPositions(ctx context.Context, chordID int) ([]chords.
 
 	return positions, nil
 }
+
+// Create upserts the chord by canonical name and appends positions for the instrument.
+func (r *Repository) Create(ctx context.Context, canonical string, instrument chords.Instrument, positions []chords.Position) (chords.Chord, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return chords.Chord{}, fmt.Errorf("begin chord tx: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	chord := chords.Chord{Instrument: instrument}
+	if err := tx.QueryRow(ctx, `
+        insert into chords (name, canonical_name)
+        values ($1, $1)
+        on conflict (canonical_name) where canonical_name is not null
+        do update set updated_at = now()
+        returning id, name, canonical_name
+    `, canonical).Scan(&chord.ID, &chord.Name, &chord.CanonicalName); err != nil {
+		return chords.Chord{}, fmt.Errorf("upsert chord: %w", err)
+	}
+
+	for _, pos := range positions {
+		var baseFret *int
+		if pos.BaseFret > 0 {
+			baseFret = &pos.BaseFret
+		}
+		fretsJSON, err := encodeNullable(pos.Frets)
+		if err != nil {
+			return chords.Chord{}, fmt.Errorf("encode chord frets: %w", err)
+		}
+		fingersJSON, err := encodeNullable(pos.Fingers)
+		if err != nil {
+			return chords.Chord{}, fmt.Errorf("encode chord fingers: %w", err)
+		}
+		keysJSON, err := encodeNullable(pos.Keys)
+		if err != nil {
+			return chords.Chord{}, fmt.Errorf("encode chord keys: %w", err)
+		}
+
+		if err := tx.QueryRow(ctx, `
+            insert into chord_positions (chord_id, instrument, base_fret, frets, fingers, keys)
+            values ($1, $2, $3, $4, $5, $6)
+            returning id
+        `, chord.ID, string(instrument), baseFret, fretsJSON, fingersJSON, keysJSON).Scan(&pos.ID); err != nil {
+			return chords.Chord{}, fmt.Errorf("insert chord position: %w", err)
+		}
+		chord.Positions = append(chord.Positions, pos)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return chords.Chord{}, fmt.Errorf("commit chord tx: %w", err)
+	}
+	return chord, nil
+}
+
+// encodeNullable marshals slices to JSON, storing empty slices as SQL null.
+func encodeNullable[T any](values []T) ([]byte, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(values)
+}
@@ -0,0 +1,219 @@
+package deltasync
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	deltasyncsvc "github.com/lyricapp/lyric/web/internal/services/deltasync"
+	"github.com/lyricapp/lyric/web/internal/storage"
+)
+
+// Repository provides Postgres-backed change queries for delta sync.
+type Repository struct {
+	db storage.Querier
+}
+
+// NewRepository constructs a Repository instance.
+func NewRepository(db storage.Querier) *Repository {
+	return &Repository{db: db}
+}
+
+// windowClause filters and orders rows of alias by (column, id) inside the
+// window, with the window arguments at $2..$4 and the limit at $5.
+func windowClause(alias, column string) string {
+	return fmt.Sprintf(`
+        and (%[1]s.%[2]s, %[1]s.id) > ($2::timestamp, $3::int)
+        and %[1]s.%[2]s <= $4::timestamp
+        order by %[1]s.%[2]s asc, %[1]s.id asc
+        limit $5`, alias, column)
+}
+
+func windowArgs(userID int, window deltasyncsvc.Window, limit int) []any {
+	return []any{userID, window.From.At.UTC(), window.From.ID, window.Until.UTC(), limit}
+}
+
+// Clock returns the database's now() as a timestamp, less settle.
+func (r *Repository) Clock(ctx context.Context, settle time.Duration) (time.Time, error) {
+	var now time.Time
+	if err := r.db.QueryRow(ctx, `
+        select (now() - make_interval(secs => $1))::timestamp
+    `, settle.Seconds()).Scan(&now); err != nil {
+		return time.Time{}, fmt.Errorf("read sync clock: %w", err)
+	}
+	return now, nil
+}
+
+// Changes reads rows of one collection updated inside the window.
+func (r *Repository) Changes(ctx context.Context, entity deltasyncsvc.Entity, userID int, window deltasyncsvc.Window, limit int, into *deltasyncsvc.Changes) ([]deltasyncsvc.Position, error) {
+	args := windowArgs(userID, window, limit)
+	switch entity {
+	case deltasyncsvc.EntityLanguages:
+		return r.named(ctx, "languages", args, &into.Languages)
+	case deltasyncsvc.EntityLevels:
+		return r.named(ctx, "levels", args, &into.Levels)
+	case deltasyncsvc.EntityArtists:
+		return r.named(ctx, "artists", args, &into.Artists)
+	case deltasyncsvc.EntityWriters:
+		return r.named(ctx, "writers", args, &into.Writers)
+	case deltasyncsvc.EntityAlbums:
+		return r.albums(ctx, args, &into.Albums)
+	case deltasyncsvc.EntitySongs:
+		return r.songs(ctx, args, &into.Songs)
+	case deltasyncsvc.EntityPlaylists:
+		return r.playlists(ctx, args, &into.Playlists)
+	default:
+		return nil, fmt.Errorf("sync: unknown entity %q", entity)
+	}
+}
+
+func (r *Repository) named(ctx context.Context, table string, args []any, into *[]deltasyncsvc.Named) ([]deltasyncsvc.Position, error) {
+	// $1 is the user id, unused for shared collections.
+	rows, err := r.db.Query(ctx, `
+        select t.id, t.name, t.updated_at
+        from `+table+` t
+        where $1::int is not null`+windowClause("t", "updated_at"), args...)
+	if err != nil {
+		return nil, fmt.Errorf("sync %s: %w", table, err)
+	}
+	return collect(rows, into, func(row pgx.Rows, item *deltasyncsvc.Named) (deltasyncsvc.Position, error) {
+		err := row.Scan(&item.ID, &item.Name, &item.UpdatedAt)
+		return deltasyncsvc.Position{At: item.UpdatedAt, ID: item.ID}, err
+	})
+}
+
+func (r *Repository) albums(ctx context.Context, args []any, into *[]deltasyncsvc.Album) ([]deltasyncsvc.Position, error) {
+	rows, err := r.db.Query(ctx, `
+        select a.id, a.name, a.release_year, a.updated_at
+        from albums a
+        where $1::int is not null`+windowClause("a", "updated_at"), args...)
+	if err != nil {
+		return nil, fmt.Errorf("sync albums: %w", err)
+	}
+	return collect(rows, into, func(row pgx.Rows, item *deltasyncsvc.Album) (deltasyncsvc.Position, error) {
+		err := row.Scan(&item.ID, &item.Name, &item.ReleaseYear, &item.UpdatedAt)
+		return deltasyncsvc.Position{At: item.UpdatedAt, ID: item.ID}, err
+	})
+}
+
+func (r *Repository) songs(ctx context.Context, args []any, into *[]deltasyncsvc.Song) ([]deltasyncsvc.Position, error) {
+	rows, err := r.db.Query(ctx, `
+        select
+            s.id, s.title, s.key, s.lyric, s.level_id, s.consensus_level_id, s.language_id, s.release_year,
+            s.bpm, s.time_signature, s.duration_seconds, s.capo, s.status,
+            coalesce((select array_agg(ars.artist_id order by ars.artist_id) from artist_song ars where ars.song_id = s.id), '{}'),
+            coalesce((select array_agg(sw.writer_id order by sw.writer_id) from song_writer sw where sw.song_id = s.id), '{}'),
+            coalesce((select array_agg(als.album_id order by als.album_id) from album_song als where als.song_id = s.id), '{}'),
+            s.updated_at
+        from songs s
+        where $1::int is not null`+windowClause("s", "updated_at"), args...)
+	if err != nil {
+		return nil, fmt.Errorf("sync songs: %w", err)
+	}
+	return collect(rows, into, func(row pgx.Rows, item *deltasyncsvc.Song) (deltasyncsvc.Position, error) {
+		var bpm, capo *int16
+		err := row.Scan(&item.ID, &item.Title, &item.Key, &item.Lyric, &item.LevelID, &item.ConsensusLevelID, &item.LanguageID, &item.ReleaseYear,
+			&bpm, &item.TimeSignature, &item.DurationSeconds, &capo, &item.Status,
+			&item.ArtistIDs, &item.WriterIDs, &item.AlbumIDs, &item.UpdatedAt)
+		item.BPM = intOrNil(bpm)
+		item.Capo = intOrNil(capo)
+		return deltasyncsvc.Position{At: item.UpdatedAt, ID: item.ID}, err
+	})
+}
+
+// playlists reads the playlists the user owns or is a member of. Changes to
+// songs, arrangements and members touch the playlist's updated_at.
+func (r *Repository) playlists(ctx context.Context, args []any, into *[]deltasyncsvc.Playlist) ([]deltasyncsvc.Position, error) {
+	rows, err := r.db.Query(ctx, `
+        select
+            p.id,
+            p.name,
+            case when p.user_id = $1 then 'owner' else pu.role end,
+            coalesce((
+                select json_agg(json_build_object(
+                    'song_id', ps.song_id,
+                    'position', ps.position,
+                    'transpose', ps.transpose,
+                    'capo', ps.capo,
+                    'display_mode', ps.display_mode,
+                    'note', ps.note
+                ) order by ps.position, ps.song_id)
+                from playlist_song ps
+                where ps.playlist_id = p.id
+            ), '[]'::json),
+            p.updated_at
+        from playlists p
+        left join playlist_user pu on pu.playlist_id = p.id and pu.user_id = $1
+        where (p.user_id = $1 or pu.user_id is not null)`+windowClause("p", "updated_at"), args...)
+	if err != nil {
+		return nil, fmt.Errorf("sync playlists: %w", err)
+	}
+	return collect(rows, into, func(row pgx.Rows, item *deltasyncsvc.Playlist) (deltasyncsvc.Position, error) {
+		err := row.Scan(&item.ID, &item.Name, &item.Role, &item.Songs, &item.UpdatedAt)
+		return deltasyncsvc.Position{At: item.UpdatedAt, ID: item.ID}, err
+	})
+}
+
+// Tombstones reads deletions inside the window. Playlist tombstones are only
+// returned to the user who lost the playlist, and not while they can still see
+// it, since a member may be removed and added back.
+func (r *Repository) Tombstones(ctx context.Context, userID int, window deltasyncsvc.Window, limit int) ([]deltasyncsvc.Tombstone, error) {
+	rows, err := r.db.Query(ctx, `
+        select t.id, t.entity, t.entity_id, t.deleted_at
+        from sync_tombstones t
+        where (t.user_id is null or t.user_id = $1)
+          and not (
+              t.entity = 'playlists' and exists (
+                  select 1 from playlists p
+                  where p.id = t.entity_id
+                    and (p.user_id = $1 or exists (
+                        select 1 from playlist_user pu where pu.playlist_id = p.id and pu.user_id = $1
+                    ))
+              )
+          )`+windowClause("t", "deleted_at"), windowArgs(userID, window, limit)...)
+	if err != nil {
+		return nil, fmt.Errorf("sync tombstones: %w", err)
+	}
+	tombstones := make([]deltasyncsvc.Tombstone, 0)
+	_, err = collect(rows, &tombstones, func(row pgx.Rows, item *deltasyncsvc.Tombstone) (deltasyncsvc.Position, error) {
+		var entity string
+		var id int64
+		err := row.Scan(&id, &entity, &item.EntityID, &item.At)
+		item.ID = int(id)
+		item.Entity = deltasyncsvc.Entity(entity)
+		return item.Position, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return tombstones, nil
+}
+
+func collect[T any](rows pgx.Rows, into *[]T, scan func(pgx.Rows, *T) (deltasyncsvc.Position, error)) ([]deltasyncsvc.Position, error) {
+	defer rows.Close()
+
+	positions := make([]deltasyncsvc.Position, 0)
+	for rows.Next() {
+		var item T
+		position, err := scan(rows, &item)
+		if err != nil {
+			return nil, fmt.Errorf("scan sync row: %w", err)
+		}
+		*into = append(*into, item)
+		positions = append(positions, position)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate sync rows: %w", err)
+	}
+	return positions, nil
+}
+
+func intOrNil(value *int16) *int {
+	if value == nil {
+		return nil
+	}
+	result := int(*value)
+	return &result
+}
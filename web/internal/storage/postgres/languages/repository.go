@@ -3,6 +3,7 @@ package languages
 import (
 	"context"
 	"fmt"
+	"time"
 
 	languagesvc "github.com/lyricapp/lyric/web/internal/services/languages"
 	"github.com/lyricapp/lyric/web/internal/storage"
@@ -46,3 +47,12 @@ func (r *Repository) List(ctx context.Context) ([]languagesvc.Language, error) {
 
 	return languages, nil
 }
+
+// LastModified returns when the languages last changed.
+func (r *Repository) LastModified(ctx context.Context) (time.Time, error) {
+	at, err := storage.LastModified(ctx, r.db, "languages")
+	if err != nil {
+		return time.Time{}, fmt.Errorf("languages last modified: %w", err)
+	}
+	return at, nil
+}
@@ -3,6 +3,7 @@ package levels
 import (
 	"context"
 	"fmt"
+	"time"
 
 	levelsvc "github.com/lyricapp/lyric/web/internal/services/levels"
 	"github.com/lyricapp/lyric/web/internal/storage"
@@ -46,3 +47,12 @@ func (r *Repository) List(ctx context.Context) ([]levelsvc.Level, error) {
 
 	return levels, nil
 }
+
+// LastModified returns when the levels last changed.
+func (r *Repository) LastModified(ctx context.Context) (time.Time, error) {
+	at, err := storage.LastModified(ctx, r.db, "levels")
+	if err != nil {
+		return time.Time{}, fmt.Errorf("levels last modified: %w", err)
+	}
+	return at, nil
+}
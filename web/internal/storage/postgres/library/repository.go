@@ -0,0 +1,144 @@
+package library
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	librarysvc "github.com/lyricapp/lyric/web/internal/services/library"
+	"github.com/lyricapp/lyric/web/internal/storage"
+)
+
+// favoriteTables maps each favourite kind to its join table, the join
+// table's catalogue column and the catalogue table.
+var favoriteTables = map[librarysvc.Kind]struct {
+	table, column, target string
+}{
+	librarysvc.KindSong:   {"favorite_songs", "song_id", "songs"},
+	librarysvc.KindAlbum:  {"favorite_albums", "album_id", "albums"},
+	librarysvc.KindArtist: {"favorite_artists", "artist_id", "artists"},
+}
+
+// songArtists selects the names of a song's artists; s is the songs alias.
+const songArtists = `array(
+	select a.name
+	from artist_song ars
+	join artists a on a.id = ars.artist_id
+	where ars.song_id = s.id
+	order by a.name
+)`
+
+// Repository provides Postgres-backed favourites and play history.
+type Repository struct {
+	db storage.Querier
+}
+
+// NewRepository constructs a Repository instance.
+func NewRepository(db storage.Querier) *Repository {
+	return &Repository{db: db}
+}
+
+// Favorites lists the user's favourite songs, albums and artists.
+func (r *Repository) Favorites(ctx context.Context, userID int) (librarysvc.Favorites, error) {
+	var (
+		favorites librarysvc.Favorites
+		err       error
+	)
+	if favorites.Songs, err = storage.QueryAll(ctx, r.db, func(rows pgx.Rows, song *librarysvc.FavoriteSong) error {
+		return rows.Scan(&song.ID, &song.Title, &song.Key, &song.Artists, &song.FavoritedAt)
+	}, `
+		select s.id, s.title, s.key, `+songArtists+`, fs.created_at
+		from favorite_songs fs
+		join songs s on s.id = fs.song_id
+		where fs.user_id = $1
+		order by fs.created_at desc, s.id desc
+	`, userID); err != nil {
+		return librarysvc.Favorites{}, fmt.Errorf("list favourite songs: %w", err)
+	}
+
+	scanFavorite := func(rows pgx.Rows, favorite *librarysvc.Favorite) error {
+		return rows.Scan(&favorite.ID, &favorite.Name, &favorite.FavoritedAt)
+	}
+	if favorites.Albums, err = storage.QueryAll(ctx, r.db, scanFavorite, `
+		select a.id, a.name, fa.created_at
+		from favorite_albums fa
+		join albums a on a.id = fa.album_id
+		where fa.user_id = $1
+		order by fa.created_at desc, a.id desc
+	`, userID); err != nil {
+		return librarysvc.Favorites{}, fmt.Errorf("list favourite albums: %w", err)
+	}
+	if favorites.Artists, err = storage.QueryAll(ctx, r.db, scanFavorite, `
+		select a.id, a.name, fa.created_at
+		from favorite_artists fa
+		join artists a on a.id = fa.artist_id
+		where fa.user_id = $1
+		order by fa.created_at desc, a.id desc
+	`, userID); err != nil {
+		return librarysvc.Favorites{}, fmt.Errorf("list favourite artists: %w", err)
+	}
+
+	return favorites, nil
+}
+
+// AddFavorite favourites the entry, reporting false when it does not exist.
+func (r *Repository) AddFavorite(ctx context.Context, userID int, kind librarysvc.Kind, id int) (bool, error) {
+	tables, ok := favoriteTables[kind]
+	if !ok {
+		return false, nil
+	}
+	var exists bool
+	err := r.db.QueryRow(ctx, fmt.Sprintf(`
+		with target as (
+			select id from %[3]s where id = $2
+		), added as (
+			insert into %[1]s (user_id, %[2]s)
+			select $1, id from target
+			on conflict (user_id, %[2]s) do nothing
+		)
+		select exists (select 1 from target)
+	`, tables.table, tables.column, tables.target), userID, id).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("add favourite %s: %w", kind, err)
+	}
+	return exists, nil
+}
+
+// RemoveFavorite removes the entry from the user's favourites.
+func (r *Repository) RemoveFavorite(ctx context.Context, userID int, kind librarysvc.Kind, id int) error {
+	tables, ok := favoriteTables[kind]
+	if !ok {
+		return nil
+	}
+	if _, err := r.db.Exec(ctx, fmt.Sprintf(`
+		delete from %s
+		where user_id = $1 and %s = $2
+	`, tables.table, tables.column), userID, id); err != nil {
+		return fmt.Errorf("remove favourite %s: %w", kind, err)
+	}
+	return nil
+}
+
+// Recent lists the songs the user played most recently, once each.
+func (r *Repository) Recent(ctx context.Context, userID, limit int) ([]librarysvc.RecentSong, error) {
+	songs, err := storage.QueryAll(ctx, r.db, func(rows pgx.Rows, song *librarysvc.RecentSong) error {
+		return rows.Scan(&song.ID, &song.Title, &song.Key, &song.Artists, &song.ViewedAt, &song.Views)
+	}, `
+		with viewed as (
+			select song_id, max(created_at) as viewed_at, count(*) as views
+			from plays
+			where user_id = $1
+			group by song_id
+			order by viewed_at desc
+			limit $2
+		)
+		select s.id, s.title, s.key, `+songArtists+`, v.viewed_at, v.views
+		from viewed v
+		join songs s on s.id = v.song_id
+		order by v.viewed_at desc, s.id desc
+	`, userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list recent songs: %w", err)
+	}
+	return songs, nil
+}
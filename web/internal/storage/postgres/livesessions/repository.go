@@ -0,0 +1,53 @@
+package livesessions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+
+	playlistsvc "github.com/lyricapp/lyric/web/internal/services/playlists"
+	"github.com/lyricapp/lyric/web/internal/storage"
+)
+
+// Repository provides Postgres-backed playlist access checks for live sessions.
+type Repository struct {
+	db storage.Querier
+}
+
+// NewRepository constructs a Repository instance.
+func NewRepository(db storage.Querier) *Repository {
+	return &Repository{db: db}
+}
+
+// Role returns the owner role for the playlist owner, the collaborator role for
+// users it is shared with, and nil for anyone else.
+func (r *Repository) Role(ctx context.Context, playlistID, userID int) (*playlistsvc.Role, error) {
+	var role string
+	err := r.db.QueryRow(ctx, `
+        select 'owner' from playlists where id = $1 and user_id = $2
+        union all
+        select pu.role from playlist_user pu where pu.playlist_id = $1 and pu.user_id = $2
+        limit 1
+    `, playlistID, userID).Scan(&role)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("load playlist role: %w", err)
+	}
+	result := playlistsvc.Role(role)
+	return &result, nil
+}
+
+// HasSong reports whether the song is part of the playlist.
+func (r *Repository) HasSong(ctx context.Context, playlistID, songID int) (bool, error) {
+	var exists bool
+	if err := r.db.QueryRow(ctx, `
+        select exists(select 1 from playlist_song where playlist_id = $1 and song_id = $2)
+    `, playlistID, songID).Scan(&exists); err != nil {
+		return false, fmt.Errorf("check playlist song: %w", err)
+	}
+	return exists, nil
+}
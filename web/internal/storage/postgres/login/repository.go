@@ -28,11 +28,11 @@ func (r *Repository) FindOrCreateUser(ctx context.Context, email string) (logins
 	email = strings.TrimSpace(strings.ToLower(email))
 	var user loginsvc.User
 	selectQuery := `
-		SELECT id, email, role, status
+		SELECT id, email, role, status, purge_after
 		FROM users
 		WHERE email = $1
 	`
-	err := r.db.QueryRow(ctx, selectQuery, email).Scan(&user.ID, &user.Email, &user.Role, &user.Status)
+	err := r.db.QueryRow(ctx, selectQuery, email).Scan(&user.ID, &user.Email, &user.Role, &user.Status, &user.PurgeAfter)
 	if err == nil {
 		return user, nil
 	}
@@ -42,9 +42,9 @@ func (r *Repository) FindOrCreateUser(ctx context.Context, email string) (logins
 	insertQuery := `
 		INSERT INTO users (email, role, status)
 		VALUES ($1, 'musician', 'active')
-		RETURNING id, email, role, status
+		RETURNING id, email, role, status, purge_after
 	`
-	err = r.db.QueryRow(ctx, insertQuery, email).Scan(&user.ID, &user.Email, &user.Role, &user.Status)
+	err = r.db.QueryRow(ctx, insertQuery, email).Scan(&user.ID, &user.Email, &user.Role, &user.Status, &user.PurgeAfter)
 	if err != nil {
 		return loginsvc.User{}, fmt.Errorf("insert user: %w", err)
 	}
@@ -89,14 +89,14 @@ func (r *Repository) ConsumeLoginCode(ctx context.Context, code string, attempte
 
 	var user loginsvc.User
 	err = tx.QueryRow(ctx, `
-		select u.id, u.email, u.role, u.status
+		select u.id, u.email, u.role, u.status, u.purge_after
 		from user_login_codes ulc
 		join users u on u.id = ulc.user_id
 		where ulc.code = $1
 		  and ulc.used_at is null
 		  and ulc.expires_at >= $2
 		for update
-	`, code, attemptedAt).Scan(&user.ID, &user.Email, &user.Role, &user.Status)
+	`, code, attemptedAt).Scan(&user.ID, &user.Email, &user.Role, &user.Status, &user.PurgeAfter)
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			return loginsvc.User{}, false, apperror.Validation("msg", map[string]string{"code": "invalid code"})
@@ -104,7 +104,7 @@ func (r *Repository) ConsumeLoginCode(ctx context.Context, code string, attempte
 		return loginsvc.User{}, false, fmt.Errorf("select login code: %w", err)
 	}
 
-	if !strings.EqualFold(strings.TrimSpace(user.Status), "active") {
+	if !user.CanSignIn(attemptedAt) {
 		return loginsvc.User{}, false, apperror.Forbidden("account is not active")
 	}
 
@@ -133,10 +133,10 @@ func (r *Repository) ConsumeLoginCode(ctx context.Context, code string, attempte
 func (r *Repository) FindUserByID(ctx context.Context, userID int) (loginsvc.User, error) {
 	var user loginsvc.User
 	err := r.db.QueryRow(ctx, `
-		select id, email, role, status
+		select id, email, role, status, purge_after, signed_out_at
 		from users
 		where id = $1
-	`, userID).Scan(&user.ID, &user.Email, &user.Role, &user.Status)
+	`, userID).Scan(&user.ID, &user.Email, &user.Role, &user.Status, &user.PurgeAfter, &user.SignedOutAt)
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			return loginsvc.User{}, apperror.NotFound("user not found")
@@ -147,18 +147,214 @@ func (r *Repository) FindUserByID(ctx context.Context, userID int) (loginsvc.Use
 	return user, nil
 }
 
-// UpdateUserStatus modifies the status for the specified user.
-func (r *Repository) UpdateUserStatus(ctx context.Context, userID int, status string) error {
+// ScheduleDeletion marks the user deleted and due for purging after purgeAfter.
+func (r *Repository) ScheduleDeletion(ctx context.Context, userID int, purgeAfter time.Time) error {
 	cmdTag, err := r.db.Exec(ctx, `
 		update users
-		set status = $1
+		set status = 'deleted',
+			purge_after = $1
 		where id = $2
-	`, strings.TrimSpace(status), userID)
+	`, purgeAfter.UTC(), userID)
 	if err != nil {
-		return fmt.Errorf("update user status: %w", err)
+		return fmt.Errorf("schedule user deletion: %w", err)
 	}
 	if cmdTag.RowsAffected() == 0 {
 		return apperror.NotFound("user not found")
 	}
 	return nil
 }
+
+// RestoreUser reactivates a deleted user and cancels their purge.
+func (r *Repository) RestoreUser(ctx context.Context, userID int) error {
+	if _, err := r.db.Exec(ctx, `
+		update users
+		set status = 'active',
+			purge_after = null
+		where id = $1
+		  and status = 'deleted'
+	`, userID); err != nil {
+		return fmt.Errorf("restore user: %w", err)
+	}
+	return nil
+}
+
+const sessionColumns = `s.id, s.user_id, s.device_name, s.user_agent, s.created_at, s.last_used_at, s.expires_at`
+
+func scanSession(row pgx.Row, extra ...any) (loginsvc.Session, error) {
+	var session loginsvc.Session
+	dest := append([]any{&session.ID, &session.UserID, &session.DeviceName, &session.UserAgent,
+		&session.CreatedAt, &session.LastUsedAt, &session.ExpiresAt}, extra...)
+	err := row.Scan(dest...)
+	return session, err
+}
+
+// CreateSession stores a new device session.
+func (r *Repository) CreateSession(ctx context.Context, params loginsvc.NewSession) (loginsvc.Session, error) {
+	session, err := scanSession(r.db.QueryRow(ctx, `
+		insert into user_sessions as s (user_id, device_name, user_agent, refresh_token_hash, last_used_at, expires_at, created_at)
+		values ($1, nullif($2, ''), nullif($3, ''), $4, $5, $6, $5)
+		returning `+sessionColumns,
+		params.UserID, params.Device.Name, params.Device.UserAgent, params.TokenHash, params.StartedAt.UTC(), params.ExpiresAt.UTC()))
+	if err != nil {
+		return loginsvc.Session{}, fmt.Errorf("insert session: %w", err)
+	}
+	return session, nil
+}
+
+// RotateSession replaces the refresh token of the live session holding currentHash.
+func (r *Repository) RotateSession(ctx context.Context, currentHash, nextHash string, at, expiresAt time.Time) (loginsvc.Session, loginsvc.User, bool, error) {
+	var user loginsvc.User
+	session, err := scanSession(r.db.QueryRow(ctx, `
+		with rotated as (
+			update user_sessions
+			set refresh_token_hash = $2,
+				previous_token_hash = refresh_token_hash,
+				last_used_at = $3,
+				expires_at = $4
+			where refresh_token_hash = $1
+			  and revoked_at is null
+			  and expires_at > $3
+			returning *
+		)
+		select `+sessionColumns+`, u.id, u.email, u.role, u.status, u.purge_after
+		from rotated s
+		join users u on u.id = s.user_id
+	`, currentHash, nextHash, at.UTC(), expiresAt.UTC()), &user.ID, &user.Email, &user.Role, &user.Status, &user.PurgeAfter)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return loginsvc.Session{}, loginsvc.User{}, false, nil
+		}
+		return loginsvc.Session{}, loginsvc.User{}, false, fmt.Errorf("rotate session: %w", err)
+	}
+	return session, user, true, nil
+}
+
+// RevokeReplayedSession revokes the live session whose previous refresh token is hash.
+func (r *Repository) RevokeReplayedSession(ctx context.Context, hash string, at time.Time) (bool, error) {
+	tag, err := r.db.Exec(ctx, `
+		update user_sessions
+		set revoked_at = $2
+		where previous_token_hash = $1
+		  and revoked_at is null
+	`, hash, at.UTC())
+	if err != nil {
+		return false, fmt.Errorf("revoke replayed session: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// ListSessions returns the user's live sessions, most recently used first.
+func (r *Repository) ListSessions(ctx context.Context, userID int, at time.Time) ([]loginsvc.Session, error) {
+	rows, err := r.db.Query(ctx, `
+		select `+sessionColumns+`
+		from user_sessions s
+		where s.user_id = $1
+		  and s.revoked_at is null
+		  and s.expires_at > $2
+		order by s.last_used_at desc, s.id desc
+	`, userID, at.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("list sessions: %w", err)
+	}
+	defer rows.Close()
+
+	sessions := make([]loginsvc.Session, 0)
+	for rows.Next() {
+		session, err := scanSession(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan session: %w", err)
+		}
+		sessions = append(sessions, session)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate sessions: %w", err)
+	}
+	return sessions, nil
+}
+
+// RevokeSession revokes one live session of the user.
+func (r *Repository) RevokeSession(ctx context.Context, userID int, sessionID int64, at time.Time) (bool, error) {
+	tag, err := r.db.Exec(ctx, `
+		update user_sessions
+		set revoked_at = $3
+		where id = $1
+		  and user_id = $2
+		  and revoked_at is null
+	`, sessionID, userID, at.UTC())
+	if err != nil {
+		return false, fmt.Errorf("revoke session: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// RevokeSessions revokes the user's live sessions other than exceptID.
+func (r *Repository) RevokeSessions(ctx context.Context, userID int, exceptID int64, at time.Time) error {
+	if _, err := r.db.Exec(ctx, `
+		update user_sessions
+		set revoked_at = $3
+		where user_id = $1
+		  and id <> $2
+		  and revoked_at is null
+	`, userID, exceptID, at.UTC()); err != nil {
+		return fmt.Errorf("revoke sessions: %w", err)
+	}
+	return nil
+}
+
+// SessionActive reports whether the session is live and its user still active.
+func (r *Repository) SessionActive(ctx context.Context, userID int, sessionID int64, at time.Time) (bool, error) {
+	var active bool
+	if err := r.db.QueryRow(ctx, `
+		select exists (
+			select 1
+			from user_sessions s
+			join users u on u.id = s.user_id
+			where s.id = $1
+			  and s.user_id = $2
+			  and s.revoked_at is null
+			  and s.expires_at > $3
+			  and u.status = 'active'
+		)
+	`, sessionID, userID, at.UTC()).Scan(&active); err != nil {
+		return false, fmt.Errorf("check session: %w", err)
+	}
+	return active, nil
+}
+
+// FindUserByIdentity returns the user a provider account is linked to.
+func (r *Repository) FindUserByIdentity(ctx context.Context, provider, subject string) (loginsvc.User, bool, error) {
+	var user loginsvc.User
+	err := r.db.QueryRow(ctx, `
+		select u.id, u.email, u.role, u.status, u.purge_after
+		from user_identities i
+		join users u on u.id = i.user_id
+		where i.provider = $1
+		  and i.subject = $2
+	`, provider, subject).Scan(&user.ID, &user.Email, &user.Role, &user.Status, &user.PurgeAfter)
+	if err == pgx.ErrNoRows {
+		return loginsvc.User{}, false, nil
+	}
+	if err != nil {
+		return loginsvc.User{}, false, fmt.Errorf("find user by identity: %w", err)
+	}
+	return user, true, nil
+}
+
+// LinkIdentity stores the provider account for the user, refreshing the email
+// and sign in time when it is already linked.
+func (r *Repository) LinkIdentity(ctx context.Context, userID int, identity loginsvc.Identity, at time.Time) error {
+	var email *string
+	if identity.Email != "" {
+		email = &identity.Email
+	}
+	if _, err := r.db.Exec(ctx, `
+		insert into user_identities (user_id, provider, subject, email, last_login_at)
+		values ($1, $2, $3, $4, $5)
+		on conflict (provider, subject) do update
+		set email = coalesce(excluded.email, user_identities.email),
+		    last_login_at = excluded.last_login_at
+	`, userID, identity.Provider, identity.Subject, email, at.UTC()); err != nil {
+		return fmt.Errorf("link identity: %w", err)
+	}
+	return nil
+}
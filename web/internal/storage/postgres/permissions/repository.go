@@ -0,0 +1,38 @@
+package permissions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/lyricapp/lyric/web/internal/storage"
+)
+
+// Repository reads account roles for permission checks.
+type Repository struct {
+	db storage.Querier
+}
+
+// NewRepository constructs a Repository instance.
+func NewRepository(db storage.Querier) *Repository {
+	return &Repository{db: db}
+}
+
+// ActiveRole returns the role of an active user.
+func (r *Repository) ActiveRole(ctx context.Context, userID int) (string, bool, error) {
+	var role string
+	err := r.db.QueryRow(ctx, `
+		select role
+		from users
+		where id = $1 and status = 'active'
+	`, userID).Scan(&role)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("read user role: %w", err)
+	}
+	return role, true, nil
+}
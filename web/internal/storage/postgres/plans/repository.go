@@ -0,0 +1,50 @@
+package plans
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/lyricapp/lyric/web/internal/apperror"
+	plansvc "github.com/lyricapp/lyric/web/internal/services/plans"
+	"github.com/lyricapp/lyric/web/internal/storage"
+)
+
+// Repository provides Postgres-backed plan lookups.
+type Repository struct {
+	db storage.Querier
+}
+
+// NewRepository constructs a Repository instance.
+func NewRepository(db storage.Querier) *Repository {
+	return &Repository{db: db}
+}
+
+// Plan returns the plan stored on the user row, upgraded to premium while the user
+// holds a subscription or unlock covering at.
+func (r *Repository) Plan(ctx context.Context, userID int, at time.Time) (plansvc.Plan, error) {
+	var plan string
+	if err := r.db.QueryRow(ctx, `
+        select case
+            when exists (
+                select 1
+                from subscriptions s
+                where s.user_id = u.id
+                  and s.starts_at <= $2
+                  and s.expires_at > $2
+            ) then 'premium'
+            else u.plan
+        end
+        from users u
+        where u.id = $1
+    `, userID, at.UTC()).Scan(&plan); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", apperror.NotFound("user not found")
+		}
+		return "", fmt.Errorf("load user plan: %w", err)
+	}
+	return plansvc.Plan(plan), nil
+}
@@ -0,0 +1,188 @@
+package playlistinvites
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/lyricapp/lyric/web/internal/apperror"
+	playlistinvitesvc "github.com/lyricapp/lyric/web/internal/services/playlistinvites"
+	playlistsvc "github.com/lyricapp/lyric/web/internal/services/playlists"
+	"github.com/lyricapp/lyric/web/internal/storage"
+)
+
+// Repository provides Postgres-backed invite link persistence.
+type Repository struct {
+	db storage.Querier
+}
+
+// NewRepository constructs a Repository instance.
+func NewRepository(db storage.Querier) *Repository {
+	return &Repository{db: db}
+}
+
+const inviteColumns = `
+    i.id, i.playlist_id, i.role, i.max_uses, i.uses, i.expires_at, i.revoked_at, i.created_at
+`
+
+func scanInvite(row pgx.Row, invite *playlistinvitesvc.Invite, extra ...any) error {
+	var role string
+	dest := append([]any{&invite.ID, &invite.PlaylistID, &role, &invite.MaxUses, &invite.Uses,
+		&invite.ExpiresAt, &invite.RevokedAt, &invite.CreatedAt}, extra...)
+	if err := row.Scan(dest...); err != nil {
+		return err
+	}
+	invite.Role = playlistsvc.Role(role)
+	return nil
+}
+
+// Create stores a hashed invite token for a playlist owned by params.OwnerID.
+func (r *Repository) Create(ctx context.Context, params playlistinvitesvc.CreateParams, role playlistsvc.Role, tokenHash string, expiresAt time.Time) (playlistinvitesvc.Invite, error) {
+	var invite playlistinvitesvc.Invite
+	err := scanInvite(r.db.QueryRow(ctx, `
+        insert into playlist_invites (playlist_id, created_by, token_hash, role, max_uses, expires_at)
+        select p.id, p.user_id, $3, $4, $5, $6
+        from playlists p
+        where p.id = $1 and p.user_id = $2
+        returning id, playlist_id, role, max_uses, uses, expires_at, revoked_at, created_at
+    `, params.PlaylistID, params.OwnerID, tokenHash, string(role), params.MaxUses, expiresAt.UTC()), &invite)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return playlistinvitesvc.Invite{}, apperror.NotFound("playlist not found")
+		}
+		return playlistinvitesvc.Invite{}, fmt.Errorf("create playlist invite: %w", err)
+	}
+	return invite, nil
+}
+
+// List returns invites that are not revoked, expired or used up.
+func (r *Repository) List(ctx context.Context, playlistID, ownerID int, at time.Time) ([]playlistinvitesvc.Invite, error) {
+	var owned bool
+	if err := r.db.QueryRow(ctx, `
+        select exists(select 1 from playlists where id = $1 and user_id = $2)
+    `, playlistID, ownerID).Scan(&owned); err != nil {
+		return nil, fmt.Errorf("check playlist owner: %w", err)
+	}
+	if !owned {
+		return nil, apperror.NotFound("playlist not found")
+	}
+
+	rows, err := r.db.Query(ctx, `
+        select `+inviteColumns+`
+        from playlist_invites i
+        where i.playlist_id = $1
+          and i.revoked_at is null
+          and i.expires_at > $2
+          and (i.max_uses is null or i.uses < i.max_uses)
+        order by i.created_at desc, i.id desc
+    `, playlistID, at.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("list playlist invites: %w", err)
+	}
+	defer rows.Close()
+
+	invites := make([]playlistinvitesvc.Invite, 0)
+	for rows.Next() {
+		var invite playlistinvitesvc.Invite
+		if err := scanInvite(rows, &invite); err != nil {
+			return nil, fmt.Errorf("scan playlist invite: %w", err)
+		}
+		invites = append(invites, invite)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate playlist invites: %w", err)
+	}
+	return invites, nil
+}
+
+// Revoke marks an invite as revoked. Revoking twice is a no-op.
+func (r *Repository) Revoke(ctx context.Context, playlistID, inviteID, ownerID int, at time.Time) error {
+	cmdTag, err := r.db.Exec(ctx, `
+        update playlist_invites i
+        set revoked_at = coalesce(i.revoked_at, $4)
+        from playlists p
+        where i.id = $2
+          and i.playlist_id = $1
+          and p.id = i.playlist_id
+          and p.user_id = $3
+    `, playlistID, inviteID, ownerID, at.UTC())
+	if err != nil {
+		return fmt.Errorf("revoke playlist invite: %w", err)
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return apperror.NotFound("invite not found")
+	}
+	return nil
+}
+
+// Find loads an invite by token hash together with the playlist owner, the user's
+// current role and how many people the playlist is already shared with.
+func (r *Repository) Find(ctx context.Context, tokenHash string, userID int) (playlistinvitesvc.Redemption, error) {
+	var (
+		redemption  playlistinvitesvc.Redemption
+		currentRole *string
+	)
+	err := scanInvite(r.db.QueryRow(ctx, `
+        select `+inviteColumns+`,
+            p.user_id,
+            (select pu.role from playlist_user pu where pu.playlist_id = p.id and pu.user_id = $2),
+            (select count(*) from playlist_user pu where pu.playlist_id = p.id)
+        from playlist_invites i
+        join playlists p on p.id = i.playlist_id
+        where i.token_hash = $1
+    `, tokenHash, userID), &redemption.Invite, &redemption.OwnerID, &currentRole, &redemption.Shared)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return playlistinvitesvc.Redemption{}, apperror.NotFound("invite not found")
+		}
+		return playlistinvitesvc.Redemption{}, fmt.Errorf("find playlist invite: %w", err)
+	}
+	if currentRole != nil {
+		role := playlistsvc.Role(*currentRole)
+		redemption.CurrentRole = &role
+	}
+	return redemption, nil
+}
+
+// Redeem counts a use of the invite and grants the role, failing if the invite
+// stopped being valid since it was loaded.
+func (r *Repository) Redeem(ctx context.Context, inviteID, userID int, role playlistsvc.Role, at time.Time) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin redeem invite: %w", err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	var playlistID int
+	if err := tx.QueryRow(ctx, `
+        update playlist_invites
+        set uses = uses + 1
+        where id = $1
+          and revoked_at is null
+          and expires_at > $2
+          and (max_uses is null or uses < max_uses)
+        returning playlist_id
+    `, inviteID, at.UTC()).Scan(&playlistID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return apperror.New(http.StatusGone, "invite link is no longer valid", nil)
+		}
+		return fmt.Errorf("count invite use: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, `
+        insert into playlist_user (playlist_id, user_id, role)
+        values ($1, $2, $3)
+        on conflict (playlist_id, user_id) do update set role = excluded.role
+    `, playlistID, userID, string(role)); err != nil {
+		return fmt.Errorf("add playlist member: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit redeem invite: %w", err)
+	}
+	return nil
+}
@@ -77,10 +77,11 @@ func (r *Repository) List(ctx context.Context, params playlistsvc.ListParams) (p
 				group by ps.playlist_id
 			)
 			select p.id, p.name, coalesce(pt.total_songs, 0) as total_songs, (p.user_id = $1) as is_owner,
+				case when p.user_id = $1 then 'owner' else coalesce(pu.role, 'viewer') end as role,
 				case
 					when p.user_id = $1 then coalesce((
 						select jsonb_agg(
-								jsonb_build_object('id', u.id, 'email', u.email)
+								jsonb_build_object('id', u.id, 'email', u.email, 'role', pu2.role)
 								order by u.id
 							)
 						from playlist_user pu2
@@ -96,7 +97,7 @@ func (r *Repository) List(ctx context.Context, params playlistsvc.ListParams) (p
 				and pu.user_id = $1
 			where (p.user_id = $1 or pu.user_id = $1)
 				%s
-			group by p.id, p.name, p.user_id, total_songs
+			group by p.id, p.name, p.user_id, total_songs, pu.role
 			order by p.id desc
 			limit %s offset %s
     `, whereClause, limitPlaceholder, offsetPlaceholder)
@@ -115,7 +116,7 @@ func (r *Repository) List(ctx context.Context, params playlistsvc.ListParams) (p
 
 	for rows.Next() {
 		var playlist playlistsvc.Playlist
-		if err := rows.Scan(&playlist.ID, &playlist.Name, &playlist.Total, &playlist.IsOwner, &playlist.SharedWith); err != nil {
+		if err := rows.Scan(&playlist.ID, &playlist.Name, &playlist.Total, &playlist.IsOwner, &playlist.Role, &playlist.SharedWith); err != nil {
 			return result, fmt.Errorf("scan playlist: %w", err)
 		}
 		playlists = append(playlists, playlist)
@@ -145,6 +146,38 @@ func (r *Repository) Create(ctx context.Context, params playlistsvc.CreateParams
 	return playlistID, nil
 }
 
+// CountOwned returns how many playlists the user owns.
+func (r *Repository) CountOwned(ctx context.Context, userID int) (int, error) {
+	var count int
+	if err := r.db.QueryRow(ctx, `
+        select count(*)
+        from playlists
+        where user_id = $1
+    `, userID).Scan(&count); err != nil {
+		return 0, fmt.Errorf("count owned playlists: %w", err)
+	}
+	return count, nil
+}
+
+// ensurePlaylistEditor allows the owner and collaborators with the editor role.
+func (r *Repository) ensurePlaylistEditor(ctx context.Context, playlistID, userID int) error {
+	var allowed bool
+	if err := r.db.QueryRow(ctx, `
+        select exists(
+                select 1 from playlists where id = $1 and user_id = $2
+        ) or exists(
+                select 1 from playlist_user where playlist_id = $1 and user_id = $2 and role = 'editor'
+        )
+        `, playlistID, userID).Scan(&allowed); err != nil {
+		return fmt.Errorf("check playlist editor: %w", err)
+	}
+	if !allowed {
+		return apperror.Unauthorized("unauthorized user")
+	}
+
+	return nil
+}
+
 func (r *Repository) ensurePlaylistOwner(ctx context.Context, playlistID, userID int) error {
 	var exists bool
 	if err := r.db.QueryRow(ctx, `
@@ -163,7 +196,7 @@ func (r *Repository) ensurePlaylistOwner(ctx context.Context, playlistID, userID
 
 // AddSongs associates songs with the provided playlist.
 func (r *Repository) AddSongs(ctx context.Context, userID int, playlistID int, songIDs []int) error {
-	if err := r.ensurePlaylistOwner(ctx, playlistID, userID); err != nil {
+	if err := r.ensurePlaylistEditor(ctx, playlistID, userID); err != nil {
 		return err
 	}
 
@@ -175,9 +208,9 @@ func (r *Repository) AddSongs(ctx context.Context, userID int, playlistID int, s
 
 	for _, songID := range songIDs {
 		if _, err := tx.Exec(ctx, `
-            insert into playlist_song (playlist_id, song_id)
-            values ($1, $2)
-						on conflict (playlist_id, song_id) do nothing
+            insert into playlist_song (playlist_id, song_id, position)
+            values ($1, $2, (select coalesce(max(position), 0) + 1 from playlist_song where playlist_id = $1))
+            on conflict (playlist_id, song_id) do nothing
         `, playlistID, songID); err != nil {
 			var pgErr *pgconn.PgError
 			if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.ForeignKeyViolation {
@@ -196,7 +229,7 @@ func (r *Repository) AddSongs(ctx context.Context, userID int, playlistID int, s
 
 // RemoveSongs detaches the provided songs from the playlist.
 func (r *Repository) RemoveSongs(ctx context.Context, userID int, playlistID int, songIDs []int) error {
-	if err := r.ensurePlaylistOwner(ctx, playlistID, userID); err != nil {
+	if err := r.ensurePlaylistEditor(ctx, playlistID, userID); err != nil {
 		return err
 	}
 
@@ -222,6 +255,97 @@ func (r *Repository) RemoveSongs(ctx context.Context, userID int, playlistID int
 	return nil
 }
 
+// Reorder rearranges the listed songs among the positions they already occupy,
+// leaving every other song in place. Listing every song is a full reorder.
+func (r *Repository) Reorder(ctx context.Context, userID int, playlistID int, songIDs []int) error {
+	if err := r.ensurePlaylistEditor(ctx, playlistID, userID); err != nil {
+		return err
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin reorder playlist: %w", err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	rows, err := tx.Query(ctx, `
+        select song_id
+        from playlist_song
+        where playlist_id = $1
+        order by position, song_id
+        for update
+    `, playlistID)
+	if err != nil {
+		return fmt.Errorf("lock playlist songs: %w", err)
+	}
+	current := make([]int, 0)
+	for rows.Next() {
+		var songID int
+		if err := rows.Scan(&songID); err != nil {
+			rows.Close()
+			return fmt.Errorf("scan playlist song: %w", err)
+		}
+		current = append(current, songID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("iterate playlist songs: %w", err)
+	}
+
+	listed := make(map[int]struct{}, len(songIDs))
+	for _, songID := range songIDs {
+		listed[songID] = struct{}{}
+	}
+	ordered := make([]int, len(current))
+	next, found := 0, 0
+	for i, songID := range current {
+		if _, ok := listed[songID]; ok {
+			ordered[i] = songIDs[next]
+			next++
+			found++
+			continue
+		}
+		ordered[i] = songID
+	}
+	if found != len(songIDs) {
+		return apperror.Validation("msg", map[string]string{"song_ids": "song_ids must only contain songs in the playlist"})
+	}
+
+	if _, err := tx.Exec(ctx, `
+        update playlist_song ps
+        set position = o.position
+        from unnest($2::int[]) with ordinality as o(song_id, position)
+        where ps.playlist_id = $1 and ps.song_id = o.song_id
+    `, playlistID, ordered); err != nil {
+		return fmt.Errorf("reorder playlist songs: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit reorder playlist: %w", err)
+	}
+	return nil
+}
+
+// UpdateArrangement stores the per-playlist arrangement of a song.
+func (r *Repository) UpdateArrangement(ctx context.Context, params playlistsvc.ArrangementParams) error {
+	if err := r.ensurePlaylistEditor(ctx, params.PlaylistID, params.UserID); err != nil {
+		return err
+	}
+
+	cmdTag, err := r.db.Exec(ctx, `
+        update playlist_song
+        set transpose = $3, capo = $4, display_mode = $5, note = $6
+        where playlist_id = $1 and song_id = $2
+    `, params.PlaylistID, params.SongID, params.Transpose, params.Capo, params.DisplayMode, params.Note)
+	if err != nil {
+		return fmt.Errorf("update playlist arrangement: %w", err)
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return apperror.NotFound("song not found in playlist")
+	}
+	return nil
+}
+
 // Update mutates a playlist name for the owner.
 func (r *Repository) Update(ctx context.Context, id int, params playlistsvc.UpdateParams) error {
 	cmdTag, err := r.db.Exec(ctx, `
@@ -280,10 +404,11 @@ func (r *Repository) Share(ctx context.Context, playlistID int, ownerID int, use
 		desired[id] = struct{}{}
 	}
 
+	// Collaborators who stay keep their role; newcomers join as viewers.
 	if _, err := tx.Exec(ctx, `
 		delete from playlist_user
-		where playlist_id = $1
-	`, playlistID); err != nil {
+		where playlist_id = $1 and not (user_id = any(coalesce($2::int[], '{}')))
+	`, playlistID, userIDs); err != nil {
 		return fmt.Errorf("remove shared user: %w", err)
 	}
 
@@ -291,6 +416,7 @@ func (r *Repository) Share(ctx context.Context, playlistID int, ownerID int, use
 		if _, err := tx.Exec(ctx, `
             insert into playlist_user (playlist_id, user_id)
             values ($1, $2)
+            on conflict (playlist_id, user_id) do nothing
         `, playlistID, id); err != nil {
 			var pgErr *pgconn.PgError
 			if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.ForeignKeyViolation {
@@ -305,6 +431,26 @@ func (r *Repository) Share(ctx context.Context, playlistID int, ownerID int, use
 	return nil
 }
 
+// UpdateRole changes a collaborator's role on a playlist owned by ownerID.
+func (r *Repository) UpdateRole(ctx context.Context, playlistID, ownerID, userID int, role playlistsvc.Role) error {
+	if err := r.ensurePlaylistOwner(ctx, playlistID, ownerID); err != nil {
+		return err
+	}
+
+	cmdTag, err := r.db.Exec(ctx, `
+        update playlist_user
+        set role = $3
+        where playlist_id = $1 and user_id = $2
+    `, playlistID, userID, string(role))
+	if err != nil {
+		return fmt.Errorf("update playlist role: %w", err)
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return apperror.NotFound("collaborator not found")
+	}
+	return nil
+}
+
 // Leave removes a non-owner user from a playlist.
 func (r *Repository) Leave(ctx context.Context, playlistID int, userID int) error {
 	cmdTag, err := r.db.Exec(ctx, `
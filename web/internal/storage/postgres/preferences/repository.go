@@ -0,0 +1,64 @@
+package preferences
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	preferencesvc "github.com/lyricapp/lyric/web/internal/services/preferences"
+	"github.com/lyricapp/lyric/web/internal/storage"
+)
+
+// Repository provides Postgres-backed user preferences.
+type Repository struct {
+	db storage.Querier
+}
+
+// NewRepository constructs a Repository instance.
+func NewRepository(db storage.Querier) *Repository {
+	return &Repository{db: db}
+}
+
+const preferenceColumns = `theme, language, font_size, display_mode, instrument, scroll_speed, updated_at`
+
+func scanPreferences(row pgx.Row) (preferencesvc.Preferences, error) {
+	var prefs preferencesvc.Preferences
+	err := row.Scan(&prefs.Theme, &prefs.Language, &prefs.FontSize, &prefs.DisplayMode, &prefs.Instrument, &prefs.ScrollSpeed, &prefs.UpdatedAt)
+	return prefs, err
+}
+
+// Get returns the user's saved preferences, reporting false when there are none.
+func (r *Repository) Get(ctx context.Context, userID int) (preferencesvc.Preferences, bool, error) {
+	prefs, err := scanPreferences(r.db.QueryRow(ctx, `
+		select `+preferenceColumns+`
+		from user_preferences
+		where user_id = $1
+	`, userID))
+	if err == pgx.ErrNoRows {
+		return preferencesvc.Preferences{}, false, nil
+	}
+	if err != nil {
+		return preferencesvc.Preferences{}, false, fmt.Errorf("select preferences: %w", err)
+	}
+	return prefs, true, nil
+}
+
+// Save stores the user's preferences, replacing any saved before.
+func (r *Repository) Save(ctx context.Context, userID int, prefs preferencesvc.Preferences) (preferencesvc.Preferences, error) {
+	saved, err := scanPreferences(r.db.QueryRow(ctx, `
+		insert into user_preferences (user_id, theme, language, font_size, display_mode, instrument, scroll_speed)
+		values ($1, $2, $3, $4, $5, $6, $7)
+		on conflict (user_id) do update
+		set theme = excluded.theme,
+			language = excluded.language,
+			font_size = excluded.font_size,
+			display_mode = excluded.display_mode,
+			instrument = excluded.instrument,
+			scroll_speed = excluded.scroll_speed
+		returning `+preferenceColumns,
+		userID, prefs.Theme, prefs.Language, prefs.FontSize, prefs.DisplayMode, prefs.Instrument, prefs.ScrollSpeed))
+	if err != nil {
+		return preferencesvc.Preferences{}, fmt.Errorf("save preferences: %w", err)
+	}
+	return saved, nil
+}
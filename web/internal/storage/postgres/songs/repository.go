@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"log"
 	"strings"
+	"time"
 
 	"github.com/jackc/pgerrcode"
 	"github.com/jackc/pgx/v5"
@@ -74,15 +75,13 @@ func (r *Repository) List(ctx context.Context, params songsvc.ListParams) (songs
 		args = append(args, *params.WriterID)
 	}
 
-	if params.PlaylistID != nil {
-		placeholder := nextPlaceholder()
-		conditions = append(conditions, fmt.Sprintf("exists (select 1 from playlist_song ps where ps.song_id = s.id and ps.playlist_id = %s)", placeholder))
-		args = append(args, *params.PlaylistID)
-	}
-
 	if params.LevelID != nil {
 		placeholder := nextPlaceholder()
-		conditions = append(conditions, fmt.Sprintf("s.level_id = %s", placeholder))
+		levelColumn := "s.level_id"
+		if params.LevelSource == songsvc.LevelSourceConsensus {
+			levelColumn = "s.consensus_level_id"
+		}
+		conditions = append(conditions, fmt.Sprintf("%s = %s", levelColumn, placeholder))
 		args = append(args, *params.LevelID)
 	}
 
@@ -101,6 +100,12 @@ func (r *Repository) List(ctx context.Context, params songsvc.ListParams) (songs
 		authUserID = *params.AuthenticatedUserID
 	}
 
+	if params.Favorite {
+		placeholder := nextPlaceholder()
+		conditions = append(conditions, fmt.Sprintf("exists (select 1 from favorite_songs fs where fs.song_id = s.id and fs.user_id = %s)", placeholder))
+		args = append(args, authUserID)
+	}
+
 	if params.IsTrending && params.LevelID != nil {
 		withClause = `
         with play_counts as (
@@ -115,6 +120,16 @@ func (r *Repository) List(ctx context.Context, params songsvc.ListParams) (songs
 		orderClause = "order by pc.total_plays desc, s.id desc"
 	}
 
+	// Playlist songs come back in the order the playlist was arranged in.
+	if params.PlaylistID != nil {
+		placeholder := nextPlaceholder()
+		joins = append(joins, fmt.Sprintf("join playlist_song ps on ps.song_id = s.id and ps.playlist_id = %s", placeholder))
+		args = append(args, *params.PlaylistID)
+		if withClause == "" {
+			orderClause = "order by ps.position asc, s.id asc"
+		}
+	}
+
 	if params.ReleaseYear != nil {
 		placeholder := nextPlaceholder()
 		joins = append(joins, "left join album_song als on als.song_id = s.id")
@@ -137,22 +152,61 @@ func (r *Repository) List(ctx context.Context, params songsvc.ListParams) (songs
 		countQuery = withClause + "\n" + countQuery
 	}
 
-	if err := r.db.QueryRow(ctx, countQuery, args...).Scan(&result.Total); err != nil {
-		return result, fmt.Errorf("count songs: %w", err)
-	}
+	// Keyset pages skip the count unless it is asked for.
+	if !params.Keyset || params.IncludeTotal {
+		if err := r.db.QueryRow(ctx, countQuery, args...).Scan(&result.Total); err != nil {
+			return result, fmt.Errorf("count songs: %w", err)
+		}
 
-	if result.Total == 0 {
-		return result, nil
+		if result.Total == 0 {
+			return result, nil
+		}
 	}
 
 	listArgs := append([]any{}, args...)
 	userLevelSelect := "NULL"
+	favoriteSelect := "false"
 	if authUserID > 0 {
 		userPlaceholder := fmt.Sprintf("$%d", len(listArgs)+1)
 		userLevelSelect = fmt.Sprintf("(select ls.level_id from level_song ls where ls.song_id = s.id and ls.user_id = %s limit 1)", userPlaceholder)
+		favoriteSelect = fmt.Sprintf("exists (select 1 from favorite_songs fs where fs.song_id = s.id and fs.user_id = %s)", userPlaceholder)
 		listArgs = append(listArgs, authUserID)
 	}
 
+	arrangementSelect := "NULL::smallint, NULL::smallint, NULL::varchar, NULL::varchar, NULL::int"
+	if params.PlaylistID != nil {
+		arrangementSelect = "ps.transpose, ps.capo, ps.display_mode, ps.note, ps.position"
+	}
+
+	// The lyric is the bulk of a row; it is only read when it is returned or
+	// needed to pace the auto scroll.
+	lyricSelect := "NULL::text"
+	if params.Fields.Has(songsvc.FieldLyric) || params.Fields.Has(songsvc.FieldAutoScroll) {
+		lyricSelect = "s.lyric"
+	}
+
+	listWhereClause := whereClause
+	limit, skip := params.PerPage, offset(params.Page, params.PerPage)
+	if params.Keyset {
+		// One extra row tells whether another page follows.
+		limit, skip = params.PerPage+1, 0
+		if params.After != nil {
+			keyset := ""
+			if params.PlaylistID != nil && params.After.Position != nil {
+				keyset = fmt.Sprintf("(ps.position, s.id) > ($%d, $%d)", len(listArgs)+1, len(listArgs)+2)
+				listArgs = append(listArgs, *params.After.Position, params.After.ID)
+			} else {
+				keyset = fmt.Sprintf("s.id < $%d", len(listArgs)+1)
+				listArgs = append(listArgs, params.After.ID)
+			}
+			if listWhereClause == "" {
+				listWhereClause = " WHERE " + keyset
+			} else {
+				listWhereClause += " AND " + keyset
+			}
+		}
+	}
+
 	limitPlaceholder := fmt.Sprintf("$%d", len(listArgs)+1)
 	offsetPlaceholder := fmt.Sprintf("$%d", len(listArgs)+2)
 
@@ -162,30 +216,40 @@ func (r *Repository) List(ctx context.Context, params songsvc.ListParams) (songs
             s.title,
             l.name,
             s.level_id,
+            cl.name,
+            s.consensus_level_id,
+            s.level_votes,
             s.key,
-            s.lyric,
+            %s,
             s.release_year,
+            s.bpm,
+            s.time_signature,
+            s.duration_seconds,
+            s.capo,
             s.status,
             la.id language_id,
             la.name language_name,
             s.created_by,
             cu.email,
             cu.status,
-            %s as user_level_id
+            %s as user_level_id,
+            %s as is_favorite,
+            %s
         from songs s
         left join levels l on l.id = s.level_id
+        left join levels cl on cl.id = s.consensus_level_id
         left join languages la on la.id = s.language_id
         %s
         %s
         %s
         limit %s offset %s
-    `, userLevelSelect, joinClause, whereClause, orderClause, limitPlaceholder, offsetPlaceholder)
+    `, lyricSelect, userLevelSelect, favoriteSelect, arrangementSelect, joinClause, listWhereClause, orderClause, limitPlaceholder, offsetPlaceholder)
 
 	if withClause != "" {
 		listQuery = withClause + "\n" + listQuery
 	}
 
-	listArgs = append(listArgs, params.PerPage, offset(params.Page, params.PerPage))
+	listArgs = append(listArgs, limit, skip)
 
 	rows, err := r.db.Query(ctx, listQuery, listArgs...)
 	if err != nil {
@@ -193,9 +257,10 @@ func (r *Repository) List(ctx context.Context, params songsvc.ListParams) (songs
 	}
 	defer rows.Close()
 
-	songs := make([]songsvc.Song, 0, params.PerPage)
+	songs := make([]songsvc.Song, 0, limit)
 	songIndex := make(map[int]int)
-	songIDs := make([]int32, 0, params.PerPage)
+	songIDs := make([]int32, 0, limit)
+	positions := make([]sql.NullInt32, 0, limit)
 
 	for rows.Next() {
 		var (
@@ -203,9 +268,16 @@ func (r *Repository) List(ctx context.Context, params songsvc.ListParams) (songs
 			title         string
 			levelName     sql.NullString
 			levelID       sql.NullInt32
+			consensusName sql.NullString
+			consensusID   sql.NullInt32
+			levelVotes    int
 			songKey       sql.NullString
 			lyric         sql.NullString
 			releaseYear   sql.NullInt32
+			bpm           sql.NullInt16
+			timeSignature sql.NullString
+			duration      sql.NullInt32
+			capo          sql.NullInt16
 			status        string
 			languageID    int
 			languageName  string
@@ -213,10 +285,18 @@ func (r *Repository) List(ctx context.Context, params songsvc.ListParams) (songs
 			creatorEmail  sql.NullString
 			creatorStatus sql.NullString
 			userLevelID   sql.NullInt32
+			isFavorite    bool
+			transpose     sql.NullInt16
+			arrangedCapo  sql.NullInt16
+			displayMode   sql.NullString
+			note          sql.NullString
+			position      sql.NullInt32
 		)
 
-		if err := rows.Scan(&id, &title, &levelName, &levelID, &songKey, &lyric, &releaseYear, &status,
-			&languageID, &languageName, &createdBy, &creatorEmail, &creatorStatus, &userLevelID); err != nil {
+		if err := rows.Scan(&id, &title, &levelName, &levelID, &consensusName, &consensusID, &levelVotes, &songKey, &lyric, &releaseYear,
+			&bpm, &timeSignature, &duration, &capo, &status,
+			&languageID, &languageName, &createdBy, &creatorEmail, &creatorStatus, &userLevelID, &isFavorite,
+			&transpose, &arrangedCapo, &displayMode, &note, &position); err != nil {
 			return result, fmt.Errorf("scan song: %w", err)
 		}
 
@@ -228,17 +308,12 @@ func (r *Repository) List(ctx context.Context, params songsvc.ListParams) (songs
 			Albums:      []songsvc.Album{},
 			PlaylistIDs: []int{},
 			Status:      status,
+			IsFavorite:  isFavorite,
+			LevelVotes:  levelVotes,
 		}
 
-		if levelID.Valid {
-			level := songsvc.Level{
-				ID: int(levelID.Int32),
-			}
-			if levelName.Valid {
-				level.Name = titleCase(levelName.String)
-			}
-			song.Level = &level
-		}
+		song.Level = levelOf(levelID, levelName)
+		song.ConsensusLevel = levelOf(consensusID, consensusName)
 		song.Language = songsvc.Language{
 			ID:   int(languageID),
 			Name: titleCase(languageName),
@@ -259,51 +334,52 @@ func (r *Repository) List(ctx context.Context, params songsvc.ListParams) (songs
 			value := int(userLevelID.Int32)
 			song.UserLevelID = &value
 		}
-		if createdBy.Valid {
-			creator := songsvc.Creator{
-				ID: int(createdBy.Int32),
+		setPerformance(&song, bpm, timeSignature, duration, capo)
+		song.Created = creatorOf(createdBy, creatorEmail, creatorStatus)
+		if transpose.Valid {
+			song.Arrangement = &songsvc.Arrangement{
+				Transpose:   int(transpose.Int16),
+				Capo:        int(arrangedCapo.Int16),
+				DisplayMode: stringOrNil(displayMode),
+				Note:        stringOrNil(note),
 			}
-			email := ""
-			if creatorEmail.Valid {
-				email = strings.TrimSpace(creatorEmail.String)
-			}
-			status := ""
-			if creatorStatus.Valid {
-				status = creatorStatus.String
-			}
-			if email != "" {
-				if !isActiveStatus(status) {
-					email = maskEmail(email)
-				}
-				creator.Email = email
-			}
-			song.Created = &creator
 		}
 		songIndex[id] = len(songs)
 		songs = append(songs, song)
 		songIDs = append(songIDs, int32(id))
+		positions = append(positions, position)
 	}
 
 	if err := rows.Err(); err != nil {
 		return result, fmt.Errorf("iterate songs: %w", err)
 	}
 
+	if params.Keyset && len(songs) > params.PerPage {
+		songs, songIDs = songs[:params.PerPage], songIDs[:params.PerPage]
+		last := params.PerPage - 1
+		result.Next = &songsvc.ListCursor{ID: songs[last].ID}
+		if positions[last].Valid {
+			value := int(positions[last].Int32)
+			result.Next.Position = &value
+		}
+	}
+
 	if len(songs) == 0 {
 		result.Data = songs
 		return result, nil
 	}
 
-	if err := r.attachArtists(ctx, songIDs, songIndex, &songs); err != nil {
-		return result, err
-	}
-	if err := r.attachWriters(ctx, songIDs, songIndex, &songs); err != nil {
+	if err := r.attachRelations(ctx, songIDs, songIndex, &songs, params.Fields); err != nil {
 		return result, err
 	}
-	if err := r.attachAlbums(ctx, songIDs, songIndex, &songs); err != nil {
-		return result, err
-	}
-	if err := r.attachPlaylists(ctx, params, songIDs, songIndex, &songs); err != nil {
-		return result, err
+	if params.Fields.Has(songsvc.FieldPlaylistIDs) {
+		if err := r.attachPlaylists(ctx, params, songIDs, songIndex, &songs); err != nil {
+			return result, err
+		}
+	} else {
+		for i := range songs {
+			songs[i].PlaylistIDs = nil
+		}
 	}
 
 	result.Data = songs
@@ -319,23 +395,45 @@ func (r *Repository) Get(ctx context.Context, id int) (songsvc.Song, error) {
             s.title,
             l.name,
             s.level_id,
+            cl.name,
+            s.consensus_level_id,
+            s.level_votes,
             s.key,
             s.lyric,
             s.release_year,
+            s.bpm,
+            s.time_signature,
+            s.duration_seconds,
+            s.capo,
+            coalesce(s.status, 'created'),
 			la.id language_id,
-			la.name language_name
+			la.name language_name,
+            s.created_by,
+            cu.email,
+            cu.status
         from songs s
         left join levels l on l.id = s.level_id
+        left join levels cl on cl.id = s.consensus_level_id
         left join languages la on la.id = s.language_id
+        left join users cu on cu.id = s.created_by
         where s.id = $1
     `
 	var (
-		levelName   sql.NullString
-		levelID     sql.NullInt32
-		songKey     sql.NullString
-		lyric       sql.NullString
-		releaseYear sql.NullInt32
-		song        songsvc.Song
+		levelName     sql.NullString
+		levelID       sql.NullInt32
+		consensusName sql.NullString
+		consensusID   sql.NullInt32
+		songKey       sql.NullString
+		lyric         sql.NullString
+		releaseYear   sql.NullInt32
+		bpm           sql.NullInt16
+		signature     sql.NullString
+		duration      sql.NullInt32
+		capo          sql.NullInt16
+		createdBy     sql.NullInt32
+		creatorEmail  sql.NullString
+		creatorStatus sql.NullString
+		song          songsvc.Song
 	)
 
 	if err := r.db.QueryRow(ctx, query, id).Scan(
@@ -343,11 +441,22 @@ func (r *Repository) Get(ctx context.Context, id int) (songsvc.Song, error) {
 		&song.Title,
 		&levelName,
 		&levelID,
+		&consensusName,
+		&consensusID,
+		&song.LevelVotes,
 		&songKey,
 		&lyric,
 		&releaseYear,
+		&bpm,
+		&signature,
+		&duration,
+		&capo,
+		&song.Status,
 		&song.Language.ID,
 		&song.Language.Name,
+		&createdBy,
+		&creatorEmail,
+		&creatorStatus,
 	); err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return songsvc.Song{}, apperror.NotFound("song not found")
@@ -355,15 +464,8 @@ func (r *Repository) Get(ctx context.Context, id int) (songsvc.Song, error) {
 		return songsvc.Song{}, fmt.Errorf("get song: %w", err)
 	}
 
-	if levelID.Valid {
-		level := songsvc.Level{
-			ID: int(levelID.Int32),
-		}
-		if levelName.Valid {
-			level.Name = titleCase(levelName.String)
-		}
-		song.Level = &level
-	}
+	song.Level = levelOf(levelID, levelName)
+	song.ConsensusLevel = levelOf(consensusID, consensusName)
 	if songKey.Valid {
 		value := songKey.String
 		song.Key = &value
@@ -376,6 +478,8 @@ func (r *Repository) Get(ctx context.Context, id int) (songsvc.Song, error) {
 		value := int(releaseYear.Int32)
 		song.ReleaseYear = &value
 	}
+	setPerformance(&song, bpm, signature, duration, capo)
+	song.Created = creatorOf(createdBy, creatorEmail, creatorStatus)
 
 	song.Artists = []songsvc.Person{}
 	song.Writers = []songsvc.Person{}
@@ -386,19 +490,166 @@ func (r *Repository) Get(ctx context.Context, id int) (songsvc.Song, error) {
 	songIndex := map[int]int{id: 0}
 	songIDs := []int32{int32(id)}
 
-	if err := r.attachArtists(ctx, songIDs, songIndex, &songs); err != nil {
+	if err := r.attachRelations(ctx, songIDs, songIndex, &songs, nil); err != nil {
 		return songsvc.Song{}, err
 	}
-	if err := r.attachWriters(ctx, songIDs, songIndex, &songs); err != nil {
-		return songsvc.Song{}, err
-	}
-	if err := r.attachAlbums(ctx, songIDs, songIndex, &songs); err != nil {
-		return songsvc.Song{}, err
+
+	// Suggesters did not choose to publish their address the way a song's
+	// owner does, and the song is publicly cached, so it is always masked.
+	contributors, err := storage.QueryAll(ctx, r.db, func(rows pgx.Rows, contributor *songsvc.Creator) error {
+		var email string
+		if err := rows.Scan(&contributor.ID, &email); err != nil {
+			return err
+		}
+		contributor.Email = maskEmail(email)
+		return nil
+	}, `
+        select u.id, u.email
+        from song_suggestions ss
+        join users u on u.id = ss.user_id
+        where ss.song_id = $1
+          and ss.status = 'accepted'
+        group by u.id, u.email
+        order by min(ss.reviewed_at), u.id
+    `, id)
+	if err != nil {
+		return songsvc.Song{}, fmt.Errorf("get song contributors: %w", err)
 	}
+	songs[0].Contributors = contributors
 
 	return songs[0], nil
 }
 
+// RecordPlay logs the user's play of the song, skipping it when they already
+// played the song within the window.
+func (r *Repository) RecordPlay(ctx context.Context, songID, userID int, window time.Duration) error {
+	if _, err := r.db.Exec(ctx, `
+        insert into plays (song_id, user_id)
+        select $1, $2
+        where not exists (
+            select 1
+            from plays
+            where song_id = $1
+              and user_id = $2
+              and created_at > now() - make_interval(secs => $3)
+        )
+    `, songID, userID, window.Seconds()); err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.ForeignKeyViolation {
+			return apperror.NotFound("song not found")
+		}
+		return fmt.Errorf("record play: %w", err)
+	}
+	return nil
+}
+
+// UserMarks returns the user's level vote for the song and whether they
+// favourited it.
+func (r *Repository) UserMarks(ctx context.Context, songID, userID int) (*int, bool, error) {
+	var (
+		levelID  sql.NullInt32
+		favorite bool
+	)
+	if err := r.db.QueryRow(ctx, `
+        select
+            (select ls.level_id from level_song ls where ls.song_id = $1 and ls.user_id = $2 limit 1),
+            exists (select 1 from favorite_songs fs where fs.song_id = $1 and fs.user_id = $2)
+    `, songID, userID).Scan(&levelID, &favorite); err != nil {
+		return nil, false, fmt.Errorf("get user song marks: %w", err)
+	}
+	if !levelID.Valid {
+		return nil, favorite, nil
+	}
+	value := int(levelID.Int32)
+	return &value, favorite, nil
+}
+
+// Arrangement returns how the playlist performs the song.
+func (r *Repository) Arrangement(ctx context.Context, playlistID, songID int) (songsvc.Arrangement, error) {
+	var (
+		arrangement songsvc.Arrangement
+		displayMode sql.NullString
+		note        sql.NullString
+	)
+	if err := r.db.QueryRow(ctx, `
+        select transpose, capo, display_mode, note
+        from playlist_song
+        where playlist_id = $1 and song_id = $2
+    `, playlistID, songID).Scan(&arrangement.Transpose, &arrangement.Capo, &displayMode, &note); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return songsvc.Arrangement{}, apperror.NotFound("song not found in playlist")
+		}
+		return songsvc.Arrangement{}, fmt.Errorf("get playlist arrangement: %w", err)
+	}
+	arrangement.DisplayMode = stringOrNil(displayMode)
+	arrangement.Note = stringOrNil(note)
+	return arrangement, nil
+}
+
+// CanViewPlaylist reports whether the user owns the playlist or is one of its
+// collaborators.
+func (r *Repository) CanViewPlaylist(ctx context.Context, playlistID, userID int) (bool, error) {
+	var ok bool
+	if err := r.db.QueryRow(ctx, `
+        select exists (select 1 from playlists where id = $1 and user_id = $2)
+            or exists (select 1 from playlist_user where playlist_id = $1 and user_id = $2)
+    `, playlistID, userID).Scan(&ok); err != nil {
+		return false, fmt.Errorf("check playlist access: %w", err)
+	}
+	return ok, nil
+}
+
+// levelOf builds a song level from a nullable join.
+func levelOf(id sql.NullInt32, name sql.NullString) *songsvc.Level {
+	if !id.Valid {
+		return nil
+	}
+	level := songsvc.Level{ID: int(id.Int32)}
+	if name.Valid {
+		level.Name = titleCase(name.String)
+	}
+	return &level
+}
+
+// creatorOf describes who added a song, masking the email of accounts that are
+// no longer active.
+func creatorOf(createdBy sql.NullInt32, email, status sql.NullString) *songsvc.Creator {
+	if !createdBy.Valid {
+		return nil
+	}
+	creator := songsvc.Creator{ID: int(createdBy.Int32)}
+	if address := strings.TrimSpace(email.String); email.Valid && address != "" {
+		if !isActiveStatus(status.String) {
+			address = maskEmail(address)
+		}
+		creator.Email = address
+	}
+	return &creator
+}
+
+func setPerformance(song *songsvc.Song, bpm sql.NullInt16, timeSignature sql.NullString, duration sql.NullInt32, capo sql.NullInt16) {
+	if bpm.Valid {
+		value := int(bpm.Int16)
+		song.BPM = &value
+	}
+	song.TimeSignature = stringOrNil(timeSignature)
+	if duration.Valid {
+		value := int(duration.Int32)
+		song.DurationSeconds = &value
+	}
+	if capo.Valid {
+		value := int(capo.Int16)
+		song.Capo = &value
+	}
+}
+
+func stringOrNil(value sql.NullString) *string {
+	if !value.Valid {
+		return nil
+	}
+	return &value.String
+}
+
 // Create persists a new song along with its artist and writer relations.
 func (r *Repository) Create(ctx context.Context, params songsvc.CreateParams) (int, error) {
 	tx, err := r.db.Begin(ctx)
@@ -409,8 +660,9 @@ func (r *Repository) Create(ctx context.Context, params songsvc.CreateParams) (i
 
 	var songID int
 	if err := tx.QueryRow(ctx, `
-		insert into songs (title, level_id, key, language_id, lyric, release_year, created_by)
-		values ($1, $2, $3, $4, $5, $6, $7)
+		insert into songs (title, level_id, key, language_id, lyric, release_year, created_by,
+		                   bpm, time_signature, duration_seconds, capo)
+		values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 		returning id
 	`,
 		params.Title,
@@ -420,6 +672,10 @@ func (r *Repository) Create(ctx context.Context, params songsvc.CreateParams) (i
 		nullableString(params.Lyric),
 		nullableInt(params.ReleaseYear),
 		nullableInt(params.CreatedBy),
+		nullableInt(params.BPM),
+		nullableString(params.TimeSignature),
+		nullableInt(params.DurationSeconds),
+		nullableInt(params.Capo),
 	).Scan(&songID); err != nil {
 		var pgErr *pgconn.PgError
 		if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.ForeignKeyViolation {
@@ -489,8 +745,12 @@ func (r *Repository) Update(ctx context.Context, id int, params songsvc.UpdatePa
 		    key = $3,
 		    language_id = $4,
 		    lyric = $5,
-		    release_year = $6
-		where id = $7 and created_by = $8
+		    release_year = $6,
+		    bpm = $9,
+		    time_signature = $10,
+		    duration_seconds = $11,
+		    capo = $12
+		where id = $7 and ($8::int is null or created_by = $8)
 	`, params.Title,
 		nullableInt(params.LevelID),
 		nullableString(params.Key),
@@ -498,7 +758,11 @@ func (r *Repository) Update(ctx context.Context, id int, params songsvc.UpdatePa
 		nullableString(params.Lyric),
 		nullableInt(params.ReleaseYear),
 		id,
-		params.UserID,
+		params.OwnerID,
+		nullableInt(params.BPM),
+		nullableString(params.TimeSignature),
+		nullableInt(params.DurationSeconds),
+		nullableInt(params.Capo),
 	)
 	if err != nil {
 		var pgErr *pgconn.PgError
@@ -592,13 +856,14 @@ func (r *Repository) Delete(ctx context.Context, id int, params songsvc.DeletePa
 	return nil
 }
 
-// UpdateStatus changes the workflow status for a song owned by the supplied user.
-func (r *Repository) UpdateStatus(ctx context.Context, id int, status string, userID int) error {
+// UpdateStatus changes the workflow status for a song, limited to the songs of
+// ownerID when set.
+func (r *Repository) UpdateStatus(ctx context.Context, id int, status string, ownerID *int) error {
 	cmdTag, err := r.db.Exec(ctx, `
 		update songs
 		set status = $1
-		where id = $2 and created_by = $3
-	`, status, id, userID)
+		where id = $2 and ($3::int is null or created_by = $3)
+	`, status, id, ownerID)
 	if err != nil {
 		return fmt.Errorf("update song status: %w", err)
 	}
@@ -610,7 +875,9 @@ func (r *Repository) UpdateStatus(ctx context.Context, id int, status string, us
 	return nil
 }
 
-// AssignLevel updates the level association for a song.
+// AssignLevel records the user's difficulty vote, replacing their earlier one.
+// The author's vote also sets the song's own level; the consensus is refreshed
+// by a trigger on level_song.
 func (r *Repository) AssignLevel(ctx context.Context, songID, levelID, userID int) error {
 	tx, err := r.db.Begin(ctx)
 	if err != nil {
@@ -618,27 +885,38 @@ func (r *Repository) AssignLevel(ctx context.Context, songID, levelID, userID in
 	}
 	defer tx.Rollback(ctx) //nolint:errcheck
 
-	cmdTag, err := tx.Exec(ctx, `
-        update songs
-        set level_id = $1
-        where id = $2
-    `, levelID, songID)
-	if err != nil {
-		var pgErr *pgconn.PgError
-		if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.ForeignKeyViolation {
-			return apperror.BadRequest("invalid level_id")
+	var createdBy sql.NullInt32
+	if err := tx.QueryRow(ctx, `
+        select created_by
+        from songs
+        where id = $1
+        for update
+    `, songID).Scan(&createdBy); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return apperror.NotFound("song not found")
 		}
-		return fmt.Errorf("assign level update song: %w", err)
+		return fmt.Errorf("assign level lock song: %w", err)
 	}
-	if cmdTag.RowsAffected() == 0 {
-		return apperror.NotFound("song not found")
+
+	if createdBy.Valid && int(createdBy.Int32) == userID {
+		if _, err := tx.Exec(ctx, `
+            update songs
+            set level_id = $1
+            where id = $2
+        `, levelID, songID); err != nil {
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.ForeignKeyViolation {
+				return apperror.BadRequest("invalid level_id")
+			}
+			return fmt.Errorf("assign level update song: %w", err)
+		}
 	}
 
 	if _, err := tx.Exec(ctx, `
         INSERT INTO level_song (song_id, level_id, user_id)
         VALUES ($1, $2, $3)
-        ON CONFLICT (song_id, level_id, user_id) DO UPDATE
-        SET updated_at = NOW()
+        ON CONFLICT (song_id, user_id) DO UPDATE
+        SET level_id = excluded.level_id, updated_at = NOW()
     `, songID, levelID, userID); err != nil {
 		var pgErr *pgconn.PgError
 		if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.ForeignKeyViolation {
@@ -654,6 +932,55 @@ func (r *Repository) AssignLevel(ctx context.Context, songID, levelID, userID in
 	return nil
 }
 
+// LevelVotes counts the song's difficulty votes for every level.
+func (r *Repository) LevelVotes(ctx context.Context, songID int) (songsvc.LevelVotes, error) {
+	var (
+		votes         songsvc.LevelVotes
+		levelID       sql.NullInt32
+		levelName     sql.NullString
+		consensusID   sql.NullInt32
+		consensusName sql.NullString
+	)
+	if err := r.db.QueryRow(ctx, `
+        select s.id, s.level_id, l.name, s.consensus_level_id, cl.name, s.level_votes
+        from songs s
+        left join levels l on l.id = s.level_id
+        left join levels cl on cl.id = s.consensus_level_id
+        where s.id = $1
+    `, songID).Scan(&votes.SongID, &levelID, &levelName, &consensusID, &consensusName, &votes.Votes); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return songsvc.LevelVotes{}, apperror.NotFound("song not found")
+		}
+		return songsvc.LevelVotes{}, fmt.Errorf("get song level votes: %w", err)
+	}
+	votes.AuthorLevel = levelOf(levelID, levelName)
+	votes.ConsensusLevel = levelOf(consensusID, consensusName)
+
+	distribution, err := storage.QueryAll(ctx, r.db, func(rows pgx.Rows, count *songsvc.LevelVoteCount) error {
+		if err := rows.Scan(&count.ID, &count.Name, &count.Votes, &count.Weight); err != nil {
+			return err
+		}
+		count.Name = titleCase(count.Name)
+		return nil
+	}, `
+        select
+            l.id,
+            l.name,
+            count(ls.id)::int,
+            coalesce(sum(case when u.role in ('admin', 'editor') then 2 else 1 end) filter (where ls.id is not null), 0)::int
+        from levels l
+        left join level_song ls on ls.level_id = l.id and ls.song_id = $1
+        left join users u on u.id = ls.user_id
+        group by l.id, l.name
+        order by l.id
+    `, songID)
+	if err != nil {
+		return songsvc.LevelVotes{}, fmt.Errorf("count song level votes: %w", err)
+	}
+	votes.Distribution = distribution
+	return votes, nil
+}
+
 // SyncPlaylists replaces the playlists associated with a song for the provided user.
 func (r *Repository) SyncPlaylists(ctx context.Context, songID, userID int, playlistIDs []int) error {
 	tx, err := r.db.Begin(ctx)
@@ -710,8 +1037,8 @@ func (r *Repository) SyncPlaylists(ctx context.Context, songID, userID int, play
 
 	for _, playlistID := range playlistIDs {
 		if _, err := tx.Exec(ctx, `
-            insert into playlist_song (playlist_id, song_id)
-            values ($1, $2)
+            insert into playlist_song (playlist_id, song_id, position)
+            values ($1, $2, (select coalesce(max(position), 0) + 1 from playlist_song where playlist_id = $1))
         `, playlistID, songID); err != nil {
 			var pgErr *pgconn.PgError
 			if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.ForeignKeyViolation {
@@ -744,100 +1071,80 @@ func maskEmail(email string) string {
 	return "****@" + strings.TrimSpace(parts[1])
 }
 
-func (r *Repository) attachArtists(ctx context.Context, songIDs []int32, songIndex map[int]int, songs *[]songsvc.Song) error {
-	query := `
-        select sa.song_id, ar.id, ar.name
-        from artist_song sa
-        join artists ar on ar.id = sa.artist_id
-        where sa.song_id = any($1::int4[])
-        order by ar.name asc
-    `
+// attachRelations loads the artists, writers and albums of the songs in a single
+// round trip, leaving out the collections fields does not select.
+func (r *Repository) attachRelations(ctx context.Context, songIDs []int32, songIndex map[int]int, songs *[]songsvc.Song, fields songsvc.Fields) error {
+	withArtists := fields.Has(songsvc.FieldArtists)
+	withWriters := fields.Has(songsvc.FieldWriters)
+	withAlbums := fields.Has(songsvc.FieldAlbums)
 
-	rows, err := r.db.Query(ctx, query, songIDs)
-	if err != nil {
-		return fmt.Errorf("list artists: %w", err)
-	}
-	defer rows.Close()
-
-	for rows.Next() {
-		var songID int
-		var artist songsvc.Person
-
-		if err := rows.Scan(&songID, &artist.ID, &artist.Name); err != nil {
-			return fmt.Errorf("scan artist: %w", err)
-		}
-
-		if idx, ok := songIndex[songID]; ok {
-			(*songs)[idx].Artists = append((*songs)[idx].Artists, artist)
+	for i := range *songs {
+		if !withArtists {
+			(*songs)[i].Artists = nil
 		}
-	}
-
-	return rows.Err()
-}
-
-func (r *Repository) attachWriters(ctx context.Context, songIDs []int32, songIndex map[int]int, songs *[]songsvc.Song) error {
-	query := `
-        select sw.song_id, w.id, w.name
-        from song_writer sw
-        join writers w on w.id = sw.writer_id
-        where sw.song_id = any($1::int4[])
-        order by w.name asc
-    `
-
-	rows, err := r.db.Query(ctx, query, songIDs)
-	if err != nil {
-		return fmt.Errorf("list writers: %w", err)
-	}
-	defer rows.Close()
-
-	for rows.Next() {
-		var songID int
-		var writer songsvc.Person
-
-		if err := rows.Scan(&songID, &writer.ID, &writer.Name); err != nil {
-			return fmt.Errorf("scan writer: %w", err)
+		if !withWriters {
+			(*songs)[i].Writers = nil
 		}
-
-		if idx, ok := songIndex[songID]; ok {
-			(*songs)[idx].Writers = append((*songs)[idx].Writers, writer)
+		if !withAlbums {
+			(*songs)[i].Albums = nil
 		}
 	}
+	if !withArtists && !withWriters && !withAlbums {
+		return nil
+	}
 
-	return rows.Err()
-}
-
-func (r *Repository) attachAlbums(ctx context.Context, songIDs []int32, songIndex map[int]int, songs *[]songsvc.Song) error {
 	query := `
-        select s.id, a.id, a.name, a.release_year
-        from songs s
-        join album_song als on als.song_id = s.id
-        join albums a on a.id = als.album_id
-        where s.id = any($1::int4[])
-        order by a.name asc
+        select
+            s.id,
+            case when $2 then coalesce((
+                select json_agg(json_build_object('id', ar.id, 'name', ar.name) order by ar.name asc)
+                from artist_song sa
+                join artists ar on ar.id = sa.artist_id
+                where sa.song_id = s.id
+            ), '[]'::json) end,
+            case when $3 then coalesce((
+                select json_agg(json_build_object('id', w.id, 'name', w.name) order by w.name asc)
+                from song_writer sw
+                join writers w on w.id = sw.writer_id
+                where sw.song_id = s.id
+            ), '[]'::json) end,
+            case when $4 then coalesce((
+                select json_agg(json_build_object('id', a.id, 'name', a.name, 'release_year', a.release_year) order by a.name asc)
+                from album_song als
+                join albums a on a.id = als.album_id
+                where als.song_id = s.id
+            ), '[]'::json) end
+        from unnest($1::int4[]) as s(id)
     `
 
-	rows, err := r.db.Query(ctx, query, songIDs)
+	rows, err := r.db.Query(ctx, query, songIDs, withArtists, withWriters, withAlbums)
 	if err != nil {
-		return fmt.Errorf("list albums: %w", err)
+		return fmt.Errorf("list song relations: %w", err)
 	}
 	defer rows.Close()
 
 	for rows.Next() {
-		var songID int
-		var album songsvc.Album
-		var releaseYear sql.NullInt32
-
-		if err := rows.Scan(&songID, &album.ID, &album.Name, &releaseYear); err != nil {
-			return fmt.Errorf("scan album: %w", err)
-		}
-
-		if releaseYear.Valid {
-			value := int(releaseYear.Int32)
-			album.ReleaseYear = &value
+		var (
+			songID  int
+			artists []songsvc.Person
+			writers []songsvc.Person
+			albums  []songsvc.Album
+		)
+		if err := rows.Scan(&songID, &artists, &writers, &albums); err != nil {
+			return fmt.Errorf("scan song relations: %w", err)
 		}
 
 		if idx, ok := songIndex[songID]; ok {
-			(*songs)[idx].Albums = append((*songs)[idx].Albums, album)
+			song := &(*songs)[idx]
+			if withArtists {
+				song.Artists = artists
+			}
+			if withWriters {
+				song.Writers = writers
+			}
+			if withAlbums {
+				song.Albums = albums
+			}
 		}
 	}
 
@@ -942,3 +1249,12 @@ func nullableInt(input *int) any {
 	}
 	return *input
 }
+
+// LastModified returns when songs or anything they embed last changed.
+func (r *Repository) LastModified(ctx context.Context) (time.Time, error) {
+	at, err := storage.LastModified(ctx, r.db, "songs", "artists", "writers", "albums", "levels", "languages", "users")
+	if err != nil {
+		return time.Time{}, fmt.Errorf("songs last modified: %w", err)
+	}
+	return at, nil
+}
@@ -0,0 +1,103 @@
+package songs_test
+
+import (
+	"context"
+	"testing"
+
+	songsvc "github.com/lyricapp/lyric/web/internal/services/songs"
+	songrepo "github.com/lyricapp/lyric/web/internal/storage/postgres/songs"
+	"github.com/lyricapp/lyric/web/internal/testutil"
+)
+
+// benchSongs is the size of the seeded catalogue; benchDepth is how far into it
+// the benchmarked page starts.
+const (
+	benchSongs = 5000
+	benchDepth = 2000
+	benchPage  = 20
+)
+
+// BenchmarkList compares a deep page/per_page listing with the keyset page at the
+// same depth, with and without lyrics and follow-up collections.
+//
+//	go test -run '^$' -bench BenchmarkList ./internal/storage/postgres/songs
+func BenchmarkList(b *testing.B) {
+	conn := testutil.SetupDB(b)
+	defer conn.Close()
+
+	ctx := context.Background()
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer tx.Rollback(ctx)
+
+	// given
+	var langID, artistID, writerID, albumID int
+	if err := tx.QueryRow(ctx, "insert into languages (name) values ('bench') returning id").Scan(&langID); err != nil {
+		b.Fatalf("failed to insert language: %v", err)
+	}
+	if err := tx.QueryRow(ctx, "insert into artists (name) values ('bench artist') returning id").Scan(&artistID); err != nil {
+		b.Fatalf("failed to insert artist: %v", err)
+	}
+	if err := tx.QueryRow(ctx, "insert into writers (name) values ('bench writer') returning id").Scan(&writerID); err != nil {
+		b.Fatalf("failed to insert writer: %v", err)
+	}
+	if err := tx.QueryRow(ctx, "insert into albums (name) values ('bench album') returning id").Scan(&albumID); err != nil {
+		b.Fatalf("failed to insert album: %v", err)
+	}
+	if _, err := tx.Exec(ctx, `
+        insert into songs (title, language_id, key, lyric, bpm)
+        select 'bench ' || n, $1, 'G', repeat('[G]Amazing grace how [C]sweet the sound' || chr(10), 60), 90
+        from generate_series(1, $2) as n
+    `, langID, benchSongs); err != nil {
+		b.Fatalf("failed to seed songs: %v", err)
+	}
+	links := []struct {
+		query string
+		id    int
+	}{
+		{"insert into artist_song (artist_id, song_id) select $1, id from songs where language_id = $2", artistID},
+		{"insert into song_writer (writer_id, song_id) select $1, id from songs where language_id = $2", writerID},
+		{"insert into album_song (album_id, song_id) select $1, id from songs where language_id = $2", albumID},
+	}
+	for _, link := range links {
+		if _, err := tx.Exec(ctx, link.query, link.id, langID); err != nil {
+			b.Fatalf("failed to link songs: %v", err)
+		}
+	}
+	if _, err := tx.Exec(ctx, "analyze songs"); err != nil {
+		b.Fatalf("failed to analyze songs: %v", err)
+	}
+
+	var afterID int
+	if err := tx.QueryRow(ctx, "select id from songs where language_id = $1 order by id desc offset $2 limit 1", langID, benchDepth-1).Scan(&afterID); err != nil {
+		b.Fatalf("failed to find cursor: %v", err)
+	}
+
+	repo := songrepo.NewRepository(tx)
+	languages := []int{langID}
+	cases := []struct {
+		name   string
+		params songsvc.ListParams
+	}{
+		{"offset", songsvc.ListParams{Page: benchDepth/benchPage + 1, PerPage: benchPage, LanguageIDs: languages}},
+		{"keyset", songsvc.ListParams{Keyset: true, After: &songsvc.ListCursor{ID: afterID}, PerPage: benchPage, LanguageIDs: languages}},
+		{"keyset_lean", songsvc.ListParams{Keyset: true, After: &songsvc.ListCursor{ID: afterID}, PerPage: benchPage, LanguageIDs: languages, Fields: songsvc.Fields{songsvc.FieldArtists: true}}},
+	}
+	for _, tc := range cases {
+		b.Run(tc.name, func(b *testing.B) {
+			// when
+			for i := 0; i < b.N; i++ {
+				result, err := repo.List(ctx, tc.params)
+				// then
+				if err != nil {
+					b.Fatal(err)
+				}
+				if len(result.Data) != benchPage {
+					b.Fatalf("got %d songs want %d", len(result.Data), benchPage)
+				}
+			}
+		})
+	}
+}
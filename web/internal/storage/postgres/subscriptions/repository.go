@@ -0,0 +1,92 @@
+package subscriptions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/lyricapp/lyric/web/internal/apperror"
+	subscriptionsvc "github.com/lyricapp/lyric/web/internal/services/subscriptions"
+	"github.com/lyricapp/lyric/web/internal/storage"
+)
+
+// Repository provides Postgres-backed subscription persistence.
+type Repository struct {
+	db storage.Querier
+}
+
+// NewRepository constructs a Repository instance.
+func NewRepository(db storage.Querier) *Repository {
+	return &Repository{db: db}
+}
+
+// Upsert records a verified purchase. Re-submitting a renewed subscription moves
+// its expiry forward; a transaction already claimed by another user is rejected.
+func (r *Repository) Upsert(ctx context.Context, userID int, transactionID string, entitlement subscriptionsvc.Entitlement) (subscriptionsvc.Entitlement, error) {
+	var stored subscriptionsvc.Entitlement
+	var provider, kind string
+	err := r.db.QueryRow(ctx, `
+        insert into subscriptions (user_id, provider, product_id, kind, transaction_id, starts_at, expires_at)
+        values ($1, $2, $3, $4, $5, $6, $7)
+        on conflict (provider, transaction_id) do update
+        set product_id = excluded.product_id,
+            expires_at = case
+                when subscriptions.kind = 'subscription' then greatest(subscriptions.expires_at, excluded.expires_at)
+                else subscriptions.expires_at
+            end
+        where subscriptions.user_id = excluded.user_id
+        returning id, provider, product_id, kind, starts_at, expires_at
+    `,
+		userID,
+		string(entitlement.Provider),
+		entitlement.ProductID,
+		string(entitlement.Kind),
+		transactionID,
+		entitlement.StartsAt.UTC(),
+		entitlement.ExpiresAt.UTC(),
+	).Scan(&stored.ID, &provider, &stored.ProductID, &kind, &stored.StartsAt, &stored.ExpiresAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return subscriptionsvc.Entitlement{}, apperror.Forbidden("purchase belongs to another account")
+		}
+		return subscriptionsvc.Entitlement{}, fmt.Errorf("upsert subscription: %w", err)
+	}
+	stored.Provider = subscriptionsvc.ProviderName(provider)
+	stored.Kind = subscriptionsvc.Kind(kind)
+	return stored, nil
+}
+
+// Active lists the user's subscriptions and unlocks covering at, latest expiry first.
+func (r *Repository) Active(ctx context.Context, userID int, at time.Time) ([]subscriptionsvc.Entitlement, error) {
+	rows, err := r.db.Query(ctx, `
+        select id, provider, product_id, kind, starts_at, expires_at
+        from subscriptions
+        where user_id = $1
+          and starts_at <= $2
+          and expires_at > $2
+        order by expires_at desc, id desc
+    `, userID, at.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("query subscriptions: %w", err)
+	}
+	defer rows.Close()
+
+	entitlements := make([]subscriptionsvc.Entitlement, 0)
+	for rows.Next() {
+		var entitlement subscriptionsvc.Entitlement
+		var provider, kind string
+		if err := rows.Scan(&entitlement.ID, &provider, &entitlement.ProductID, &kind, &entitlement.StartsAt, &entitlement.ExpiresAt); err != nil {
+			return nil, fmt.Errorf("scan subscription: %w", err)
+		}
+		entitlement.Provider = subscriptionsvc.ProviderName(provider)
+		entitlement.Kind = subscriptionsvc.Kind(kind)
+		entitlements = append(entitlements, entitlement)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate subscriptions: %w", err)
+	}
+	return entitlements, nil
+}
@@ -0,0 +1,130 @@
+package suggestions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/lyricapp/lyric/web/internal/apperror"
+	suggestionsvc "github.com/lyricapp/lyric/web/internal/services/suggestions"
+	"github.com/lyricapp/lyric/web/internal/storage"
+)
+
+// Repository provides Postgres-backed song suggestions.
+type Repository struct {
+	db storage.Querier
+}
+
+// NewRepository constructs a Repository instance.
+func NewRepository(db storage.Querier) *Repository {
+	return &Repository{db: db}
+}
+
+const suggestionColumns = `
+    ss.id, ss.song_id, s.title, ss.user_id, ss.comment, ss.status,
+    ss.reviewed_by, ss.review_note, ss.reviewed_at, ss.created_at
+`
+
+func scanSuggestion(row pgx.Row, suggestion *suggestionsvc.Suggestion, extra ...any) error {
+	return row.Scan(append([]any{
+		&suggestion.ID, &suggestion.SongID, &suggestion.SongTitle, &suggestion.UserID,
+		&suggestion.Comment, &suggestion.Status, &suggestion.ReviewedBy, &suggestion.ReviewNote,
+		&suggestion.ReviewedAt, &suggestion.CreatedAt,
+	}, extra...)...)
+}
+
+// Create stores a pending suggestion made against baseLyric.
+func (r *Repository) Create(ctx context.Context, params suggestionsvc.CreateParams, baseLyric *string) (int, error) {
+	var id int
+	if err := r.db.QueryRow(ctx, `
+        insert into song_suggestions (song_id, user_id, base_lyric, lyric, comment)
+        values ($1, $2, $3, $4, $5)
+        returning id
+    `, params.SongID, params.UserID, baseLyric, params.Lyric, params.Comment).Scan(&id); err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.ForeignKeyViolation {
+			return 0, apperror.NotFound("song not found")
+		}
+		return 0, fmt.Errorf("insert song suggestion: %w", err)
+	}
+	return id, nil
+}
+
+// Get returns a suggestion with its lyrics, the song's owner and the
+// suggester's email while their account is active.
+func (r *Repository) Get(ctx context.Context, id int) (suggestionsvc.Suggestion, bool, error) {
+	var (
+		suggestion suggestionsvc.Suggestion
+		email      *string
+	)
+	err := scanSuggestion(r.db.QueryRow(ctx, `
+        select `+suggestionColumns+`,
+            ss.lyric, ss.base_lyric, s.created_by,
+            case when u.status = 'active' then u.email end
+        from song_suggestions ss
+        join songs s on s.id = ss.song_id
+        join users u on u.id = ss.user_id
+        where ss.id = $1
+    `, id), &suggestion, &suggestion.Lyric, &suggestion.BaseLyric, &suggestion.SongOwnerID, &email)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return suggestionsvc.Suggestion{}, false, nil
+	}
+	if err != nil {
+		return suggestionsvc.Suggestion{}, false, fmt.Errorf("get song suggestion: %w", err)
+	}
+	if email != nil {
+		suggestion.Email = *email
+	}
+	return suggestion, true, nil
+}
+
+// List returns the song's suggestions with the status, or every status when
+// it is empty, newest first.
+func (r *Repository) List(ctx context.Context, songID int, status string) ([]suggestionsvc.Suggestion, error) {
+	suggestions, err := storage.QueryAll(ctx, r.db, func(rows pgx.Rows, suggestion *suggestionsvc.Suggestion) error {
+		return scanSuggestion(rows, suggestion)
+	}, `
+        select `+suggestionColumns+`
+        from song_suggestions ss
+        join songs s on s.id = ss.song_id
+        where ss.song_id = $1
+          and ($2::text = '' or ss.status = $2)
+        order by ss.created_at desc, ss.id desc
+    `, songID, status)
+	if err != nil {
+		return nil, fmt.Errorf("list song suggestions: %w", err)
+	}
+	return suggestions, nil
+}
+
+// Resolve records the review of a pending suggestion.
+func (r *Repository) Resolve(ctx context.Context, id int, status string, reviewerID int, note *string) (bool, error) {
+	cmdTag, err := r.db.Exec(ctx, `
+        update song_suggestions
+        set status = $2,
+            reviewed_by = $3,
+            review_note = $4,
+            reviewed_at = now()
+        where id = $1 and status = 'pending'
+    `, id, status, reviewerID, note)
+	if err != nil {
+		return false, fmt.Errorf("resolve song suggestion: %w", err)
+	}
+	return cmdTag.RowsAffected() > 0, nil
+}
+
+// MarkNotified records that the suggester was told about the review.
+func (r *Repository) MarkNotified(ctx context.Context, id int) error {
+	if _, err := r.db.Exec(ctx, `
+        update song_suggestions
+        set notified_at = now()
+        where id = $1
+    `, id); err != nil {
+		return fmt.Errorf("mark song suggestion notified: %w", err)
+	}
+	return nil
+}
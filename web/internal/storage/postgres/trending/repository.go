@@ -23,7 +23,7 @@ func NewRepository(db storage.Querier) *Repository {
 // TrendingSets retrieves curated trending collections.
 func (r *Repository) TrendingSets(ctx context.Context) ([]trendingsvc.Trending, error) {
 	rows, err := r.db.Query(ctx, `
-        select ts.id, ts.name, ts.level_id, l.name, ts.description
+        select ts.id, ts.name, ts.level_id, l.name, ts.description, ts.level_source
         from trending_songs ts
         left join levels l on l.id = ts.level_id
         order by ts.id asc
@@ -43,7 +43,7 @@ func (r *Repository) TrendingSets(ctx context.Context) ([]trendingsvc.Trending,
 			desc      sql.NullString
 		)
 
-		if err := rows.Scan(&item.ID, &item.Name, &levelID, &levelName, &desc); err != nil {
+		if err := rows.Scan(&item.ID, &item.Name, &levelID, &levelName, &desc, &item.LevelSource); err != nil {
 			return nil, fmt.Errorf("scan trending: %w", err)
 		}
 
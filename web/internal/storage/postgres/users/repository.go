@@ -2,10 +2,15 @@ package users
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 
+	"github.com/jackc/pgx/v5"
 	usersvc "github.com/lyricapp/lyric/web/internal/services/users"
 	"github.com/lyricapp/lyric/web/internal/storage"
+	"github.com/lyricapp/lyric/web/pkg/pagination"
 )
 
 // Repository provides Postgres-backed user queries.
@@ -18,34 +23,74 @@ func NewRepository(db storage.Querier) *Repository {
 	return &Repository{db: db}
 }
 
-// List returns all users.
-func (r *Repository) List(ctx context.Context) ([]usersvc.User, error) {
-	query := `
-		select id, email, role
-		from users
-		order by id asc
-	`
+const accountColumns = `
+	u.id, u.email, u.role, u.status, u.ban_reason, u.banned_at, u.created_at,
+	(select max(s.last_used_at) from user_sessions s where s.user_id = u.id) as last_seen_at
+`
+
+// List returns a page of users matching the filters.
+func (r *Repository) List(ctx context.Context, params usersvc.ListParams) (usersvc.ListResult, error) {
+	result := usersvc.ListResult{
+		Data:    make([]usersvc.Account, 0),
+		Page:    params.Page,
+		PerPage: params.PerPage,
+	}
+
+	var (
+		conditions []string
+		args       []any
+	)
+	if params.Query != "" {
+		args = append(args, "%"+params.Query+"%")
+		conditions = append(conditions, fmt.Sprintf("u.email ilike $%d", len(args)))
+	}
+	if params.Role != "" {
+		args = append(args, params.Role)
+		conditions = append(conditions, fmt.Sprintf("u.role = $%d", len(args)))
+	}
+	if params.Status != "" {
+		args = append(args, params.Status)
+		conditions = append(conditions, fmt.Sprintf("u.status = $%d", len(args)))
+	}
+	where := ""
+	if len(conditions) > 0 {
+		where = "where " + strings.Join(conditions, " and ")
+	}
 
-	rows, err := r.db.Query(ctx, query)
+	if err := r.db.QueryRow(ctx, `select count(*) from users u `+where, args...).Scan(&result.Total); err != nil {
+		return usersvc.ListResult{}, fmt.Errorf("count users: %w", err)
+	}
+	if result.Total == 0 {
+		return result, nil
+	}
+
+	args = append(args, params.PerPage, pagination.Offset(params.Page, params.PerPage))
+	query := fmt.Sprintf(`
+		select %s
+		from users u
+		%s
+		order by u.id asc
+		limit $%d offset $%d
+	`, accountColumns, where, len(args)-1, len(args))
+
+	rows, err := r.db.Query(ctx, query, args...)
 	if err != nil {
-		return nil, fmt.Errorf("list users: %w", err)
+		return usersvc.ListResult{}, fmt.Errorf("list users: %w", err)
 	}
 	defer rows.Close()
 
-	var users []usersvc.User
 	for rows.Next() {
-		var user usersvc.User
-		if err := rows.Scan(&user.ID, &user.Email, &user.Role); err != nil {
-			return nil, fmt.Errorf("scan user: %w", err)
+		account, err := scanAccount(rows)
+		if err != nil {
+			return usersvc.ListResult{}, err
 		}
-		users = append(users, user)
+		result.Data = append(result.Data, account)
 	}
-
 	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("iterate users: %w", err)
+		return usersvc.ListResult{}, fmt.Errorf("iterate users: %w", err)
 	}
 
-	return users, nil
+	return result, nil
 }
 
 // SearchByEmail returns all users.
@@ -77,4 +122,219 @@ func (r *Repository) SearchByEmail(ctx context.Context, email string) ([]usersvc
 	}
 
 	return users, nil
-}
\ No newline at end of file
+}
+
+// Account returns the user.
+func (r *Repository) Account(ctx context.Context, id int) (usersvc.Account, bool, error) {
+	return r.queryAccount(ctx, `select `+accountColumns+` from users u where u.id = $1`, id)
+}
+
+// Detail returns the user with their most recent songs, playlists, feedback,
+// device sessions and linked identities.
+func (r *Repository) Detail(ctx context.Context, id, limit int) (usersvc.Detail, bool, error) {
+	account, ok, err := r.Account(ctx, id)
+	if err != nil || !ok {
+		return usersvc.Detail{}, false, err
+	}
+
+	detail := usersvc.Detail{
+		Account:    account,
+		Songs:      make([]usersvc.Song, 0),
+		Playlists:  make([]usersvc.Playlist, 0),
+		Feedback:   make([]usersvc.Feedback, 0),
+		Logins:     make([]usersvc.Login, 0),
+		Identities: make([]usersvc.Identity, 0),
+	}
+
+	rows, err := r.db.Query(ctx, `
+		select id, title, coalesce(status, 'created'), created_at
+		from songs
+		where created_by = $1
+		order by created_at desc, id desc
+		limit $2
+	`, id, limit)
+	if err != nil {
+		return usersvc.Detail{}, false, fmt.Errorf("list user songs: %w", err)
+	}
+	for rows.Next() {
+		var song usersvc.Song
+		if err := rows.Scan(&song.ID, &song.Title, &song.Status, &song.CreatedAt); err != nil {
+			rows.Close()
+			return usersvc.Detail{}, false, fmt.Errorf("scan user song: %w", err)
+		}
+		detail.Songs = append(detail.Songs, song)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return usersvc.Detail{}, false, fmt.Errorf("iterate user songs: %w", err)
+	}
+
+	rows, err = r.db.Query(ctx, `
+		select p.id, p.name, (select count(*) from playlist_song ps where ps.playlist_id = p.id), p.created_at
+		from playlists p
+		where p.user_id = $1
+		order by p.created_at desc, p.id desc
+		limit $2
+	`, id, limit)
+	if err != nil {
+		return usersvc.Detail{}, false, fmt.Errorf("list user playlists: %w", err)
+	}
+	for rows.Next() {
+		var playlist usersvc.Playlist
+		if err := rows.Scan(&playlist.ID, &playlist.Name, &playlist.Songs, &playlist.CreatedAt); err != nil {
+			rows.Close()
+			return usersvc.Detail{}, false, fmt.Errorf("scan user playlist: %w", err)
+		}
+		detail.Playlists = append(detail.Playlists, playlist)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return usersvc.Detail{}, false, fmt.Errorf("iterate user playlists: %w", err)
+	}
+
+	rows, err = r.db.Query(ctx, `
+		select id, coalesce(message, ''), created_at
+		from feedbacks
+		where user_id = $1
+		order by created_at desc, id desc
+		limit $2
+	`, id, limit)
+	if err != nil {
+		return usersvc.Detail{}, false, fmt.Errorf("list user feedback: %w", err)
+	}
+	for rows.Next() {
+		var feedback usersvc.Feedback
+		if err := rows.Scan(&feedback.ID, &feedback.Message, &feedback.CreatedAt); err != nil {
+			rows.Close()
+			return usersvc.Detail{}, false, fmt.Errorf("scan user feedback: %w", err)
+		}
+		detail.Feedback = append(detail.Feedback, feedback)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return usersvc.Detail{}, false, fmt.Errorf("iterate user feedback: %w", err)
+	}
+
+	rows, err = r.db.Query(ctx, `
+		select id, device_name, user_agent, created_at, last_used_at, expires_at, revoked_at
+		from user_sessions
+		where user_id = $1
+		order by created_at desc, id desc
+		limit $2
+	`, id, limit)
+	if err != nil {
+		return usersvc.Detail{}, false, fmt.Errorf("list user sessions: %w", err)
+	}
+	for rows.Next() {
+		var login usersvc.Login
+		if err := rows.Scan(&login.ID, &login.DeviceName, &login.UserAgent, &login.CreatedAt, &login.LastUsedAt, &login.ExpiresAt, &login.RevokedAt); err != nil {
+			rows.Close()
+			return usersvc.Detail{}, false, fmt.Errorf("scan user session: %w", err)
+		}
+		detail.Logins = append(detail.Logins, login)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return usersvc.Detail{}, false, fmt.Errorf("iterate user sessions: %w", err)
+	}
+
+	rows, err = r.db.Query(ctx, `
+		select provider, email, last_login_at
+		from user_identities
+		where user_id = $1
+		order by last_login_at desc
+	`, id)
+	if err != nil {
+		return usersvc.Detail{}, false, fmt.Errorf("list user identities: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var identity usersvc.Identity
+		if err := rows.Scan(&identity.Provider, &identity.Email, &identity.LastLoginAt); err != nil {
+			return usersvc.Detail{}, false, fmt.Errorf("scan user identity: %w", err)
+		}
+		detail.Identities = append(detail.Identities, identity)
+	}
+	if err := rows.Err(); err != nil {
+		return usersvc.Detail{}, false, fmt.Errorf("iterate user identities: %w", err)
+	}
+
+	return detail, true, nil
+}
+
+// SetRole changes the role of a user that has not been deleted.
+func (r *Repository) SetRole(ctx context.Context, id int, role string) (usersvc.Account, bool, error) {
+	return r.queryAccount(ctx, `
+		update users u
+		set role = $2
+		where u.id = $1 and u.status <> 'deleted'
+		returning `+accountColumns, id, role)
+}
+
+// Ban marks a user that has not been deleted as banned.
+func (r *Repository) Ban(ctx context.Context, id int, reason string, bannedBy int, at time.Time) (usersvc.Account, bool, error) {
+	return r.queryAccount(ctx, `
+		update users u
+		set status = 'banned', ban_reason = $2, banned_by = nullif($3, 0), banned_at = $4
+		where u.id = $1 and u.status <> 'deleted'
+		returning `+accountColumns, id, reason, bannedBy, at)
+}
+
+// Unban reactivates a user that has not been deleted and clears the ban.
+func (r *Repository) Unban(ctx context.Context, id int) (usersvc.Account, bool, error) {
+	return r.queryAccount(ctx, `
+		update users u
+		set status = 'active', ban_reason = null, banned_by = null, banned_at = null
+		where u.id = $1 and u.status <> 'deleted'
+		returning `+accountColumns, id)
+}
+
+// RevokeSessions revokes the user's device sessions that are still live and
+// records when they were signed out, for credentials without a session.
+func (r *Repository) RevokeSessions(ctx context.Context, userID int, at time.Time) (int, error) {
+	var revoked int
+	if err := r.db.QueryRow(ctx, `
+		with revoked as (
+			update user_sessions
+			set revoked_at = $2
+			where user_id = $1 and revoked_at is null and expires_at > $2
+			returning id
+		), signed_out as (
+			update users
+			set signed_out_at = $2
+			where id = $1
+		)
+		select count(*) from revoked
+	`, userID, at.UTC()).Scan(&revoked); err != nil {
+		return 0, fmt.Errorf("revoke user sessions: %w", err)
+	}
+	return revoked, nil
+}
+
+func (r *Repository) queryAccount(ctx context.Context, query string, args ...any) (usersvc.Account, bool, error) {
+	account, err := scanAccount(r.db.QueryRow(ctx, query, args...))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return usersvc.Account{}, false, nil
+	}
+	if err != nil {
+		return usersvc.Account{}, false, err
+	}
+	return account, true, nil
+}
+
+func scanAccount(row pgx.Row) (usersvc.Account, error) {
+	var account usersvc.Account
+	if err := row.Scan(
+		&account.ID,
+		&account.Email,
+		&account.Role,
+		&account.Status,
+		&account.BanReason,
+		&account.BannedAt,
+		&account.CreatedAt,
+		&account.LastSeenAt,
+	); err != nil {
+		return usersvc.Account{}, fmt.Errorf("scan user: %w", err)
+	}
+	return account, nil
+}
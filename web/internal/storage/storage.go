@@ -2,6 +2,10 @@ package storage
 
 import (
 	"context"
+	"fmt"
+	"strings"
+	"time"
+
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 )
@@ -13,3 +17,64 @@ type Querier interface {
 	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
 	Begin(ctx context.Context) (pgx.Tx, error)
 }
+
+// QueryAll runs the query and scans every row with scan. It returns an empty,
+// non-nil slice when there are no rows.
+func QueryAll[T any](ctx context.Context, db Querier, scan func(pgx.Rows, *T) error, query string, args ...any) ([]T, error) {
+	rows, err := db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := make([]T, 0)
+	for rows.Next() {
+		var item T
+		if err := scan(rows, &item); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// InTx runs fn in a transaction on db, committing when it returns nil and
+// rolling back otherwise. On a transaction it runs in a savepoint.
+func InTx(ctx context.Context, db Querier, fn func(tx Querier) error) error {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+	return nil
+}
+
+// LastModified returns when a row of the tables was last updated, or deleted
+// according to sync_tombstones, which names deletions after their table. It
+// returns the zero time when there is neither.
+func LastModified(ctx context.Context, db Querier, tables ...string) (time.Time, error) {
+	latest := make([]string, 0, len(tables)+1)
+	for _, table := range tables {
+		latest = append(latest, "(select max(updated_at) from "+table+")")
+	}
+	latest = append(latest, "(select max(deleted_at) from sync_tombstones where entity = any($1))")
+
+	var at *time.Time
+	if err := db.QueryRow(ctx, "select greatest("+strings.Join(latest, ", ")+")", tables).Scan(&at); err != nil {
+		return time.Time{}, err
+	}
+	if at == nil {
+		return time.Time{}, nil
+	}
+	return *at, nil
+}
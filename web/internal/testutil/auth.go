@@ -26,7 +26,7 @@ func AuthToken(t *testing.T, userID int) (*chi.Mux, string) {
 
 	r := chi.NewRouter()
 	r.Use(jwtauth.Verifier(tokenAuth))
-	r.Use(auth.Authenticator(tokenAuth))
+	r.Use(auth.Authenticator(tokenAuth, nil))
 
 	return r, tokenString
 }
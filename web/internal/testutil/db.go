@@ -11,7 +11,7 @@ import (
 	"github.com/lyricapp/lyric/web/internal/storage/postgres"
 )
 
-func SetupDB(t *testing.T) *pgxpool.Pool {
+func SetupDB(t testing.TB) *pgxpool.Pool {
 	projectRoot := ProjectRoot()
 	err := godotenv.Load(filepath.Join(projectRoot, ".env.test"))
 	if err != nil {
@@ -0,0 +1,79 @@
+package components
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"github.com/lyricapp/lyric/web/internal/services/audit"
+)
+
+// AdminAuditListProps drives the admin audit log.
+type AdminAuditListProps struct {
+	Entries     []audit.Entry
+	Actor       string
+	Action      string
+	EntityType  string
+	EntityID    string
+	Total       int
+	Page        int
+	HasNext     bool
+	Errors      []string
+	CurrentUser string
+}
+
+// adminAuditEntityTypes lists the entity types the log can be filtered by.
+var adminAuditEntityTypes = []string{audit.EntitySong, audit.EntityUser, audit.EntityChord}
+
+// adminAuditPageURL links to another page of the log, keeping the filters.
+func adminAuditPageURL(props AdminAuditListProps, page int) string {
+	query := url.Values{}
+	if props.Actor != "" {
+		query.Set("actor", props.Actor)
+	}
+	if props.Action != "" {
+		query.Set("action", props.Action)
+	}
+	if props.EntityType != "" {
+		query.Set("entity_type", props.EntityType)
+	}
+	if props.EntityID != "" {
+		query.Set("entity_id", props.EntityID)
+	}
+	query.Set("page", strconv.Itoa(page))
+	return "/admin/audit?" + query.Encode()
+}
+
+// adminAuditEntityURL filters the log down to one entity's history.
+func adminAuditEntityURL(entry audit.Entry) string {
+	query := url.Values{}
+	query.Set("entity_type", entry.EntityType)
+	query.Set("entity_id", entry.EntityID)
+	return "/admin/audit?" + query.Encode()
+}
+
+// adminAuditActor names who made a change.
+func adminAuditActor(entry audit.Entry) string {
+	switch {
+	case entry.ActorEmail != nil && *entry.ActorEmail != "":
+		return *entry.ActorEmail
+	case entry.ActorID != nil:
+		return fmt.Sprintf("User #%d", *entry.ActorID)
+	default:
+		return "System"
+	}
+}
+
+// adminAuditSnapshot pretty prints a snapshot for display.
+func adminAuditSnapshot(raw json.RawMessage) string {
+	if len(raw) == 0 {
+		return "—"
+	}
+	var out bytes.Buffer
+	if err := json.Indent(&out, raw, "", "  "); err != nil {
+		return string(raw)
+	}
+	return out.String()
+}
@@ -0,0 +1,30 @@
+package components
+
+import (
+	"strings"
+
+	"github.com/lyricapp/lyric/web/internal/services/chordrequests"
+)
+
+// AdminChordRequestListProps drives the admin chord request queue.
+type AdminChordRequestListProps struct {
+	Requests    []chordrequests.Request
+	Total       int
+	Page        int
+	HasNext     bool
+	Added       string
+	Errors      []string
+	CurrentUser string
+}
+
+// chordRequestSongs lists the songs a chord was requested for.
+func chordRequestSongs(songs []chordrequests.Song) string {
+	if len(songs) == 0 {
+		return "—"
+	}
+	titles := make([]string, 0, len(songs))
+	for _, song := range songs {
+		titles = append(titles, song.Title)
+	}
+	return strings.Join(titles, ", ")
+}
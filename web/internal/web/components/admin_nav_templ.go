@@ -29,7 +29,7 @@ func AdminNav(activeNav string) templ.Component {
 			templ_7745c5c3_Var1 = templ.NopComponent
 		}
 		ctx = templ.ClearChildren(ctx)
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 1, "<header class=\"bg-base-100/80 sticky top-0 z-10 backdrop-blur\"><div class=\"navbar mx-auto max-w-6xl px-6\"><div class=\"navbar-start\"><a href=\"/admin/songs\" class=\"text-xl font-semibold\">Lyric</a></div><div class=\"navbar-end hidden space-x-2 lg:flex\"><ul class=\"menu menu-horizontal space-x-2\"><li><a href=\"/admin/songs\" class=\"font-medium\" hx-boost=\"true\">Songs</a></li><li><a href=\"/admin/users\" class=\"font-medium\" hx-boost=\"true\">Users</a></li></ul></div></div></header>")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 1, "<header class=\"bg-base-100/80 sticky top-0 z-10 backdrop-blur\"><div class=\"navbar mx-auto max-w-6xl px-6\"><div class=\"navbar-start\"><a href=\"/admin/songs\" class=\"text-xl font-semibold\">Lyric</a></div><div class=\"navbar-end hidden space-x-2 lg:flex\"><ul class=\"menu menu-horizontal space-x-2\"><li><a href=\"/admin/songs\" class=\"font-medium\" hx-boost=\"true\">Songs</a></li><li><a href=\"/admin/users\" class=\"font-medium\" hx-boost=\"true\">Users</a></li><li><a href=\"/admin/chord-requests\" class=\"font-medium\" hx-boost=\"true\">Chord requests</a></li><li><a href=\"/admin/audit\" class=\"font-medium\" hx-boost=\"true\">Audit log</a></li></ul></div></div></header>")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
@@ -33,12 +33,12 @@ type AdminSongEditProps struct {
 
 // AdminSongListProps contains information for the admin song index.
 type AdminSongListProps struct {
-	SearchTerm      string
-	Total           int
-	Songs           []AdminSongListItem
-	CurrentUser     string
-	CurrentUserRole string
-	ResultsLabel    string
+	SearchTerm   string
+	Total        int
+	Songs        []AdminSongListItem
+	CurrentUser  string
+	CanDelete    bool
+	ResultsLabel string
 }
 
 // AdminSongListItem represents a single row in the admin song list.
@@ -80,6 +80,10 @@ type AdminSongFormValues struct {
 	ArtistIDs       []string
 	WriterIDs       []string
 	Lyric           string
+	BPM             string
+	TimeSignature   string
+	Duration        string
+	Capo            string
 }
 
 // AdminSongOption represents a selectable option in the admin form.
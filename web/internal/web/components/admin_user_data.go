@@ -0,0 +1,132 @@
+package components
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/lyricapp/lyric/web/internal/services/permissions"
+	"github.com/lyricapp/lyric/web/internal/services/users"
+)
+
+// AdminUserListProps drives the admin user list.
+type AdminUserListProps struct {
+	Users       []users.Account
+	Query       string
+	Role        string
+	Status      string
+	Total       int
+	Page        int
+	HasNext     bool
+	Errors      []string
+	CurrentUser string
+}
+
+// AdminUserDetailProps drives the admin user detail page.
+type AdminUserDetailProps struct {
+	User        users.Detail
+	Self        bool
+	Notice      string
+	Errors      []string
+	CurrentUser string
+}
+
+// adminUserRoles lists the roles an admin can assign.
+func adminUserRoles() []string {
+	roles := make([]string, 0, len(permissions.Roles))
+	for _, role := range permissions.Roles {
+		roles = append(roles, string(role))
+	}
+	return roles
+}
+
+// adminUserPageURL links to another page of the list, keeping the filters.
+func adminUserPageURL(props AdminUserListProps, page int) string {
+	query := url.Values{}
+	if props.Query != "" {
+		query.Set("q", props.Query)
+	}
+	if props.Role != "" {
+		query.Set("role", props.Role)
+	}
+	if props.Status != "" {
+		query.Set("status", props.Status)
+	}
+	query.Set("page", strconv.Itoa(page))
+	return "/admin/users?" + query.Encode()
+}
+
+// adminUserNotice describes the action that just succeeded.
+func adminUserNotice(notice string) string {
+	switch notice {
+	case "role":
+		return "Role updated."
+	case "banned":
+		return "User banned and signed out of every device."
+	case "unbanned":
+		return "Ban lifted."
+	case "logged-out":
+		return "User signed out of every device."
+	default:
+		return ""
+	}
+}
+
+// adminUserStatusBadge picks the badge colour for an account status.
+func adminUserStatusBadge(status string) string {
+	switch status {
+	case users.StatusBanned:
+		return "badge badge-error"
+	case users.StatusDeleted:
+		return "badge badge-ghost"
+	default:
+		return "badge badge-success badge-outline"
+	}
+}
+
+// adminUserLoginDevice names the device a session was opened on.
+func adminUserLoginDevice(login users.Login) string {
+	if login.DeviceName != nil && *login.DeviceName != "" {
+		return *login.DeviceName
+	}
+	if login.UserAgent != nil && *login.UserAgent != "" {
+		return *login.UserAgent
+	}
+	return "Unknown device"
+}
+
+// adminUserLoginState describes whether a session is still usable.
+func adminUserLoginState(login users.Login) string {
+	switch {
+	case login.RevokedAt != nil:
+		return "Revoked " + login.RevokedAt.Format("2006-01-02 15:04")
+	case !login.Active(time.Now()):
+		return "Expired"
+	default:
+		return "Active"
+	}
+}
+
+// adminUserTime formats an optional timestamp.
+func adminUserTime(at *time.Time) string {
+	if at == nil {
+		return "—"
+	}
+	return at.Format("2006-01-02 15:04")
+}
+
+// adminUserOptional renders an optional string.
+func adminUserOptional(value *string) string {
+	if value == nil || *value == "" {
+		return "—"
+	}
+	return *value
+}
+
+func adminUserPath(id int, action string) string {
+	if action == "" {
+		return fmt.Sprintf("/admin/users/%d", id)
+	}
+	return fmt.Sprintf("/admin/users/%d/%s", id, action)
+}
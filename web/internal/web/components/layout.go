@@ -34,6 +34,7 @@ type PageMeta struct {
 	ActiveNav   string
 	MainClass   string
 	NoIndex     bool
+	Theme       string
 	SchemaJSON  string
 	ExtraHead   []templ.Component
 	BodyScripts []templ.Component
@@ -100,6 +101,17 @@ func (m PageMeta) twitterCardValue() string {
 	return "summary_large_image"
 }
 
+// themeAttributes pins the page to the reader's light or dark theme. The
+// "system" default sets no data-theme, so the stylesheet follows the browser's
+// prefers-color-scheme.
+func (m PageMeta) themeAttributes() templ.Attributes {
+	switch m.Theme {
+	case "light", "dark":
+		return templ.Attributes{"data-theme": m.Theme}
+	}
+	return templ.Attributes{}
+}
+
 func (m PageMeta) mainClassValue() string {
 	if strings.TrimSpace(m.MainClass) != "" {
 		return m.MainClass
@@ -2,10 +2,12 @@ package components
 
 import (
 	"encoding/json"
+	"fmt"
 	"log"
 	"sort"
 	"strings"
 
+	"github.com/lyricapp/lyric/web/internal/services/library"
 	"github.com/lyricapp/lyric/web/internal/web/data"
 )
 
@@ -16,14 +18,22 @@ type LibrarySong struct {
 	Artist string `json:"artist"`
 }
 
-// LibraryProps contains the data required to render the library page.
+// LibraryHistory is what a signed-in reader keeps coming back to.
+type LibraryHistory struct {
+	Recent    []library.RecentSong
+	Favorites library.Favorites
+}
+
+// LibraryProps contains the data required to render the library page. History
+// is nil for anonymous readers.
 type LibraryProps struct {
 	Songs        []LibrarySong
 	SongsPayload string
+	History      *LibraryHistory
 }
 
 // BuildLibraryProps assembles a sorted list of songs and a JSON payload for client-side interactions.
-func BuildLibraryProps() LibraryProps {
+func BuildLibraryProps(history *LibraryHistory) LibraryProps {
 	songs := make([]LibrarySong, 0, len(data.Songs))
 	for _, song := range data.Songs {
 		songs = append(songs, LibrarySong{
@@ -47,5 +57,19 @@ func BuildLibraryProps() LibraryProps {
 	return LibraryProps{
 		Songs:        songs,
 		SongsPayload: string(payloadBytes),
+		History:      history,
+	}
+}
+
+// libraryArtists lists a song's artists on one line.
+func libraryArtists(names []string) string {
+	return strings.Join(names, ", ")
+}
+
+// libraryViews describes how often a recently viewed song was opened.
+func libraryViews(song library.RecentSong) string {
+	if song.Views == 1 {
+		return "Viewed once, " + song.ViewedAt.Format("2 Jan 2006")
 	}
+	return fmt.Sprintf("Viewed %d times, last %s", song.Views, song.ViewedAt.Format("2 Jan 2006"))
 }
@@ -6,6 +6,7 @@ import (
 	"regexp"
 	"strings"
 
+	songsvc "github.com/lyricapp/lyric/web/internal/services/songs"
 	"github.com/lyricapp/lyric/web/internal/web/data"
 )
 
@@ -24,8 +25,18 @@ const (
 	songMaxLineGap   = 24
 	songMinColumns   = 1
 	songMaxColumns   = 2
+	songMinFontSize  = 12
+	songMaxFontSize  = 28
 )
 
+// SongDetailPreferences are a signed-in reader's saved defaults for the song
+// page. The zero value keeps the site defaults.
+type SongDetailPreferences struct {
+	Mode     SongDetailMode
+	FontSize int
+	Theme    string
+}
+
 // SongModeOption represents a selectable chord display mode toggle.
 type SongModeOption struct {
 	Label  string
@@ -84,6 +95,8 @@ type SongDetailProps struct {
 	ModeOptions   []SongModeOption
 	Columns       int
 	ColumnOptions []SongColumnOption
+	FontSize      int
+	Theme         string
 
 	Prelude []string
 
@@ -128,6 +141,10 @@ type SongDetailProps struct {
 
 	ShowOverGapControls bool
 	ShowLineGapControls bool
+
+	// AutoScroll paces the sheet's auto scroll; nil hides the control.
+	AutoScroll        *songsvc.AutoScroll
+	AutoScrollDisplay string
 }
 
 var (
@@ -153,7 +170,12 @@ func init() {
 }
 
 // BuildSongDetailProps prepares the view model for rendering a song detail page.
-func BuildSongDetailProps(song data.Song, mode SongDetailMode, transpose, overGap, lineGap, columns int) SongDetailProps {
+// An empty mode falls back to the reader's preferred one.
+func BuildSongDetailProps(song data.Song, mode SongDetailMode, transpose, overGap, lineGap, columns int, prefs SongDetailPreferences) SongDetailProps {
+	defaultMode := normalizeSongMode(prefs.Mode)
+	if mode == "" {
+		mode = defaultMode
+	}
 	normalizedMode := normalizeSongMode(mode)
 	clampedTranspose := clampTranspose(transpose)
 	clampedOverGap := clampOverGap(overGap)
@@ -172,9 +194,11 @@ func BuildSongDetailProps(song data.Song, mode SongDetailMode, transpose, overGa
 	props := SongDetailProps{
 		Song:          song,
 		Mode:          normalizedMode,
-		ModeOptions:   buildModeOptions(song.ID, normalizedMode, clampedTranspose, clampedOverGap, clampedLineGap, clampedColumns),
+		ModeOptions:   buildModeOptions(song.ID, defaultMode, normalizedMode, clampedTranspose, clampedOverGap, clampedLineGap, clampedColumns),
 		Columns:       clampedColumns,
-		ColumnOptions: buildColumnOptions(song.ID, normalizedMode, clampedTranspose, clampedOverGap, clampedLineGap, clampedColumns),
+		ColumnOptions: buildColumnOptions(song.ID, defaultMode, normalizedMode, clampedTranspose, clampedOverGap, clampedLineGap, clampedColumns),
+		FontSize:      clampFontSize(prefs.FontSize),
+		Theme:         prefs.Theme,
 		Prelude:       prelude,
 		Overlay:       buildOverlayLines(parsed),
 		Inline:        buildInlineLines(parsed),
@@ -184,9 +208,9 @@ func BuildSongDetailProps(song data.Song, mode SongDetailMode, transpose, overGa
 		MinTranspose:      songMinTranspose,
 		MaxTranspose:      songMaxTranspose,
 		TransposeDisplay:  formatSigned(clampedTranspose),
-		TransposeDownURL:  songDetailURL(song.ID, normalizedMode, clampedTranspose-1, clampedOverGap, clampedLineGap, clampedColumns),
-		TransposeUpURL:    songDetailURL(song.ID, normalizedMode, clampedTranspose+1, clampedOverGap, clampedLineGap, clampedColumns),
-		TransposeResetURL: songDetailURL(song.ID, normalizedMode, 0, clampedOverGap, clampedLineGap, clampedColumns),
+		TransposeDownURL:  songDetailURL(song.ID, defaultMode, normalizedMode, clampedTranspose-1, clampedOverGap, clampedLineGap, clampedColumns),
+		TransposeUpURL:    songDetailURL(song.ID, defaultMode, normalizedMode, clampedTranspose+1, clampedOverGap, clampedLineGap, clampedColumns),
+		TransposeResetURL: songDetailURL(song.ID, defaultMode, normalizedMode, 0, clampedOverGap, clampedLineGap, clampedColumns),
 		CanTransposeDown:  clampedTranspose > songMinTranspose,
 		CanTransposeUp:    clampedTranspose < songMaxTranspose,
 		BaseKey:           baseKey,
@@ -195,22 +219,27 @@ func BuildSongDetailProps(song data.Song, mode SongDetailMode, transpose, overGa
 
 		OverGap:            clampedOverGap,
 		OverGapDisplay:     formatPixels(clampedOverGap),
-		OverGapDownURL:     songDetailURL(song.ID, normalizedMode, clampedTranspose, clampedOverGap-2, clampedLineGap, clampedColumns),
-		OverGapUpURL:       songDetailURL(song.ID, normalizedMode, clampedTranspose, clampedOverGap+2, clampedLineGap, clampedColumns),
-		OverGapResetURL:    songDetailURL(song.ID, normalizedMode, clampedTranspose, 2, clampedLineGap, clampedColumns),
+		OverGapDownURL:     songDetailURL(song.ID, defaultMode, normalizedMode, clampedTranspose, clampedOverGap-2, clampedLineGap, clampedColumns),
+		OverGapUpURL:       songDetailURL(song.ID, defaultMode, normalizedMode, clampedTranspose, clampedOverGap+2, clampedLineGap, clampedColumns),
+		OverGapResetURL:    songDetailURL(song.ID, defaultMode, normalizedMode, clampedTranspose, 2, clampedLineGap, clampedColumns),
 		CanDecreaseOverGap: clampedOverGap > songMinOverGap,
 		CanIncreaseOverGap: clampedOverGap < songMaxOverGap,
 
 		LineGap:            clampedLineGap,
 		LineGapDisplay:     formatPixels(clampedLineGap),
-		LineGapDownURL:     songDetailURL(song.ID, normalizedMode, clampedTranspose, clampedOverGap, clampedLineGap-2, clampedColumns),
-		LineGapUpURL:       songDetailURL(song.ID, normalizedMode, clampedTranspose, clampedOverGap, clampedLineGap+2, clampedColumns),
-		LineGapResetURL:    songDetailURL(song.ID, normalizedMode, clampedTranspose, clampedOverGap, 0, clampedColumns),
+		LineGapDownURL:     songDetailURL(song.ID, defaultMode, normalizedMode, clampedTranspose, clampedOverGap, clampedLineGap-2, clampedColumns),
+		LineGapUpURL:       songDetailURL(song.ID, defaultMode, normalizedMode, clampedTranspose, clampedOverGap, clampedLineGap+2, clampedColumns),
+		LineGapResetURL:    songDetailURL(song.ID, defaultMode, normalizedMode, clampedTranspose, clampedOverGap, 0, clampedColumns),
 		CanDecreaseLineGap: clampedLineGap > songMinLineGap,
 		CanIncreaseLineGap: clampedLineGap < songMaxLineGap,
 
 		ShowOverGapControls: true,
 		ShowLineGapControls: normalizedMode == SongModeInline,
+
+		AutoScroll: songAutoScroll(song),
+	}
+	if props.AutoScroll != nil {
+		props.AutoScrollDisplay = formatAutoScroll(*props.AutoScroll)
 	}
 
 	props.TransposeDownAria = boolToAria(!props.CanTransposeDown)
@@ -231,11 +260,11 @@ func BuildSongDetailProps(song data.Song, mode SongDetailMode, transpose, overGa
 	return props
 }
 
-func buildModeOptions(songID string, active SongDetailMode, transpose, overGap, lineGap, columns int) []SongModeOption {
+func buildModeOptions(songID string, defaultMode, active SongDetailMode, transpose, overGap, lineGap, columns int) []SongModeOption {
 	return []SongModeOption{
-		modeOption("Overlay", SongModeOverlay, active, songID, transpose, overGap, lineGap, columns),
-		modeOption("Inline", SongModeInline, active, songID, transpose, overGap, lineGap, columns),
-		modeOption("Lyric", SongModeLyric, active, songID, transpose, overGap, lineGap, columns),
+		modeOption("Overlay", SongModeOverlay, defaultMode, active, songID, transpose, overGap, lineGap, columns),
+		modeOption("Inline", SongModeInline, defaultMode, active, songID, transpose, overGap, lineGap, columns),
+		modeOption("Lyric", SongModeLyric, defaultMode, active, songID, transpose, overGap, lineGap, columns),
 	}
 }
 
@@ -250,31 +279,33 @@ func normalizeSongMode(mode SongDetailMode) SongDetailMode {
 	}
 }
 
-func buildColumnOptions(songID string, mode SongDetailMode, transpose, overGap, lineGap, columns int) []SongColumnOption {
+func buildColumnOptions(songID string, defaultMode, mode SongDetailMode, transpose, overGap, lineGap, columns int) []SongColumnOption {
 	opts := []SongColumnOption{
 		{Label: "1 column", Columns: 1},
 		{Label: "2 columns", Columns: 2},
 	}
 	for i := range opts {
 		opts[i].Active = opts[i].Columns == columns
-		opts[i].URL = songDetailURL(songID, mode, transpose, overGap, lineGap, opts[i].Columns)
+		opts[i].URL = songDetailURL(songID, defaultMode, mode, transpose, overGap, lineGap, opts[i].Columns)
 	}
 	return opts
 }
 
-func modeOption(label string, value SongDetailMode, active SongDetailMode, songID string, transpose, overGap, lineGap, columns int) SongModeOption {
+func modeOption(label string, value, defaultMode, active SongDetailMode, songID string, transpose, overGap, lineGap, columns int) SongModeOption {
 	return SongModeOption{
 		Label:  label,
 		Mode:   value,
 		Active: value == active,
-		URL:    songDetailURL(songID, value, transpose, overGap, lineGap, columns),
+		URL:    songDetailURL(songID, defaultMode, value, transpose, overGap, lineGap, columns),
 	}
 }
 
-func songDetailURL(songID string, mode SongDetailMode, transpose, overGap, lineGap, columns int) string {
+// songDetailURL links to the song page, leaving out settings at their
+// defaults. The view is left out when it is the reader's preferred mode.
+func songDetailURL(songID string, defaultMode, mode SongDetailMode, transpose, overGap, lineGap, columns int) string {
 	base := fmt.Sprintf("/songs/%s", songID)
 	params := url.Values{}
-	if mode != SongModeOverlay {
+	if mode != defaultMode {
 		params.Set("view", string(mode))
 	}
 	if transpose != 0 {
@@ -335,6 +366,30 @@ func clampColumns(value int) int {
 	return value
 }
 
+// clampFontSize keeps a preferred font size in range; zero keeps the default.
+func clampFontSize(value int) int {
+	if value == 0 {
+		return 0
+	}
+	if value < songMinFontSize {
+		return songMinFontSize
+	}
+	if value > songMaxFontSize {
+		return songMaxFontSize
+	}
+	return value
+}
+
+// columnStyle lays the arrangement out in columns, at the reader's preferred
+// font size when they have one.
+func (p SongDetailProps) columnStyle() string {
+	style := fmt.Sprintf("column-count:%d; column-gap:2.5rem;", p.Columns)
+	if p.FontSize > 0 {
+		style += fmt.Sprintf(" --text-base:%dpx;", p.FontSize)
+	}
+	return style
+}
+
 type parsedSongLine struct {
 	raw        string
 	trimmed    string
@@ -635,6 +690,33 @@ func formatSigned(value int) string {
 	return fmt.Sprintf("%d", value)
 }
 
+// songAutoScroll paces the song the same way the API's auto_scroll does.
+func songAutoScroll(song data.Song) *songsvc.AutoScroll {
+	lyric := song.Body
+	performance := songsvc.Song{Lyric: &lyric}
+	if song.BPM > 0 {
+		bpm := song.BPM
+		performance.BPM = &bpm
+	}
+	if strings.TrimSpace(song.TimeSignature) != "" {
+		signature := song.TimeSignature
+		performance.TimeSignature = &signature
+	}
+	if song.DurationSeconds > 0 {
+		duration := song.DurationSeconds
+		performance.DurationSeconds = &duration
+	}
+	return songsvc.ComputeAutoScroll(performance)
+}
+
+func formatAutoScroll(scroll songsvc.AutoScroll) string {
+	label := fmt.Sprintf("%d:%02d · %g lines per minute", scroll.DurationSeconds/60, scroll.DurationSeconds%60, scroll.LinesPerMinute)
+	if scroll.Estimated {
+		label += " (estimated from the tempo)"
+	}
+	return label
+}
+
 func formatPixels(value int) string {
 	return fmt.Sprintf("%dpx", value)
 }
@@ -9,6 +9,11 @@ type Song struct {
 	Key      string
 	Language FilterLanguage
 	Body     string
+
+	// Performance metadata pacing the auto scroll; zero when unknown.
+	BPM             int
+	TimeSignature   string
+	DurationSeconds int
 }
 
 var Songs = []Song{
@@ -48,6 +53,8 @@ Verse 4
 That [G]saved a [C]wretch like [D]me
 [G]I once was [C]lost, but [G]now am [C]found
 Was [G]blind, but [D]now I [G]see`,
+		BPM:           72,
+		TimeSignature: "3/4",
 	},
 	{
 		ID:       "auld-lang-syne",
@@ -71,6 +78,7 @@ And [C]days of [D]auld lang [G]syne.
 For [C]auld lang [D]syne,
 [G]We'll tak a [D]cup o' [G]kindness yet,
 For [C]auld [D]lang [G]syne.`,
+		DurationSeconds: 150,
 	},
 	{
 		ID:       "greensleeves",
@@ -94,6 +102,8 @@ De[Em]lighting [D]in your [G]compa[B7]ny
 [D]Greensleeves was my [B7]delight
 [Em]Greensleeves was my [G]heart of gold
 [D]And who but my [B7]lady [Em]Greensleeves`,
+		BPM:           96,
+		TimeSignature: "6/8",
 	},
 }
 
@@ -0,0 +1,302 @@
+package chordtheory
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ErrInvalidSymbol is returned when a chord symbol cannot be parsed.
+var ErrInvalidSymbol = errors.New("chordtheory: invalid chord symbol")
+
+// Interval offsets in semitones from the chord root.
+const (
+	intervalRoot         = 0
+	intervalFlatNinth    = 1
+	intervalSecond       = 2
+	intervalMinorThird   = 3
+	intervalMajorThird   = 4
+	intervalFourth       = 5
+	intervalFlatFifth    = 6
+	intervalFifth        = 7
+	intervalSharpFifth   = 8
+	intervalSixth        = 9
+	intervalMinorSeventh = 10
+	intervalMajorSeventh = 11
+)
+
+// noInterval marks an absent chord degree.
+const noInterval = -1
+
+var naturalPitchClasses = map[byte]int{
+	'C': 0,
+	'D': 2,
+	'E': 4,
+	'F': 5,
+	'G': 7,
+	'A': 9,
+	'B': 11,
+}
+
+// Chord is a parsed chord symbol reduced to its pitch content.
+// Quality and Extensions hold the canonical spelling of the symbol's suffix,
+// e.g. "m" and ["7", "b5"] are folded into "m7b5" with no extensions.
+type Chord struct {
+	Symbol     string
+	Root       int
+	Bass       int
+	Quality    string
+	Extensions []string
+	Intervals  []int
+}
+
+// HasSlashBass reports whether the chord specifies a bass note other than its root.
+func (c Chord) HasSlashBass() bool {
+	return c.Bass != c.Root
+}
+
+// PitchClasses returns the distinct pitch classes (C=0 … B=11) sounded by the chord,
+// ordered by interval from the root. A slash bass is included when it is not already a chord tone.
+func (c Chord) PitchClasses() []int {
+	seen := make(map[int]struct{}, len(c.Intervals)+1)
+	classes := make([]int, 0, len(c.Intervals)+1)
+	for _, interval := range c.Intervals {
+		pc := (c.Root + interval) % 12
+		if _, ok := seen[pc]; ok {
+			continue
+		}
+		seen[pc] = struct{}{}
+		classes = append(classes, pc)
+	}
+	if _, ok := seen[c.Bass]; !ok {
+		classes = append(classes, c.Bass)
+	}
+	return classes
+}
+
+// Parse converts a chord symbol such as "Gsus2/B" or "F#m7b5" into its pitch set.
+func Parse(symbol string) (Chord, error) {
+	trimmed := strings.TrimSpace(symbol)
+	if trimmed == "" {
+		return Chord{}, ErrInvalidSymbol
+	}
+
+	root, rest, err := parseNote(trimmed)
+	if err != nil {
+		return Chord{}, fmt.Errorf("%w: %q", ErrInvalidSymbol, symbol)
+	}
+
+	bass := root
+	if idx := strings.LastIndex(rest, "/"); idx >= 0 {
+		if pc, remainder, noteErr := parseNote(rest[idx+1:]); noteErr == nil && remainder == "" {
+			bass = pc
+			rest = rest[:idx]
+		}
+	}
+
+	shape, err := parseQuality(rest)
+	if err != nil {
+		return Chord{}, fmt.Errorf("%w: %q", ErrInvalidSymbol, symbol)
+	}
+	quality, extensions := shape.spell()
+
+	return Chord{
+		Symbol:     trimmed,
+		Root:       root,
+		Bass:       bass,
+		Quality:    quality,
+		Extensions: extensions,
+		Intervals:  shape.intervals(),
+	}, nil
+}
+
+// parseNote reads a note letter plus accidentals from the start of input.
+func parseNote(input string) (int, string, error) {
+	if input == "" {
+		return 0, "", ErrInvalidSymbol
+	}
+
+	letter := input[0]
+	if letter >= 'a' && letter <= 'g' {
+		letter -= 'a' - 'A'
+	}
+	pc, ok := naturalPitchClasses[letter]
+	if !ok {
+		return 0, "", ErrInvalidSymbol
+	}
+
+	rest := input[1:]
+	for {
+		switch {
+		case strings.HasPrefix(rest, "#"):
+			pc++
+			rest = rest[1:]
+		case strings.HasPrefix(rest, "♯"):
+			pc++
+			rest = rest[len("♯"):]
+		case strings.HasPrefix(rest, "♭"):
+			pc--
+			rest = rest[len("♭"):]
+		case strings.HasPrefix(rest, "b"):
+			pc--
+			rest = rest[1:]
+		default:
+			return ((pc % 12) + 12) % 12, rest, nil
+		}
+	}
+}
+
+// chordShape accumulates degrees while the quality and extensions are read.
+type chordShape struct {
+	third   int
+	fifth   int
+	seventh int
+	extras  map[int]struct{}
+	// major records a "maj"/"M" prefix, which raises any seventh that follows.
+	major bool
+}
+
+func (s *chordShape) add(interval int) {
+	s.extras[interval] = struct{}{}
+}
+
+func (s *chordShape) ensureSeventh() {
+	if s.seventh == noInterval {
+		s.seventh = intervalMinorSeventh
+	}
+}
+
+func (s chordShape) intervals() []int {
+	set := map[int]struct{}{intervalRoot: {}}
+	for _, degree := range []int{s.third, s.fifth, s.seventh} {
+		if degree != noInterval {
+			set[degree] = struct{}{}
+		}
+	}
+	for extra := range s.extras {
+		set[extra%12] = struct{}{}
+	}
+
+	intervals := make([]int, 0, len(set))
+	for interval := range set {
+		intervals = append(intervals, interval)
+	}
+	sort.Ints(intervals)
+	return intervals
+}
+
+func halfDiminished(s *chordShape) {
+	s.third, s.fifth, s.seventh = intervalMinorThird, intervalFlatFifth, intervalMinorSeventh
+}
+
+func diminishedSeventh(s *chordShape) {
+	s.third, s.fifth, s.seventh = intervalMinorThird, intervalFlatFifth, intervalSixth
+}
+
+// qualityTokens are matched immediately after the root, longest first.
+var qualityTokens = []struct {
+	token string
+	apply func(*chordShape)
+}{
+	{"m7b5", halfDiminished},
+	{"ø7", halfDiminished},
+	{"ø", halfDiminished},
+	{"dim7", diminishedSeventh},
+	{"°7", diminishedSeventh},
+	{"o7", diminishedSeventh},
+	{"dim", func(s *chordShape) { s.third, s.fifth = intervalMinorThird, intervalFlatFifth }},
+	{"°", func(s *chordShape) { s.third, s.fifth = intervalMinorThird, intervalFlatFifth }},
+	{"aug", func(s *chordShape) { s.fifth = intervalSharpFifth }},
+	{"+", func(s *chordShape) { s.fifth = intervalSharpFifth }},
+	{"maj", func(s *chordShape) { s.major = true }},
+	{"Maj", func(s *chordShape) { s.major = true }},
+	{"ma", func(s *chordShape) { s.major = true }},
+	{"M", func(s *chordShape) { s.major = true }},
+	{"Δ", func(s *chordShape) { s.seventh = intervalMajorSeventh }},
+	{"min", func(s *chordShape) { s.third = intervalMinorThird }},
+	{"mi", func(s *chordShape) { s.third = intervalMinorThird }},
+	{"m", func(s *chordShape) { s.third = intervalMinorThird }},
+	{"-", func(s *chordShape) { s.third = intervalMinorThird }},
+	{"5", func(s *chordShape) { s.third = noInterval }},
+}
+
+// extensionTokens are matched repeatedly after the quality, longest first.
+var extensionTokens = []struct {
+	token string
+	apply func(*chordShape)
+}{
+	{"add13", func(s *chordShape) { s.add(intervalSixth) }},
+	{"add11", func(s *chordShape) { s.add(intervalFourth) }},
+	{"add9", func(s *chordShape) { s.add(intervalSecond) }},
+	{"add4", func(s *chordShape) { s.add(intervalFourth) }},
+	{"add2", func(s *chordShape) { s.add(intervalSecond) }},
+	{"sus2", func(s *chordShape) { s.third = intervalSecond }},
+	{"sus4", func(s *chordShape) { s.third = intervalFourth }},
+	{"sus", func(s *chordShape) { s.third = intervalFourth }},
+	{"maj7", func(s *chordShape) { s.seventh = intervalMajorSeventh }},
+	{"Maj7", func(s *chordShape) { s.seventh = intervalMajorSeventh }},
+	{"M7", func(s *chordShape) { s.seventh = intervalMajorSeventh }},
+	{"Δ7", func(s *chordShape) { s.seventh = intervalMajorSeventh }},
+	{"Δ", func(s *chordShape) { s.seventh = intervalMajorSeventh }},
+	{"maj", func(s *chordShape) { s.major = true }},
+	{"Maj", func(s *chordShape) { s.major = true }},
+	{"#11", func(s *chordShape) { s.ensureSeventh(); s.add(intervalFlatFifth) }},
+	{"b13", func(s *chordShape) { s.ensureSeventh(); s.add(intervalSharpFifth) }},
+	{"13", func(s *chordShape) { s.ensureSeventh(); s.add(intervalSecond); s.add(intervalSixth) }},
+	{"11", func(s *chordShape) { s.ensureSeventh(); s.add(intervalSecond); s.add(intervalFourth) }},
+	{"b9", func(s *chordShape) { s.ensureSeventh(); s.add(intervalFlatNinth) }},
+	{"#9", func(s *chordShape) { s.ensureSeventh(); s.add(intervalMinorThird) }},
+	{"b5", func(s *chordShape) { s.fifth = intervalFlatFifth }},
+	{"#5", func(s *chordShape) { s.fifth = intervalSharpFifth }},
+	{"6/9", func(s *chordShape) { s.add(intervalSixth); s.add(intervalSecond) }},
+	{"69", func(s *chordShape) { s.add(intervalSixth); s.add(intervalSecond) }},
+	{"9", func(s *chordShape) { s.ensureSeventh(); s.add(intervalSecond) }},
+	{"7", func(s *chordShape) { s.ensureSeventh() }},
+	{"6", func(s *chordShape) { s.add(intervalSixth) }},
+	{"2", func(s *chordShape) { s.add(intervalSecond) }},
+	{"4", func(s *chordShape) { s.add(intervalFourth) }},
+}
+
+// parseQuality interprets everything after the root (and slash bass) as degrees.
+func parseQuality(input string) (chordShape, error) {
+	shape := chordShape{
+		third:   intervalMajorThird,
+		fifth:   intervalFifth,
+		seventh: noInterval,
+		extras:  map[int]struct{}{},
+	}
+
+	rest := strings.NewReplacer("(", "", ")", "", " ", "", ",", "").Replace(input)
+
+	for _, candidate := range qualityTokens {
+		if !strings.HasPrefix(rest, candidate.token) {
+			continue
+		}
+		candidate.apply(&shape)
+		rest = rest[len(candidate.token):]
+		break
+	}
+
+	for rest != "" {
+		matched := false
+		for _, candidate := range extensionTokens {
+			if !strings.HasPrefix(rest, candidate.token) {
+				continue
+			}
+			candidate.apply(&shape)
+			rest = rest[len(candidate.token):]
+			matched = true
+			break
+		}
+		if !matched {
+			return chordShape{}, ErrInvalidSymbol
+		}
+	}
+
+	if shape.major && shape.seventh != noInterval {
+		shape.seventh = intervalMajorSeventh
+	}
+
+	return shape, nil
+}
@@ -0,0 +1,63 @@
+package chordtheory_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/lyricapp/lyric/web/pkg/chordtheory"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		symbol    string
+		root      int
+		bass      int
+		intervals []int
+	}{
+		{symbol: "C", root: 0, bass: 0, intervals: []int{0, 4, 7}},
+		{symbol: "Am", root: 9, bass: 9, intervals: []int{0, 3, 7}},
+		{symbol: "Bb", root: 10, bass: 10, intervals: []int{0, 4, 7}},
+		{symbol: "F#m7b5", root: 6, bass: 6, intervals: []int{0, 3, 6, 10}},
+		{symbol: "Gsus2/B", root: 7, bass: 11, intervals: []int{0, 2, 7}},
+		{symbol: "Cmaj7", root: 0, bass: 0, intervals: []int{0, 4, 7, 11}},
+		{symbol: "CM7", root: 0, bass: 0, intervals: []int{0, 4, 7, 11}},
+		{symbol: "Cmaj", root: 0, bass: 0, intervals: []int{0, 4, 7}},
+		{symbol: "E7", root: 4, bass: 4, intervals: []int{0, 4, 7, 10}},
+		{symbol: "Bdim7", root: 11, bass: 11, intervals: []int{0, 3, 6, 9}},
+		{symbol: "D5", root: 2, bass: 2, intervals: []int{0, 7}},
+		{symbol: "C6/9", root: 0, bass: 0, intervals: []int{0, 2, 4, 7, 9}},
+		{symbol: "G7(b9)", root: 7, bass: 7, intervals: []int{0, 1, 4, 7, 10}},
+		{symbol: "AmM7", root: 9, bass: 9, intervals: []int{0, 3, 7, 11}},
+		{symbol: "Eb/G", root: 3, bass: 7, intervals: []int{0, 4, 7}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.symbol, func(t *testing.T) {
+			// when
+			chord, err := chordtheory.Parse(tt.symbol)
+
+			// then
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if chord.Root != tt.root {
+				t.Errorf("root: got %d want %d", chord.Root, tt.root)
+			}
+			if chord.Bass != tt.bass {
+				t.Errorf("bass: got %d want %d", chord.Bass, tt.bass)
+			}
+			if !reflect.DeepEqual(chord.Intervals, tt.intervals) {
+				t.Errorf("intervals: got %v want %v", chord.Intervals, tt.intervals)
+			}
+		})
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	for _, symbol := range []string{"", "H", "Cxyz", "7"} {
+		if _, err := chordtheory.Parse(symbol); !errors.Is(err, chordtheory.ErrInvalidSymbol) {
+			t.Errorf("%q: expected ErrInvalidSymbol, got %v", symbol, err)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package chordtheory
+
+import (
+	"regexp"
+	"strings"
+)
+
+// StandardUkulele is re-entrant G4 C4 E4 A4.
+var StandardUkulele = Tuning{67, 60, 64, 69}
+
+// StandardMandolin is G3 D4 A4 E5, one note per course.
+var StandardMandolin = Tuning{55, 62, 69, 76}
+
+// StandardBass is E1 A1 D2 G2.
+var StandardBass = Tuning{28, 33, 38, 43}
+
+const (
+	pianoChordOctave = 60 // C4
+	pianoBassOctave  = 48 // C3
+)
+
+// BassDyad reduces the chord to its bass note and fifth, the shape bass players read
+// from a chord chart. Chords without a fifth reduce to the bass note alone.
+func (c Chord) BassDyad() Chord {
+	dyad := Chord{Symbol: c.Symbol, Root: c.Bass, Bass: c.Bass, Intervals: []int{0}}
+	if c.HasSlashBass() {
+		return dyad
+	}
+	for _, interval := range c.Intervals {
+		if interval == intervalFlatFifth || interval == intervalFifth || interval == intervalSharpFifth {
+			dyad.Intervals = append(dyad.Intervals, interval)
+			break
+		}
+	}
+	return dyad
+}
+
+// PianoKeys returns MIDI note numbers for a close root-position voicing starting at
+// middle C's octave. A slash bass is added an octave below.
+func PianoKeys(c Chord) []int {
+	keys := make([]int, 0, len(c.Intervals)+1)
+	if c.HasSlashBass() {
+		keys = append(keys, pianoBassOctave+c.Bass)
+	}
+	root := pianoChordOctave + c.Root
+	for _, interval := range c.Intervals {
+		keys = append(keys, root+interval)
+	}
+	return keys
+}
+
+var chordTokenPattern = regexp.MustCompile(`\[([^\]]+)\]`)
+
+// ExtractSymbols returns the distinct chord symbols found in ChordPro-style
+// "[G]" markers, in order of first appearance. Section markers that do not
+// parse as chords are skipped.
+func ExtractSymbols(lyric string) []string {
+	seen := map[string]struct{}{}
+	symbols := make([]string, 0)
+	for _, match := range chordTokenPattern.FindAllStringSubmatch(lyric, -1) {
+		symbol := strings.TrimSpace(match[1])
+		if _, ok := seen[symbol]; ok {
+			continue
+		}
+		if _, err := Parse(symbol); err != nil {
+			continue
+		}
+		seen[symbol] = struct{}{}
+		symbols = append(symbols, symbol)
+	}
+	return symbols
+}
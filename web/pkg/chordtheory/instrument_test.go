@@ -0,0 +1,58 @@
+package chordtheory_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/lyricapp/lyric/web/pkg/chordtheory"
+)
+
+func TestPianoKeys(t *testing.T) {
+	chord, err := chordtheory.Parse("Am7/G")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+
+	keys := chordtheory.PianoKeys(chord)
+
+	if want := []int{55, 69, 72, 76, 79}; !reflect.DeepEqual(keys, want) {
+		t.Errorf("got %v want %v", keys, want)
+	}
+}
+
+func TestBassDyad(t *testing.T) {
+	chord, _ := chordtheory.Parse("Bdim")
+	if got := chord.BassDyad().Intervals; !reflect.DeepEqual(got, []int{0, 6}) {
+		t.Errorf("Bdim: got %v", got)
+	}
+
+	slash, _ := chordtheory.Parse("C/E")
+	dyad := slash.BassDyad()
+	if dyad.Root != 4 || !reflect.DeepEqual(dyad.Intervals, []int{0}) {
+		t.Errorf("C/E: got root %d intervals %v", dyad.Root, dyad.Intervals)
+	}
+}
+
+func TestVoicings_Ukulele(t *testing.T) {
+	chord, _ := chordtheory.Parse("C")
+	opts := chordtheory.VoicingOptions{MaxFret: 12, MaxSpan: 4, MinStrings: 4, Limit: 3}
+
+	voicings := chordtheory.Voicings(chord, chordtheory.StandardUkulele, opts)
+
+	if len(voicings) == 0 {
+		t.Fatalf("expected voicings, got none")
+	}
+	if want := []int{0, 0, 0, 3}; !reflect.DeepEqual(voicings[0].Frets, want) {
+		t.Errorf("expected %v first, got %v", want, voicings[0].Frets)
+	}
+}
+
+func TestExtractSymbols(t *testing.T) {
+	lyric := "Key:[G]\n[Verse]\n[C]Amazing [G]grace, how [C]sweet [D7/F#]the sound"
+
+	symbols := chordtheory.ExtractSymbols(lyric)
+
+	if want := []string{"G", "C", "D7/F#"}; !reflect.DeepEqual(symbols, want) {
+		t.Errorf("got %v want %v", symbols, want)
+	}
+}
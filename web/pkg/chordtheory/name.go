@@ -0,0 +1,163 @@
+package chordtheory
+
+import (
+	"sort"
+	"strings"
+)
+
+// noteNames spells each pitch class the way chord charts usually do: sharps for
+// C# and F#, flats for the remaining black keys.
+var noteNames = [12]string{"C", "C#", "D", "Eb", "E", "F", "F#", "G", "Ab", "A", "Bb", "B"}
+
+// alterations lists altered tensions in the order they are written after the chord number.
+var alterations = []struct {
+	interval int
+	label    string
+}{
+	{intervalFlatNinth, "b9"},
+	{intervalMinorThird, "#9"},
+	{intervalFlatFifth, "#11"},
+	{intervalSharpFifth, "b13"},
+}
+
+// NoteName returns the canonical spelling of a pitch class (C=0 … B=11).
+func NoteName(pitchClass int) string {
+	return noteNames[((pitchClass%12)+12)%12]
+}
+
+// Name returns the canonical chord symbol, so that "B♭maj7", "A#M7" and "BbΔ7"
+// all read "Bbmaj7".
+func (c Chord) Name() string {
+	var b strings.Builder
+	b.WriteString(NoteName(c.Root))
+	b.WriteString(c.Quality)
+	for i, extension := range c.Extensions {
+		// "mmaj7" is easy to misread, so the major seventh is set apart.
+		if i == 0 && c.Quality == "m" && strings.HasPrefix(extension, "maj") {
+			b.WriteString("(" + extension + ")")
+			continue
+		}
+		b.WriteString(extension)
+	}
+	if c.HasSlashBass() {
+		b.WriteString("/")
+		b.WriteString(NoteName(c.Bass))
+	}
+	return b.String()
+}
+
+// Normalize parses a chord symbol and returns its canonical name.
+func Normalize(symbol string) (string, error) {
+	chord, err := Parse(symbol)
+	if err != nil {
+		return "", err
+	}
+	return chord.Name(), nil
+}
+
+// spell folds the accumulated degrees into a canonical quality and extension list.
+// Spelling is lossless: parsing the result yields the same degrees.
+func (s chordShape) spell() (string, []string) {
+	remaining := make(map[int]struct{}, len(s.extras))
+	for extra := range s.extras {
+		remaining[extra] = struct{}{}
+	}
+	// A suspended degree doubled by an added tone is a single note.
+	delete(remaining, s.third)
+
+	has := func(interval int) bool {
+		_, ok := remaining[interval]
+		return ok
+	}
+
+	fifth, seventh := s.fifth, s.seventh
+	quality := ""
+	switch {
+	case s.third == intervalMinorThird && fifth == intervalFlatFifth && seventh == intervalMinorSeventh:
+		quality, fifth, seventh = "m7b5", intervalFifth, noInterval
+	case s.third == intervalMinorThird && fifth == intervalFlatFifth && seventh == intervalSixth:
+		quality, fifth, seventh = "dim7", intervalFifth, noInterval
+	case s.third == intervalMinorThird && fifth == intervalFlatFifth && seventh == noInterval:
+		quality, fifth = "dim", intervalFifth
+	case s.third == intervalMajorThird && fifth == intervalSharpFifth && seventh == noInterval:
+		quality, fifth = "aug", intervalFifth
+	case s.third == intervalMinorThird:
+		quality = "m"
+	case s.third == noInterval:
+		quality = "5"
+	}
+
+	// A diminished seventh left over once the third was suspended sounds as a sixth.
+	if seventh == intervalSixth {
+		remaining[intervalSixth] = struct{}{}
+		seventh = noInterval
+	}
+
+	extensions := make([]string, 0, 4)
+	switch {
+	case seventh != noInterval:
+		number := "7"
+		switch {
+		case has(intervalSecond) && has(intervalSixth):
+			number = "13"
+			delete(remaining, intervalSecond)
+			delete(remaining, intervalSixth)
+		case has(intervalSecond) && has(intervalFourth):
+			number = "11"
+			delete(remaining, intervalSecond)
+			delete(remaining, intervalFourth)
+		case has(intervalSecond):
+			number = "9"
+			delete(remaining, intervalSecond)
+		}
+		if seventh == intervalMajorSeventh {
+			number = "maj" + number
+		}
+		extensions = append(extensions, number)
+	case has(intervalSixth) && has(intervalSecond):
+		extensions = append(extensions, "6/9")
+		delete(remaining, intervalSixth)
+		delete(remaining, intervalSecond)
+	case has(intervalSixth):
+		extensions = append(extensions, "6")
+		delete(remaining, intervalSixth)
+	}
+
+	switch s.third {
+	case intervalSecond:
+		extensions = append(extensions, "sus2")
+	case intervalFourth:
+		extensions = append(extensions, "sus4")
+	}
+
+	switch fifth {
+	case intervalFlatFifth:
+		extensions = append(extensions, "b5")
+	case intervalSharpFifth:
+		extensions = append(extensions, "#5")
+	}
+	for _, alteration := range alterations {
+		if has(alteration.interval) {
+			extensions = append(extensions, alteration.label)
+			delete(remaining, alteration.interval)
+		}
+	}
+
+	added := make([]int, 0, len(remaining))
+	for interval := range remaining {
+		added = append(added, interval)
+	}
+	sort.Ints(added)
+	for _, interval := range added {
+		switch interval {
+		case intervalSecond:
+			extensions = append(extensions, "add9")
+		case intervalFourth:
+			extensions = append(extensions, "add11")
+		case intervalSixth:
+			extensions = append(extensions, "add13")
+		}
+	}
+
+	return quality, extensions
+}
@@ -0,0 +1,90 @@
+package chordtheory_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/lyricapp/lyric/web/pkg/chordtheory"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		symbol string
+		want   string
+	}{
+		{symbol: "C", want: "C"},
+		{symbol: "Bb", want: "Bb"},
+		{symbol: "B♭", want: "Bb"},
+		{symbol: "A#", want: "Bb"},
+		{symbol: "Gb", want: "F#"},
+		{symbol: "Cmaj7", want: "Cmaj7"},
+		{symbol: "CM7", want: "Cmaj7"},
+		{symbol: "CΔ7", want: "Cmaj7"},
+		{symbol: "CΔ", want: "Cmaj7"},
+		{symbol: "CM9", want: "Cmaj9"},
+		{symbol: "Amin7", want: "Am7"},
+		{symbol: "A-7", want: "Am7"},
+		{symbol: "AmM7", want: "Am(maj7)"},
+		{symbol: "CmMaj7", want: "Cm(maj7)"},
+		{symbol: "Cm(maj7)", want: "Cm(maj7)"},
+		{symbol: "CmMaj9", want: "Cm(maj9)"},
+		{symbol: "CMaj7", want: "Cmaj7"},
+		{symbol: "Bø", want: "Bm7b5"},
+		{symbol: "Bm7b5", want: "Bm7b5"},
+		{symbol: "B°7", want: "Bdim7"},
+		{symbol: "C°", want: "Cdim"},
+		{symbol: "C+", want: "Caug"},
+		{symbol: "C7#5", want: "C7#5"},
+		{symbol: "Gsus", want: "Gsus4"},
+		{symbol: "G7sus4", want: "G7sus4"},
+		{symbol: "Gsus2/B", want: "Gsus2/B"},
+		{symbol: "C2", want: "Cadd9"},
+		{symbol: "C7add9", want: "C9"},
+		{symbol: "C69", want: "C6/9"},
+		{symbol: "G7(b9)", want: "G7b9"},
+		{symbol: "D5", want: "D5"},
+		{symbol: "D#m/F#", want: "Ebm/F#"},
+		{symbol: " am7 ", want: "Am7"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.symbol, func(t *testing.T) {
+			// when
+			got, err := chordtheory.Normalize(tt.symbol)
+
+			// then
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalize_RoundTrip(t *testing.T) {
+	symbols := []string{"C13", "C11", "Cmaj7#11", "Cm6/9", "C7add13", "Fm(maj9)", "Cadd11", "C7b5", "Caug/E", "Bdim7/D"}
+
+	for _, symbol := range symbols {
+		// given
+		original, err := chordtheory.Parse(symbol)
+		if err != nil {
+			t.Fatalf("%q: expected no error, got %v", symbol, err)
+		}
+
+		// when
+		reparsed, err := chordtheory.Parse(original.Name())
+
+		// then
+		if err != nil {
+			t.Fatalf("%q: canonical name %q did not parse: %v", symbol, original.Name(), err)
+		}
+		if reparsed.Name() != original.Name() {
+			t.Errorf("%q: name changed from %q to %q", symbol, original.Name(), reparsed.Name())
+		}
+		if !reflect.DeepEqual(reparsed.Intervals, original.Intervals) || reparsed.Bass != original.Bass {
+			t.Errorf("%q: %q sounds different from the original", symbol, original.Name())
+		}
+	}
+}
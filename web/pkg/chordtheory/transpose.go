@@ -0,0 +1,41 @@
+package chordtheory
+
+import "strings"
+
+// Transpose shifts a chord symbol by the given number of semitones. Only the root
+// and slash bass are respelled; the quality keeps the way it was written, so
+// "F#m7/C#" up one reads "Gm7/D".
+func Transpose(symbol string, semitones int) (string, error) {
+	trimmed := strings.TrimSpace(symbol)
+	if _, err := Parse(trimmed); err != nil {
+		return "", err
+	}
+	if semitones%12 == 0 {
+		return trimmed, nil
+	}
+
+	root, rest, _ := parseNote(trimmed)
+	bass := ""
+	if idx := strings.LastIndex(rest, "/"); idx >= 0 {
+		if pc, remainder, err := parseNote(rest[idx+1:]); err == nil && remainder == "" {
+			bass = "/" + NoteName(pc+semitones)
+			rest = rest[:idx]
+		}
+	}
+	return NoteName(root+semitones) + rest + bass, nil
+}
+
+// TransposeLyric shifts every ChordPro "[G]" marker in the lyric. Markers that are
+// not chords, such as "[Chorus]", are left untouched.
+func TransposeLyric(lyric string, semitones int) string {
+	if semitones%12 == 0 {
+		return lyric
+	}
+	return chordTokenPattern.ReplaceAllStringFunc(lyric, func(token string) string {
+		transposed, err := Transpose(token[1:len(token)-1], semitones)
+		if err != nil {
+			return token
+		}
+		return "[" + transposed + "]"
+	})
+}
@@ -0,0 +1,44 @@
+package chordtheory
+
+import "testing"
+
+func TestTranspose(t *testing.T) {
+	testCases := []struct {
+		symbol    string
+		semitones int
+		expected  string
+	}{
+		{"G", 2, "A"},
+		{"F#m7/C#", 1, "Gm7/D"},
+		{"Bbmaj7", -3, "Gmaj7"},
+		{"B♭M7", 1, "BM7"},
+		{"Csus4", 12, "Csus4"},
+		{"E", -4, "C"},
+		{"A", 1, "Bb"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.symbol, func(t *testing.T) {
+			got, err := Transpose(tc.symbol, tc.semitones)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tc.expected {
+				t.Errorf("Transpose(%q, %d) = %q, want %q", tc.symbol, tc.semitones, got, tc.expected)
+			}
+		})
+	}
+
+	if _, err := Transpose("Chorus", 2); err == nil {
+		t.Error("expected an error for a non-chord symbol")
+	}
+}
+
+func TestTransposeLyric(t *testing.T) {
+	lyric := "[Chorus]\n[G]Amazing [D/F#]grace"
+	got := TransposeLyric(lyric, 2)
+	expected := "[Chorus]\n[A]Amazing [E/Ab]grace"
+	if got != expected {
+		t.Errorf("TransposeLyric() = %q, want %q", got, expected)
+	}
+}
@@ -0,0 +1,345 @@
+package chordtheory
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	mutedString = -1
+	maxFingers  = 4
+)
+
+// Tuning lists open-string MIDI note numbers from the lowest string to the highest.
+type Tuning []int
+
+// StandardGuitar is E2 A2 D3 G3 B3 E4.
+var StandardGuitar = Tuning{40, 45, 50, 55, 59, 64}
+
+// VoicingOptions bounds the search space of the generator.
+type VoicingOptions struct {
+	// MaxFret is the highest fret considered.
+	MaxFret int
+	// MaxSpan is the widest stretch, in frets, between the lowest and highest fretted notes.
+	MaxSpan int
+	// MinStrings is the minimum number of sounding strings.
+	MinStrings int
+	// Limit caps the number of voicings returned; zero returns all.
+	Limit int
+}
+
+// DefaultVoicingOptions returns sensible bounds for a six-string guitar.
+func DefaultVoicingOptions() VoicingOptions {
+	return VoicingOptions{
+		MaxFret:    12,
+		MaxSpan:    4,
+		MinStrings: 4,
+		Limit:      5,
+	}
+}
+
+// Voicing is a playable fingering with absolute fret numbers per string.
+// Muted strings use -1 in Frets and nil in Fingers; open strings use 0 and nil.
+type Voicing struct {
+	BaseFret int
+	Frets    []int
+	Fingers  []*int
+	score    int
+	// inverted marks a voicing with a tone other than the root in the bass.
+	inverted bool
+}
+
+// Voicings generates playable voicings for the chord on the tuning, best first.
+// Voicings with the root in the bass always rank ahead of inversions; within each
+// group candidates are ranked by fret span, finger count and how close the shape
+// sits to the nut.
+func Voicings(chord Chord, tuning Tuning, opts VoicingOptions) []Voicing {
+	if len(tuning) == 0 {
+		return nil
+	}
+	defaults := DefaultVoicingOptions()
+	if opts.MaxFret <= 0 {
+		opts.MaxFret = defaults.MaxFret
+	}
+	if opts.MaxSpan <= 0 {
+		opts.MaxSpan = defaults.MaxSpan
+	}
+	if opts.MinStrings <= 0 || opts.MinStrings > len(tuning) {
+		opts.MinStrings = min(defaults.MinStrings, len(tuning))
+	}
+
+	pitchClasses := chord.PitchClasses()
+	allowed := make(map[int]struct{}, len(pitchClasses))
+	for _, pc := range pitchClasses {
+		allowed[pc] = struct{}{}
+	}
+	required := requiredPitchClasses(chord, pitchClasses)
+
+	seen := map[string]struct{}{}
+	voicings := make([]Voicing, 0)
+
+	for start := 1; start <= opts.MaxFret; start++ {
+		end := min(start+opts.MaxSpan-1, opts.MaxFret)
+
+		candidates := make([][]int, len(tuning))
+		for i, open := range tuning {
+			options := []int{mutedString}
+			if _, ok := allowed[open%12]; ok {
+				options = append(options, 0)
+			}
+			for fret := start; fret <= end; fret++ {
+				if _, ok := allowed[(open+fret)%12]; ok {
+					options = append(options, fret)
+				}
+			}
+			candidates[i] = options
+		}
+
+		frets := make([]int, len(tuning))
+		var walk func(stringIdx int)
+		walk = func(stringIdx int) {
+			if stringIdx == len(tuning) {
+				voicing, ok := evaluate(chord, tuning, frets, required, opts)
+				if !ok {
+					return
+				}
+				key := voicingKey(voicing.Frets)
+				if _, dup := seen[key]; dup {
+					return
+				}
+				seen[key] = struct{}{}
+				voicings = append(voicings, voicing)
+				return
+			}
+			for _, fret := range candidates[stringIdx] {
+				frets[stringIdx] = fret
+				walk(stringIdx + 1)
+			}
+		}
+		walk(0)
+	}
+
+	sort.SliceStable(voicings, func(i, j int) bool {
+		if voicings[i].inverted != voicings[j].inverted {
+			return !voicings[i].inverted
+		}
+		if voicings[i].score != voicings[j].score {
+			return voicings[i].score < voicings[j].score
+		}
+		return voicingKey(voicings[i].Frets) < voicingKey(voicings[j].Frets)
+	})
+
+	if opts.Limit > 0 && len(voicings) > opts.Limit {
+		voicings = voicings[:opts.Limit]
+	}
+	return voicings
+}
+
+// requiredPitchClasses drops tones that are conventionally omitted on fretted instruments:
+// the perfect fifth once the chord has four or more tones, and the ninth on six-tone chords.
+func requiredPitchClasses(chord Chord, pitchClasses []int) map[int]struct{} {
+	required := make(map[int]struct{}, len(pitchClasses))
+	for _, pc := range pitchClasses {
+		required[pc] = struct{}{}
+	}
+
+	hasInterval := func(interval int) bool {
+		for _, value := range chord.Intervals {
+			if value == interval {
+				return true
+			}
+		}
+		return false
+	}
+
+	if len(pitchClasses) >= 4 && hasInterval(intervalFifth) {
+		delete(required, (chord.Root+intervalFifth)%12)
+	}
+	if len(pitchClasses) >= 6 && hasInterval(intervalSecond) {
+		delete(required, (chord.Root+intervalSecond)%12)
+	}
+	return required
+}
+
+// evaluate checks a fret combination for playability and scores it.
+func evaluate(chord Chord, tuning Tuning, frets []int, required map[int]struct{}, opts VoicingOptions) (Voicing, bool) {
+	lowest, highest := -1, -1
+	for i, fret := range frets {
+		if fret == mutedString {
+			continue
+		}
+		if lowest == -1 {
+			lowest = i
+		}
+		highest = i
+	}
+	if lowest == -1 {
+		return Voicing{}, false
+	}
+
+	// Sounding strings must be contiguous so the shape can be strummed.
+	sounding := 0
+	for i := lowest; i <= highest; i++ {
+		if frets[i] == mutedString {
+			return Voicing{}, false
+		}
+		sounding++
+	}
+	if sounding < opts.MinStrings {
+		return Voicing{}, false
+	}
+
+	present := map[int]struct{}{}
+	minFret, maxFret := 0, 0
+	open := 0
+	for i := lowest; i <= highest; i++ {
+		fret := frets[i]
+		present[(tuning[i]+fret)%12] = struct{}{}
+		if fret == 0 {
+			open++
+			continue
+		}
+		if minFret == 0 || fret < minFret {
+			minFret = fret
+		}
+		if fret > maxFret {
+			maxFret = fret
+		}
+	}
+	for pc := range required {
+		if _, ok := present[pc]; !ok {
+			return Voicing{}, false
+		}
+	}
+
+	// The bass is the lowest sounding pitch, which on re-entrant tunings such as
+	// the ukulele is not necessarily on the lowest string.
+	bassPitch := tuning[lowest] + frets[lowest]
+	for i := lowest + 1; i <= highest; i++ {
+		bassPitch = min(bassPitch, tuning[i]+frets[i])
+	}
+	bass := bassPitch % 12
+	if chord.HasSlashBass() && bass != chord.Bass {
+		return Voicing{}, false
+	}
+
+	fingers, fingerCount, ok := assignFingers(frets, minFret)
+	if !ok {
+		return Voicing{}, false
+	}
+
+	span := 0
+	if minFret > 0 {
+		span = maxFret - minFret
+	}
+	if span >= opts.MaxSpan {
+		return Voicing{}, false
+	}
+
+	score := span*3 + fingerCount*2 + minFret + (len(tuning) - sounding)
+	// Open strings help near the nut but make shapes awkward further up the neck.
+	if minFret > opts.MaxSpan {
+		score += open
+	} else {
+		score -= open
+	}
+
+	baseFret := 1
+	if maxFret > opts.MaxSpan {
+		baseFret = minFret
+	}
+
+	copied := make([]int, len(frets))
+	copy(copied, frets)
+
+	return Voicing{
+		BaseFret: baseFret,
+		Frets:    copied,
+		Fingers:  fingers,
+		score:    score,
+		inverted: bass != chord.Root && !chord.HasSlashBass(),
+	}, true
+}
+
+// assignFingers maps fretted notes to fingers 1-4, using a first-finger barre when the
+// lowest fret repeats across strings with no open string in between.
+func assignFingers(frets []int, minFret int) ([]*int, int, bool) {
+	fingers := make([]*int, len(frets))
+	if minFret == 0 {
+		return fingers, 0, true
+	}
+
+	barreFrom, barreTo := -1, -1
+	for i, fret := range frets {
+		if fret == minFret {
+			if barreFrom == -1 {
+				barreFrom = i
+			}
+			barreTo = i
+		}
+	}
+	barre := barreTo > barreFrom
+	for i := barreFrom; barre && i <= barreTo; i++ {
+		if frets[i] <= 0 {
+			barre = false
+		}
+	}
+
+	type note struct {
+		stringIdx int
+		fret      int
+	}
+	notes := make([]note, 0, len(frets))
+	for i, fret := range frets {
+		if fret <= 0 {
+			continue
+		}
+		if barre && fret == minFret {
+			continue
+		}
+		notes = append(notes, note{stringIdx: i, fret: fret})
+	}
+	sort.SliceStable(notes, func(i, j int) bool {
+		if notes[i].fret != notes[j].fret {
+			return notes[i].fret < notes[j].fret
+		}
+		return notes[i].stringIdx < notes[j].stringIdx
+	})
+
+	next := 1
+	count := 0
+	if barre {
+		for i := barreFrom; i <= barreTo; i++ {
+			if frets[i] == minFret {
+				fingers[i] = intPtr(1)
+			}
+		}
+		next = 2
+		count = 1
+	}
+
+	for _, n := range notes {
+		finger := max(next, 1+n.fret-minFret)
+		if finger > maxFingers {
+			return nil, 0, false
+		}
+		fingers[n.stringIdx] = intPtr(finger)
+		next = finger + 1
+		count++
+	}
+
+	return fingers, count, true
+}
+
+func voicingKey(frets []int) string {
+	parts := make([]string, len(frets))
+	for i, fret := range frets {
+		parts[i] = fmt.Sprint(fret)
+	}
+	return strings.Join(parts, ",")
+}
+
+func intPtr(value int) *int {
+	return &value
+}
@@ -0,0 +1,117 @@
+package chordtheory_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/lyricapp/lyric/web/pkg/chordtheory"
+)
+
+func TestVoicings_OpenChordRanksFirst(t *testing.T) {
+	// given
+	chord, err := chordtheory.Parse("C")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+
+	// when
+	voicings := chordtheory.Voicings(chord, chordtheory.StandardGuitar, chordtheory.DefaultVoicingOptions())
+
+	// then
+	if len(voicings) == 0 {
+		t.Fatalf("expected voicings, got none")
+	}
+	if want := []int{-1, 3, 2, 0, 1, 0}; !reflect.DeepEqual(voicings[0].Frets, want) {
+		t.Errorf("expected %v first, got %v", want, voicings[0].Frets)
+	}
+	if voicings[0].BaseFret != 1 {
+		t.Errorf("expected base fret 1, got %d", voicings[0].BaseFret)
+	}
+}
+
+func TestVoicings_Playable(t *testing.T) {
+	opts := chordtheory.DefaultVoicingOptions()
+
+	for _, symbol := range []string{"Gsus2/B", "F#m7b5", "Bb", "C13", "Bdim7"} {
+		t.Run(symbol, func(t *testing.T) {
+			chord, err := chordtheory.Parse(symbol)
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+
+			voicings := chordtheory.Voicings(chord, chordtheory.StandardGuitar, opts)
+			if len(voicings) == 0 {
+				t.Fatalf("expected voicings, got none")
+			}
+			if len(voicings) > opts.Limit {
+				t.Fatalf("expected at most %d voicings, got %d", opts.Limit, len(voicings))
+			}
+
+			for _, v := range voicings {
+				minFret, maxFret, fingers := 0, 0, map[int]struct{}{}
+				lowest := -1
+				for i, fret := range v.Frets {
+					if fret < 0 {
+						continue
+					}
+					if lowest == -1 {
+						lowest = i
+					}
+					if fret == 0 {
+						continue
+					}
+					if minFret == 0 || fret < minFret {
+						minFret = fret
+					}
+					maxFret = max(maxFret, fret)
+					if v.Fingers[i] == nil {
+						t.Fatalf("%v: fretted string %d has no finger", v.Frets, i)
+					}
+					fingers[*v.Fingers[i]] = struct{}{}
+				}
+				if maxFret-minFret >= opts.MaxSpan {
+					t.Errorf("%v: span too wide", v.Frets)
+				}
+				if len(fingers) > 4 {
+					t.Errorf("%v: needs %d fingers", v.Frets, len(fingers))
+				}
+				if chord.HasSlashBass() {
+					bass := (chordtheory.StandardGuitar[lowest] + v.Frets[lowest]) % 12
+					if bass != chord.Bass {
+						t.Errorf("%v: expected bass %d, got %d", v.Frets, chord.Bass, bass)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestVoicings_RootInBassRanksFirst(t *testing.T) {
+	for _, symbol := range []string{"Bbmaj7", "Cmaj7", "Fmaj7", "Am7", "Dm7", "Ebm7"} {
+		t.Run(symbol, func(t *testing.T) {
+			// given
+			chord, err := chordtheory.Parse(symbol)
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+
+			// when
+			voicings := chordtheory.Voicings(chord, chordtheory.StandardGuitar, chordtheory.DefaultVoicingOptions())
+
+			// then
+			if len(voicings) == 0 {
+				t.Fatalf("expected voicings, got none")
+			}
+			top := voicings[0]
+			for i, fret := range top.Frets {
+				if fret < 0 {
+					continue
+				}
+				if bass := (chordtheory.StandardGuitar[i] + fret) % 12; bass != chord.Root {
+					t.Errorf("%v: expected root %d in the bass, got %d", top.Frets, chord.Root, bass)
+				}
+				break
+			}
+		})
+	}
+}